@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// stripAccents reads accented letters as the letters under the accents, so é counts as E, when
+// scanning text for ngrams and words. Otherwise letters outside the active alphabet are skipped like punctuation
+var stripAccents bool
+
+// letters that don't break down into a plain letter and accents, so they're spelled out
+var accentlessSpellings = map[rune]string{
+	'ß': "SS", 'ẞ': "SS",
+	'Æ': "AE", 'æ': "AE",
+	'Œ': "OE", 'œ': "OE",
+	'Ø': "O", 'ø': "O",
+	'Ł': "L", 'ł': "L",
+	'Đ': "D", 'đ': "D",
+	'Ð': "D", 'ð': "D",
+	'Þ': "TH", 'þ': "TH",
+	'ı': "I",
+}
+
+// appendFoldedRune appends the alphabet symbols r is read as to symbols: r itself, folded, if it's in
+// the active alphabet, its letters without their accents if stripAccents is set, and otherwise nothing
+func appendFoldedRune(symbols []byte, r rune) []byte {
+	if r < utf8.RuneSelf {
+		if activeAlphabet.accepts(byte(r)) {
+			symbols = append(symbols, activeAlphabet.fold(byte(r)))
+		}
+		return symbols
+	}
+	if !stripAccents {
+		return symbols
+	}
+
+	for _, curByte := range []byte(withoutAccents(r)) {
+		if activeAlphabet.accepts(curByte) {
+			symbols = append(symbols, activeAlphabet.fold(curByte))
+		}
+	}
+	return symbols
+}
+
+// withoutAccents spells r in ASCII, such as E for é or SS for ß. Runes with nothing in ASCII under them,
+// such as Greek letters or dashes, come back empty
+func withoutAccents(r rune) string {
+	if spelling, exists := accentlessSpellings[r]; exists {
+		return spelling
+	}
+	// compatibility decomposition splits off the accents and also undoes ligatures and full-width forms
+	spelling := []byte(norm.NFKD.String(string(r)))
+	ascii := spelling[:0]
+	for _, curByte := range spelling {
+		// the accents themselves are outside ASCII, so they drop out here
+		if curByte < utf8.RuneSelf {
+			ascii = append(ascii, curByte)
+		}
+	}
+	return string(ascii)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// An alphabet is the set of plaintext symbols a puzzle is written in. Most puzzles use A-Z, but some
+// merge letters (I and J in a 25-letter Playfair-style square, or I/J and U/V for 24 letters) or add
+// the digits for 36 symbols. Letters that have been merged away are folded into the letter they share
+// a slot with, so JUMP is read as IUMP in a 25-letter alphabet.
+type alphabet struct {
+	*symbolRing
+	folds map[byte]byte
+}
+
+var standardAlphabet = mustAlphabet(upperAlphabet, nil)
+
+// activeAlphabet is the alphabet the solvers work in, set by --alphabet-size
+var activeAlphabet = standardAlphabet
+
+// newAlphabet creates an alphabet out of symbols. folds maps symbols that aren't in the alphabet
+// to the one they should be read as
+func newAlphabet(symbols string, folds map[byte]byte) (*alphabet, error) {
+	ring, err := newSymbolRing(symbols)
+	if err != nil {
+		return nil, err
+	}
+	for from, to := range folds {
+		if ring.contains(from) || !ring.contains(to) {
+			return nil, fmt.Errorf("can't fold %c into %c in %s", from, to, symbols)
+		}
+	}
+	return &alphabet{ring, folds}, nil
+}
+
+func mustAlphabet(symbols string, folds map[byte]byte) *alphabet {
+	alphabet, err := newAlphabet(symbols, folds)
+	if err != nil {
+		panic(err)
+	}
+	return alphabet
+}
+
+// alphabetForSize returns one of the alphabets puzzles commonly use: 24 (I/J and U/V merged),
+// 25 (I/J merged), 26 (A-Z), or 36 (A-Z then 0-9)
+func alphabetForSize(size int) (*alphabet, error) {
+	switch size {
+	case 24:
+		return newAlphabet(strings.NewReplacer("J", "", "V", "").Replace(upperAlphabet), map[byte]byte{'J': 'I', 'V': 'U'})
+	case 25:
+		return newAlphabet(strings.Replace(upperAlphabet, "J", "", 1), map[byte]byte{'J': 'I'})
+	case 26:
+		return standardAlphabet, nil
+	case 36:
+		return newAlphabet(upperAlphabet+digitAlphabet, nil)
+	}
+	return nil, fmt.Errorf("no %d-symbol alphabet; use 24, 25, 26, or 36", size)
+}
+
+// fold uppercases symbol and replaces it with the symbol it's merged into, if any
+func (alphabet *alphabet) fold(symbol byte) byte {
+	symbol = upperCaseByte(symbol)
+	if folded, exists := alphabet.folds[symbol]; exists {
+		return folded
+	}
+	return symbol
+}
+
+// foldString folds every symbol in input, leaving anything outside the alphabet as is
+func (alphabet *alphabet) foldString(input string) string {
+	folded := []byte(input)
+	for index, symbol := range folded {
+		folded[index] = alphabet.fold(symbol)
+	}
+	return string(folded)
+}
+
+// letters returns the symbols of the alphabet in order as one-character strings
+func (alphabet *alphabet) letters() []string {
+	letters := make([]string, 0, alphabet.size())
+	for _, symbol := range alphabet.symbols {
+		letters = append(letters, string(symbol))
+	}
+	return letters
+}
+
+// accepts reports whether symbol is in the alphabet once it's been folded, so j is accepted by a 25-letter alphabet
+func (alphabet *alphabet) accepts(symbol byte) bool {
+	return alphabet.contains(alphabet.fold(symbol))
+}
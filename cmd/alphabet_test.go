@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+// withAlphabet runs check with activeAlphabet set to the alphabet of the given size
+func withAlphabet(test *testing.T, size int, check func()) {
+	alphabet, err := alphabetForSize(size)
+	if err != nil {
+		test.Fatalf("Expected a %d-symbol alphabet but got %v", size, err)
+	}
+	previous := activeAlphabet
+	activeAlphabet = alphabet
+	defer func() { activeAlphabet = previous }()
+	check()
+}
+
+func TestAlphabetForSize(test *testing.T) {
+	tests := map[int]string{
+		24: "ABCDEFGHIKLMNOPQRSTUWXYZ",
+		25: "ABCDEFGHIKLMNOPQRSTUVWXYZ",
+		26: upperAlphabet,
+		36: upperAlphabet + digitAlphabet,
+	}
+	for size, expected := range tests {
+		alphabet, err := alphabetForSize(size)
+		if err != nil {
+			test.Errorf("Expected a %d-symbol alphabet but got %v", size, err)
+			continue
+		}
+		if alphabet.size() != size || strings.Join(alphabet.letters(), "") != expected {
+			test.Errorf("Expected %s for size %d but got %v", expected, size, alphabet.letters())
+		}
+	}
+
+	if _, err := alphabetForSize(27); err == nil {
+		test.Errorf("Expected an error for a 27-symbol alphabet")
+	}
+}
+
+func TestAlphabetFold(test *testing.T) {
+	withAlphabet(test, 24, func() {
+		if activeAlphabet.foldString("Jive, 42!") != "IIUE, 42!" {
+			test.Errorf("Expected IIUE, 42! but got %s", activeAlphabet.foldString("Jive, 42!"))
+		}
+		if !activeAlphabet.accepts('j') || activeAlphabet.contains('J') || activeAlphabet.accepts('4') {
+			test.Errorf("Expected j to be accepted by folding, but not J directly or 4")
+		}
+	})
+
+	withAlphabet(test, 36, func() {
+		if !activeAlphabet.accepts('4') {
+			test.Errorf("Expected digits to be accepted by the 36-symbol alphabet")
+		}
+	})
+}
+
+func TestAlphabetThroughSolvers(test *testing.T) {
+	withAlphabet(test, 25, func() {
+		key, err := parseHillclimbKey("ZYXWVUTSRQPONMLKIHGFEDCBA")
+		if err != nil {
+			test.Errorf("Expected a 25-letter key to parse but got %v", err)
+		}
+		// K is the tenth letter of the 25-letter alphabet, so it deciphers to Q
+		if decipherStringFromKey("AK", key) != "ZQ" {
+			test.Errorf("Expected ZQ but got %s", decipherStringFromKey("AK", key))
+		}
+		if _, err := parseHillclimbKey(upperAlphabet); err == nil {
+			test.Errorf("Expected a 26-letter key to be rejected by a 25-letter alphabet")
+		}
+
+		rings, _ := ringsForName("letters", "")
+		if len(rings) != 1 || rings[0].shift('I', 1) != 'K' {
+			test.Errorf("Expected the letters ring to skip J")
+		}
+	})
+
+	withAlphabet(test, 36, func() {
+		if substitutionPattern("A1B1") != "ABCB" {
+			test.Errorf("Expected digits to be part of the pattern but got %s", substitutionPattern("A1B1"))
+		}
+	})
+}
@@ -0,0 +1,117 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var substitutionKey string
+
+// applyKeyCmd represents the apply-key command
+var applyKeyCmd = &cobra.Command{
+	Use:   "apply-key",
+	Short: "Applies a known substitution key to ciphertext",
+	Long: `Deciphers the arguments using the key passed in with --key. The key can either be
+	a 26-letter string, where the first letter is the plaintext for ciphertext A, the second for B
+	and so on (use _ for unknown letters), or a comma-separated list of mappings like A=e,B=t.
+
+	Solved letters are printed in lowercase and unsolved letters are left as uppercase ciphertext.
+	`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  applyKey,
+}
+
+func applyKey(cmd *cobra.Command, args []string) {
+	cipherToPlain, err := parseSubstitutionKey(substitutionKey)
+	if err != nil {
+		fmt.Printf("Invalid key: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(applySubstitutionKey(strings.ToUpper(strings.Join(args, " ")), cipherToPlain))
+}
+
+// parseSubstitutionKey turns either a 26-letter key or a list of A=b mappings into
+// a map of uppercase cipher bytes to lowercase plain bytes
+func parseSubstitutionKey(key string) (map[byte]byte, error) {
+	cipherToPlain := make(map[byte]byte)
+	if len(key) == 26 && !strings.Contains(key, "=") {
+		for index, plainByte := range []byte(strings.ToLower(key)) {
+			if plainByte == '_' {
+				continue
+			}
+			if !isLowercaseAscii(plainByte) {
+				return nil, fmt.Errorf("%c is not a letter", plainByte)
+			}
+			cipherToPlain[byte(ASCII_A+index)] = plainByte
+		}
+		return cipherToPlain, nil
+	}
+
+	for _, mapping := range strings.Split(key, ",") {
+		mapping = strings.TrimSpace(mapping)
+		if mapping == "" {
+			continue
+		}
+		if len(mapping) != 3 {
+			return nil, fmt.Errorf("%s is not of the form A=b", mapping)
+		}
+		mappingBytes := []byte(strings.ToUpper(mapping[0:1]) + "=" + strings.ToLower(mapping[2:3]))
+		if !substitutionCommand.Match(mappingBytes) || mappingBytes[2] == '_' {
+			return nil, fmt.Errorf("%s is not of the form A=b", mapping)
+		}
+		cipherToPlain[mappingBytes[0]] = mappingBytes[2]
+	}
+	return cipherToPlain, nil
+}
+
+// formatSubstitutionKey writes cipherToPlain as comma-separated A=b mappings in cipher letter order,
+// the way parseSubstitutionKey reads them
+func formatSubstitutionKey(cipherToPlain map[byte]byte) string {
+	mappings := make([]string, 0, len(cipherToPlain))
+	for cipherByte, plainByte := range cipherToPlain {
+		mappings = append(mappings, string([]byte{upperCaseByte(cipherByte), '=', lowerCaseByte(plainByte)}))
+	}
+	sort.Strings(mappings)
+	return strings.Join(mappings, ",")
+}
+
+// applySubstitutionKey deciphers cipherText using cipherToPlain, leaving any unmapped
+// bytes in place
+func applySubstitutionKey(cipherText string, cipherToPlain map[byte]byte) string {
+	plainText := strings.Builder{}
+	plainText.Grow(len(cipherText))
+	for _, cipherByte := range []byte(cipherText) {
+		plainByte, mapped := cipherToPlain[cipherByte]
+		if mapped {
+			plainText.WriteByte(plainByte)
+		} else {
+			plainText.WriteByte(cipherByte)
+		}
+	}
+	return plainText.String()
+}
+
+func init() {
+	applyKeyCmd.Flags().StringVarP(&substitutionKey, "key", "k", "", "a 26-letter key or comma-separated A=b mappings")
+	applyKeyCmd.MarkFlagRequired("key")
+	substitutionCmd.AddCommand(applyKeyCmd)
+}
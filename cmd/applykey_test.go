@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"testing"
+)
+
+type applyKeyTest struct {
+	key           string
+	cipherText    string
+	expected      string
+	errorExpected bool
+}
+
+func TestApplyKey(test *testing.T) {
+	tests := []applyKeyTest{
+		applyKeyTest{"bcdefghijklmnopqrstuvwxyza", "AB ZY", "bc az", false},
+		applyKeyTest{"b_________________________", "AB", "bB", false},
+		applyKeyTest{"A=h,B=i", "AB, CD", "hi, CD", false},
+		applyKeyTest{"a=h, b=I", "AB", "hi", false},
+		applyKeyTest{"A=hi", "AB", "", true},
+		applyKeyTest{"bcdefghijklmnopqrstuvwxy1a", "AB", "", true},
+	}
+
+	for index, testCase := range tests {
+		cipherToPlain, err := parseSubstitutionKey(testCase.key)
+		if testCase.errorExpected {
+			if err == nil {
+				test.Errorf("Test case %d: expected an error parsing %s", index, testCase.key)
+			}
+			continue
+		}
+		if err != nil {
+			test.Errorf("Test case %d: unexpected error %v", index, err)
+			continue
+		}
+
+		actual := applySubstitutionKey(testCase.cipherText, cipherToPlain)
+		if actual != testCase.expected {
+			test.Errorf("Test case %d: expected %s but got %s", index, testCase.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// aristocratCmd represents the aristocrat command
+var aristocratCmd = &cobra.Command{
+	Use:   "aristocrat TEXT...",
+	Short: "Points out the usual footholds in an aristocrat and suggests a starting key",
+	Long: `Lists the things a solver looks at first in an aristocrat (a substitution cipher that keeps its
+	word breaks): doubled letters, one-letter words, common two and three-letter words, and the letters
+	after apostrophes. From those it suggests some mappings to start with, such as the most common
+	three-letter word as THE, and prints them as a --key for substitution solve or hint.
+
+	Examples:
+	  puzzle_helper cryptogram aristocrat "GSV XZG WRWM'G HVV GSV WLT"`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  printAristocratAnalysis,
+}
+
+// the usual plaintext for each kind of foothold, most likely first
+const (
+	doubledLetterCandidates = "LL EE SS OO TT FF RR NN PP CC"
+	oneLetterCandidates     = "A I"
+	apostropheCandidates    = "'S 'T 'D 'M 'LL 'RE 'VE"
+)
+
+var commonTwoLetterWords = []string{"OF", "TO", "IN", "IT", "IS", "BE", "AS", "AT", "SO", "WE", "HE", "BY", "OR", "ON", "DO", "IF", "ME", "MY", "UP", "AN", "GO", "NO", "US", "AM"}
+
+var commonThreeLetterWords = []string{"THE", "AND", "FOR", "ARE", "BUT", "NOT", "YOU", "ALL", "ANY", "CAN", "HAD", "HER", "WAS", "ONE",
+	"OUR", "OUT", "DAY", "GET", "HAS", "HIM", "HIS", "HOW", "MAN", "NEW", "NOW", "OLD", "SEE", "TWO", "WAY", "WHO", "BOY", "DID", "ITS",
+	"LET", "PUT", "SAY", "SHE", "TOO", "USE"}
+
+// aristocratAnalysis is what stands out in an aristocrat's ciphertext. Each list is sorted from most to least common
+type aristocratAnalysis struct {
+	doubledLetters []ngramCount
+	oneLetter      []ngramCount
+	twoLetter      []ngramCount
+	threeLetter    []ngramCount
+	// the letters after apostrophes, such as 'X
+	apostrophes []ngramCount
+	suggestions []aristocratSuggestion
+}
+
+// aristocratSuggestion is a guess at the plaintext of a cipher word, adding at least one new mapping
+type aristocratSuggestion struct {
+	cipher string
+	plain  string
+	reason string
+}
+
+func printAristocratAnalysis(cmd *cobra.Command, args []string) {
+	analysis := analyzeAristocrat(strings.Join(args, " "))
+
+	// the footholds are for reading; the suggestions are the results
+	printer := newResultPrinter("cipher", "plain", "reason")
+	printFootholds(printer, "Doubled letters", doubledLetterCandidates, analysis.doubledLetters)
+	printFootholds(printer, "One-letter words", oneLetterCandidates, analysis.oneLetter)
+	printFootholds(printer, "Two-letter words", strings.Join(commonTwoLetterWords[:8], " "), analysis.twoLetter)
+	printFootholds(printer, "Three-letter words", strings.Join(commonThreeLetterWords[:8], " "), analysis.threeLetter)
+	printFootholds(printer, "After apostrophes", apostropheCandidates, analysis.apostrophes)
+
+	if len(analysis.suggestions) == 0 {
+		printer.text("No suggested mappings; there weren't enough short words to go on")
+		recordResults(0)
+		printer.finish(false)
+		return
+	}
+	printer.text("Suggested mappings:")
+	for _, suggestion := range analysis.suggestions {
+		printer.result(fmt.Sprintf("  %s=%s (%s)", suggestion.cipher, suggestion.plain, suggestion.reason), suggestion.cipher, suggestion.plain, suggestion.reason)
+	}
+	printer.text("Starting key: --key %s", suggestionKey(analysis.suggestions))
+	printer.finish(false)
+}
+
+// printFootholds prints one kind of foothold with its counts, and what it usually turns out to be
+func printFootholds(printer *resultPrinter, title string, candidates string, counts []ngramCount) {
+	if len(counts) == 0 {
+		return
+	}
+	printer.text("%s (usually %s):", title, candidates)
+	for _, count := range counts {
+		printer.text("  %s x%d", count.ngram, count.count)
+	}
+	printer.text("")
+}
+
+// analyzeAristocrat collects the footholds in text and the mappings they suggest
+func analyzeAristocrat(text string) aristocratAnalysis {
+	doubled := make(map[string]int)
+	byLength := map[int]map[string]int{1: {}, 2: {}, 3: {}}
+	apostrophes := make(map[string]int)
+
+	for _, token := range strings.Fields(strings.ToUpper(text)) {
+		word, suffix := splitApostrophe(token)
+		if suffix != "" {
+			apostrophes["'"+suffix]++
+		}
+		for index := 1; index < len(word); index++ {
+			if word[index] == word[index-1] {
+				doubled[word[index-1:index+1]]++
+			}
+		}
+		if suffix == "" {
+			if counts, short := byLength[len(word)]; short {
+				counts[word]++
+			}
+		}
+	}
+
+	analysis := aristocratAnalysis{
+		doubledLetters: sortedNgramCounts(doubled),
+		oneLetter:      sortedNgramCounts(byLength[1]),
+		twoLetter:      sortedNgramCounts(byLength[2]),
+		threeLetter:    sortedNgramCounts(byLength[3]),
+		apostrophes:    sortedNgramCounts(apostrophes),
+	}
+	analysis.suggestions = suggestAristocratMappings(analysis)
+	return analysis
+}
+
+// splitApostrophe splits a token into its letters before the first apostrophe and the letters after it
+func splitApostrophe(token string) (string, string) {
+	parts := strings.SplitN(token, "'", 2)
+	word := string(justUppercaseLetters(parts[0]))
+	if len(parts) == 1 {
+		return word, ""
+	}
+	return word, string(justUppercaseLetters(parts[1]))
+}
+
+// suggestAristocratMappings starts from the strongest footholds (the most common three-letter word as THE,
+// one-letter words as A and I, a doubled letter after an apostrophe as 'LL) and then fills in short words
+// that only one common word fits, given what's already mapped
+func suggestAristocratMappings(analysis aristocratAnalysis) []aristocratSuggestion {
+	key := newAristocratKey()
+	suggestions := make([]aristocratSuggestion, 0)
+	suggest := func(cipher, plain, reason string) {
+		if key.fits(cipher, plain) && key.adds(cipher) {
+			key.add(cipher, plain)
+			suggestions = append(suggestions, aristocratSuggestion{cipher, plain, reason})
+		}
+	}
+
+	for _, count := range analysis.threeLetter {
+		// THE has three different letters and should turn up more than once
+		if count.count > 1 && substitutionPattern(count.ngram) == "ABC" {
+			suggest(count.ngram, "THE", "the most common three-letter word")
+			break
+		}
+	}
+	// A is the more common, but when the counts tie there's no telling which is which
+	if oneLetter := analysis.oneLetter; len(oneLetter) == 1 || (len(oneLetter) > 1 && oneLetter[0].count > oneLetter[1].count) {
+		suggest(oneLetter[0].ngram, "A", "the most common one-letter word")
+		if len(oneLetter) > 1 {
+			suggest(oneLetter[1].ngram, "I", "the other one-letter word")
+		}
+	}
+	for _, count := range analysis.apostrophes {
+		if len(count.ngram) == 3 && count.ngram[1] == count.ngram[2] {
+			suggest(count.ngram[1:], "LL", "doubled letter after an apostrophe")
+		}
+	}
+
+	// each new mapping can narrow down more words, so keep going until nothing changes
+	words := append(append([]ngramCount{}, analysis.threeLetter...), analysis.twoLetter...)
+	sort.SliceStable(words, func(i, j int) bool {
+		return words[i].count > words[j].count
+	})
+	for added := true; added; {
+		added = false
+		for _, count := range words {
+			if !key.adds(count.ngram) || !key.knowsAny(count.ngram) {
+				continue
+			}
+			candidates := commonTwoLetterWords
+			if len(count.ngram) == 3 {
+				candidates = commonThreeLetterWords
+			}
+			fitting := make([]string, 0, 1)
+			for _, candidate := range candidates {
+				if key.fits(count.ngram, candidate) {
+					fitting = append(fitting, candidate)
+				}
+			}
+			if len(fitting) == 1 {
+				suggest(count.ngram, fitting[0], "the only common word that fits")
+				added = true
+			}
+		}
+	}
+	return suggestions
+}
+
+// aristocratKey is a partial key being built from suggestions, kept in both directions
+// so that no plain letter gets used for two cipher letters
+type aristocratKey struct {
+	cipherToPlain map[byte]byte
+	plainToCipher map[byte]byte
+}
+
+func newAristocratKey() *aristocratKey {
+	return &aristocratKey{make(map[byte]byte), make(map[byte]byte)}
+}
+
+// fits reports whether cipher could decode to plain without contradicting the key
+func (key *aristocratKey) fits(cipher, plain string) bool {
+	if len(cipher) != len(plain) || substitutionPattern(cipher) != substitutionPattern(plain) {
+		return false
+	}
+	for index := range []byte(cipher) {
+		if mapped, known := key.cipherToPlain[cipher[index]]; known && mapped != plain[index] {
+			return false
+		}
+		if mapped, known := key.plainToCipher[plain[index]]; known && mapped != cipher[index] {
+			return false
+		}
+	}
+	return true
+}
+
+// adds reports whether cipher has any letters the key doesn't know yet
+func (key *aristocratKey) adds(cipher string) bool {
+	for _, cipherByte := range []byte(cipher) {
+		if _, known := key.cipherToPlain[cipherByte]; !known {
+			return true
+		}
+	}
+	return false
+}
+
+// knowsAny reports whether the key knows any of cipher's letters
+func (key *aristocratKey) knowsAny(cipher string) bool {
+	for _, cipherByte := range []byte(cipher) {
+		if _, known := key.cipherToPlain[cipherByte]; known {
+			return true
+		}
+	}
+	return false
+}
+
+func (key *aristocratKey) add(cipher, plain string) {
+	for index := range []byte(cipher) {
+		key.cipherToPlain[cipher[index]] = plain[index]
+		key.plainToCipher[plain[index]] = cipher[index]
+	}
+}
+
+// suggestionKey writes the suggested mappings as comma-separated A=b mappings, in cipher letter order
+func suggestionKey(suggestions []aristocratSuggestion) string {
+	key := newAristocratKey()
+	for _, suggestion := range suggestions {
+		key.add(suggestion.cipher, suggestion.plain)
+	}
+	return formatSubstitutionKey(key.cipherToPlain)
+}
+
+// runAristocratSolver is the aristocrat command's suggested mappings for the solver registry
+func runAristocratSolver(ctx context.Context, input solverInput) (*resultTable, error) {
+	analysis := analyzeAristocrat(input.getString("text"))
+	table := newResultTable("cipher", "plain", "reason")
+	for _, suggestion := range analysis.suggestions {
+		table.addRow(suggestion.cipher, suggestion.plain, suggestion.reason)
+	}
+	return table, nil
+}
+
+func init() {
+	cryptogramCmd.AddCommand(aristocratCmd)
+
+	mustRegisterSolver(&solver{
+		name:        "aristocrat",
+		description: "Starting mappings for an aristocrat from its short words, doubled letters, and apostrophes",
+		parameters: []solverParameter{
+			solverParameter{name: "text", kind: solverString, description: "the ciphertext, with its word breaks", required: true},
+		},
+		run: runAristocratSolver,
+	})
+}
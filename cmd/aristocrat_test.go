@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func atbash(text string) string {
+	return strings.Map(func(letter rune) rune {
+		if letter >= 'A' && letter <= 'Z' {
+			return 'Z' - (letter - 'A')
+		}
+		return letter
+	}, text)
+}
+
+func TestAnalyzeAristocrat(test *testing.T) {
+	plain := "A CAT AND THE DOG DIDN'T SEE A TREE, BUT I THINK IT'LL BE FINE. THE DOG IS OUT OF THE HOUSE OF A FRIEND"
+	analysis := analyzeAristocrat(atbash(plain))
+
+	if analysis.threeLetter[0].ngram != atbash("THE") || analysis.threeLetter[0].count != 3 {
+		test.Errorf("Expected THE to be the most common three-letter word but got %v", analysis.threeLetter)
+	}
+	if len(analysis.oneLetter) != 2 || analysis.oneLetter[0].ngram != atbash("A") {
+		test.Errorf("Expected the one-letter words A then I but got %v", analysis.oneLetter)
+	}
+	if len(analysis.doubledLetters) != 1 || analysis.doubledLetters[0].ngram != atbash("EE") {
+		test.Errorf("Expected just the doubled EE but got %v", analysis.doubledLetters)
+	}
+	if len(analysis.apostrophes) != 2 {
+		test.Errorf("Expected 'T and 'LL after apostrophes but got %v", analysis.apostrophes)
+	}
+
+	// everything suggested should be right for this text
+	for _, suggestion := range analysis.suggestions {
+		if atbash(suggestion.cipher) != suggestion.plain {
+			test.Errorf("Expected %s to be suggested as %s but got %s", suggestion.cipher, atbash(suggestion.cipher), suggestion.plain)
+		}
+	}
+	expectedKey := "G=t,H=s,O=l,R=i,S=h,V=e,Z=a"
+	if key := suggestionKey(analysis.suggestions); key != expectedKey {
+		test.Errorf("Expected key %s but got %s", expectedKey, key)
+	}
+	if _, err := parseSubstitutionKey(suggestionKey(analysis.suggestions)); err != nil {
+		test.Errorf("Expected the key to be usable as --key but got %v", err)
+	}
+}
+
+func TestAristocratKeyFits(test *testing.T) {
+	key := newAristocratKey()
+	key.add("XYZ", "THE")
+
+	tests := []struct {
+		cipher   string
+		plain    string
+		expected bool
+	}{
+		{"XQ", "TO", true},
+		{"XQ", "HO", false},
+		{"QX", "TO", false},
+		{"QR", "IT", false},
+		{"QQ", "OF", false},
+		{"XYZ", "THE", true},
+	}
+	for _, testCase := range tests {
+		if actual := key.fits(testCase.cipher, testCase.plain); actual != testCase.expected {
+			test.Errorf("Expected fits(%s, %s) to be %v but got %v", testCase.cipher, testCase.plain, testCase.expected, actual)
+		}
+	}
+
+	// with nothing in common, ties between one-letter words shouldn't be guessed at
+	if suggestions := analyzeAristocrat("Q R").suggestions; len(suggestions) != 0 {
+		test.Errorf("Expected no suggestions for tied one-letter words but got %v", suggestions)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var boggleMinLength int
+var bogglePlainQ bool
+
+// boggleCmd represents the boggle command
+var boggleCmd = &cobra.Command{
+	Use:   "boggle [ROW...]",
+	Short: "Finds the words on a Boggle board",
+	Long: `Finds every dictionary word of at least --min-length letters (3 by default) that can be traced
+	through neighboring squares of the board, across, down, or diagonally, using each square once.
+	The board is given one argument per row, or on stdin a line at a time, and can be any size as long
+	as the rows are the same length. As on the real dice, a Q square stands for QU unless --plain-q
+	is given. Words are grouped from the longest down, with the points Boggle gives for each length.
+
+	Example:
+	  puzzle_helper boggle SERS PATG LINE SERS --dictionary words.txt`,
+	Run: printBoggleWords,
+}
+
+// boggleWord is a word found on the board
+type boggleWord struct {
+	word   string
+	points int
+}
+
+func printBoggleWords(cmd *cobra.Command, args []string) {
+	board, err := newBoggleBoard(gridRows(args, os.Stdin), !bogglePlainQ)
+	if err != nil {
+		fmt.Printf("Invalid board: %v\n", err)
+		os.Exit(1)
+	}
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+
+	printer := newResultPrinter("word", "points")
+	total, lastLength := 0, 0
+	for _, found := range findBoggleWords(rootTrie, board, boggleMinLength) {
+		if len(found.word) != lastLength {
+			lastLength = len(found.word)
+			printer.text("%d letters, %d points each:", lastLength, found.points)
+		}
+		total += found.points
+		printer.result("  "+found.word, found.word, strconv.Itoa(found.points))
+	}
+	printer.text("%d points in all", total)
+	printer.finish(false)
+}
+
+// newBoggleBoard reads the board's rows into the text of each square, upper cased, with Q as QU if quSquares is set
+func newBoggleBoard(rows []string, quSquares bool) ([][]string, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("the board has no rows")
+	}
+	board := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		letters := justUppercaseLetters(row)
+		if len(letters) == 0 || (len(board) > 0 && len(letters) != len(board[0])) {
+			return nil, fmt.Errorf("every row should have the same number of letters, but %s has %d", row, len(letters))
+		}
+		squares := make([]string, 0, len(letters))
+		for _, letter := range letters {
+			if letter == 'Q' && quSquares {
+				squares = append(squares, "QU")
+			} else {
+				squares = append(squares, string(letter))
+			}
+		}
+		board = append(board, squares)
+	}
+	return board, nil
+}
+
+// findBoggleWords finds the words of at least minLength letters that can be traced on the board, longest first
+// and then alphabetically. The search follows the trie, so a path stops as soon as no word starts with it
+func findBoggleWords(rootTrie *trieNode, board [][]string, minLength int) []boggleWord {
+	found := make(map[string]bool)
+	visited := make([][]bool, len(board))
+	for row := range board {
+		visited[row] = make([]bool, len(board[row]))
+	}
+	for row := range board {
+		for column := range board[row] {
+			traceBoggleWords(rootTrie, board, visited, row, column, "", minLength, found)
+		}
+	}
+
+	words := make([]boggleWord, 0, len(found))
+	for word := range found {
+		words = append(words, boggleWord{word, bogglePoints(len(word))})
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if len(words[i].word) != len(words[j].word) {
+			return len(words[i].word) > len(words[j].word)
+		}
+		return words[i].word < words[j].word
+	})
+	return words
+}
+
+func traceBoggleWords(node *trieNode, board [][]string, visited [][]bool, row int, column int, currentWord string, minLength int, found map[string]bool) {
+	for _, letter := range []byte(board[row][column]) {
+		if node = node.Child(letter); node == nil {
+			return
+		}
+	}
+	currentWord += board[row][column]
+	if node.IsWord() && len(currentWord) >= minLength {
+		found[currentWord] = true
+	}
+
+	visited[row][column] = true
+	for nextRow := row - 1; nextRow <= row+1; nextRow++ {
+		for nextColumn := column - 1; nextColumn <= column+1; nextColumn++ {
+			if nextRow >= 0 && nextRow < len(board) && nextColumn >= 0 && nextColumn < len(board[nextRow]) && !visited[nextRow][nextColumn] {
+				traceBoggleWords(node, board, visited, nextRow, nextColumn, currentWord, minLength, found)
+			}
+		}
+	}
+	visited[row][column] = false
+}
+
+// bogglePoints is what Boggle scores a word of length letters
+func bogglePoints(length int) int {
+	switch {
+	case length <= 4:
+		return 1
+	case length == 5:
+		return 2
+	case length == 6:
+		return 3
+	case length == 7:
+		return 5
+	}
+	return 11
+}
+
+func init() {
+	boggleCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+	boggleCmd.MarkFlagRequired("dictionary")
+	boggleCmd.Flags().IntVarP(&boggleMinLength, "min-length", "", 3, "The fewest letters a word can have")
+	boggleCmd.Flags().BoolVarP(&bogglePlainQ, "plain-q", "", false, "Read a Q square as just Q rather than QU")
+	rootCmd.AddCommand(boggleCmd)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFindBoggleWords(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"CAT", "CATS", "ACTS", "TACT", "QUIT", "QUITS", "SCAT", "TA"} {
+		trie.Add(word, nil)
+	}
+
+	// C A
+	// T S
+	// Q I
+	board, err := newBoggleBoard([]string{"CA", "TS", "QI"}, true)
+	if err != nil {
+		test.Fatalf("Expected a board but got %v", err)
+	}
+	described := make([]string, 0)
+	for _, found := range findBoggleWords(trie, board, 3) {
+		described = append(described, fmt.Sprintf("%s:%d", found.word, found.points))
+	}
+	// TACT would need the T twice and TA is too short; QUITS is five letters with the QU square
+	expected := "QUITS:2,ACTS:1,CATS:1,QUIT:1,SCAT:1,CAT:1"
+	if actual := strings.Join(described, ","); actual != expected {
+		test.Errorf("Expected %s but got %s", expected, actual)
+	}
+
+	plain, _ := newBoggleBoard([]string{"CA", "TS", "QI"}, false)
+	for _, found := range findBoggleWords(trie, plain, 3) {
+		if strings.HasPrefix(found.word, "Q") {
+			test.Errorf("Expected no Q words when Q is just Q but got %s", found.word)
+		}
+	}
+}
+
+func TestNewBoggleBoard(test *testing.T) {
+	for _, rows := range [][]string{{}, {"ABC", "DE"}, {"AB", "12"}} {
+		if _, err := newBoggleBoard(rows, true); err == nil {
+			test.Errorf("Expected an error for %v but got none", rows)
+		}
+	}
+}
+
+func TestBogglePoints(test *testing.T) {
+	tests := []struct {
+		length   int
+		expected int
+	}{
+		{3, 1}, {4, 1}, {5, 2}, {6, 3}, {7, 5}, {8, 11}, {12, 11},
+	}
+	for _, currentTest := range tests {
+		if actual := bogglePoints(currentTest.length); actual != currentTest.expected {
+			test.Errorf("Expected %d points for %d letters but got %d", currentTest.expected, currentTest.length, actual)
+		}
+	}
+}
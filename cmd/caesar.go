@@ -1,41 +1,376 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"github.com/spf13/cobra"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
+
+	"github.com/spf13/cobra"
 )
 
+var caesarRing string
+var caesarAlphabet string
+var caesarKeyword string
+var caesarRot int
+var caesarShiftAmount int
+var caesarEncode bool
+var caesarOnlyWords bool
+var caesarMinWordFraction float64
+
 func printCaesarShifts(command *cobra.Command, args []string) {
+	if caesarRot != 0 {
+		printRotation(strings.Join(args, " "))
+		return
+	}
+
+	rings, err := ringsForName(caesarRing, caesarAlphabet)
+	if err != nil {
+		fmt.Printf("Invalid ring: %v\n", err)
+		os.Exit(1)
+	}
+	// merged letters have to be folded before they can be found in the ring
+	foldInput := rings[0] == activeAlphabet.symbolRing && activeAlphabet != standardAlphabet
+	if caesarKeyword != "" {
+		keyword := caesarKeyword
+		if foldInput {
+			keyword = activeAlphabet.foldString(keyword)
+		}
+		rings, err = keyedRings(rings, keyword)
+		if err != nil {
+			fmt.Printf("Invalid keyword: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	maxShift := 0
+	for _, ring := range rings {
+		if ring.size() > maxShift {
+			maxShift = ring.size()
+		}
+	}
+
 	fullString := strings.Join(args, " ")
-	// run each possible shift
-	for shift := 1; shift <= 25; shift++ {
-		fmt.Printf("%d. ", shift)
-		for _, curByte := range []byte(fullString) {
-			fmt.Printf("%c", shiftByte(curByte, shift))
+	if foldInput {
+		fullString = activeAlphabet.foldString(fullString)
+	}
+	if command.Flags().Changed("shift") {
+		// just the one shift, such as when building a puzzle
+		printer := newResultPrinter("shift", "text")
+		shifted := shiftText(fullString, caesarShiftAmount, rings)
+		if caesarEncode {
+			printer.result(shifted, strconv.Itoa(caesarShiftAmount), shifted)
+		} else {
+			printer.result(fmt.Sprintf("%d. %s", caesarShiftAmount, shifted), strconv.Itoa(caesarShiftAmount), shifted)
+		}
+		printer.finish(false)
+		return
+	}
+	if caesarEncode {
+		fmt.Println("--encode needs a --shift to encode with")
+		os.Exit(1)
+	}
+
+	var words map[string]bool
+	if len(dictionaryFiles) > 0 {
+		words = readWordSet(dictionaryFiles...)
+	}
+
+	shifts := caesarShifts(fullString, maxShift, rings)
+	if caesarOnlyWords {
+		if words == nil {
+			fmt.Println("--only-words needs a --dictionary to check the words against")
+			os.Exit(1)
+		}
+		shifts = filterShiftsByWords(shifts, words, caesarMinWordFraction)
+	}
+
+	scorer, explainer := caesarScorerFromFlags(words)
+	if scorer == nil {
+		printer := newResultPrinter("shift", "text")
+		for _, shift := range shifts {
+			printer.result(fmt.Sprintf("%d. %s", shift.shift, shift.text), strconv.Itoa(shift.shift), shift.text)
+		}
+		printer.finish(false)
+		return
+	}
+
+	// with something to score against, the likeliest shift comes first and is marked
+	printer := newResultPrinter("shift", "text", "score")
+	scoreCaesarShifts(shifts, scorer)
+	if len(shifts) == 0 {
+		printer.text("No shifts had enough dictionary words")
+	}
+	for index, shift := range shifts {
+		marker := ""
+		if index == 0 {
+			marker = " <- most likely"
+		}
+		score := fmt.Sprintf("%.2f", shift.score)
+		printer.explained(fmt.Sprintf("%d. %s\t%s%s", shift.shift, shift.text, score, marker), explainer(shift.text), strconv.Itoa(shift.shift), shift.text, score)
+	}
+	printer.finish(false)
+}
+
+// printRotation prints text with just the --rot transform applied, rather than every shift
+func printRotation(text string) {
+	if caesarKeyword != "" || caesarAlphabet != "" {
+		fmt.Println("--rot uses its own fixed alphabets, so it can't be combined with --keyword or --alphabet")
+		os.Exit(1)
+	}
+	rotated, err := rotate(text, caesarRot)
+	if err != nil {
+		fmt.Printf("Invalid rot: %v\n", err)
+		os.Exit(1)
+	}
+	printer := newResultPrinter("text")
+	printer.result(rotated, rotated)
+	printer.finish(false)
+}
+
+// caesarShift is one rotation of the text, along with how English-like it is if it's been scored
+type caesarShift struct {
+	shift int
+	text  string
+	score float64
+}
+
+// caesarShifts runs every shift from 1 up to (but not including) maxShift over text
+func caesarShifts(text string, maxShift int, rings []*symbolRing) []caesarShift {
+	shifts := make([]caesarShift, 0, maxShift)
+	for shift := 1; shift < maxShift; shift++ {
+		shifts = append(shifts, caesarShift{shift: shift, text: shiftText(text, shift, rings)})
+	}
+	return shifts
+}
+
+// shiftText moves every symbol of text that's in one of the rings shift places around it
+func shiftText(text string, shift int, rings []*symbolRing) string {
+	shifted := make([]byte, len(text))
+	for index, curByte := range []byte(text) {
+		shifted[index] = shiftByRings(curByte, shift, rings)
+	}
+	return string(shifted)
+}
+
+// caesarScorerFromFlags returns a function scoring text by --frequency-file if given, else by the words
+// from --dictionary, or nil when there's nothing to score with. The second function gives the
+// reasons behind a score for --explain
+func caesarScorerFromFlags(words map[string]bool) (func(string) float64, func(string) []string) {
+	if ngramFrequencyFile != "" {
+		frequencyMap, err := loadFrequencyMap(ngramFrequencyFile)
+		if err != nil {
+			fmt.Printf("Error with frequency file: %v\n", err)
+			os.Exit(1)
+		}
+		return ngramScorer(frequencyMap), func(text string) []string {
+			return explainNgramFitness(lettersOnly(text), frequencyMap)
+		}
+	}
+	if words != nil {
+		return dictionaryScorer(words), func(text string) []string {
+			return explainDictionaryWords(text, words)
+		}
+	}
+	return nil, nil
+}
+
+// ngramScorer scores text by the ngram fitness of its letters
+func ngramScorer(frequencyMap *ngramFrequencyMap) func(string) float64 {
+	return func(text string) float64 {
+		return calculateNgramFitness(lettersOnly(text), frequencyMap)
+	}
+}
+
+// dictionaryScorer scores text by how many of its words are in the dictionary
+func dictionaryScorer(words map[string]bool) func(string) float64 {
+	return func(text string) float64 {
+		found, _ := countDictionaryWords(text, words)
+		return float64(found)
+	}
+}
+
+// countDictionaryWords returns how many of the words in text are in the dictionary, out of how many
+// words there are. Tokens with no letters, like numbers, don't count as words
+func countDictionaryWords(text string, words map[string]bool) (int, int) {
+	found := 0
+	total := 0
+	for _, token := range strings.Fields(text) {
+		letters := lettersOnly(token)
+		if letters == "" {
+			continue
+		}
+		total++
+		if words[letters] {
+			found++
+		}
+	}
+	return found, total
+}
+
+// explainDictionaryWords lists which of the words in text are in the dictionary and which aren't
+func explainDictionaryWords(text string, words map[string]bool) []string {
+	found := make([]string, 0)
+	missing := make([]string, 0)
+	for _, token := range strings.Fields(text) {
+		letters := lettersOnly(token)
+		if letters == "" {
+			continue
+		}
+		if words[letters] {
+			found = append(found, letters)
+		} else {
+			missing = append(missing, letters)
+		}
+	}
+
+	reasons := []string{fmt.Sprintf("%d of %d words in the dictionary", len(found), len(found)+len(missing))}
+	if len(found) > 0 {
+		reasons = append(reasons, "found: "+strings.Join(found, " "))
+	}
+	if len(missing) > 0 {
+		reasons = append(reasons, "not found: "+strings.Join(missing, " "))
+	}
+	return reasons
+}
+
+// filterShiftsByWords keeps the shifts where at least minFraction of the words are in the dictionary
+func filterShiftsByWords(shifts []caesarShift, words map[string]bool, minFraction float64) []caesarShift {
+	kept := make([]caesarShift, 0, len(shifts))
+	for _, shift := range shifts {
+		found, total := countDictionaryWords(shift.text, words)
+		if total > 0 && float64(found) >= minFraction*float64(total) {
+			kept = append(kept, shift)
+		}
+	}
+	return kept
+}
+
+// lettersOnly strips everything outside the active alphabet from text and folds the rest to uppercase
+func lettersOnly(text string) string {
+	letters := make([]byte, 0, len(text))
+	for _, curByte := range []byte(text) {
+		if activeAlphabet.accepts(curByte) {
+			letters = append(letters, activeAlphabet.fold(curByte))
 		}
-		fmt.Print("\n")
 	}
+	return string(letters)
 }
 
+// scoreCaesarShifts scores each shift and sorts them from best to worst, keeping shift order for ties
+func scoreCaesarShifts(shifts []caesarShift, scorer func(string) float64) {
+	for index := range shifts {
+		shifts[index].score = scorer(shifts[index].text)
+	}
+	sort.SliceStable(shifts, func(i, j int) bool {
+		return shifts[i].score > shifts[j].score
+	})
+}
+
+// shiftByte shifts letters around A-Z or a-z, leaving everything else alone
 func shiftByte(byteToShift byte, shiftAmount int) byte {
-	var startByte byte
-	var endByte byte
-	if isUppercaseAscii(byteToShift) {
-		startByte = 'A'
-		endByte = 'Z'
-	} else if isLowercaseAscii(byteToShift) {
-		startByte = 'a'
-		endByte = 'z'
-	} else {
-		return byteToShift
-	}
-
-	// at a mininum, shift the byte
-	newByte := byteToShift + byte(shiftAmount)
-	// but if it runs over the edge of the letters mod by startByte and add that to startByte
-	if newByte > endByte {
-		newByte = startByte + (newByte - endByte - byte(1))
-	}
-	return newByte
+	return shiftByRings(byteToShift, shiftAmount, []*symbolRing{upperRing, lowerRing})
+}
+
+// runCaesarSolver is the caesar command for the solver registry
+func runCaesarSolver(ctx context.Context, input solverInput) (*resultTable, error) {
+	rings, err := ringsForName(input.getString("ring"), "")
+	if err != nil {
+		return nil, err
+	}
+	text := input.getString("text")
+	if rings[0] == activeAlphabet.symbolRing && activeAlphabet != standardAlphabet {
+		text = activeAlphabet.foldString(text)
+	}
+
+	shift := input.getInt("shift")
+	if input.getBool("encode") {
+		// just the shifted text, as caesar --encode prints it
+		if shift == 0 {
+			return nil, fmt.Errorf("encode needs a shift to encode with")
+		}
+		table := newResultTable("text")
+		table.addRow(shiftText(text, shift, rings))
+		return table, nil
+	}
+	table := newResultTable("shift", "text")
+	if shift != 0 {
+		table.addRow(strconv.Itoa(shift), shiftText(text, shift, rings))
+		return table, nil
+	}
+	maxShift := 0
+	for _, ring := range rings {
+		if ring.size() > maxShift {
+			maxShift = ring.size()
+		}
+	}
+	shifts := caesarShifts(text, maxShift, rings)
+	if !input.getBool("rank") {
+		for _, shift := range shifts {
+			table.addRow(strconv.Itoa(shift.shift), shift.text)
+		}
+		return table, nil
+	}
+
+	// requests can't name a frequency file, so shifts are ranked by the built-in tetragrams
+	frequencyMap, err := loadFrequencyMap("")
+	if err != nil {
+		return nil, err
+	}
+	scoreCaesarShifts(shifts, ngramScorer(frequencyMap))
+	table = newResultTable("shift", "text", "score")
+	for _, shift := range shifts {
+		table.addRow(strconv.Itoa(shift.shift), shift.text, fmt.Sprintf("%.2f", shift.score))
+	}
+	return table, nil
+}
+
+// runRotSolver is caesar --rot for the solver registry
+func runRotSolver(ctx context.Context, input solverInput) (*resultTable, error) {
+	rotated, err := rotate(input.getString("text"), input.getInt("n"))
+	if err != nil {
+		return nil, err
+	}
+	table := newResultTable("text")
+	table.addRow(rotated)
+	return table, nil
+}
+
+func init() {
+	mustRegisterSolver(&solver{
+		name:        "caesar",
+		description: "Every caesar shift of the text, or just one with shift, or the text encoded with shift",
+		parameters: []solverParameter{
+			solverParameter{name: "text", kind: solverString, description: "the text to shift", required: true},
+			solverParameter{name: "shift", kind: solverInt, description: "only this shift; 0 lists them all"},
+			solverParameter{name: "encode", kind: solverBool, description: "return just the text shifted by shift, for building puzzles"},
+			solverParameter{name: "rank", kind: solverBool, description: "score the shifts with English tetragram frequencies and list the likeliest first"},
+			solverParameter{name: "ring", kind: solverString, description: "the symbols to rotate, as for caesar --ring", defaultValue: "letters"},
+		},
+		run: runCaesarSolver,
+	})
+	mustRegisterSolver(&solver{
+		name:        "rot",
+		description: "ROT5, ROT13, ROT18, or ROT47 of the text",
+		parameters: []solverParameter{
+			solverParameter{name: "text", kind: solverString, description: "the text to rotate", required: true},
+			solverParameter{name: "n", kind: solverInt, description: "which ROT: 5, 13, 18, or 47", defaultValue: "13"},
+		},
+		run: runRotSolver,
+	})
+
+	caesarCmd.Flags().StringVarP(&caesarRing, "ring", "r", "letters", "the symbols to rotate: letters, digits, alphanumeric (A-Z then 0-9), letters+digits (each on their own), or ascii (printable ASCII)")
+	caesarCmd.Flags().IntVarP(&caesarShiftAmount, "shift", "", 0, "only print this shift (negative shifts go backwards)")
+	caesarCmd.Flags().BoolVarP(&caesarEncode, "encode", "e", false, "print just the shifted text, without the shift number, for building puzzles. Needs --shift")
+	caesarCmd.Flags().IntVarP(&caesarRot, "rot", "", 0, "just apply ROT5 (digits), ROT13 (letters), ROT18 (both), or ROT47 (printable ASCII) instead of listing every shift")
+	caesarCmd.Flags().StringVarP(&caesarAlphabet, "alphabet", "a", "", "a custom ordered set of symbols to rotate through instead of --ring")
+	caesarCmd.Flags().StringVarP(&caesarKeyword, "keyword", "k", "", "mix the ring with this keyword (keyword first, then the rest in order) before shifting, for keyed caesars")
+	caesarCmd.Flags().StringVarP(&ngramFrequencyFile, "frequency-file", "f", "", "an ngram frequency file (as hillclimb uses) to rank the shifts by")
+	caesarCmd.Flags().StringVarP(&ngramSmoothing, "smoothing", "", smoothingFloor, "how to score ngrams that aren't in the frequency file: floor, add-k, or fixed, as for hillclimb")
+	caesarCmd.Flags().Float64VarP(&ngramSmoothingK, "smoothing-k", "", 0.5, "the k for --smoothing add-k")
+	caesarCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "a dictionary file to rank the shifts by how many of their words it has. Repeat it or separate files with commas to merge several")
+	caesarCmd.Flags().BoolVarP(&caesarOnlyWords, "only-words", "", false, "only print shifts where enough of the words are in --dictionary")
+	caesarCmd.Flags().Float64VarP(&caesarMinWordFraction, "min-word-fraction", "", 0.5, "the fraction of words that have to be in the dictionary for --only-words")
 }
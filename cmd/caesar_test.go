@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -26,3 +27,203 @@ func TestShiftByte(test *testing.T) {
 		}
 	}
 }
+
+func TestSymbolRing(test *testing.T) {
+	ring, err := newSymbolRing("0123456789")
+	if err != nil {
+		test.Fatalf("Unexpected error creating ring: %v", err)
+	}
+
+	tests := []shiftTest{
+		shiftTest{'0', 3, '3'},
+		shiftTest{'8', 5, '3'},
+		shiftTest{'2', -3, '9'},
+		shiftTest{'A', 4, 'A'},
+	}
+	for _, curTest := range tests {
+		shifted := ring.shift(curTest.start, curTest.shiftAmount)
+		if shifted != curTest.expected {
+			test.Errorf("Expected %c from shifting %c by %d but got %c", curTest.expected, curTest.start, curTest.shiftAmount, shifted)
+		}
+	}
+
+	if _, err := newSymbolRing("ABCA"); err == nil {
+		test.Errorf("Expected an error for a ring with duplicate symbols")
+	}
+}
+
+func TestRingsForName(test *testing.T) {
+	rings, err := ringsForName("alphanumeric", "")
+	if err != nil {
+		test.Fatalf("Unexpected error: %v", err)
+	}
+	if shifted := shiftByRings('Z', 1, rings); shifted != '0' {
+		test.Errorf("Expected Z to shift to 0 in the alphanumeric ring but got %c", shifted)
+	}
+	if shifted := shiftByRings('9', 1, rings); shifted != 'A' {
+		test.Errorf("Expected 9 to shift to A in the alphanumeric ring but got %c", shifted)
+	}
+
+	rings, err = ringsForName("letters", "QWERTY")
+	if err != nil {
+		test.Fatalf("Unexpected error: %v", err)
+	}
+	if shifted := shiftByRings('Y', 2, rings); shifted != 'W' {
+		test.Errorf("Expected a custom alphabet to take precedence, shifting Y to W, but got %c", shifted)
+	}
+
+	if _, err := ringsForName("klingon", ""); err == nil {
+		test.Errorf("Expected an error for an unknown ring")
+	}
+}
+
+func TestScoreCaesarShifts(test *testing.T) {
+	shifts := caesarShifts("Uryyb, jbeyq", 26, []*symbolRing{upperRing, lowerRing})
+	if len(shifts) != 25 || shifts[12].text != "Hello, world" {
+		test.Fatalf("Expected 25 shifts with shift 13 reading Hello, world but got %v", shifts)
+	}
+
+	frequencyMap, _, _ := populateFrequencyMapFromReader(strings.NewReader("HEL\t-1.0\nELL\t-1.0\nLLO\t-1.0\nLOW\t-2.0\nOWO\t-2.0\nWOR\t-1.5\nORL\t-1.5\nRLD\t-1.5"))
+	scoreCaesarShifts(shifts, ngramScorer(frequencyMap))
+	if shifts[0].shift != 13 {
+		test.Errorf("Expected shift 13 to score best by ngrams but got %v", shifts[0])
+	}
+
+	shifts = caesarShifts("Uryyb, jbeyq", 26, []*symbolRing{upperRing, lowerRing})
+	scoreCaesarShifts(shifts, dictionaryScorer(map[string]bool{"HELLO": true, "WORLD": true}))
+	if shifts[0].shift != 13 || shifts[0].score != 2 {
+		test.Errorf("Expected shift 13 to match two dictionary words but got %v", shifts[0])
+	}
+	// ties stay in shift order
+	if shifts[1].shift != 1 || shifts[2].shift != 2 {
+		test.Errorf("Expected the unscored shifts to stay in order but got %v then %v", shifts[1], shifts[2])
+	}
+}
+
+func TestKeyedRings(test *testing.T) {
+	rings, err := keyedRings([]*symbolRing{upperRing, lowerRing}, "Kryptos")
+	if err != nil {
+		test.Fatalf("Unexpected error keying rings: %v", err)
+	}
+	if string(rings[0].symbols) != "KRYPTOSABCDEFGHIJLMNQUVWXZ" || string(rings[1].symbols) != "kryptosabcdefghijlmnquvwxz" {
+		test.Errorf("Expected keyword-mixed rings but got %s and %s", rings[0].symbols, rings[1].symbols)
+	}
+
+	tests := []shiftTest{
+		shiftTest{'K', 1, 'R'},
+		shiftTest{'Z', 1, 'K'},
+		shiftTest{'s', 2, 'b'},
+		shiftTest{' ', 3, ' '},
+	}
+	for _, curTest := range tests {
+		if shifted := shiftByRings(curTest.start, curTest.shiftAmount, rings); shifted != curTest.expected {
+			test.Errorf("Expected %c shifted %d to be %c but got %c", curTest.start, curTest.shiftAmount, curTest.expected, shifted)
+		}
+	}
+
+	// repeated letters only count once
+	rings, _ = keyedRings([]*symbolRing{mustSymbolRing(digitAlphabet)}, "3113")
+	if string(rings[0].symbols) != "3102456789" {
+		test.Errorf("Expected 3102456789 but got %s", rings[0].symbols)
+	}
+
+	if _, err := keyedRings([]*symbolRing{mustSymbolRing(digitAlphabet)}, "ABC"); err == nil {
+		test.Errorf("Expected an error for a keyword outside the ring")
+	}
+}
+
+func TestRotate(test *testing.T) {
+	tests := []struct {
+		input    string
+		rot      int
+		expected string
+	}{
+		{"Call 555-0199", 5, "Call 000-5644"},
+		{"Call 555-0199", 13, "Pnyy 555-0199"},
+		{"Call 555-0199", 18, "Pnyy 000-5644"},
+		{"Hello, World!", 47, "w6==@[ (@C=5P"},
+	}
+
+	for _, testCase := range tests {
+		rotated, err := rotate(testCase.input, testCase.rot)
+		if err != nil {
+			test.Errorf("Unexpected error for ROT%d: %v", testCase.rot, err)
+		}
+		if rotated != testCase.expected {
+			test.Errorf("Expected ROT%d of %q to be %q but got %q", testCase.rot, testCase.input, testCase.expected, rotated)
+		}
+		// every one of these is its own inverse
+		if back, _ := rotate(rotated, testCase.rot); back != testCase.input {
+			test.Errorf("Expected ROT%d to undo itself but got %q", testCase.rot, back)
+		}
+	}
+
+	if _, err := rotate("abc", 7); err == nil {
+		test.Errorf("Expected an error for ROT7")
+	}
+}
+
+func TestShiftText(test *testing.T) {
+	letters := []*symbolRing{upperRing, lowerRing}
+	keyed, _ := keyedRings(letters, "kryptos")
+	tests := []struct {
+		text     string
+		shift    int
+		rings    []*symbolRing
+		expected string
+	}{
+		{"Hello, World", 3, letters, "Khoor, Zruog"},
+		{"Khoor, Zruog", -3, letters, "Hello, World"},
+		{"Hello", 29, letters, "Khoor"},
+		{"Hello", 1, keyed, "Ifmms"},
+		{"Call 555", 5, []*symbolRing{upperRing, lowerRing, digitRing}, "Hfqq 000"},
+	}
+
+	for _, testCase := range tests {
+		if shifted := shiftText(testCase.text, testCase.shift, testCase.rings); shifted != testCase.expected {
+			test.Errorf("Expected %q shifted %d to be %q but got %q", testCase.text, testCase.shift, testCase.expected, shifted)
+		}
+	}
+}
+
+func TestFilterShiftsByWords(test *testing.T) {
+	words := map[string]bool{"HELLO": true, "THERE": true}
+	shifts := caesarShifts("Uryyb gurer, Obo 42", 26, []*symbolRing{upperRing, lowerRing})
+
+	tests := []struct {
+		minFraction    float64
+		expectedShifts []int
+	}{
+		// Bob isn't in the dictionary and 42 isn't a word, so shift 13 has 2 of 3
+		{0.5, []int{13}},
+		{0.66, []int{13}},
+		{0.7, []int{}},
+		{0, nil},
+	}
+	for _, testCase := range tests {
+		kept := filterShiftsByWords(shifts, words, testCase.minFraction)
+		if testCase.expectedShifts == nil {
+			if len(kept) != len(shifts) {
+				test.Errorf("Expected a fraction of 0 to keep every shift but kept %d", len(kept))
+			}
+			continue
+		}
+		if len(kept) != len(testCase.expectedShifts) {
+			test.Errorf("Expected shifts %v at %.2f but got %v", testCase.expectedShifts, testCase.minFraction, kept)
+			continue
+		}
+		for index, shift := range kept {
+			if shift.shift != testCase.expectedShifts[index] {
+				test.Errorf("Expected shifts %v at %.2f but got %v", testCase.expectedShifts, testCase.minFraction, kept)
+			}
+		}
+	}
+}
+
+func TestExplainDictionaryWords(test *testing.T) {
+	reasons := explainDictionaryWords("HELLO there, WORLD 42", map[string]bool{"HELLO": true, "WORLD": true})
+	expected := []string{"2 of 3 words in the dictionary", "found: HELLO WORLD", "not found: THERE"}
+	if strings.Join(reasons, "|") != strings.Join(expected, "|") {
+		test.Errorf("Expected %v but got %v", expected, reasons)
+	}
+}
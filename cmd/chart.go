@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Simple charting for frequency distributions. The shape of a distribution says a lot about
+// the kind of cipher being looked at (a flat one suggests polyalphabetic, an English-like one
+// suggests transposition or monoalphabetic substitution), so these give a quick visual of it.
+
+const asciiChartWidth = 50
+
+const svgBarWidth = 20
+const svgChartHeight = 200
+const svgLabelHeight = 20
+
+// writeAsciiBarChart writes one line per label with a bar of #s scaled so that the largest value
+// is asciiChartWidth characters wide
+func writeAsciiBarChart(writer io.Writer, labels []string, values []int) error {
+	maxValue := maxInt(values)
+	labelWidth := 0
+	for _, label := range labels {
+		if len(label) > labelWidth {
+			labelWidth = len(label)
+		}
+	}
+
+	for index, label := range labels {
+		barLength := 0
+		if maxValue > 0 {
+			barLength = values[index] * asciiChartWidth / maxValue
+		}
+		_, err := fmt.Fprintf(writer, "%-*s | %s %d\n", labelWidth, label, strings.Repeat("#", barLength), values[index])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSvgHistogram writes a standalone SVG document with a vertical bar for each label
+func writeSvgHistogram(writer io.Writer, labels []string, values []int) error {
+	maxValue := maxInt(values)
+	width := svgBarWidth * len(labels)
+	height := svgChartHeight + svgLabelHeight
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height))
+	for index, label := range labels {
+		barHeight := 0
+		if maxValue > 0 {
+			barHeight = values[index] * svgChartHeight / maxValue
+		}
+		x := index * svgBarWidth
+		builder.WriteString(fmt.Sprintf(`  <rect x="%d" y="%d" width="%d" height="%d" fill="steelblue"><title>%s: %d</title></rect>`+"\n",
+			x+1, svgChartHeight-barHeight, svgBarWidth-2, barHeight, label, values[index]))
+		builder.WriteString(fmt.Sprintf(`  <text x="%d" y="%d" font-family="monospace" font-size="10" text-anchor="middle">%s</text>`+"\n",
+			x+svgBarWidth/2, height-5, label))
+	}
+	builder.WriteString("</svg>\n")
+
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
+
+func maxInt(values []int) int {
+	maxValue := 0
+	for _, value := range values {
+		if value > maxValue {
+			maxValue = value
+		}
+	}
+	return maxValue
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteAsciiBarChart(test *testing.T) {
+	var output bytes.Buffer
+	writeAsciiBarChart(&output, []string{"A", "BB"}, []int{2, 4})
+
+	expected := "A  | " + strings.Repeat("#", asciiChartWidth/2) + " 2\n" +
+		"BB | " + strings.Repeat("#", asciiChartWidth) + " 4\n"
+	if output.String() != expected {
+		test.Errorf("Expected chart\n%s\nbut got\n%s", expected, output.String())
+	}
+}
+
+func TestWriteSvgHistogram(test *testing.T) {
+	var output bytes.Buffer
+	writeSvgHistogram(&output, []string{"A", "B", "C"}, []int{1, 2, 0})
+
+	svg := output.String()
+	if !strings.HasPrefix(svg, "<svg") || !strings.HasSuffix(svg, "</svg>\n") {
+		test.Errorf("Expected a complete svg document but got %s", svg)
+	}
+	if strings.Count(svg, "<rect") != 3 {
+		test.Errorf("Expected 3 bars but got %d", strings.Count(svg, "<rect"))
+	}
+	if !strings.Contains(svg, `height="200"`) {
+		test.Errorf("Expected the largest bar to take up the full chart height")
+	}
+}
+
+func TestMostCommonNgrams(test *testing.T) {
+	pairs := []TrieWord[int]{{"AB", 1}, {"CD", 5}, {"EF", 3}, {"AA", 3}}
+	labels, values := mostCommonNgrams(pairs, 3)
+
+	expectedLabels := []string{"CD", "AA", "EF"}
+	expectedValues := []int{5, 3, 3}
+	for index := range expectedLabels {
+		if labels[index] != expectedLabels[index] || values[index] != expectedValues[index] {
+			test.Errorf("Expected %s: %d at %d but got %s: %d", expectedLabels[index], expectedValues[index], index, labels[index], values[index])
+		}
+	}
+	if len(labels) != 3 {
+		test.Errorf("Expected 3 ngrams but got %d", len(labels))
+	}
+}
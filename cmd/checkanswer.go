@@ -0,0 +1,196 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var expectedHash string
+var hashAlgorithm string
+var answerCase string
+var keepSpaces bool
+
+// checkAnswerCmd represents the checkanswer command
+var checkAnswerCmd = &cobra.Command{
+	Use:   "checkanswer [CANDIDATE...]",
+	Short: "Checks candidate answers against a hashed answer",
+	Long: `Some puzzles only confirm an answer by publishing its hash. This normalizes each candidate
+	(removing anything that isn't a letter or digit, and optionally spaces) and compares its hash
+	against --hash. Candidates come from the arguments, one per argument, or from every line of
+	the file passed in with --dictionary.
+
+	The algorithm (md5, sha1, sha256, sha512, or crc32) is worked out from the length of the hash
+	unless --algorithm is given. --case can be upper, lower, or any, which tries both.
+	`,
+	Run: checkAnswers,
+}
+
+var answerNormalizer = regexp.MustCompile("[^A-Za-z0-9 ]+")
+
+func checkAnswers(cmd *cobra.Command, args []string) {
+	algorithm := hashAlgorithm
+	if algorithm == "" {
+		algorithm = detectHashAlgorithm(expectedHash)
+		if algorithm == "" {
+			fmt.Printf("Could not tell which algorithm produced %s; use --algorithm\n", expectedHash)
+			os.Exit(1)
+		}
+	}
+	if newAnswerHash(algorithm) == nil {
+		fmt.Printf("Unknown hash algorithm %s\n", algorithm)
+		os.Exit(1)
+	}
+
+	candidates := make(chan string)
+	go func() {
+		if len(dictionaryFiles) == 0 {
+			for _, arg := range args {
+				candidates <- arg
+			}
+			close(candidates)
+			return
+		}
+		readAnswerCandidates(candidates, dictionaryFiles...)
+	}()
+
+	found := false
+	printer := newResultPrinter("candidate", "hashed_as")
+	for candidate := range candidates {
+		for _, normalized := range normalizeAnswer(candidate, answerCase, keepSpaces) {
+			if answerMatchesHash(normalized, expectedHash, algorithm) {
+				printer.result(fmt.Sprintf("Match: %s (hashed as %s)", candidate, normalized), candidate, normalized)
+				found = true
+			}
+		}
+	}
+	if !found {
+		printer.text("No candidates matched")
+	}
+	printer.finish(false)
+}
+
+// readAnswerCandidates pushes every line of paths into candidates without changing its case,
+// skipping lines that have already been sent
+func readAnswerCandidates(candidates chan string, paths ...string) {
+	seen := make(map[string]bool)
+	exitOnDictionaryError(withDictionaryReaders(paths, func(readers []*bufio.Reader) error {
+		for _, reader := range readers {
+			scanner := bufio.NewScanner(reader)
+			for scanner.Scan() {
+				if !seen[scanner.Text()] {
+					seen[scanner.Text()] = true
+					candidates <- scanner.Text()
+				}
+			}
+			if err := scanner.Err(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	close(candidates)
+}
+
+// normalizeAnswer strips punctuation (and spaces unless keepSpaces is set) and returns the forms
+// of the answer to try for the given case mode
+func normalizeAnswer(answer, caseMode string, keepSpaces bool) []string {
+	normalized := answerNormalizer.ReplaceAllString(answer, "")
+	if keepSpaces {
+		normalized = strings.Join(strings.Fields(normalized), " ")
+	} else {
+		normalized = strings.ReplaceAll(normalized, " ", "")
+	}
+
+	switch caseMode {
+	case "upper":
+		return []string{strings.ToUpper(normalized)}
+	case "lower":
+		return []string{strings.ToLower(normalized)}
+	default:
+		if strings.ToUpper(normalized) == strings.ToLower(normalized) {
+			return []string{normalized}
+		}
+		return []string{strings.ToUpper(normalized), strings.ToLower(normalized)}
+	}
+}
+
+// detectHashAlgorithm guesses the algorithm based on the number of hex digits, returning "" if it can't
+func detectHashAlgorithm(hexHash string) string {
+	switch len(strings.TrimSpace(hexHash)) {
+	case 8:
+		return "crc32"
+	case 32:
+		return "md5"
+	case 40:
+		return "sha1"
+	case 64:
+		return "sha256"
+	case 128:
+		return "sha512"
+	}
+	return ""
+}
+
+func newAnswerHash(algorithm string) hash.Hash {
+	switch strings.ToLower(algorithm) {
+	case "md5":
+		return md5.New()
+	case "sha1":
+		return sha1.New()
+	case "sha256":
+		return sha256.New()
+	case "sha512":
+		return sha512.New()
+	case "crc32":
+		return crc32.NewIEEE()
+	}
+	return nil
+}
+
+// hashAnswer returns the lowercase hex digest of answer
+func hashAnswer(answer, algorithm string) string {
+	hasher := newAnswerHash(algorithm)
+	hasher.Write([]byte(answer))
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+func answerMatchesHash(answer, expected, algorithm string) bool {
+	return hashAnswer(answer, algorithm) == strings.ToLower(strings.TrimSpace(expected))
+}
+
+func init() {
+	checkAnswerCmd.Flags().StringVarP(&expectedHash, "hash", "", "", "the hex digest of the correct answer")
+	checkAnswerCmd.MarkFlagRequired("hash")
+	checkAnswerCmd.Flags().StringVarP(&hashAlgorithm, "algorithm", "a", "", "md5, sha1, sha256, sha512, or crc32. Detected from the hash length by default")
+	checkAnswerCmd.Flags().StringVarP(&answerCase, "case", "", "any", "upper, lower, or any to try both")
+	checkAnswerCmd.Flags().BoolVarP(&keepSpaces, "keep-spaces", "", false, "keep single spaces between words instead of removing them")
+	checkAnswerCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "check every line of this file instead of the arguments, or - to use stdin. Repeat it or separate files with commas to merge several")
+	rootCmd.AddCommand(checkAnswerCmd)
+}
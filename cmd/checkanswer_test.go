@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestNormalizeAnswer(test *testing.T) {
+	tests := []struct {
+		answer     string
+		caseMode   string
+		keepSpaces bool
+		expected   []string
+	}{
+		{"Don't  stop!", "upper", false, []string{"DONTSTOP"}},
+		{"Don't  stop!", "lower", true, []string{"dont stop"}},
+		{"7-Eleven", "any", false, []string{"7ELEVEN", "7eleven"}},
+		{"867-5309", "any", false, []string{"8675309"}},
+	}
+
+	for index, testCase := range tests {
+		actual := normalizeAnswer(testCase.answer, testCase.caseMode, testCase.keepSpaces)
+		if len(actual) != len(testCase.expected) {
+			test.Errorf("Test case %d: expected %v but got %v", index, testCase.expected, actual)
+			continue
+		}
+		for formIndex, form := range testCase.expected {
+			if actual[formIndex] != form {
+				test.Errorf("Test case %d: expected %v but got %v", index, testCase.expected, actual)
+			}
+		}
+	}
+}
+
+func TestAnswerMatchesHash(test *testing.T) {
+	// digests of "hello"
+	tests := map[string]string{
+		"md5":    "5d41402abc4b2a76b9719d911017c592",
+		"sha1":   "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d",
+		"sha256": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		"crc32":  "3610a686",
+	}
+
+	for algorithm, digest := range tests {
+		if detectHashAlgorithm(digest) != algorithm {
+			test.Errorf("Expected %s to be detected as %s but got %s", digest, algorithm, detectHashAlgorithm(digest))
+		}
+		if !answerMatchesHash("hello", digest, algorithm) {
+			test.Errorf("Expected hello to match %s with %s", digest, algorithm)
+		}
+		if answerMatchesHash("HELLO", digest, algorithm) {
+			test.Errorf("Expected HELLO not to match %s with %s", digest, algorithm)
+		}
+	}
+
+	if !answerMatchesHash("hello", "5D41402ABC4B2A76B9719D911017C592", "md5") {
+		test.Errorf("Expected uppercase hex digests to match")
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// the index of coincidence of English text and of letters picked at random. A polyalphabetic cipher's
+// columns come out near English at the right period and near random at the wrong ones
+const (
+	englishIndexOfCoincidence = 0.0667
+	randomIndexOfCoincidence  = 1.0 / 26.0
+)
+
+// indexOfCoincidence is the chance that two letters picked from letters without replacement are the same
+func indexOfCoincidence(letters []byte) float64 {
+	if len(letters) < 2 {
+		return 0
+	}
+	var counts [26]int
+	for _, letter := range letters {
+		counts[letter-ASCII_A]++
+	}
+	matches := 0
+	for _, count := range counts {
+		matches += count * (count - 1)
+	}
+	return float64(matches) / float64(len(letters)*(len(letters)-1))
+}
+
+// periodicColumns deals letters out into period columns, so that with the right period each column
+// was enciphered with the same alphabet
+func periodicColumns(letters []byte, period int) [][]byte {
+	columns := make([][]byte, period)
+	for index, letter := range letters {
+		columns[index%period] = append(columns[index%period], letter)
+	}
+	return columns
+}
+
+// friedmanPeriodEstimate is the Friedman test's estimate of a polyalphabetic cipher's period, from how far
+// the text's index of coincidence has dropped from English toward random
+func friedmanPeriodEstimate(letters []byte) float64 {
+	count := float64(len(letters))
+	denominator := (count-1)*indexOfCoincidence(letters) - randomIndexOfCoincidence*count + englishIndexOfCoincidence
+	if denominator <= 0 {
+		return 0
+	}
+	return (englishIndexOfCoincidence - randomIndexOfCoincidence) * count / denominator
+}
+
+// printPeriodicAnalysis prints the index of coincidence of the whole text and of each of its columns at
+// period, along with the Friedman estimate of the period
+func printPeriodicAnalysis(text string, period int) {
+	letters := []byte(frequencyLetters(text))
+	printer := newResultPrinter("column", "ioc", "letters")
+	title := fmt.Sprintf("Index of Coincidence, Period %d", period)
+	printer.text(title)
+	printer.text(strings.Repeat("-", len(title)))
+	printer.text("Total letters: %v", len(letters))
+	printer.text("Whole text: %.4f (English %.4f, random %.4f)", indexOfCoincidence(letters), englishIndexOfCoincidence, randomIndexOfCoincidence)
+	if estimate := friedmanPeriodEstimate(letters); estimate > 0 {
+		printer.text("Friedman estimate of the period: %.1f", estimate)
+	}
+
+	total := 0.0
+	for index, column := range periodicColumns(letters, period) {
+		columnIoc := fmt.Sprintf("%.4f", indexOfCoincidence(column))
+		total += indexOfCoincidence(column)
+		printer.result(fmt.Sprintf("Column %d: %s (%d letters)", index+1, columnIoc, len(column)), strconv.Itoa(index+1), columnIoc, strconv.Itoa(len(column)))
+	}
+	printer.text("Average: %.4f", total/float64(period))
+	printer.finish(false)
+}
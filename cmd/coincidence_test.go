@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIndexOfCoincidence(test *testing.T) {
+	tests := []struct {
+		letters  string
+		expected float64
+	}{
+		{"", 0},
+		{"A", 0},
+		{"AA", 1},
+		{"AB", 0},
+		{"AABB", 1.0 / 3.0},
+	}
+	for _, testCase := range tests {
+		if actual := indexOfCoincidence([]byte(testCase.letters)); math.Abs(actual-testCase.expected) > 0.0001 {
+			test.Errorf("Expected %s to have an IoC of %.4f but got %.4f", testCase.letters, testCase.expected, actual)
+		}
+	}
+}
+
+func TestPeriodicColumns(test *testing.T) {
+	columns := periodicColumns([]byte("ABCDEFG"), 3)
+	expected := []string{"ADG", "BE", "CF"}
+	for index, column := range columns {
+		if string(column) != expected[index] {
+			test.Errorf("Expected column %d to be %s but got %s", index+1, expected[index], column)
+		}
+	}
+}
+
+func TestPeriodicColumnsFoldLowercase(test *testing.T) {
+	lower := periodicColumns([]byte(frequencyLetters("abc, def g")), 3)
+	upper := periodicColumns([]byte(frequencyLetters("ABCDEFG")), 3)
+	for index, column := range lower {
+		if string(column) != string(upper[index]) || indexOfCoincidence(column) != indexOfCoincidence(upper[index]) {
+			test.Errorf("Expected lowercase column %d to be %s but got %s", index+1, upper[index], column)
+		}
+	}
+}
+
+func TestPeriodicIndexOfCoincidence(test *testing.T) {
+	plain := justUppercaseLetters("It was the best of times, it was the worst of times, it was the age of wisdom, it was the age of foolishness, " +
+		"it was the epoch of belief, it was the epoch of incredulity, it was the season of light, it was the season of darkness")
+	key := []byte("LEMON")
+	cipher := make([]byte, len(plain))
+	for index, letter := range plain {
+		cipher[index] = byte((int(letter-ASCII_A)+int(key[index%len(key)]-ASCII_A))%26) + ASCII_A
+	}
+
+	average := func(period int) float64 {
+		total := 0.0
+		for _, column := range periodicColumns(cipher, period) {
+			total += indexOfCoincidence(column)
+		}
+		return total / float64(period)
+	}
+	if average(5) < 0.06 {
+		test.Errorf("Expected the columns at the key's period to look like English but got %.4f", average(5))
+	}
+	if average(4) > 0.05 || average(3) > 0.05 {
+		test.Errorf("Expected the columns at the wrong periods to look random but got %.4f and %.4f", average(4), average(3))
+	}
+
+	if estimate := friedmanPeriodEstimate(cipher); estimate < 2 || estimate > 10 {
+		test.Errorf("Expected the Friedman estimate to be somewhere near 5 but got %.1f", estimate)
+	}
+}
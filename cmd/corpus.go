@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// how far into a corpus to look for a Project Gutenberg start marker before deciding there isn't one
+const gutenbergHeaderWindow = 64 * 1024
+
+var gutenbergStartMarker = []byte("*** START OF")
+
+const gutenbergEndMarker = "*** END OF"
+
+// corpusClient fetches URL corpora. The timeout is generous since whole books stream through it
+var corpusClient = &http.Client{Timeout: 10 * time.Minute}
+
+// openCorpus opens a corpus for reading, whether it's stdin (-), an http(s) URL, or a file.
+// URLs are streamed rather than downloaded first
+func openCorpus(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+
+	if strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://") {
+		response, err := corpusClient.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		if response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			return nil, fmt.Errorf("%s returned %s", name, response.Status)
+		}
+		return response.Body, nil
+	}
+
+	return os.Open(name)
+}
+
+// weightedCorpus is a corpus to merge with others, counting in proportion to its weight
+type weightedCorpus struct {
+	name   string
+	weight float64
+}
+
+// parseWeightedCorpus reads a corpus name with an optional weight after the last colon, as in
+// words.txt:0.3. Anything after the colon that isn't a number is part of the name, so URLs come through whole.
+// A URL's weight has to come after its path, so that a port like localhost:8080 isn't taken for one
+func parseWeightedCorpus(spec string) (weightedCorpus, error) {
+	separator := strings.LastIndex(spec, ":")
+	if separator < 1 || separator < urlPathStart(spec) {
+		return weightedCorpus{spec, 1}, nil
+	}
+	weight, err := strconv.ParseFloat(spec[separator+1:], 64)
+	if err != nil {
+		return weightedCorpus{spec, 1}, nil
+	}
+	if weight <= 0 || math.IsInf(weight, 0) || math.IsNaN(weight) {
+		return weightedCorpus{}, fmt.Errorf("the weight of %s should be a positive number but got %s", spec[:separator], spec[separator+1:])
+	}
+	return weightedCorpus{spec[:separator], weight}, nil
+}
+
+// urlPathStart is where the path starts in an http(s) URL, or the end of it if there's no path. It's 0 for
+// anything that isn't a URL
+func urlPathStart(spec string) int {
+	for _, scheme := range []string{"http://", "https://"} {
+		if strings.HasPrefix(spec, scheme) {
+			if slash := strings.Index(spec[len(scheme):], "/"); slash >= 0 {
+				return len(scheme) + slash
+			}
+			return len(spec)
+		}
+	}
+	return 0
+}
+
+// gutenbergReader passes text through, leaving out the license header and footer Project Gutenberg
+// wraps its texts in so they don't skew the ngram counts. Text without a start marker comes through untouched,
+// even if it has a line that looks like the end marker
+type gutenbergReader struct {
+	lines    *bufio.Reader
+	pending  []byte
+	finished bool
+	// framed is whether the start marker was found, so the footer should be left out too
+	framed bool
+}
+
+func newGutenbergReader(reader io.Reader) *gutenbergReader {
+	gutenberg := &gutenbergReader{lines: bufio.NewReaderSize(reader, gutenbergHeaderWindow)}
+
+	// Peek hands back what it could read even when it errors, which is all that's needed here
+	header, _ := gutenberg.lines.Peek(gutenbergHeaderWindow)
+	if start := bytes.Index(header, gutenbergStartMarker); start >= 0 && (start == 0 || header[start-1] == '\n') {
+		// drop everything through the end of the marker line
+		gutenberg.framed = true
+		for {
+			line, err := gutenberg.lines.ReadString('\n')
+			if strings.HasPrefix(line, string(gutenbergStartMarker)) || err != nil {
+				break
+			}
+		}
+	}
+	return gutenberg
+}
+
+func (gutenberg *gutenbergReader) Read(buffer []byte) (int, error) {
+	for len(gutenberg.pending) == 0 {
+		if gutenberg.finished {
+			return 0, io.EOF
+		}
+
+		line, err := gutenberg.lines.ReadBytes('\n')
+		if gutenberg.framed && bytes.HasPrefix(line, []byte(gutenbergEndMarker)) {
+			gutenberg.finished = true
+			return 0, io.EOF
+		}
+		gutenberg.pending = line
+		if err == io.EOF {
+			gutenberg.finished = true
+		} else if err != nil {
+			return 0, err
+		}
+	}
+
+	count := copy(buffer, gutenberg.pending)
+	gutenberg.pending = gutenberg.pending[count:]
+	return count, nil
+}
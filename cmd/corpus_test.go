@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGutenbergReader(test *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"The Project Gutenberg eBook\r\n*** START OF THE PROJECT GUTENBERG EBOOK ALICE ***\r\nDown the rabbit hole\r\n*** END OF THE PROJECT GUTENBERG EBOOK ALICE ***\r\nlicense text", "Down the rabbit hole\r\n"},
+		{"no markers here\nat all", "no markers here\nat all"},
+		{"See the note *** START OF nothing\nstays", "See the note *** START OF nothing\nstays"},
+		{"*** START OF THIS PROJECT GUTENBERG EBOOK\nno footer", "no footer"},
+		{"no header\n*** END OF the first act\nmore", "no header\n*** END OF the first act\nmore"},
+	}
+
+	for _, testCase := range tests {
+		actual, err := ioutil.ReadAll(newGutenbergReader(strings.NewReader(testCase.input)))
+		if err != nil {
+			test.Errorf("Unexpected error reading %q: %v", testCase.input, err)
+		}
+		if string(actual) != testCase.expected {
+			test.Errorf("Expected %q but got %q", testCase.expected, string(actual))
+		}
+	}
+}
+
+func TestOpenCorpusURL(test *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/alice.txt" {
+			http.NotFound(writer, request)
+			return
+		}
+		writer.Write([]byte("header\n*** START OF THE PROJECT GUTENBERG EBOOK\nattack a tacky\n*** END OF THE PROJECT GUTENBERG EBOOK\nfooter"))
+	}))
+	defer server.Close()
+
+	corpus, err := openCorpus(server.URL + "/alice.txt")
+	if err != nil {
+		test.Fatalf("Unexpected error opening corpus: %v", err)
+	}
+	defer corpus.Close()
+
+	trie, total := readNgramsIntoTrie(newGutenbergReader(corpus), 4)
+	if total != 9 {
+		test.Errorf("Expected 9 ngrams from the text between the markers but got %d", total)
+	}
+	if count, _ := trie.Get("TACK"); count != 2 {
+		test.Errorf("Expected TACK twice but got %d", count)
+	}
+
+	if _, err := openCorpus(server.URL + "/missing.txt"); err == nil {
+		test.Errorf("Expected an error for a missing URL")
+	}
+}
+
+func TestParseWeightedCorpus(test *testing.T) {
+	tests := []struct {
+		spec   string
+		name   string
+		weight float64
+	}{
+		{"books.txt", "books.txt", 1},
+		{"words.txt:0.3", "words.txt", 0.3},
+		{"-:2", "-", 2},
+		{"https://www.gutenberg.org/cache/epub/11/pg11.txt", "https://www.gutenberg.org/cache/epub/11/pg11.txt", 1},
+		{"https://example.com/words.txt:0.5", "https://example.com/words.txt", 0.5},
+		{"notes:draft.txt", "notes:draft.txt", 1},
+		{"http://localhost:8080", "http://localhost:8080", 1},
+		{"http://localhost:8080/book.txt", "http://localhost:8080/book.txt", 1},
+		{"http://localhost:8080/book.txt:2", "http://localhost:8080/book.txt", 2},
+	}
+	for _, testCase := range tests {
+		corpus, err := parseWeightedCorpus(testCase.spec)
+		if err != nil || corpus.name != testCase.name || corpus.weight != testCase.weight {
+			test.Errorf("Expected %s to be %s with weight %f but got %v (%v)", testCase.spec, testCase.name, testCase.weight, corpus, err)
+		}
+	}
+
+	for _, bad := range []string{"words.txt:0", "words.txt:-1", "words.txt:NaN"} {
+		if _, err := parseWeightedCorpus(bad); err == nil {
+			test.Errorf("Expected an error for %s", bad)
+		}
+	}
+}
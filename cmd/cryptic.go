@@ -0,0 +1,350 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var crypticEnumeration string
+
+// crypticCmd represents the cryptic command
+var crypticCmd = &cobra.Command{
+	Use:   "cryptic CLUE...",
+	Short: "Looks for anagram, hidden word, and reversal wordplay in a cryptic crossword clue",
+	Long: `Finds the words in the clue that usually indicate an anagram, a hidden word, or a reversal,
+	takes the words next to each one as possible fodder, and tries the wordplay on any fodder with the
+	right number of letters. The enumeration is read from the end of the clue, as in (9) or (4,5),
+	or can be given with --enumeration.
+
+	Examples:
+	  puzzle_helper cryptic "Dirty room is broken for students (9)" --dictionary words.txt`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  solveCrypticClue,
+}
+
+// crypticIndicators are words and phrases that often signal each kind of wordplay
+var crypticIndicators = map[string][]string{
+	"anagram": {"about", "abroad", "around", "awful", "awfully", "bad", "badly", "broken", "changed", "confused", "cooked",
+		"crazy", "damaged", "dancing", "disturbed", "drunk", "mad", "messy", "mixed", "mixed up", "new", "novel", "odd",
+		"oddly", "off", "out", "poor", "poorly", "rearranged", "reformed", "ruined", "scrambled", "shaken", "silly",
+		"strange", "strangely", "twisted", "unusual", "upset", "wild", "wrecked", "wrong"},
+	"hidden": {"among", "amid", "concealed", "conceals", "contains", "from", "held by", "hidden", "hides", "holds",
+		"housed", "in", "inside", "part of", "partly", "some", "within"},
+	"reversal": {"back", "backwards", "going back", "recalled", "reflected", "retiring", "returned", "returning",
+		"returns", "reversed", "turned", "turned back", "up", "westward"},
+}
+
+// crypticLinkWords can sit between an indicator and its fodder without being part of either, as in "room is broken"
+var crypticLinkWords = map[string]bool{"A": true, "AND": true, "BEING": true, "FOR": true, "IS": true, "OF": true, "THE": true, "TO": true, "WITH": true}
+
+// the kinds of wordplay in the order they're tried
+var crypticWordplayKinds = []string{"anagram", "hidden", "reversal"}
+
+var enumerationPattern = regexp.MustCompile(`\(\s*(\d+(?:\s*[,\- ]\s*\d+)*)\s*\)\s*$`)
+
+// crypticIndicator is an indicator found in a clue, covering clue words start up to (not including) end
+type crypticIndicator struct {
+	kind   string
+	phrase string
+	start  int
+	end    int
+}
+
+// crypticFodder is a run of clue words next to an indicator that the wordplay could apply to
+type crypticFodder struct {
+	indicator crypticIndicator
+	words     []string
+}
+
+func (fodder crypticFodder) letters() string {
+	return strings.Join(fodder.words, "")
+}
+
+// crypticSolution is a dictionary answer the wordplay gives from some fodder
+type crypticSolution struct {
+	fodder crypticFodder
+	answer string
+}
+
+func solveCrypticClue(cmd *cobra.Command, args []string) {
+	if len(dictionaryFiles) == 0 {
+		fmt.Println("A dictionary file is required for solving cryptic clues")
+		os.Exit(1)
+	}
+	clue, enumeration, err := parseCrypticClue(strings.Join(args, " "), crypticEnumeration)
+	if err != nil {
+		fmt.Printf("Invalid clue: %v\n", err)
+		os.Exit(1)
+	}
+
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+
+	ctx, cancel := solveContext()
+	defer cancel()
+
+	printer := newResultPrinter("wordplay", "indicator", "fodder", "answer")
+	words := crypticClueWords(clue)
+	indicators := findCrypticIndicators(words)
+	if len(indicators) == 0 {
+		printer.text("No anagram, hidden word, or reversal indicators found")
+	}
+	for _, indicator := range indicators {
+		printer.text("Possible %s indicator: %s", indicator.kind, indicator.phrase)
+	}
+
+	for _, solution := range solveCrypticFodder(ctx, rootTrie, crypticFodderCandidates(words, indicators, enumeration), enumeration) {
+		indicator := solution.fodder.indicator
+		fodder := strings.Join(solution.fodder.words, " ")
+		printer.result(fmt.Sprintf("%s of %s (%s): %s", indicator.kind, fodder, indicator.phrase, solution.answer),
+			indicator.kind, indicator.phrase, fodder, solution.answer)
+	}
+	printer.finish(ctx.Err() != nil)
+}
+
+// parseCrypticClue splits the enumeration off the end of the clue, or uses override if it's given.
+// The enumeration is returned as the length of each word in the answer
+func parseCrypticClue(clue string, override string) (string, []int, error) {
+	lengthsText := override
+	if match := enumerationPattern.FindStringSubmatchIndex(clue); match != nil {
+		if lengthsText == "" {
+			lengthsText = clue[match[2]:match[3]]
+		}
+		clue = strings.TrimSpace(clue[:match[0]])
+	}
+	if lengthsText == "" {
+		return "", nil, fmt.Errorf("no enumeration such as (5) or (3,4) at the end of the clue and no --enumeration")
+	}
+
+	lengths, err := parseEnumeration(lengthsText)
+	if err != nil {
+		return "", nil, err
+	}
+	return clue, lengths, nil
+}
+
+// parseEnumeration reads word lengths such as 9, 4,5, or (5-3), with or without the parentheses
+func parseEnumeration(text string) ([]int, error) {
+	lengths := make([]int, 0, 2)
+	for _, part := range strings.FieldsFunc(strings.Trim(strings.TrimSpace(text), "()"), func(separator rune) bool {
+		return separator == ',' || separator == '-' || separator == ' '
+	}) {
+		length, err := strconv.Atoi(part)
+		if err != nil || length < 1 {
+			return nil, fmt.Errorf("%s is not a valid enumeration", text)
+		}
+		lengths = append(lengths, length)
+	}
+	if len(lengths) == 0 {
+		return nil, fmt.Errorf("%s is not a valid enumeration", text)
+	}
+	return lengths, nil
+}
+
+// crypticClueWords splits a clue into its words, uppercased and without punctuation
+func crypticClueWords(clue string) []string {
+	words := make([]string, 0)
+	for _, token := range strings.Fields(clue) {
+		if word := string(justUppercaseLetters(token)); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// findCrypticIndicators finds every indicator phrase in words, in the order they appear
+func findCrypticIndicators(words []string) []crypticIndicator {
+	indicators := make([]crypticIndicator, 0)
+	for start := range words {
+		for _, kind := range crypticWordplayKinds {
+			for _, phrase := range crypticIndicators[kind] {
+				phraseWords := strings.Fields(strings.ToUpper(phrase))
+				end := start + len(phraseWords)
+				if end <= len(words) && strings.Join(words[start:end], " ") == strings.Join(phraseWords, " ") {
+					indicators = append(indicators, crypticIndicator{kind, phrase, start, end})
+				}
+			}
+		}
+	}
+	return indicators
+}
+
+// crypticFodderCandidates takes the runs of words just before and just after each indicator. Anagram and
+// reversal fodder has to have as many letters as the answer; hidden word fodder has to have more
+func crypticFodderCandidates(words []string, indicators []crypticIndicator, enumeration []int) []crypticFodder {
+	total := 0
+	for _, length := range enumeration {
+		total += length
+	}
+
+	candidates := make([]crypticFodder, 0)
+	fits := func(kind string, letters int) bool {
+		if kind == "hidden" {
+			return letters > total
+		}
+		return letters == total
+	}
+	for _, indicator := range indicators {
+		// the fodder can come right up to the indicator or be separated from it by a link word
+		befores := []int{indicator.start}
+		if indicator.start > 0 && crypticLinkWords[words[indicator.start-1]] {
+			befores = append(befores, indicator.start-1)
+		}
+		for _, before := range befores {
+			letters := 0
+			for start := before - 1; start >= 0 && (letters < total || indicator.kind == "hidden"); start-- {
+				letters += len(words[start])
+				if fits(indicator.kind, letters) {
+					candidates = append(candidates, crypticFodder{indicator, words[start:before]})
+				}
+				if indicator.kind == "hidden" && letters-len(words[start]) > total {
+					// the hidden word has to start in the word furthest from the indicator
+					break
+				}
+			}
+		}
+
+		afters := []int{indicator.end}
+		if indicator.end < len(words) && crypticLinkWords[words[indicator.end]] {
+			afters = append(afters, indicator.end+1)
+		}
+		for _, after := range afters {
+			letters := 0
+			for end := after + 1; end <= len(words) && (letters < total || indicator.kind == "hidden"); end++ {
+				letters += len(words[end-1])
+				if fits(indicator.kind, letters) {
+					candidates = append(candidates, crypticFodder{indicator, words[after:end]})
+				}
+				if indicator.kind == "hidden" && letters-len(words[end-1]) > total {
+					break
+				}
+			}
+		}
+	}
+	return candidates
+}
+
+// solveCrypticFodder applies each candidate's wordplay and keeps the answers that are in the dictionary
+// and fit the enumeration. The same answer from the same wordplay is only given once
+func solveCrypticFodder(ctx context.Context, rootTrie *trieNode, candidates []crypticFodder, enumeration []int) []crypticSolution {
+	solutions := make([]crypticSolution, 0)
+	seen := make(map[string]bool)
+	add := func(fodder crypticFodder, answer string) {
+		key := fodder.indicator.kind + " " + answer
+		if !seen[key] {
+			seen[key] = true
+			solutions = append(solutions, crypticSolution{fodder, answer})
+		}
+	}
+
+	for _, fodder := range candidates {
+		if ctx.Err() != nil {
+			break
+		}
+		letters := fodder.letters()
+		switch fodder.indicator.kind {
+		case "anagram":
+			for _, answer := range crypticAnagrams(ctx, rootTrie, letters, enumeration) {
+				add(fodder, answer)
+			}
+		case "reversal":
+			if answer, found := splitIntoEnumeration(rootTrie, reverseString(letters), enumeration); found {
+				add(fodder, answer)
+			}
+		case "hidden":
+			for _, hidden := range hiddenRuns(fodder.words, enumeration) {
+				if answer, found := splitIntoEnumeration(rootTrie, hidden, enumeration); found {
+					add(fodder, answer)
+				}
+			}
+		}
+	}
+	return solutions
+}
+
+// crypticAnagrams finds the transposals of letters whose word lengths match the enumeration, leaving out
+// the fodder itself since the answer can't just be the clue's own words. Every ordering of the words is
+// searched, since the enumeration decides which one comes first
+func crypticAnagrams(ctx context.Context, rootTrie *trieNode, letters string, enumeration []int) []string {
+	answers := make([]string, 0)
+	for words := range streamTransposals(ctx, rootTrie, transposalRequest{letterCounts: createLetterCountsMap(letters), permutations: true}) {
+		if len(words) != len(enumeration) || strings.Join(words, "") == letters {
+			continue
+		}
+		matches := true
+		for index, word := range words {
+			matches = matches && len(word) == enumeration[index]
+		}
+		if matches {
+			answers = append(answers, strings.Join(words, " "))
+		}
+	}
+	return answers
+}
+
+// hiddenRuns lists the runs of letters in words as long as the answer that start in the first word and
+// end in the last, so they span the fodder the way hidden words do
+func hiddenRuns(words []string, enumeration []int) []string {
+	total := 0
+	for _, length := range enumeration {
+		total += length
+	}
+	letters := strings.Join(words, "")
+	lastStart := len(letters) - len(words[len(words)-1])
+
+	runs := make([]string, 0)
+	for start := 0; start < len(words[0]) && start+total <= len(letters); start++ {
+		if len(words) > 1 && start+total <= lastStart {
+			continue
+		}
+		run := letters[start : start+total]
+		if len(words) == 1 && run == letters {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// splitIntoEnumeration splits letters up by the enumeration and checks each piece is in the dictionary
+func splitIntoEnumeration(rootTrie *trieNode, letters string, enumeration []int) (string, bool) {
+	pieces := make([]string, 0, len(enumeration))
+	for _, length := range enumeration {
+		if length > len(letters) {
+			return "", false
+		}
+		if _, found := rootTrie.Get(letters[:length]); !found {
+			return "", false
+		}
+		pieces = append(pieces, letters[:length])
+		letters = letters[length:]
+	}
+	return strings.Join(pieces, " "), letters == ""
+}
+
+func init() {
+	crypticCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+	crypticCmd.MarkFlagRequired("dictionary")
+	crypticCmd.Flags().StringVarP(&crypticEnumeration, "enumeration", "e", "", "the answer's word lengths, such as 9 or 4,5, if the clue doesn't end with one")
+	rootCmd.AddCommand(crypticCmd)
+}
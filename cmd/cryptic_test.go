@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseCrypticClue(test *testing.T) {
+	tests := []struct {
+		clue          string
+		override      string
+		expectedClue  string
+		expectedEnums []int
+	}{
+		{"Dirty room is broken for students (9)", "", "Dirty room is broken for students", []int{9}},
+		{"Some clue (4,5)", "", "Some clue", []int{4, 5}},
+		{"Some clue (4-5)", "", "Some clue", []int{4, 5}},
+		{"Some clue ( 3, 2 )", "", "Some clue", []int{3, 2}},
+		{"Some clue", "6", "Some clue", []int{6}},
+		{"Some clue (5)", "2,3", "Some clue", []int{2, 3}},
+	}
+	for _, testCase := range tests {
+		clue, enumeration, err := parseCrypticClue(testCase.clue, testCase.override)
+		if err != nil {
+			test.Errorf("Unexpected error parsing %s: %v", testCase.clue, err)
+			continue
+		}
+		if clue != testCase.expectedClue || !intSlicesEqual(enumeration, testCase.expectedEnums) {
+			test.Errorf("Expected %q with %v but got %q with %v", testCase.expectedClue, testCase.expectedEnums, clue, enumeration)
+		}
+	}
+
+	for _, bad := range []string{"No enumeration here", "Zero letters (0)"} {
+		if _, _, err := parseCrypticClue(bad, ""); err == nil {
+			test.Errorf("Expected an error parsing %s", bad)
+		}
+	}
+}
+
+func intSlicesEqual(first, second []int) bool {
+	if len(first) != len(second) {
+		return false
+	}
+	for index := range first {
+		if first[index] != second[index] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFindCrypticIndicators(test *testing.T) {
+	indicators := findCrypticIndicators(crypticClueWords("Evil, mixed up, is fiendish"))
+	found := make([]string, 0)
+	for _, indicator := range indicators {
+		found = append(found, indicator.kind+":"+indicator.phrase)
+	}
+	expected := "anagram:mixed anagram:mixed up reversal:up"
+	if strings.Join(found, " ") != expected {
+		test.Errorf("Expected %s but got %v", expected, found)
+	}
+}
+
+func TestCrypticFodderCandidates(test *testing.T) {
+	words := crypticClueWords("Dirty room is broken for students")
+	candidates := crypticFodderCandidates(words, findCrypticIndicators(words), []int{9})
+	if len(candidates) != 1 || candidates[0].letters() != "DIRTYROOM" {
+		test.Errorf("Expected DIRTY ROOM, skipping the link word, as the only fodder but got %v", candidates)
+	}
+
+	if runs := hiddenRuns([]string{"DEAR", "TOPIC"}, []int{3}); strings.Join(runs, " ") != "ART RTO" {
+		test.Errorf("Expected the runs spanning both words but got %v", runs)
+	}
+}
+
+func TestSolveCrypticFodder(test *testing.T) {
+	rootTrie := newTrie()
+	for _, word := range []string{"DORMITORY", "DIRTY", "ROOM", "STAR", "RATS", "ART", "EAR", "TEA"} {
+		rootTrie.Add(word, nil)
+	}
+
+	tests := []struct {
+		clue     string
+		expected string
+	}{
+		{"Dirty room is broken for students (9)", "anagram DORMITORY"},
+		{"Rats returned to shine (4)", "reversal STAR"},
+		{"Painting hidden in dear topic (3)", "hidden EAR|hidden ART"},
+	}
+	for _, testCase := range tests {
+		clue, enumeration, _ := parseCrypticClue(testCase.clue, "")
+		words := crypticClueWords(clue)
+		candidates := crypticFodderCandidates(words, findCrypticIndicators(words), enumeration)
+		found := make([]string, 0)
+		for _, solution := range solveCrypticFodder(context.Background(), rootTrie, candidates, enumeration) {
+			found = append(found, solution.fodder.indicator.kind+" "+solution.answer)
+		}
+		if strings.Join(found, "|") != testCase.expected {
+			test.Errorf("Expected %s for %s but got %v", testCase.expected, testCase.clue, found)
+		}
+	}
+}
@@ -17,6 +17,9 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -24,6 +27,15 @@ import (
 
 // cryptogramCmd represents the cryptogram command
 var concurrency int
+var cribs []string
+var maxUnmatched int
+var solveCipherSymbols string
+var showFrequencyChart bool
+var frequencySvgFile string
+var frequencyNgramSize int
+var frequencyPeriod int
+var frequencyWindow int
+var frequencyWindowStep int
 
 var cryptogramCmd = &cobra.Command{
 	Use:   "cryptogram",
@@ -50,7 +62,6 @@ var substitutionCmd = &cobra.Command{
 var substitutionReplCmd = &cobra.Command{
 	Use:   "repl",
 	Short: "Creates an interactive session for solving substitution ciphers",
-	Args:  cobra.MinimumNArgs(1),
 	Run:   substitutionShell,
 }
 
@@ -74,11 +85,21 @@ var caesarCmd = &cobra.Command{
 
 func init() {
 	substitutionCmd.AddCommand(substitutionReplCmd)
-	substitutionSolveCmd.Flags().StringVarP(&dictionaryFile, "dictionary", "d", "", "Dictionary file to use, or - to use stdin")
+	substitutionSolveCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
 	substitutionSolveCmd.MarkFlagRequired("dictionary")
 	substitutionSolveCmd.Flags().IntVarP(&concurrency, "concurrency", "c", 10, "The maximum goroutines to create for solving. Defaults to 10.")
+	substitutionSolveCmd.Flags().StringArrayVarP(&cribs, "crib", "", nil, "known plaintext for a word, as N=WORD where N is the word's position starting at 1. Can be repeated")
+	substitutionSolveCmd.Flags().StringVarP(&substitutionKey, "key", "k", "", "letters that are already known, as comma-separated A=b mappings like the REPL")
+	substitutionSolveCmd.Flags().StringVarP(&solveCipherSymbols, "cipher-symbols", "s", "", "digits or punctuation in the ciphertext that stand for letters, such as 0123456789 or '")
+	substitutionSolveCmd.Flags().IntVarP(&maxUnmatched, "max-unmatched", "", 0, "how many words with no dictionary matches (proper nouns, rare words) to leave unsolved instead of giving up")
 	substitutionCmd.AddCommand(substitutionSolveCmd)
 
+	freqCmd.Flags().BoolVarP(&showFrequencyChart, "chart", "", false, "print an ASCII bar chart of the letter frequencies")
+	freqCmd.Flags().StringVarP(&frequencySvgFile, "svg", "", "", "write an SVG histogram of the letter frequencies to this path")
+	freqCmd.Flags().IntVarP(&frequencyNgramSize, "ngram", "n", 1, "count single letters (1), digraphs (2), or trigraphs (3)")
+	freqCmd.Flags().IntVarP(&frequencyWindow, "window", "w", 0, "slide a window of this many letters along the text and report where its index of coincidence or letter frequencies shift, such as where one cipher ends and another begins")
+	freqCmd.Flags().IntVarP(&frequencyWindowStep, "step", "", 0, "how many letters the --window moves each time. Defaults to a quarter of the window")
+	freqCmd.Flags().IntVarP(&frequencyPeriod, "period", "p", 0, "split the text into this many columns and print each one's index of coincidence, to check a polyalphabetic cipher's period")
 	cryptogramCmd.AddCommand(freqCmd)
 	cryptogramCmd.AddCommand(substitutionCmd)
 	cryptogramCmd.AddCommand(caesarCmd)
@@ -87,17 +108,103 @@ func init() {
 
 // printFrequencyTable generates output about the frequency of characters, digraphs, and trigraphs in a string
 func printFrequencyTable(cmd *cobra.Command, args []string) {
-	totalString := strings.Join(args, " ")
+	// every table counts the same letters, whatever case they were typed in
+	totalString := frequencyLetters(strings.Join(args, " "))
+	if frequencyPeriod < 0 {
+		fmt.Println("--period has to be at least 1")
+		os.Exit(1)
+	}
+	if frequencyPeriod > 0 {
+		printPeriodicAnalysis(totalString, frequencyPeriod)
+		return
+	}
+	if frequencyWindow != 0 {
+		step := frequencyWindowStep
+		if step == 0 {
+			step = frequencyWindow / 4
+			if step == 0 {
+				step = 1
+			}
+		}
+		if frequencyWindow < 2 || step < 1 {
+			fmt.Println("--window has to be at least 2 letters and --step at least 1")
+			os.Exit(1)
+		}
+		printWindowAnalysis(totalString, frequencyWindow, step)
+		return
+	}
+	switch frequencyNgramSize {
+	case 1:
+	case 2, 3:
+		printNgramFrequencyTable(totalString, frequencyNgramSize)
+		return
+	default:
+		fmt.Println("--ngram can be 1 (letters), 2 (digraphs), or 3 (trigraphs)")
+		os.Exit(1)
+	}
+
 	singleLetterCounts := frequencyCountInString(totalString)
 	totalLetterCount := countTotalCharacters(totalString)
-	fmt.Println("Frequency Table")
-	fmt.Println("---------------")
-	fmt.Printf("Total letters: %v\n", totalLetterCount)
-	for curByte, count := range singleLetterCounts {
-		fmt.Printf("%c: %v (%v%%)\n", curByte, count, fmt.Sprintf("%.2f", 100.0*(float32(count)/float32(totalLetterCount))))
+	printer := newResultPrinter("letter", "count", "percent", "english_percent", "flag")
+	printer.text("Frequency Table")
+	printer.text("---------------")
+	printer.text("Total letters: %v", totalLetterCount)
+	for _, row := range letterFrequencyStats(singleLetterCounts, totalLetterCount) {
+		printer.result(row.String(), row.letter, strconv.Itoa(row.count), fmt.Sprintf("%.2f", row.percent), fmt.Sprintf("%.2f", row.englishPercent), row.flag)
+	}
+	printer.finish(false)
+
+	// the chart would get in the way of the JSON, but an SVG can still be written
+	showChart := showFrequencyChart && !printer.json
+	if !showChart && frequencySvgFile == "" {
+		return
+	}
+
+	// charts always go in alphabetical order so the shape of the distribution is visible
+	labels := make([]string, 0, 26)
+	values := make([]int, 0, 26)
+	for curByte := byte('A'); curByte <= byte('Z'); curByte++ {
+		labels = append(labels, string(curByte))
+		values = append(values, singleLetterCounts[curByte])
+	}
+
+	if showChart {
+		fmt.Println()
+		writeAsciiBarChart(os.Stdout, labels, values)
+	}
+
+	if frequencySvgFile != "" {
+		writeSvgHistogramToFile(frequencySvgFile, labels, values)
+	}
+}
+
+// writeSvgHistogramToFile creates path and writes an SVG histogram of values to it
+func writeSvgHistogramToFile(path string, labels []string, values []int) {
+	svgFile, err := os.Create(path)
+	if err != nil {
+		fmt.Printf("Could not open %s for writing: %v\n", path, err)
+		os.Exit(1)
+	}
+	defer svgFile.Close()
+
+	err = writeSvgHistogram(svgFile, labels, values)
+	if err != nil {
+		fmt.Printf("Could not write histogram to %s: %v\n", path, err)
+		os.Exit(1)
 	}
 }
 
+// frequencyLetters folds text into the active alphabet and drops everything else, the same way the ngram
+// scanner reads it, so single letters and ngrams are counted from the same letters
+func frequencyLetters(text string) string {
+	letters := make([]byte, 0, len(text))
+	scanner := NewNgramScanner(strings.NewReader(text), 1, false)
+	for scanner.Scan() {
+		letters = append(letters, scanner.Bytes()...)
+	}
+	return string(letters)
+}
+
 // countTotalCharacters counts the number of uppercase letters in the given string
 func countTotalCharacters(toCount string) int {
 	var totalCount = 0
@@ -127,6 +234,200 @@ func frequencyCountInString(toCount string) map[byte]int {
 	return counts
 }
 
+// letterFrequency is how often a letter appears, as a percentage of all letters
+type letterFrequency struct {
+	letter  byte
+	percent float64
+}
+
+// englishLetterFrequencies are the usual frequencies of letters in English text, most common first
+var englishLetterFrequencies = []letterFrequency{
+	{'E', 12.70}, {'T', 9.06}, {'A', 8.17}, {'O', 7.51}, {'I', 6.97}, {'N', 6.75}, {'S', 6.33},
+	{'H', 6.09}, {'R', 5.99}, {'D', 4.25}, {'L', 4.03}, {'C', 2.78}, {'U', 2.76}, {'M', 2.41},
+	{'W', 2.36}, {'F', 2.23}, {'G', 2.02}, {'Y', 1.97}, {'P', 1.93}, {'B', 1.29}, {'V', 0.98},
+	{'K', 0.77}, {'J', 0.15}, {'X', 0.15}, {'Q', 0.10}, {'Z', 0.07},
+}
+
+// how many percentage points a letter's frequency can be off from English before it's flagged
+const frequencyDeviationThreshold = 3.0
+
+// letterFrequencyRow is one letter's count in a text next to how common it is in English. flag is high
+// or low when the two are well apart
+type letterFrequencyRow struct {
+	letter         string
+	count          int
+	percent        float64
+	englishPercent float64
+	flag           string
+}
+
+func (row letterFrequencyRow) String() string {
+	flag := ""
+	if row.flag != "" {
+		flag = " <- " + row.flag
+	}
+	return fmt.Sprintf("%s: %v (%.2f%%, English %.2f%%)%s", row.letter, row.count, row.percent, row.englishPercent, flag)
+}
+
+// letterFrequencyRows describes each letter's count, from most to least common, next to how common
+// the letter is in English. Letters that are well off from English are flagged, including common
+// English letters that don't show up at all
+func letterFrequencyRows(counts map[byte]int, total int) []string {
+	stats := letterFrequencyStats(counts, total)
+	rows := make([]string, 0, len(stats))
+	for _, row := range stats {
+		rows = append(rows, row.String())
+	}
+	return rows
+}
+
+// letterFrequencyStats is letterFrequencyRows before it's formatted
+func letterFrequencyStats(counts map[byte]int, total int) []letterFrequencyRow {
+	english := make(map[byte]float64)
+	for _, frequency := range englishLetterFrequencies {
+		english[frequency.letter] = frequency.percent
+	}
+
+	sorted := make([]ngramCount, 0, len(english))
+	for letter, percent := range english {
+		if counts[letter] > 0 || percent >= frequencyDeviationThreshold {
+			sorted = append(sorted, ngramCount{string(letter), counts[letter]})
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].count == sorted[j].count {
+			return sorted[i].ngram < sorted[j].ngram
+		}
+		return sorted[i].count > sorted[j].count
+	})
+
+	rows := make([]letterFrequencyRow, 0, len(sorted))
+	for _, letterCount := range sorted {
+		percent := 0.0
+		if total > 0 {
+			percent = 100.0 * float64(letterCount.count) / float64(total)
+		}
+		englishPercent := english[letterCount.ngram[0]]
+		flag := ""
+		if percent-englishPercent >= frequencyDeviationThreshold {
+			flag = "high"
+		} else if englishPercent-percent >= frequencyDeviationThreshold {
+			flag = "low"
+		}
+		rows = append(rows, letterFrequencyRow{letterCount.ngram, letterCount.count, percent, englishPercent, flag})
+	}
+	return rows
+}
+
+// englishNgramFrequencies are the most common digraphs and trigraphs in English text, as percentages of
+// all the digraphs or trigraphs, most common first. They're counted across word breaks
+var englishNgramFrequencies = map[int][]ngramFrequency{
+	2: {
+		{"TH", 3.56}, {"HE", 3.07}, {"IN", 2.43}, {"ER", 2.05}, {"AN", 1.99}, {"RE", 1.85}, {"ON", 1.76},
+		{"AT", 1.49}, {"EN", 1.45}, {"ND", 1.35}, {"TI", 1.34}, {"ES", 1.34}, {"OR", 1.28}, {"TE", 1.20},
+		{"OF", 1.17}, {"ED", 1.17}, {"IS", 1.13}, {"IT", 1.12}, {"AL", 1.09}, {"AR", 1.07}, {"ST", 1.05},
+		{"TO", 1.04}, {"NT", 1.04}, {"NG", 0.95}, {"SE", 0.93}, {"HA", 0.93}, {"AS", 0.87}, {"OU", 0.87},
+		{"IO", 0.83}, {"LE", 0.83},
+	},
+	3: {
+		{"THE", 1.81}, {"AND", 0.73}, {"ING", 0.72}, {"ENT", 0.42}, {"ION", 0.42}, {"HER", 0.36}, {"FOR", 0.34},
+		{"THA", 0.33}, {"NTH", 0.33}, {"INT", 0.32}, {"ERE", 0.31}, {"TIO", 0.31}, {"TER", 0.30}, {"EST", 0.28},
+		{"ERS", 0.28}, {"ATI", 0.26}, {"HAT", 0.26}, {"ATE", 0.25}, {"ALL", 0.25}, {"ETH", 0.24}, {"HES", 0.24},
+		{"VER", 0.24}, {"HIS", 0.24}, {"OFT", 0.22}, {"ITH", 0.21}, {"FTH", 0.21}, {"STH", 0.21}, {"OTH", 0.21},
+		{"RES", 0.21}, {"ONT", 0.20},
+	},
+}
+
+// ngramFrequency is how often a digraph or trigraph appears, as a percentage of all of them
+type ngramFrequency struct {
+	ngram   string
+	percent float64
+}
+
+// ngramCount is how many times an ngram appears in a text
+type ngramCount struct {
+	ngram string
+	count int
+}
+
+// countNgrams counts the ngrams of size in text, running across spaces and punctuation the way the
+// English tables do. They're sorted from most to least common, alphabetically for ties
+func countNgrams(text string, size int) ([]ngramCount, int) {
+	counts := make(map[string]int)
+	total := 0
+	scanner := NewNgramScanner(strings.NewReader(text), size, false)
+	for scanner.Scan() {
+		counts[scanner.Text()]++
+		total++
+	}
+	return sortedNgramCounts(counts), total
+}
+
+// sortedNgramCounts turns counts into a list from most to least common, alphabetically for ties
+func sortedNgramCounts(counts map[string]int) []ngramCount {
+	sorted := make([]ngramCount, 0, len(counts))
+	for ngram, count := range counts {
+		sorted = append(sorted, ngramCount{ngram, count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].count == sorted[j].count {
+			return sorted[i].ngram < sorted[j].ngram
+		}
+		return sorted[i].count > sorted[j].count
+	})
+	return sorted
+}
+
+// printNgramFrequencyTable prints the digraphs or trigraphs in text from most to least common, next to
+// how common each is in English, followed by the most common English ones to compare against
+func printNgramFrequencyTable(text string, size int) {
+	name := map[int]string{2: "Digraph", 3: "Trigraph"}[size]
+	counts, total := countNgrams(text, size)
+	english := make(map[string]float64)
+	englishOrder := make([]string, 0, len(englishNgramFrequencies[size]))
+	for _, frequency := range englishNgramFrequencies[size] {
+		english[frequency.ngram] = frequency.percent
+		englishOrder = append(englishOrder, frequency.ngram)
+	}
+
+	printer := newResultPrinter("ngram", "count", "percent", "english_percent")
+	title := name + " Frequency Table"
+	printer.text(title)
+	printer.text(strings.Repeat("-", len(title)))
+	printer.text("Total %ss: %v", strings.ToLower(name), total)
+	for _, count := range counts {
+		englishPercent := ""
+		textPercent := "-"
+		if percent, known := english[count.ngram]; known {
+			englishPercent = fmt.Sprintf("%.2f", percent)
+			textPercent = englishPercent + "%"
+		}
+		percent := fmt.Sprintf("%.2f", 100.0*float64(count.count)/float64(total))
+		printer.result(fmt.Sprintf("%s: %v (%s%%, English %s)", count.ngram, count.count, percent, textPercent), count.ngram, strconv.Itoa(count.count), percent, englishPercent)
+	}
+	printer.text("\nMost common in English: %s", strings.Join(englishOrder, " "))
+	printer.finish(false)
+
+	showChart := showFrequencyChart && !printer.json
+	if !showChart && frequencySvgFile == "" {
+		return
+	}
+	// there are too many possible ngrams to chart them all, so just the most common ones are
+	labels := make([]string, 0, 26)
+	values := make([]int, 0, 26)
+	for index := 0; index < len(counts) && index < 26; index++ {
+		labels = append(labels, counts[index].ngram)
+		values = append(values, counts[index].count)
+	}
+	if showChart {
+		fmt.Println()
+		writeAsciiBarChart(os.Stdout, labels, values)
+	}
+	if frequencySvgFile != "" {
+		writeSvgHistogramToFile(frequencySvgFile, labels, values)
+	}
+}
+
 func isUppercaseAscii(check byte) bool {
 	return check >= 65 && check < 91
 }
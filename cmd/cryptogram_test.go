@@ -71,3 +71,78 @@ func TestCountTotalCharacters(test *testing.T) {
 		}
 	}
 }
+
+func TestCountNgrams(test *testing.T) {
+	counts, total := countNgrams("THE CAT, THE HAT", 2)
+	if total != 11 {
+		test.Errorf("Expected 11 digraphs across the word breaks but got %d", total)
+	}
+	expected := []ngramCount{{"AT", 2}, {"HE", 2}, {"TH", 2}, {"CA", 1}}
+	for index, expectedCount := range expected {
+		if counts[index] != expectedCount {
+			test.Errorf("Expected %v at %d but got %v", expectedCount, index, counts[index])
+		}
+	}
+
+	counts, total = countNgrams("THE THE", 3)
+	if total != 4 || counts[0] != (ngramCount{"THE", 2}) {
+		test.Errorf("Expected THE twice out of 4 trigraphs but got %v of %d", counts, total)
+	}
+
+	if _, total = countNgrams("AB", 3); total != 0 {
+		test.Errorf("Expected no trigraphs in a two-letter text but got %d", total)
+	}
+}
+
+func TestFrequencyLetters(test *testing.T) {
+	tests := map[string]string{
+		"hello world": "HELLOWORLD",
+		"D'M d'll":    "DMDLL",
+		"%$ 123":      "",
+	}
+	for text, expected := range tests {
+		if actual := frequencyLetters(text); actual != expected {
+			test.Errorf("Expected %s for %s but got %s", expected, text, actual)
+		}
+	}
+}
+
+func TestFrequencyModesFoldLowercase(test *testing.T) {
+	letters := frequencyLetters("hello world")
+	if total := countTotalCharacters(letters); total != 10 {
+		test.Errorf("Expected 10 letters in lowercase input but got %d", total)
+	}
+	if counts := frequencyCountInString(letters); counts['L'] != 3 || counts['O'] != 2 {
+		test.Errorf("Expected 3 Ls and 2 Os in lowercase input but got %v", counts)
+	}
+	for size, expectedTotal := range map[int]int{2: 9, 3: 8} {
+		_, total := countNgrams(letters, size)
+		_, unfolded := countNgrams("hello world", size)
+		if total != expectedTotal || unfolded != expectedTotal {
+			test.Errorf("Expected %d ngrams of size %d in lowercase input but got %d and %d", expectedTotal, size, total, unfolded)
+		}
+	}
+}
+
+func TestLetterFrequencyRows(test *testing.T) {
+	text := "ZZZZ EEEE TA"
+	rows := letterFrequencyRows(frequencyCountInString(text), countTotalCharacters(text))
+	expected := []string{
+		"E: 4 (40.00%, English 12.70%) <- high",
+		"Z: 4 (40.00%, English 0.07%) <- high",
+		"A: 1 (10.00%, English 8.17%)",
+		"T: 1 (10.00%, English 9.06%)",
+		"D: 0 (0.00%, English 4.25%) <- low",
+		"H: 0 (0.00%, English 6.09%) <- low",
+	}
+	for index, expectedRow := range expected {
+		if index >= len(rows) || rows[index] != expectedRow {
+			test.Errorf("Expected row %d to be %q but got %v", index, expectedRow, rows)
+			break
+		}
+	}
+	// the four letters used, plus the eight missing ones common enough to be flagged
+	if len(rows) != 12 {
+		test.Errorf("Expected 12 rows but got %d: %v", len(rows), rows)
+	}
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import "fmt"
+
+// dictionaryFilter trims dictionaries to a puzzle's constraints as they're read, so big word lists
+// don't have to be cut down by hand. It's set by --min-dict-word-length, --max-dict-word-length,
+// and --exclude-words-file; lengths of 0 mean no limit
+type dictionaryFilter struct {
+	minLength int
+	maxLength int
+	excluded  map[string]bool
+}
+
+var activeDictionaryFilter dictionaryFilter
+
+// newDictionaryFilter makes a filter for the given lengths, leaving out the words of excludeFile if there is one
+func newDictionaryFilter(minLength, maxLength int, excludeFile string) (dictionaryFilter, error) {
+	if minLength < 0 || maxLength < 0 {
+		return dictionaryFilter{}, fmt.Errorf("word lengths can't be negative")
+	}
+	if maxLength > 0 && minLength > maxLength {
+		return dictionaryFilter{}, fmt.Errorf("the minimum word length %d is more than the maximum %d", minLength, maxLength)
+	}
+	filter := dictionaryFilter{minLength: minLength, maxLength: maxLength}
+	if excludeFile != "" {
+		filter.excluded = readWordSet(excludeFile)
+	}
+	return filter, nil
+}
+
+// active reports whether the filter leaves anything out
+func (filter dictionaryFilter) active() bool {
+	return filter.minLength > 0 || filter.maxLength > 0 || len(filter.excluded) > 0
+}
+
+// keeps reports whether word makes it through the filter
+func (filter dictionaryFilter) keeps(word string) bool {
+	if len(word) < filter.minLength || (filter.maxLength > 0 && len(word) > filter.maxLength) {
+		return false
+	}
+	return !filter.excluded[word]
+}
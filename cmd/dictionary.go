@@ -0,0 +1,252 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var hideUniqueWords bool
+var compiledTrieFile string
+var fetchedWordListFile string
+
+// dictionaryCmd represents the dictionary command
+var dictionaryCmd = &cobra.Command{
+	Use:   "dictionary",
+	Short: "Tools for working with the word lists the solvers use",
+}
+
+var dictionaryCompareCmd = &cobra.Command{
+	Use:   "compare FIRST SECOND",
+	Short: "Shows the words unique to each of two lists and how much they overlap",
+	Long: `
+	Before merging a themed list into a solving dictionary, it helps to know whether it adds anything.
+	This reads both lists (case is ignored, as it is everywhere else) and prints the words found in only one
+	of them, followed by counts of each list, the words they share, and what percentage of each list the
+	other one covers. Use - for either file to read it from stdin.
+	`,
+	Args: cobra.ExactArgs(2),
+	Run:  compareDictionaries,
+}
+
+var dictionaryCompileCmd = &cobra.Command{
+	Use:   "compile DICTIONARY...",
+	Short: "Builds a dictionary's trie once and saves it, so other commands can load it without rebuilding it",
+	Long: `
+	Every command that takes --dictionary builds a trie from the word list when it starts, which takes a
+	while for big lists. This builds it once and writes it to --output-file (the first dictionary's name with
+	.trie in place of its extension, by default). Pass the .trie file to --dictionary anywhere a word
+	list would go. Several dictionaries are merged in the order given, and words keep their rank by
+	position as they would have in the list.
+
+	The words are folded for --alphabet-size when they're compiled, so compile with the same size the
+	solvers will use.
+
+	Examples:
+	  puzzle_helper dictionary compile words.txt
+	  puzzle_helper transposal --dictionary words.trie BEAST
+	`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  compileDictionary,
+}
+
+var dictionaryFetchCmd = &cobra.Command{
+	Use:   "fetch enable|sowpods|wordnet",
+	Short: "Downloads a well-known word list and saves it in the format the solvers read",
+	Long: `
+	Downloads one of the standard word lists and writes it to --out (the list's name with .txt, by default)
+	as uppercase words, one per line. Entries with anything besides letters are dropped, and so are repeats.
+
+	  enable   the public domain ENABLE list used by many word games, from Peter Norvig's copy
+	  sowpods  the SOWPODS tournament Scrabble list used outside North America. Collins licenses it, so
+	           there's nowhere to fetch it from; this says so rather than downloading an unlicensed copy
+	  wordnet  the single-word lemmas of Princeton's WordNet 3.1, which leaves out most inflections
+
+	Examples:
+	  puzzle_helper dictionary fetch enable
+	  puzzle_helper dictionary fetch wordnet --out words.txt
+	`,
+	Args:      cobra.ExactValidArgs(1),
+	ValidArgs: wordListNames(),
+	Run:       fetchDictionary,
+}
+
+// wordListComparison holds the result of comparing two word lists
+type wordListComparison struct {
+	firstCount   int
+	secondCount  int
+	onlyInFirst  []string
+	onlyInSecond []string
+	shared       int
+}
+
+// jaccard returns the size of the overlap divided by the size of the union of the lists
+func (comparison wordListComparison) jaccard() float64 {
+	union := comparison.firstCount + comparison.secondCount - comparison.shared
+	if union == 0 {
+		return 0
+	}
+	return float64(comparison.shared) / float64(union)
+}
+
+func compareDictionaries(cmd *cobra.Command, args []string) {
+	comparison := compareWordLists(readWordSet(args[0]), readWordSet(args[1]))
+
+	if !hideUniqueWords {
+		fmt.Printf("Only in %s:\n", args[0])
+		for _, word := range comparison.onlyInFirst {
+			fmt.Println(word)
+		}
+		fmt.Printf("\nOnly in %s:\n", args[1])
+		for _, word := range comparison.onlyInSecond {
+			fmt.Println(word)
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%s: %d words, %d unique\n", args[0], comparison.firstCount, len(comparison.onlyInFirst))
+	fmt.Printf("%s: %d words, %d unique\n", args[1], comparison.secondCount, len(comparison.onlyInSecond))
+	fmt.Printf("Shared: %d words\n", comparison.shared)
+	fmt.Printf("%s covers %.2f%% of %s\n", args[1], percentOf(comparison.shared, comparison.firstCount), args[0])
+	fmt.Printf("%s covers %.2f%% of %s\n", args[0], percentOf(comparison.shared, comparison.secondCount), args[1])
+	fmt.Printf("Jaccard similarity: %.4f\n", comparison.jaccard())
+}
+
+// readWordSet reads every non-blank line of paths into a set of uppercase words
+func readWordSet(paths ...string) map[string]bool {
+	entries := make(chan string)
+	go func() {
+		feedDictionaryPaths(entries, paths...)
+	}()
+
+	words := make(map[string]bool)
+	for entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry != "" {
+			words[entry] = true
+		}
+	}
+	return words
+}
+
+// compareWordLists works out which words are in only one of the sets and how many they share.
+// The unique words are sorted alphabetically
+func compareWordLists(first, second map[string]bool) wordListComparison {
+	comparison := wordListComparison{firstCount: len(first), secondCount: len(second)}
+	for word := range first {
+		if second[word] {
+			comparison.shared++
+		} else {
+			comparison.onlyInFirst = append(comparison.onlyInFirst, word)
+		}
+	}
+	for word := range second {
+		if !first[word] {
+			comparison.onlyInSecond = append(comparison.onlyInSecond, word)
+		}
+	}
+	sort.Strings(comparison.onlyInFirst)
+	sort.Strings(comparison.onlyInSecond)
+	return comparison
+}
+
+func percentOf(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return 100.0 * float64(part) / float64(whole)
+}
+
+func compileDictionary(cmd *cobra.Command, args []string) {
+	output := compiledTrieFile
+	if output == "" {
+		if args[0] == "-" {
+			fmt.Println("--output-file is needed to compile a dictionary from stdin")
+			os.Exit(1)
+		}
+		output = strings.TrimSuffix(args[0], filepath.Ext(args[0])) + ".trie"
+	}
+
+	entries := make(chan dictionaryEntry)
+	go func() {
+		feedWeightedDictionaryPaths(entries, args...)
+	}()
+	trie, count := readDictionaryToRankedTrie(entries)
+
+	file, err := os.Create(output)
+	if err != nil {
+		fmt.Printf("Could not create %s: %v\n", output, err)
+		os.Exit(1)
+	}
+	defer file.Close()
+	if err := trie.Save(file, writeDictionaryWord); err != nil {
+		fmt.Printf("Could not write %s: %v\n", output, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Compiled %d words into %s\n", count, output)
+}
+
+func fetchDictionary(cmd *cobra.Command, args []string) {
+	source, err := findWordList(args[0])
+	if err != nil {
+		fmt.Printf("Could not fetch %s: %v\n", args[0], err)
+		os.Exit(1)
+	}
+	output := fetchedWordListFile
+	if output == "" {
+		output = source.name + ".txt"
+	}
+
+	// the list is written next to its destination first, so a failed download doesn't leave half a file behind
+	partial, err := os.CreateTemp(filepath.Dir(output), filepath.Base(output)+".*.partial")
+	if err != nil {
+		fmt.Printf("Could not create %s: %v\n", output, err)
+		os.Exit(1)
+	}
+	count, err := fetchWordList(&http.Client{Timeout: 10 * time.Minute}, source, partial)
+	if closeErr := partial.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(partial.Name())
+		fmt.Printf("Could not fetch %s: %v\n", source.name, err)
+		os.Exit(1)
+	}
+	if err := os.Rename(partial.Name(), output); err != nil {
+		os.Remove(partial.Name())
+		fmt.Printf("Could not write %s: %v\n", output, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Saved %d words from %s to %s\n", count, source.name, output)
+}
+
+func init() {
+	dictionaryFetchCmd.Flags().StringVarP(&fetchedWordListFile, "out", "", "", "where to save the word list. Defaults to the list's name with .txt")
+	dictionaryCmd.AddCommand(dictionaryFetchCmd)
+	dictionaryCompileCmd.Flags().StringVarP(&compiledTrieFile, "output-file", "o", "", "where to write the compiled trie. Defaults to the first dictionary with a .trie extension")
+	dictionaryCmd.AddCommand(dictionaryCompileCmd)
+	dictionaryCompareCmd.Flags().BoolVarP(&hideUniqueWords, "summary", "s", false, "only print the counts, not the unique words")
+	dictionaryCmd.AddCommand(dictionaryCompareCmd)
+	rootCmd.AddCommand(dictionaryCmd)
+}
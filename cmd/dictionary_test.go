@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareWordLists(test *testing.T) {
+	first := map[string]bool{"APPLE": true, "BANANA": true, "CHERRY": true}
+	second := map[string]bool{"BANANA": true, "DATE": true, "APPLE": true, "ELDER": true}
+
+	comparison := compareWordLists(first, second)
+	if strings.Join(comparison.onlyInFirst, ",") != "CHERRY" {
+		test.Errorf("Expected CHERRY only in the first list but got %v", comparison.onlyInFirst)
+	}
+	if strings.Join(comparison.onlyInSecond, ",") != "DATE,ELDER" {
+		test.Errorf("Expected DATE,ELDER only in the second list but got %v", comparison.onlyInSecond)
+	}
+	if comparison.shared != 2 {
+		test.Errorf("Expected 2 shared words but got %d", comparison.shared)
+	}
+	if comparison.jaccard() != 0.4 {
+		test.Errorf("Expected a Jaccard similarity of 0.4 but got %f", comparison.jaccard())
+	}
+
+	empty := compareWordLists(map[string]bool{}, map[string]bool{})
+	if empty.jaccard() != 0 || percentOf(empty.shared, empty.firstCount) != 0 {
+		test.Errorf("Expected empty lists to compare as 0")
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var dropQuoteColumns []string
+var dropQuoteRowBreaks bool
+var dropQuoteMaxResults int
+
+// dropQuoteCmd represents the dropquote command
+var dropQuoteCmd = &cobra.Command{
+	Use:   "dropquote [ROW...]",
+	Short: "Solves dropquotes",
+	Long: `Fills in a dropquote, where each column's letters have dropped out of the grid in a jumble and have to
+	go back into that column's white squares so the rows spell out a quote. The grid is given a row per argument,
+	or on stdin a line at a time, with . for a white square and # for a black one, and --columns lists the
+	letters of each column, left to right, in any order. Black squares end words, and words carry on from the
+	end of one row to the start of the next unless --row-breaks is given. The fillings where every word is in the
+	dictionary are printed with the most common words first; --max-results stops the search after that many.
+
+	Example:
+	  puzzle_helper dropquote "...#" ".#.." --columns IN,O,GW,O`,
+	Run: printDropQuotes,
+}
+
+// dropQuote is a dropquote grid. cells are its white squares in reading order, and pools has the letters left for
+// each column
+type dropQuote struct {
+	width int
+	cells []dropQuoteCell
+	pools []map[byte]int
+}
+
+// dropQuoteCell is a white square, with whether the word it's in ends there
+type dropQuoteCell struct {
+	row     int
+	column  int
+	wordEnd bool
+}
+
+func printDropQuotes(cmd *cobra.Command, args []string) {
+	quote, err := newDropQuote(gridRows(args, os.Stdin), dropQuoteColumns, dropQuoteRowBreaks)
+	if err != nil {
+		fmt.Printf("Invalid dropquote: %v\n", err)
+		os.Exit(1)
+	}
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+
+	ctx, cancel := solveContext()
+	defer cancel()
+
+	printer := newResultPrinter("quote")
+	for _, words := range rankTransposals(rootTrie, solveDropQuote(ctx, rootTrie, quote, dropQuoteMaxResults), 0) {
+		text := strings.Join(words, " ")
+		printer.result(text, text)
+	}
+	printer.finish(ctx.Err() != nil)
+}
+
+// newDropQuote reads a grid layout and the letters of each of its columns, which have to fill the column's white
+// squares exactly
+func newDropQuote(rows []string, columns []string, rowBreaks bool) (dropQuote, error) {
+	if len(rows) == 0 {
+		return dropQuote{}, fmt.Errorf("the grid has no rows")
+	}
+	quote := dropQuote{width: len(rows[0])}
+	if len(columns) != quote.width {
+		return dropQuote{}, fmt.Errorf("the grid is %d squares wide, but there are letters for %d columns", quote.width, len(columns))
+	}
+	whiteSquares := make([]int, quote.width)
+	for rowIndex, row := range rows {
+		if len(row) != quote.width {
+			return dropQuote{}, fmt.Errorf("every row should be %d squares wide, but %s is %d", quote.width, row, len(row))
+		}
+		for column := 0; column < quote.width; column++ {
+			switch row[column] {
+			case '#':
+				if count := len(quote.cells); count > 0 {
+					quote.cells[count-1].wordEnd = true
+				}
+			case '.':
+				quote.cells = append(quote.cells, dropQuoteCell{rowIndex, column, false})
+				whiteSquares[column]++
+			default:
+				return dropQuote{}, fmt.Errorf("squares are . for white or # for black, but %s has %c", row, row[column])
+			}
+		}
+		if count := len(quote.cells); rowBreaks && count > 0 {
+			quote.cells[count-1].wordEnd = true
+		}
+	}
+	if len(quote.cells) == 0 {
+		return dropQuote{}, fmt.Errorf("the grid has no white squares")
+	}
+	quote.cells[len(quote.cells)-1].wordEnd = true
+
+	for column, letters := range columns {
+		pool := make(map[byte]int)
+		for _, letter := range justUppercaseLetters(letters) {
+			pool[letter]++
+		}
+		if len(justUppercaseLetters(letters)) != whiteSquares[column] {
+			return dropQuote{}, fmt.Errorf("column %d has %d white squares but the letters %s", column+1, whiteSquares[column], letters)
+		}
+		quote.pools = append(quote.pools, pool)
+	}
+	return quote, nil
+}
+
+// solveDropQuote fills the white squares in reading order with letters from their columns, following the trie so that
+// a letter is only tried if the word it's in can still be a dictionary word. It returns the words of each way to fill
+// the grid, up to maxResults of them if that's more than 0, stopping early if ctx is done
+func solveDropQuote(ctx context.Context, rootTrie *trieNode, quote dropQuote, maxResults int) [][]string {
+	solutions := make([][]string, 0)
+	letters := make([]byte, len(quote.cells))
+	var fill func(index int, node *trieNode) bool
+	fill = func(index int, node *trieNode) bool {
+		if index == len(quote.cells) {
+			solutions = append(solutions, dropQuoteWords(quote, letters))
+			return maxResults <= 0 || len(solutions) < maxResults
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+
+		cell := quote.cells[index]
+		pool := quote.pools[cell.column]
+		for _, letter := range poolLetters(pool) {
+			child := node.Child(letter)
+			if child == nil || (cell.wordEnd && !child.IsWord()) {
+				continue
+			}
+			next := child
+			if cell.wordEnd {
+				next = rootTrie
+			}
+			letters[index] = letter
+			pool[letter]--
+			keepGoing := fill(index+1, next)
+			pool[letter]++
+			if !keepGoing {
+				return false
+			}
+		}
+		return true
+	}
+	fill(0, rootTrie)
+	return solutions
+}
+
+// poolLetters is the letters left in pool, each once, in alphabetical order
+func poolLetters(pool map[byte]int) []byte {
+	letters := make([]byte, 0, len(pool))
+	for letter, count := range pool {
+		if count > 0 {
+			letters = append(letters, letter)
+		}
+	}
+	sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+	return letters
+}
+
+// dropQuoteWords splits the letters filled into the quote's cells into its words
+func dropQuoteWords(quote dropQuote, letters []byte) []string {
+	words := make([]string, 0)
+	start := 0
+	for index, cell := range quote.cells {
+		if cell.wordEnd {
+			words = append(words, string(letters[start:index+1]))
+			start = index + 1
+		}
+	}
+	return words
+}
+
+// runDropQuoteSolver is dropquote for the solver registry
+func runDropQuoteSolver(ctx context.Context, input solverInput) (*resultTable, error) {
+	quote, err := newDropQuote(input.getList("rows"), input.getList("columns"), input.getBool("row_breaks"))
+	if err != nil {
+		return nil, err
+	}
+	rootTrie, _, err := openDictionaryTrie(input.getList("dictionary")...)
+	if err != nil {
+		return nil, err
+	}
+	table := newResultTable("quote")
+	for _, words := range rankTransposals(rootTrie, solveDropQuote(ctx, rootTrie, quote, input.getInt("max_results")), 0) {
+		table.addRow(strings.Join(words, " "))
+	}
+	return table, nil
+}
+
+func init() {
+	dropQuoteCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+	dropQuoteCmd.MarkFlagRequired("dictionary")
+	dropQuoteCmd.Flags().StringSliceVarP(&dropQuoteColumns, "columns", "c", nil, "The letters of each column, left to right, separated by commas")
+	dropQuoteCmd.MarkFlagRequired("columns")
+	dropQuoteCmd.Flags().BoolVarP(&dropQuoteRowBreaks, "row-breaks", "", false, "End a word at the end of every row, instead of carrying it on to the next")
+	dropQuoteCmd.Flags().IntVarP(&dropQuoteMaxResults, "max-results", "", 0, "Stop after this many fillings. Defaults to all of them")
+	rootCmd.AddCommand(dropQuoteCmd)
+
+	mustRegisterSolver(&solver{
+		name:        "dropquote",
+		description: "Ways to put a dropquote's column letters back into its white squares so every word is in the dictionary",
+		parameters: []solverParameter{
+			solverParameter{name: "rows", kind: solverString, description: "the grid's rows separated by commas, with . for a white square and # for a black one, such as ...#,.#..", required: true},
+			solverParameter{name: "columns", kind: solverString, description: "the letters of each column, left to right, separated by commas", required: true},
+			solverParameter{name: "dictionary", kind: solverString, description: "the dictionary file to use, or several separated by commas", required: true},
+			solverParameter{name: "row_breaks", kind: solverBool, description: "end a word at the end of every row instead of carrying it on to the next"},
+			solverParameter{name: "max_results", kind: solverInt, description: "the most fillings to return", defaultValue: "100"},
+		},
+		run: runDropQuoteSolver,
+	})
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSolveDropQuote(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"NOW", "I", "GO", "WON", "ION", "GOWN"} {
+		trie.Add(word, nil)
+	}
+
+	tests := []struct {
+		rows      []string
+		columns   []string
+		rowBreaks bool
+		expected  string
+	}{
+		// the first row can only be NOW, leaving I and GO
+		{[]string{"...#", ".#.."}, []string{"IN", "O", "GW", "O"}, false, "NOW I GO"},
+		{[]string{"..", ".."}, []string{"GW", "NO"}, false, "GOWN"},
+		// GO and WN aren't both words
+		{[]string{"..", ".."}, []string{"GW", "NO"}, true, ""},
+		{[]string{"...", "..."}, []string{"NW", "OO", "NW"}, true, "NOW WON,WON NOW"},
+	}
+	for _, currentTest := range tests {
+		quote, err := newDropQuote(currentTest.rows, currentTest.columns, currentTest.rowBreaks)
+		if err != nil {
+			test.Errorf("Expected a dropquote for %v but got %v", currentTest.rows, err)
+			continue
+		}
+		solutions := make([]string, 0)
+		for _, words := range solveDropQuote(context.Background(), trie, quote, 0) {
+			solutions = append(solutions, strings.Join(words, " "))
+		}
+		if actual := strings.Join(solutions, ","); actual != currentTest.expected {
+			test.Errorf("Expected %s for %v with %v but got %s", currentTest.expected, currentTest.rows, currentTest.columns, actual)
+		}
+	}
+
+	quote, _ := newDropQuote([]string{"...", "..."}, []string{"NW", "OO", "NW"}, true)
+	if solutions := solveDropQuote(context.Background(), trie, quote, 1); len(solutions) != 1 {
+		test.Errorf("Expected the search to stop after one filling but got %v", solutions)
+	}
+}
+
+func TestNewDropQuoteErrors(test *testing.T) {
+	tests := []struct {
+		rows    []string
+		columns []string
+	}{
+		{[]string{}, []string{}},
+		{[]string{"..", "..."}, []string{"A", "B"}},
+		{[]string{".."}, []string{"A"}},
+		{[]string{".x"}, []string{"A", "B"}},
+		{[]string{"##"}, []string{"", ""}},
+		{[]string{"..", ".#"}, []string{"AB", "CD"}},
+	}
+	for _, currentTest := range tests {
+		if _, err := newDropQuote(currentTest.rows, currentTest.columns, false); err == nil {
+			test.Errorf("Expected an error for %v with %v but got none", currentTest.rows, currentTest.columns)
+		}
+	}
+}
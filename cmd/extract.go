@@ -0,0 +1,144 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var extractMode string
+var extractUnit string
+var extractIndexes []int
+
+// extractModes are the ways extract can pick a letter out of each word or line
+var extractModes = []string{"first", "last", "nth", "diagonal"}
+
+// extractMissing stands in for a letter that a word or line is too short to have
+const extractMissing = '?'
+
+// extractCmd represents the extract command
+var extractCmd = &cobra.Command{
+	Use:   "extract [LINE...]",
+	Short: "Pulls a letter out of each word or line of a text",
+	Long: `Reads a letter out of each word of the text, or each line with --by lines, and prints them together.
+	The text is the arguments, one line per argument, or stdin a line at a time when there are none or the only
+	one is -. Only letters count, and a word too short for its letter gets a ?. --mode can be
+	  first: the first letter of each, as in an acrostic
+	  last: the last letter of each
+	  nth: the letter at --index, counting from 1, or from the end if it's negative. Several comma-separated
+	       indexes are used for the words in turn, for indexing a list of answers
+	  diagonal: the first letter of the first word, the second of the second, and so on
+
+	Example:
+	  puzzle_helper extract --by lines "Every good" "Boy deserves" "Fudge"
+	  puzzle_helper extract --mode nth --index 3,1,4 APPLE BANANA CHERRY`,
+	Run: printExtraction,
+}
+
+func printExtraction(cmd *cobra.Command, args []string) {
+	units, err := extractionUnits(gridRows(args, os.Stdin), extractUnit)
+	if err != nil {
+		fmt.Printf("Could not extract letters: %v\n", err)
+		os.Exit(1)
+	}
+	extracted, err := extractLetters(units, extractMode, extractIndexes)
+	if err != nil {
+		fmt.Printf("Could not extract letters: %v\n", err)
+		os.Exit(1)
+	}
+
+	printer := newResultPrinter("extracted")
+	printer.result(extracted, extracted)
+	printer.finish(false)
+}
+
+// extractionUnits splits lines into the words or lines letters are taken from, each as just its uppercase letters.
+// Words and lines without any letters are skipped
+func extractionUnits(lines []string, unit string) ([]string, error) {
+	var pieces []string
+	switch unit {
+	case "words":
+		for _, line := range lines {
+			pieces = append(pieces, strings.Fields(line)...)
+		}
+	case "lines":
+		pieces = lines
+	default:
+		return nil, fmt.Errorf("unknown unit %s; use words or lines", unit)
+	}
+
+	units := make([]string, 0, len(pieces))
+	for _, piece := range pieces {
+		if letters := justUppercaseLetters(piece); len(letters) > 0 {
+			units = append(units, string(letters))
+		}
+	}
+	return units, nil
+}
+
+// extractLetters takes a letter from each unit as mode says. indexes is only used by nth
+func extractLetters(units []string, mode string, indexes []int) (string, error) {
+	if mode == "nth" && len(indexes) == 0 {
+		return "", fmt.Errorf("nth needs an --index")
+	}
+	for _, index := range indexes {
+		if index == 0 {
+			// counting starts at 1 from the front and -1 from the back, so 0 isn't any letter
+			return "", fmt.Errorf("--index counts from 1, or from -1 at the end, so it can't be 0")
+		}
+	}
+	extracted := make([]byte, 0, len(units))
+	for position, unit := range units {
+		var index int
+		switch mode {
+		case "first":
+			index = 1
+		case "last":
+			index = -1
+		case "nth":
+			index = indexes[position%len(indexes)]
+		case "diagonal":
+			index = position + 1
+		default:
+			return "", fmt.Errorf("unknown mode %s; use %s", mode, strings.Join(extractModes, ", "))
+		}
+		extracted = append(extracted, letterAtIndex(unit, index))
+	}
+	return string(extracted), nil
+}
+
+// letterAtIndex is the letter at index in unit, counting from 1 at the start or -1 at the end, or extractMissing if
+// there's no such letter
+func letterAtIndex(unit string, index int) byte {
+	if index < 0 {
+		index += len(unit) + 1
+	}
+	if index < 1 || index > len(unit) {
+		return extractMissing
+	}
+	return unit[index-1]
+}
+
+func init() {
+	extractCmd.Flags().StringVarP(&extractMode, "mode", "m", "first", "which letter to take: "+strings.Join(extractModes, ", "))
+	extractCmd.Flags().StringVarP(&extractUnit, "by", "", "words", "take a letter from each of the text's words or lines")
+	extractCmd.Flags().IntSliceVarP(&extractIndexes, "index", "i", nil, "with --mode nth, which letter to take, counting from 1 or from -1 at the end. Several are used in turn")
+	rootCmd.AddCommand(extractCmd)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestExtractLetters(test *testing.T) {
+	tests := []struct {
+		lines    []string
+		unit     string
+		mode     string
+		indexes  []int
+		expected string
+	}{
+		{[]string{"Every good", "boy deserves fudge"}, "words", "first", nil, "EGBDF"},
+		{[]string{"Every good", "boy deserves fudge!"}, "lines", "first", nil, "EB"},
+		{[]string{"Every good", "boy deserves fudge!"}, "words", "last", nil, "YDYSE"},
+		{[]string{"APPLE BANANA CHERRY"}, "words", "nth", []int{3, 1, 4}, "PBR"},
+		{[]string{"APPLE BANANA CHERRY"}, "words", "nth", []int{2}, "PAH"},
+		{[]string{"APPLE BANANA CHERRY"}, "words", "nth", []int{-2}, "LNR"},
+		{[]string{"APPLE BANANA CHERRY"}, "words", "nth", []int{6}, "?AY"},
+		{[]string{"APPLE BANANA CHERRY"}, "words", "nth", []int{-7}, "???"},
+		{[]string{"CAT", "DOG —", "— EMU"}, "lines", "diagonal", nil, "COU"},
+		{[]string{"I", "AM"}, "words", "diagonal", nil, "IM"},
+		{[]string{"AB", "C"}, "words", "diagonal", nil, "A?"},
+	}
+	for _, currentTest := range tests {
+		units, err := extractionUnits(currentTest.lines, currentTest.unit)
+		if err != nil {
+			test.Errorf("Expected units for %v but got %v", currentTest.lines, err)
+			continue
+		}
+		actual, err := extractLetters(units, currentTest.mode, currentTest.indexes)
+		if err != nil || actual != currentTest.expected {
+			test.Errorf("Expected %s from %v by %s with %s but got %s (%v)", currentTest.expected, currentTest.lines, currentTest.unit, currentTest.mode, actual, err)
+		}
+	}
+}
+
+func TestExtractErrors(test *testing.T) {
+	if _, err := extractionUnits([]string{"A"}, "letters"); err == nil {
+		test.Errorf("Expected an error for an unknown unit")
+	}
+	if _, err := extractLetters([]string{"A"}, "middle", nil); err == nil {
+		test.Errorf("Expected an error for an unknown mode")
+	}
+	if _, err := extractLetters([]string{"A"}, "nth", nil); err == nil {
+		test.Errorf("Expected an error for nth without an index")
+	}
+	if _, err := extractLetters([]string{"APPLE", "BANANA"}, "nth", []int{2, 0}); err == nil {
+		test.Errorf("Expected an error for an index of 0")
+	}
+}
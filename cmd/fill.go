@@ -0,0 +1,178 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var fillRegex bool
+var fillCrossings []string
+
+// fillCmd represents the fill command
+var fillCmd = &cobra.Command{
+	Use:   "fill PATTERN",
+	Short: "Finds the words that fit a crossword slot, given its crossing words",
+	Long: `Lists the dictionary words that fit PATTERN, written like pattern's C?T?? with ? (or .) for each
+	unknown letter, or as a regular expression with --regex. Each --cross narrows the fill by a crossing
+	word: POSITION:PATTERN, where POSITION is the square of the slot it crosses, counting from 1, and PATTERN
+	is the crossing word's pattern with a * for the shared square. Only letters that leave the crossing word
+	with a dictionary match are allowed there. Matches are listed alphabetically, or from the most to the
+	least common word for a dictionary of word<TAB>frequency lines.
+
+	Examples:
+	  puzzle_helper fill .A..LE --cross '1:*OT' --cross '4:??*E' --dictionary words.txt
+	  puzzle_helper fill --regex '[AEIOU]+Q.*' --dictionary words.txt`,
+	Args: cobra.ExactArgs(1),
+	Run:  printFillMatches,
+}
+
+// fillCrossing is a crossing word's square in the slot being filled, with the letters that fit both words
+type fillCrossing struct {
+	position int
+	letters  map[byte]bool
+}
+
+func printFillMatches(cmd *cobra.Command, args []string) {
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+	matches, err := fillMatches(rootTrie, args[0], fillRegex, fillCrossings)
+	if err != nil {
+		fmt.Printf("Could not fill %s: %v\n", args[0], err)
+		os.Exit(1)
+	}
+	printer := newResultPrinter("word")
+	for _, word := range matches {
+		printer.result(word, word)
+	}
+	printer.finish(false)
+}
+
+// fillMatches is the words in rootTrie that fit pattern, or match it as a regular expression if isRegex is set,
+// and that share a letter with a dictionary word at each of the crossings, given as POSITION:PATTERN
+func fillMatches(rootTrie *trieNode, pattern string, isRegex bool, crossingSpecs []string) ([]string, error) {
+	crossings := make([]fillCrossing, 0, len(crossingSpecs))
+	for _, spec := range crossingSpecs {
+		crossing, err := parseFillCrossing(rootTrie, spec)
+		if err != nil {
+			return nil, err
+		}
+		crossings = append(crossings, crossing)
+	}
+
+	var candidates []string
+	if isRegex {
+		expression, err := regexp.Compile("^(?i:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("%s isn't a regular expression: %v", pattern, err)
+		}
+		candidates = make([]string, 0)
+		rootTrie.Walk(func(word string, value interface{}) bool {
+			if expression.MatchString(word) {
+				candidates = append(candidates, word)
+			}
+			return true
+		})
+	} else {
+		normalized, err := normalizeWordPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		candidates = rootTrie.MatchPattern(normalized)
+	}
+
+	matches := make([]string, 0, len(candidates))
+CandidateLoop:
+	for _, word := range candidates {
+		for _, crossing := range crossings {
+			if crossing.position > len(word) || !crossing.letters[word[crossing.position-1]] {
+				continue CandidateLoop
+			}
+		}
+		matches = append(matches, word)
+	}
+	if hasWordFrequencies(rootTrie) {
+		sortWordsByFrequency(rootTrie, matches)
+	}
+	return matches, nil
+}
+
+// parseFillCrossing reads a crossing given as POSITION:PATTERN and works out which letters can go in the
+// square marked * in the pattern
+func parseFillCrossing(rootTrie *trieNode, spec string) (fillCrossing, error) {
+	positionText, pattern, found := strings.Cut(spec, ":")
+	position, err := strconv.Atoi(positionText)
+	if !found || err != nil || position < 1 {
+		return fillCrossing{}, fmt.Errorf("a crossing should be POSITION:PATTERN, such as 2:C*T, but got %s", spec)
+	}
+	square := strings.Index(pattern, "*")
+	if square < 0 || strings.Count(pattern, "*") > 1 {
+		return fillCrossing{}, fmt.Errorf("the crossing %s should mark the shared square with one *", spec)
+	}
+	normalized, err := normalizeWordPattern(strings.Replace(pattern, "*", "?", 1))
+	if err != nil {
+		return fillCrossing{}, err
+	}
+
+	crossing := fillCrossing{position, make(map[byte]bool)}
+	for _, word := range rootTrie.MatchPattern(normalized) {
+		crossing.letters[word[square]] = true
+	}
+	return crossing, nil
+}
+
+// runFillSolver is the fill command for the solver registry
+func runFillSolver(ctx context.Context, input solverInput) (*resultTable, error) {
+	rootTrie, _, err := openDictionaryTrie(input.getList("dictionary")...)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := fillMatches(rootTrie, input.getString("pattern"), input.getBool("regex"), input.getList("crossings"))
+	if err != nil {
+		return nil, err
+	}
+	table := newResultTable("word")
+	for _, word := range matches {
+		table.addRow(word)
+	}
+	return table, nil
+}
+
+func init() {
+	fillCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+	fillCmd.MarkFlagRequired("dictionary")
+	fillCmd.Flags().BoolVarP(&fillRegex, "regex", "", false, "Read the pattern as a regular expression that has to match the whole word")
+	fillCmd.Flags().StringArrayVarP(&fillCrossings, "cross", "", nil, "A crossing word as POSITION:PATTERN, with a * in the pattern for the shared square. Repeat it for each crossing")
+	rootCmd.AddCommand(fillCmd)
+
+	mustRegisterSolver(&solver{
+		name:        "fill",
+		description: "Dictionary words that fit a crossword slot, narrowed by the patterns of the words crossing it",
+		parameters: []solverParameter{
+			solverParameter{name: "pattern", kind: solverString, description: "the slot's known letters, with ? (or .) for each unknown one, or a regular expression if regex is set", required: true},
+			solverParameter{name: "regex", kind: solverBool, description: "read the pattern as a regular expression that has to match the whole word"},
+			solverParameter{name: "crossings", kind: solverString, description: "crossing words as POSITION:PATTERN, separated by commas, such as 1:*OT,4:??*E. POSITION counts from 1 and * marks the shared square"},
+			solverParameter{name: "dictionary", kind: solverString, description: "the dictionary file to use, or several separated by commas", required: true},
+		},
+		run: runFillSolver,
+	})
+}
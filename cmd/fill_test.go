@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFillMatches(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"CANDLE", "HANDLE", "DANGLE", "HOT", "COT", "ABLE", "IDLE", "QUA", "AQUA"} {
+		trie.Add(word, nil)
+	}
+
+	testCases := []struct {
+		pattern   string
+		isRegex   bool
+		crossings []string
+		expected  string
+	}{
+		{".A..LE", false, nil, "CANDLE,DANGLE,HANDLE"},
+		{".A..LE", false, []string{"1:*OT"}, "CANDLE,HANDLE"},
+		{".A..LE", false, []string{"1:*OT", "4:?*LE"}, "CANDLE,HANDLE"},
+		{".A..LE", false, []string{"1:*OT", "4:*BLE"}, ""},
+		{"a?qua", false, nil, ""},
+		{"[AEIOU]?QU.*", true, nil, "AQUA,QUA"},
+		{".*le", true, []string{"2:?*LE"}, "ABLE,IDLE"},
+		// a crossing past the end of a word rules it out
+		{".*O.*", true, []string{"4:*BLE"}, ""},
+	}
+
+	for _, testCase := range testCases {
+		matches, err := fillMatches(trie, testCase.pattern, testCase.isRegex, testCase.crossings)
+		if err != nil {
+			test.Errorf("Unexpected error filling %s with %v: %v", testCase.pattern, testCase.crossings, err)
+			continue
+		}
+		if strings.Join(matches, ",") != testCase.expected {
+			test.Errorf("Expected %q for %s with %v but got %v", testCase.expected, testCase.pattern, testCase.crossings, matches)
+		}
+	}
+
+	for _, crossing := range []string{"OT", "0:*OT", "x:*OT", "1:COT", "1:**T"} {
+		if _, err := fillMatches(trie, "?OT", false, []string{crossing}); err == nil {
+			test.Errorf("Expected an error for the crossing %s", crossing)
+		}
+	}
+	if _, err := fillMatches(trie, "(", true, nil); err == nil {
+		test.Errorf("Expected an error for a bad regular expression")
+	}
+}
@@ -0,0 +1,403 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+var gologMaxResults int
+
+// gologCmd represents the golog command
+var gologCmd = &cobra.Command{
+	Use:   "golog PUZZLE_FILE",
+	Short: "Solves logic grid puzzles",
+	Long: `Solves logic grid (Einstein) puzzles described in a JSON or YAML file, or on stdin if the file is -. The puzzle lists
+	its categories, each with the same number of items, and the clues as constraints. Every item has to be a
+	different name so the constraints can refer to them:
+
+	  {
+	    "categories": [
+	      {"name": "person", "items": ["Ann", "Bob", "Cy"]},
+	      {"name": "pet", "items": ["cat", "dog", "fish"]},
+	      {"name": "house", "items": ["1", "2", "3"]}
+	    ],
+	    "constraints": [
+	      {"same": ["Ann", "cat"]},
+	      {"different": ["Bob", "fish", "1"]},
+	      {"before": ["fish", "dog"], "by": "house"},
+	      {"before": ["Ann", "Bob"], "by": "house", "offset": 2}
+	    ]
+	  }
+
+	The same puzzle in YAML, which is read whenever the file doesn't start with a {:
+
+	  categories:
+	    - {name: person, items: [Ann, Bob, Cy]}
+	    - {name: pet, items: [cat, dog, fish]}
+	    - {name: house, items: ["1", "2", "3"]}
+	  constraints:
+	    - same: [Ann, cat]
+	    - different: [Bob, fish, "1"]
+	    - {before: [fish, dog], by: house}
+	    - {before: [Ann, Bob], by: house, offset: 2}
+
+	"same" items go together and "different" items all go with different ones. "before" puts the first item's
+	place in the "by" category, whose items are in order, ahead of the second's, exactly "offset" places ahead
+	if it's given. Every consistent solution is printed as a row for each item of the first category, up to
+	--max-results of them.
+
+	Example:
+	  puzzle_helper golog zebra.json`,
+	Args: cobra.ExactArgs(1),
+	Run:  printLogicSolutions,
+}
+
+// logicPuzzle is a logic grid puzzle as it's written in a puzzle file
+type logicPuzzle struct {
+	Categories  []logicCategory   `json:"categories" yaml:"categories"`
+	Constraints []logicConstraint `json:"constraints" yaml:"constraints"`
+}
+
+type logicCategory struct {
+	Name  string   `json:"name" yaml:"name"`
+	Items []string `json:"items" yaml:"items"`
+}
+
+// logicConstraint is one clue. Only one of Same, Different, and Before is set
+type logicConstraint struct {
+	Same      []string `json:"same,omitempty" yaml:"same,omitempty"`
+	Different []string `json:"different,omitempty" yaml:"different,omitempty"`
+	Before    []string `json:"before,omitempty" yaml:"before,omitempty"`
+	By        string   `json:"by,omitempty" yaml:"by,omitempty"`
+	Offset    int      `json:"offset,omitempty" yaml:"offset,omitempty"`
+}
+
+// logicItem is where an item is: its category and its index in that category's items
+type logicItem struct {
+	category int
+	index    int
+}
+
+// logicRule is a constraint with its items looked up
+type logicRule struct {
+	kind   string
+	items  []logicItem
+	by     int
+	offset int
+}
+
+func printLogicSolutions(cmd *cobra.Command, args []string) {
+	var contents []byte
+	var err error
+	if args[0] == "-" {
+		contents, err = io.ReadAll(os.Stdin)
+	} else {
+		contents, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		fmt.Printf("Could not read %s: %v\n", args[0], err)
+		os.Exit(1)
+	}
+	puzzle, rules, err := parseLogicPuzzle(contents)
+	if err != nil {
+		fmt.Printf("Invalid puzzle: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := solveContext()
+	defer cancel()
+
+	columns := []string{"solution"}
+	for _, category := range puzzle.Categories {
+		columns = append(columns, category.Name)
+	}
+	printer := newResultPrinter(columns...)
+	count := 0
+	solveLogicPuzzle(ctx, puzzle, rules, func(solution [][]string) bool {
+		count++
+		printer.text("Solution %d:", count)
+		for _, row := range solution {
+			printer.result("  "+strings.Join(row, " "), append([]string{strconv.Itoa(count)}, row...)...)
+		}
+		return gologMaxResults <= 0 || count < gologMaxResults
+	})
+	if count == 0 {
+		printer.text("No solutions")
+	}
+	printer.finish(ctx.Err() != nil)
+}
+
+// parseLogicPuzzle reads a puzzle file, JSON if it starts with a { and YAML otherwise, and checks that its categories are the same size, its items are all
+// different, and its constraints refer to items and categories it has
+func parseLogicPuzzle(contents []byte) (logicPuzzle, []logicRule, error) {
+	var puzzle logicPuzzle
+	unmarshal := yaml.Unmarshal
+	if bytes.HasPrefix(bytes.TrimSpace(contents), []byte("{")) {
+		unmarshal = json.Unmarshal
+	}
+	if err := unmarshal(contents, &puzzle); err != nil {
+		return puzzle, nil, err
+	}
+	if len(puzzle.Categories) < 2 {
+		return puzzle, nil, fmt.Errorf("a puzzle needs at least two categories")
+	}
+
+	items := make(map[string]logicItem)
+	categories := make(map[string]int)
+	size := len(puzzle.Categories[0].Items)
+	for categoryIndex, category := range puzzle.Categories {
+		if _, present := categories[category.Name]; present || category.Name == "" {
+			return puzzle, nil, fmt.Errorf("every category needs a different name, but got %q twice or empty", category.Name)
+		}
+		categories[category.Name] = categoryIndex
+		if len(category.Items) == 0 {
+			return puzzle, nil, fmt.Errorf("every category needs items, but %s has none", category.Name)
+		}
+		if len(category.Items) != size {
+			return puzzle, nil, fmt.Errorf("every category needs the same number of items, but %s has %d and %s has %d",
+				puzzle.Categories[0].Name, size, category.Name, len(category.Items))
+		}
+		for itemIndex, item := range category.Items {
+			if _, present := items[item]; present {
+				return puzzle, nil, fmt.Errorf("%s is in the puzzle twice", item)
+			}
+			items[item] = logicItem{categoryIndex, itemIndex}
+		}
+	}
+
+	rules := make([]logicRule, 0, len(puzzle.Constraints))
+	for _, constraint := range puzzle.Constraints {
+		rule := logicRule{by: -1, offset: constraint.Offset}
+		names := constraint.Same
+		switch {
+		case len(constraint.Same) > 0 && len(constraint.Different) == 0 && len(constraint.Before) == 0:
+			rule.kind = "same"
+		case len(constraint.Different) > 0 && len(constraint.Same) == 0 && len(constraint.Before) == 0:
+			rule.kind, names = "different", constraint.Different
+		case len(constraint.Before) > 0 && len(constraint.Same) == 0 && len(constraint.Different) == 0:
+			rule.kind, names = "before", constraint.Before
+			by, present := categories[constraint.By]
+			if !present {
+				return puzzle, nil, fmt.Errorf("before needs a category to put %v in order by, but got %q", constraint.Before, constraint.By)
+			}
+			if len(names) != 2 || constraint.Offset < 0 {
+				return puzzle, nil, fmt.Errorf("before takes two items and an offset that isn't negative, but got %v and %d", constraint.Before, constraint.Offset)
+			}
+			rule.by = by
+		default:
+			return puzzle, nil, fmt.Errorf("each constraint needs exactly one of same, different, or before")
+		}
+		if len(names) < 2 {
+			return puzzle, nil, fmt.Errorf("%s needs at least two items, but got %v", rule.kind, names)
+		}
+		for _, name := range names {
+			item, present := items[name]
+			if !present {
+				return puzzle, nil, fmt.Errorf("%s isn't an item in any category", name)
+			}
+			rule.items = append(rule.items, item)
+		}
+		rules = append(rules, rule)
+	}
+	return puzzle, rules, nil
+}
+
+// logicGrid is a partly solved puzzle. rows[category][row] is the index of the item of that category in the row,
+// or -1 if it isn't known yet, and places[category][index] is the row that item is in, or -1. Each row goes with
+// one item of the first category, so the first category's rows are always known
+type logicGrid struct {
+	rows   [][]int
+	places [][]int
+}
+
+// placeOf is the item of category in the same row as item, or -1 if either isn't placed yet
+func (grid logicGrid) placeOf(item logicItem, category int) int {
+	row := grid.places[item.category][item.index]
+	if row < 0 {
+		return -1
+	}
+	return grid.rows[category][row]
+}
+
+// broken reports whether rule can't hold given what's placed so far
+func (grid logicGrid) broken(rule logicRule) bool {
+	switch rule.kind {
+	case "same", "different":
+		seen := make(map[int]bool)
+		known := -1
+		for _, item := range rule.items {
+			row := grid.places[item.category][item.index]
+			if row < 0 {
+				continue
+			}
+			if rule.kind == "same" && known >= 0 && row != known {
+				return true
+			}
+			if rule.kind == "different" && seen[row] {
+				return true
+			}
+			known = row
+			seen[row] = true
+		}
+	case "before":
+		first, second := grid.placeOf(rule.items[0], rule.by), grid.placeOf(rule.items[1], rule.by)
+		if first < 0 || second < 0 {
+			return false
+		}
+		if rule.offset > 0 {
+			return second-first != rule.offset
+		}
+		return first >= second
+	}
+	return false
+}
+
+// logicRulesByItem lists, for each item, the rules that placing it can break: the ones it's in, and for items
+// of a category that a "before" rule is ordered by, that rule too, since placing them fills in where its items fall
+func logicRulesByItem(puzzle logicPuzzle, rules []logicRule) [][][]logicRule {
+	rulesFor := make([][][]logicRule, len(puzzle.Categories))
+	for category := range rulesFor {
+		rulesFor[category] = make([][]logicRule, len(puzzle.Categories[category].Items))
+	}
+	for _, rule := range rules {
+		for _, item := range rule.items {
+			rulesFor[item.category][item.index] = append(rulesFor[item.category][item.index], rule)
+		}
+		if rule.kind == "before" {
+			for index := range rulesFor[rule.by] {
+				rulesFor[rule.by][index] = append(rulesFor[rule.by][index], rule)
+			}
+		}
+	}
+	return rulesFor
+}
+
+// prune narrows domains, the rows each item could still go in, to what's left now: rows its category hasn't
+// filled, where placing the item wouldn't break one of its rules. Items that are placed get no rows. It returns
+// false if some item has nowhere left to go, so choices that are bound to fail are dropped right away
+func (grid logicGrid) prune(domains [][][]int, rulesFor [][][]logicRule) ([][][]int, bool) {
+	pruned := make([][][]int, len(domains))
+	for category := range domains {
+		pruned[category] = make([][]int, len(domains[category]))
+		for index, rows := range domains[category] {
+			if grid.places[category][index] >= 0 {
+				continue
+			}
+			kept := make([]int, 0, len(rows))
+			for _, row := range rows {
+				if grid.rows[category][row] >= 0 {
+					continue
+				}
+				grid.rows[category][row], grid.places[category][index] = index, row
+				consistent := true
+				for _, rule := range rulesFor[category][index] {
+					if grid.broken(rule) {
+						consistent = false
+						break
+					}
+				}
+				grid.rows[category][row], grid.places[category][index] = -1, -1
+				if consistent {
+					kept = append(kept, row)
+				}
+			}
+			if len(kept) == 0 {
+				return nil, false
+			}
+			pruned[category][index] = kept
+		}
+	}
+	return pruned, true
+}
+
+// solveLogicPuzzle places the items of each category after the first in the rows. After each one it prunes the
+// rows every item that's left could go in, so a choice that leaves some item nowhere to go is abandoned before
+// anything else is placed, and calls found with the rows of each solution until it returns false or ctx is done
+func solveLogicPuzzle(ctx context.Context, puzzle logicPuzzle, rules []logicRule, found func(solution [][]string) bool) {
+	size := len(puzzle.Categories[0].Items)
+	grid := logicGrid{make([][]int, len(puzzle.Categories)), make([][]int, len(puzzle.Categories))}
+	for category := range puzzle.Categories {
+		grid.rows[category] = make([]int, size)
+		grid.places[category] = make([]int, size)
+		for index := 0; index < size; index++ {
+			grid.rows[category][index], grid.places[category][index] = -1, -1
+			if category == 0 {
+				grid.rows[category][index], grid.places[category][index] = index, index
+			}
+		}
+	}
+
+	rulesFor := logicRulesByItem(puzzle, rules)
+	domains := make([][][]int, len(puzzle.Categories))
+	for category := range domains {
+		domains[category] = make([][]int, size)
+		for index := range domains[category] {
+			for row := 0; row < size; row++ {
+				domains[category][index] = append(domains[category][index], row)
+			}
+		}
+	}
+	domains, possible := grid.prune(domains, rulesFor)
+	if !possible {
+		return
+	}
+
+	var place func(category int, index int, domains [][][]int) bool
+	place = func(category int, index int, domains [][][]int) bool {
+		if category == len(puzzle.Categories) {
+			solution := make([][]string, size)
+			for row := range solution {
+				for column, items := range grid.rows {
+					solution[row] = append(solution[row], puzzle.Categories[column].Items[items[row]])
+				}
+			}
+			return found(solution)
+		}
+		if index == size {
+			return place(category+1, 0, domains)
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+
+		// every row left in the item's domain is free and keeps its rules, so only the other items need checking
+		for _, row := range domains[category][index] {
+			grid.rows[category][row], grid.places[category][index] = index, row
+			if next, possible := grid.prune(domains, rulesFor); possible && !place(category, index+1, next) {
+				return false
+			}
+			grid.rows[category][row], grid.places[category][index] = -1, -1
+		}
+		return true
+	}
+	place(1, 0, domains)
+}
+
+func init() {
+	gologCmd.Flags().IntVarP(&gologMaxResults, "max-results", "", 0, "Stop after this many solutions. Defaults to all of them")
+	rootCmd.AddCommand(gologCmd)
+}
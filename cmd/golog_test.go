@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testLogicPuzzle = `{
+	"categories": [
+		{"name": "person", "items": ["Ann", "Bob", "Cy"]},
+		{"name": "pet", "items": ["cat", "dog", "fish"]},
+		{"name": "house", "items": ["1", "2", "3"]}
+	],
+	"constraints": [
+		{"same": ["Ann", "cat"]},
+		{"different": ["Bob", "fish", "1"]},
+		{"before": ["fish", "dog"], "by": "house"},
+		{"before": ["Ann", "Bob"], "by": "house", "offset": 2}
+	]
+}`
+
+// logicSolutions solves contents and describes each solution as its rows separated by slashes
+func logicSolutions(test *testing.T, contents string) []string {
+	puzzle, rules, err := parseLogicPuzzle([]byte(contents))
+	if err != nil {
+		test.Fatalf("Expected a puzzle but got %v", err)
+	}
+	solutions := make([]string, 0)
+	solveLogicPuzzle(context.Background(), puzzle, rules, func(solution [][]string) bool {
+		rows := make([]string, 0, len(solution))
+		for _, row := range solution {
+			rows = append(rows, strings.Join(row, " "))
+		}
+		solutions = append(solutions, strings.Join(rows, "/"))
+		return true
+	})
+	return solutions
+}
+
+func TestSolveLogicPuzzle(test *testing.T) {
+	expected := "Ann cat 1/Bob dog 3/Cy fish 2"
+	if actual := strings.Join(logicSolutions(test, testLogicPuzzle), ","); actual != expected {
+		test.Errorf("Expected %s but got %s", expected, actual)
+	}
+
+	// with just Ann somewhere before Bob and the fish before the dog, Cy can be in any house
+	loose := strings.Replace(strings.Replace(testLogicPuzzle, `, "offset": 2`, "", 1), `{"different": ["Bob", "fish", "1"]},`, "", 1)
+	if solutions := logicSolutions(test, loose); len(solutions) != 3 {
+		test.Errorf("Expected three solutions without the offset or the different clue but got %v", solutions)
+	}
+
+	contradiction := strings.Replace(testLogicPuzzle, `["fish", "dog"]`, `["dog", "fish"]`, 1)
+	if solutions := logicSolutions(test, contradiction); len(solutions) != 0 {
+		test.Errorf("Expected no solutions when dog has to come first but got %v", solutions)
+	}
+}
+
+func TestSolveLogicPuzzleYaml(test *testing.T) {
+	yamlPuzzle := `
+categories:
+  - {name: person, items: [Ann, Bob, Cy]}
+  - {name: pet, items: [cat, dog, fish]}
+  - {name: house, items: ["1", "2", "3"]}
+constraints:
+  - same: [Ann, cat]
+  - different: [Bob, fish, "1"]
+  - {before: [fish, dog], by: house}
+  - {before: [Ann, Bob], by: house, offset: 2}
+`
+	expected := "Ann cat 1/Bob dog 3/Cy fish 2"
+	if actual := strings.Join(logicSolutions(test, yamlPuzzle), ","); actual != expected {
+		test.Errorf("Expected %s from the YAML puzzle but got %s", expected, actual)
+	}
+}
+
+func TestLogicGridPrune(test *testing.T) {
+	puzzle, rules, err := parseLogicPuzzle([]byte(testLogicPuzzle))
+	if err != nil {
+		test.Fatalf("Expected a puzzle but got %v", err)
+	}
+	grid := logicGrid{[][]int{{0, 1, 2}, {-1, -1, -1}, {-1, -1, -1}}, [][]int{{0, 1, 2}, {-1, -1, -1}, {-1, -1, -1}}}
+	all := [][]int{{0, 1, 2}, {0, 1, 2}, {0, 1, 2}}
+	domains, possible := grid.prune([][][]int{all, all, all}, logicRulesByItem(puzzle, rules))
+	if !possible {
+		test.Fatalf("Expected the empty grid to leave every item somewhere to go")
+	}
+	// the cat goes with Ann and the fish can't be with Bob
+	if len(domains[1][0]) != 1 || domains[1][0][0] != 0 {
+		test.Errorf("Expected the cat to be left only Ann's row but got %v", domains[1][0])
+	}
+	if fmt.Sprint(domains[1][2]) != "[0 2]" {
+		test.Errorf("Expected the fish to be left Ann's and Cy's rows but got %v", domains[1][2])
+	}
+
+	// with the dog in Ann's row, the cat has nowhere to go
+	grid.rows[1][0], grid.places[1][1] = 1, 0
+	if _, possible = grid.prune(domains, logicRulesByItem(puzzle, rules)); possible {
+		test.Errorf("Expected putting the dog in Ann's row to leave the cat nowhere to go")
+	}
+}
+
+func TestParseLogicPuzzleErrors(test *testing.T) {
+	tests := []string{
+		`not json`,
+		`{"categories": [{"name": "person", "items": ["Ann"]}]}`,
+		`{"categories": [{"name": "person", "items": ["Ann", "Bob"]}, {"name": "pet", "items": ["cat"]}]}`,
+		`{"categories": [{"name": "person", "items": ["Ann", "Bob"]}, {"name": "person", "items": ["cat", "dog"]}]}`,
+		`{"categories": [{"name": "person", "items": ["Ann", "Bob"]}, {"name": "pet", "items": ["cat", "Ann"]}]}`,
+		`{"categories": [{"name": "person", "items": ["Ann", "Bob"]}, {"name": "pet", "items": ["cat", "dog"]}],
+		  "constraints": [{"same": ["Ann", "emu"]}]}`,
+		`{"categories": [{"name": "person", "items": ["Ann", "Bob"]}, {"name": "pet", "items": ["cat", "dog"]}],
+		  "constraints": [{"same": ["Ann", "cat"], "different": ["Bob", "dog"]}]}`,
+		`{"categories": [{"name": "person", "items": ["Ann", "Bob"]}, {"name": "pet", "items": ["cat", "dog"]}],
+		  "constraints": [{"before": ["cat", "dog"], "by": "house"}]}`,
+		`{"categories": [{"name": "person", "items": ["Ann", "Bob"]}, {"name": "pet", "items": ["cat", "dog"]}],
+		  "constraints": [{"same": ["Ann"]}]}`,
+	}
+	for _, contents := range tests {
+		if _, _, err := parseLogicPuzzle([]byte(contents)); err == nil {
+			test.Errorf("Expected an error for %s but got none", contents)
+		}
+	}
+
+	empty := `{"categories": [{"name": "person", "items": []}, {"name": "pet", "items": []}]}`
+	if _, _, err := parseLogicPuzzle([]byte(empty)); err == nil || err.Error() != "every category needs items, but person has none" {
+		test.Errorf("Expected empty categories to be called out but got %v", err)
+	}
+}
@@ -0,0 +1,311 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var gridWidths []int
+var gridKeepSpaces bool
+var gridPad string
+var gridReverseRowOrder bool
+var gridReadOrder string
+var gridColumnOrder []int
+
+// gridCmd represents the grid command
+var gridCmd = &cobra.Command{
+	Use:   "grid",
+	Short: "Reshapes text into grids and reads it back off, for working transpositions by hand",
+	Long: `The grid commands each print a grid one row per line, so they can be piped into each other.
+	Grids are read from the arguments, one argument per row, or from stdin a line at a time when
+	there are no arguments or the only one is -.
+
+	Examples:
+	  puzzle_helper grid shape "WE ARE DISCOVERED FLEE AT ONCE" --width 6 | puzzle_helper grid transpose
+	  puzzle_helper grid shape WEAREDISCOVERED --width 5 | puzzle_helper grid read --order columns --columns 3,1,2,5,4`,
+}
+
+var gridShapeCmd = &cobra.Command{
+	Use:   "shape TEXT...",
+	Short: "Writes text into rows of the given width",
+	Long: `Writes the text into rows --width letters wide, ignoring spaces unless --keep-spaces is given.
+	Several widths, such as --width 3,4,5, are used for the rows in turn.`,
+	Run: printShapedGrid,
+}
+
+var gridTransposeCmd = &cobra.Command{
+	Use:   "transpose [ROW...]",
+	Short: "Swaps a grid's rows and columns",
+	Run:   printTransposedGrid,
+}
+
+var gridReverseCmd = &cobra.Command{
+	Use:   "reverse [ROW...]",
+	Short: "Reverses each row of a grid, or the order of the rows with --rows",
+	Run:   printReversedGrid,
+}
+
+var gridReadCmd = &cobra.Command{
+	Use:   "read [ROW...]",
+	Short: "Reads a grid off into one line",
+	Long: `Reads the grid off in the --order given:
+	  rows: left to right along each row, top to bottom
+	  columns: top to bottom down each column, left to right, or in the order given by --columns
+	  boustrophedon: along the rows, alternating left to right and right to left
+	  spiral: clockwise around the outside from the top left, working inward`,
+	Run: printReadGrid,
+}
+
+// gridRows gets a grid's rows from args, or from stdin when there are none or the only one is -
+func gridRows(args []string, in io.Reader) []string {
+	if len(args) > 0 && !(len(args) == 1 && args[0] == "-") {
+		return args
+	}
+
+	rows := make([]string, 0)
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if row := strings.TrimRight(scanner.Text(), "\r"); strings.TrimSpace(row) != "" {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func printGrid(rows []string) {
+	printer := newResultPrinter("row")
+	for _, row := range rows {
+		printer.result(row, row)
+	}
+	printer.finish(false)
+}
+
+func printShapedGrid(cmd *cobra.Command, args []string) {
+	text := strings.Join(gridRows(args, os.Stdin), " ")
+	if !gridKeepSpaces {
+		text = strings.Join(strings.Fields(text), "")
+	}
+	rows, err := shapeGrid(text, gridWidths, gridPad)
+	if err != nil {
+		fmt.Printf("Could not shape the grid: %v\n", err)
+		os.Exit(1)
+	}
+	printGrid(rows)
+}
+
+func printTransposedGrid(cmd *cobra.Command, args []string) {
+	printGrid(transposeGrid(gridRows(args, os.Stdin)))
+}
+
+func printReversedGrid(cmd *cobra.Command, args []string) {
+	printGrid(reverseGrid(gridRows(args, os.Stdin), gridReverseRowOrder))
+}
+
+func printReadGrid(cmd *cobra.Command, args []string) {
+	text, err := readGrid(gridRows(args, os.Stdin), gridReadOrder, gridColumnOrder)
+	if err != nil {
+		fmt.Printf("Could not read the grid: %v\n", err)
+		os.Exit(1)
+	}
+	printer := newResultPrinter("text")
+	printer.result(text, text)
+	printer.finish(false)
+}
+
+// shapeGrid writes text into rows, taking their widths from widths in turn. With a pad, the last row
+// is filled out to its full width
+func shapeGrid(text string, widths []int, pad string) ([]string, error) {
+	if len(widths) == 0 {
+		return nil, fmt.Errorf("a width is needed")
+	}
+	for _, width := range widths {
+		if width < 1 {
+			return nil, fmt.Errorf("widths have to be at least 1 but got %d", width)
+		}
+	}
+	if len(pad) > 1 {
+		return nil, fmt.Errorf("the pad has to be a single character but got %s", pad)
+	}
+
+	symbols := []rune(text)
+	rows := make([]string, 0, len(symbols)/widths[0]+1)
+	for start, index := 0, 0; start < len(symbols); index++ {
+		width := widths[index%len(widths)]
+		end := start + width
+		if end > len(symbols) {
+			end = len(symbols)
+		}
+		row := string(symbols[start:end])
+		if pad != "" {
+			row += strings.Repeat(pad, width-(end-start))
+		}
+		rows = append(rows, row)
+		start = end
+	}
+	return rows, nil
+}
+
+// transposeGrid turns the columns of rows into rows. Short rows just leave gaps in the columns they don't reach
+func transposeGrid(rows []string) []string {
+	grid := make([][]rune, len(rows))
+	width := 0
+	for index, row := range rows {
+		grid[index] = []rune(row)
+		if len(grid[index]) > width {
+			width = len(grid[index])
+		}
+	}
+
+	columns := make([]string, width)
+	for column := range columns {
+		var builder strings.Builder
+		for _, row := range grid {
+			if column < len(row) {
+				builder.WriteRune(row[column])
+			}
+		}
+		columns[column] = builder.String()
+	}
+	return columns
+}
+
+// reverseGrid reverses the symbols in each row, or just the order of the rows with rowOrder
+func reverseGrid(rows []string, rowOrder bool) []string {
+	reversed := make([]string, len(rows))
+	for index, row := range rows {
+		if rowOrder {
+			reversed[len(rows)-1-index] = row
+		} else {
+			reversed[index] = reverseString(row)
+		}
+	}
+	return reversed
+}
+
+func reverseString(text string) string {
+	symbols := []rune(text)
+	for left, right := 0, len(symbols)-1; left < right; left, right = left+1, right-1 {
+		symbols[left], symbols[right] = symbols[right], symbols[left]
+	}
+	return string(symbols)
+}
+
+// readGrid reads rows off in order. columnOrder, which only applies to reading by columns, lists the
+// columns to read by number starting at 1, as a columnar transposition key would
+func readGrid(rows []string, order string, columnOrder []int) (string, error) {
+	if len(columnOrder) > 0 && order != "columns" {
+		return "", fmt.Errorf("a column order only works when reading by columns")
+	}
+
+	switch order {
+	case "rows":
+		return strings.Join(rows, ""), nil
+	case "columns":
+		columns := transposeGrid(rows)
+		if len(columnOrder) == 0 {
+			return strings.Join(columns, ""), nil
+		}
+		if len(columnOrder) != len(columns) {
+			return "", fmt.Errorf("the grid has %d columns but the column order has %d", len(columns), len(columnOrder))
+		}
+		seen := make(map[int]bool)
+		var builder strings.Builder
+		for _, column := range columnOrder {
+			if column < 1 || column > len(columns) || seen[column] {
+				return "", fmt.Errorf("the column order has to use each of 1 to %d once", len(columns))
+			}
+			seen[column] = true
+			builder.WriteString(columns[column-1])
+		}
+		return builder.String(), nil
+	case "boustrophedon":
+		var builder strings.Builder
+		for index, row := range rows {
+			if index%2 == 1 {
+				row = reverseString(row)
+			}
+			builder.WriteString(row)
+		}
+		return builder.String(), nil
+	case "spiral":
+		return readGridSpiral(rows), nil
+	}
+	return "", fmt.Errorf("unknown order %s; use rows, columns, boustrophedon, or spiral", order)
+}
+
+// readGridSpiral reads clockwise around the outside of the grid from the top left, then around the
+// next ring in and so on. Short rows are treated as having gaps at the end
+func readGridSpiral(rows []string) string {
+	grid := make([][]rune, len(rows))
+	width := 0
+	for index, row := range rows {
+		grid[index] = []rune(row)
+		if len(grid[index]) > width {
+			width = len(grid[index])
+		}
+	}
+	var builder strings.Builder
+	visit := func(row, column int) {
+		if column < len(grid[row]) {
+			builder.WriteRune(grid[row][column])
+		}
+	}
+
+	top, bottom, left, right := 0, len(grid)-1, 0, width-1
+	for top <= bottom && left <= right {
+		for column := left; column <= right; column++ {
+			visit(top, column)
+		}
+		for row := top + 1; row <= bottom; row++ {
+			visit(row, right)
+		}
+		if top < bottom {
+			for column := right - 1; column >= left; column-- {
+				visit(bottom, column)
+			}
+		}
+		if left < right {
+			for row := bottom - 1; row > top; row-- {
+				visit(row, left)
+			}
+		}
+		top, bottom, left, right = top+1, bottom-1, left+1, right-1
+	}
+	return builder.String()
+}
+
+func init() {
+	gridShapeCmd.Flags().IntSliceVarP(&gridWidths, "width", "w", nil, "how many symbols go in each row. Several comma-separated widths are used for the rows in turn")
+	gridShapeCmd.MarkFlagRequired("width")
+	gridShapeCmd.Flags().BoolVarP(&gridKeepSpaces, "keep-spaces", "", false, "keep the spaces in the text instead of leaving them out")
+	gridShapeCmd.Flags().StringVarP(&gridPad, "pad", "", "", "fill out the last row with this character, such as X")
+	gridReverseCmd.Flags().BoolVarP(&gridReverseRowOrder, "rows", "", false, "reverse the order of the rows instead of the symbols in each one")
+	gridReadCmd.Flags().StringVarP(&gridReadOrder, "order", "o", "rows", "how to read the grid off: rows, columns, boustrophedon, or spiral")
+	gridReadCmd.Flags().IntSliceVarP(&gridColumnOrder, "columns", "", nil, "with --order columns, the order to read the columns in, such as 3,1,2")
+
+	gridCmd.AddCommand(gridShapeCmd)
+	gridCmd.AddCommand(gridTransposeCmd)
+	gridCmd.AddCommand(gridReverseCmd)
+	gridCmd.AddCommand(gridReadCmd)
+	rootCmd.AddCommand(gridCmd)
+}
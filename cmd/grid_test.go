@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShapeGrid(test *testing.T) {
+	tests := []struct {
+		text     string
+		widths   []int
+		pad      string
+		expected []string
+	}{
+		{"WEAREDISCOVERED", []int{5}, "", []string{"WEARE", "DISCO", "VERED"}},
+		{"WEAREDISCOVERED", []int{4}, "", []string{"WEAR", "EDIS", "COVE", "RED"}},
+		{"WEAREDISCOVERED", []int{4}, "X", []string{"WEAR", "EDIS", "COVE", "REDX"}},
+		{"WEAREDISCOVERED", []int{1, 2, 3}, "", []string{"W", "EA", "RED", "I", "SC", "OVE", "R", "ED"}},
+		{"", []int{3}, "", []string{}},
+	}
+	for _, testCase := range tests {
+		rows, err := shapeGrid(testCase.text, testCase.widths, testCase.pad)
+		if err != nil {
+			test.Errorf("Unexpected error shaping %s: %v", testCase.text, err)
+		}
+		if strings.Join(rows, "|") != strings.Join(testCase.expected, "|") {
+			test.Errorf("Expected %v but got %v", testCase.expected, rows)
+		}
+	}
+
+	if _, err := shapeGrid("ABC", []int{0}, ""); err == nil {
+		test.Errorf("Expected an error for a zero width")
+	}
+	if _, err := shapeGrid("ABC", []int{2}, "XY"); err == nil {
+		test.Errorf("Expected an error for a pad that isn't one character")
+	}
+}
+
+func TestTransposeAndReverseGrid(test *testing.T) {
+	transposed := transposeGrid([]string{"ABC", "DEF", "G"})
+	if strings.Join(transposed, "|") != "ADG|BE|CF" {
+		test.Errorf("Expected ADG|BE|CF but got %v", transposed)
+	}
+	if back := transposeGrid(transposeGrid([]string{"ABC", "DEF"})); strings.Join(back, "|") != "ABC|DEF" {
+		test.Errorf("Expected transposing twice to give the grid back but got %v", back)
+	}
+
+	if reversed := reverseGrid([]string{"ABC", "DE"}, false); strings.Join(reversed, "|") != "CBA|ED" {
+		test.Errorf("Expected CBA|ED but got %v", reversed)
+	}
+	if reversed := reverseGrid([]string{"ABC", "DE", "F"}, true); strings.Join(reversed, "|") != "F|DE|ABC" {
+		test.Errorf("Expected F|DE|ABC but got %v", reversed)
+	}
+}
+
+func TestReadGrid(test *testing.T) {
+	grid := []string{"ABC", "DEF", "GHI"}
+	tests := []struct {
+		order       string
+		columnOrder []int
+		expected    string
+	}{
+		{"rows", nil, "ABCDEFGHI"},
+		{"columns", nil, "ADGBEHCFI"},
+		{"columns", []int{3, 1, 2}, "CFIADGBEH"},
+		{"boustrophedon", nil, "ABCFEDGHI"},
+		{"spiral", nil, "ABCFIHGDE"},
+	}
+	for _, testCase := range tests {
+		actual, err := readGrid(grid, testCase.order, testCase.columnOrder)
+		if err != nil {
+			test.Errorf("Unexpected error reading by %s: %v", testCase.order, err)
+		}
+		if actual != testCase.expected {
+			test.Errorf("Expected %s reading by %s but got %s", testCase.expected, testCase.order, actual)
+		}
+	}
+
+	if spiral := readGridSpiral([]string{"ABCD", "EFGH"}); spiral != "ABCDHGFE" {
+		test.Errorf("Expected ABCDHGFE spiralling a wide grid but got %s", spiral)
+	}
+
+	badReads := []struct {
+		order       string
+		columnOrder []int
+	}{
+		{"diagonal", nil},
+		{"rows", []int{1, 2, 3}},
+		{"columns", []int{1, 2}},
+		{"columns", []int{1, 1, 2}},
+		{"columns", []int{1, 2, 4}},
+	}
+	for _, bad := range badReads {
+		if _, err := readGrid(grid, bad.order, bad.columnOrder); err == nil {
+			test.Errorf("Expected an error reading by %s with columns %v", bad.order, bad.columnOrder)
+		}
+	}
+}
+
+func TestGridRows(test *testing.T) {
+	if rows := gridRows([]string{"ABC", "DEF"}, strings.NewReader("ignored")); strings.Join(rows, "|") != "ABC|DEF" {
+		test.Errorf("Expected the arguments as rows but got %v", rows)
+	}
+	if rows := gridRows([]string{"-"}, strings.NewReader("ABC\r\n\nDEF\n")); strings.Join(rows, "|") != "ABC|DEF" {
+		test.Errorf("Expected stdin's lines as rows without blank lines but got %v", rows)
+	}
+}
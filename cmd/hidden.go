@@ -0,0 +1,191 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var hiddenMinLength int
+var hiddenLength int
+var hiddenSpanning bool
+var hiddenReversed bool
+
+// hiddenCmd represents the hidden command
+var hiddenCmd = &cobra.Command{
+	Use:   "hidden PHRASE...",
+	Short: "Finds the dictionary words hidden in a phrase",
+	Long: `Finds dictionary words spelled by consecutive letters of a phrase, ignoring spaces and punctuation,
+	the way cryptic crossword clues hide their answers: "planet" hides LANE and "mad amateur" hides DAMA.
+	Each is shown in the phrase with its letters in capitals. The phrase's own words don't count.
+
+	--min-length sets the shortest word (3 by default), --length only looks for words of that many letters,
+	--spanning only keeps words that run across a space between words, and --reversed also looks for words
+	spelled backward.
+
+	Example:
+	  puzzle_helper hidden "Some ram blessed the mill" --length 7 --dictionary words.txt`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  printHiddenWords,
+}
+
+// hiddenRequest is what findHiddenWords looks for. length is 0 for any length
+type hiddenRequest struct {
+	minLength int
+	length    int
+	spanning  bool
+	reversed  bool
+}
+
+// hiddenWord is a word found in a phrase, where the letters from start to end, in the phrase without
+// its spaces and punctuation, spell it forward or, if reversed, backward
+type hiddenWord struct {
+	word     string
+	start    int
+	end      int
+	reversed bool
+}
+
+func printHiddenWords(cmd *cobra.Command, args []string) {
+	phrase := strings.Join(args, " ")
+	if len(justUppercaseLetters(phrase)) == 0 {
+		fmt.Println("The phrase has no letters to hide words in")
+		os.Exit(1)
+	}
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+
+	request := hiddenRequest{hiddenMinLength, hiddenLength, hiddenSpanning, hiddenReversed}
+	printer := newResultPrinter("word", "shown", "reversed")
+	for _, hidden := range findHiddenWords(rootTrie, phrase, request) {
+		shown := showHiddenWord(phrase, hidden)
+		line := fmt.Sprintf("%s: %s", hidden.word, shown)
+		if hidden.reversed {
+			line += " (reversed)"
+		}
+		printer.result(line, hidden.word, shown, strconv.FormatBool(hidden.reversed))
+	}
+	printer.finish(false)
+}
+
+// findHiddenWords finds the dictionary words in the letters of phrase that request asks for, longest first and
+// then in the order they appear. Words that are one of the phrase's own words, or that can only be found that way,
+// are left out
+func findHiddenWords(rootTrie *trieNode, phrase string, request hiddenRequest) []hiddenWord {
+	letters := string(justUppercaseLetters(phrase))
+	// wordStarts marks the positions in letters where each of the phrase's words begins
+	wordStarts := make(map[int]bool)
+	ownWords := make(map[string]bool)
+	position := 0
+	for _, word := range strings.Fields(phrase) {
+		wordLetters := string(justUppercaseLetters(word))
+		if wordLetters == "" {
+			continue
+		}
+		wordStarts[position] = true
+		ownWords[wordLetters] = true
+		position += len(wordLetters)
+	}
+
+	minLength := request.minLength
+	if request.length > minLength {
+		minLength = request.length
+	}
+	found := make([]hiddenWord, 0)
+	seen := make(map[string]bool)
+	keep := func(hidden hiddenWord) {
+		if request.length > 0 && len(hidden.word) != request.length {
+			return
+		}
+		if ownWords[letters[hidden.start:hidden.end]] && wordStarts[hidden.start] && (hidden.end == len(letters) || wordStarts[hidden.end]) {
+			return
+		}
+		if request.spanning && !spansWords(wordStarts, hidden.start, hidden.end) {
+			return
+		}
+		key := fmt.Sprintf("%s %v", hidden.word, hidden.reversed)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		found = append(found, hidden)
+	}
+
+	for _, forward := range findWordsInString(rootTrie, letters, minLength) {
+		keep(hiddenWord{forward.word, forward.offset, forward.offset + len(forward.word), false})
+	}
+	if request.reversed {
+		backward := reverseString(letters)
+		for _, word := range findWordsInString(rootTrie, backward, minLength) {
+			if reverseString(word.word) == word.word {
+				// a palindrome was already found forward
+				continue
+			}
+			end := len(letters) - word.offset
+			keep(hiddenWord{word.word, end - len(word.word), end, true})
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if len(found[i].word) != len(found[j].word) {
+			return len(found[i].word) > len(found[j].word)
+		}
+		return found[i].start < found[j].start
+	})
+	return found
+}
+
+// spansWords reports whether the letters from start up to end include the start of one of the phrase's words
+// after the first letter, so they run across a gap between words
+func spansWords(wordStarts map[int]bool, start int, end int) bool {
+	for position := start + 1; position < end; position++ {
+		if wordStarts[position] {
+			return true
+		}
+	}
+	return false
+}
+
+// showHiddenWord returns phrase in lowercase except for the letters of hidden, which are uppercase
+func showHiddenWord(phrase string, hidden hiddenWord) string {
+	shown := []byte(strings.ToLower(phrase))
+	letter := 0
+	for index, character := range shown {
+		if !isUppercaseAscii(upperCaseByte(character)) {
+			continue
+		}
+		if letter >= hidden.start && letter < hidden.end {
+			shown[index] = upperCaseByte(character)
+		}
+		letter++
+	}
+	return string(shown)
+}
+
+func init() {
+	hiddenCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+	hiddenCmd.MarkFlagRequired("dictionary")
+	hiddenCmd.Flags().IntVarP(&hiddenMinLength, "min-length", "", 3, "The fewest letters a hidden word can have")
+	hiddenCmd.Flags().IntVarP(&hiddenLength, "length", "", 0, "Only find words with exactly this many letters")
+	hiddenCmd.Flags().BoolVarP(&hiddenSpanning, "spanning", "", false, "Only find words that run across the gap between two of the phrase's words")
+	hiddenCmd.Flags().BoolVarP(&hiddenReversed, "reversed", "", false, "Also find words spelled backward")
+	rootCmd.AddCommand(hiddenCmd)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFindHiddenWords(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"LANE", "PLANE", "NET", "PLANET", "SIT", "TIS", "EAR", "NEAR", "THE", "ERA"} {
+		trie.Add(word, nil)
+	}
+
+	tests := []struct {
+		phrase   string
+		request  hiddenRequest
+		expected string
+	}{
+		// PLANET, THE, and NEAR are the phrase's own words, and ERA would be RAE backward
+		{"The planet is near!", hiddenRequest{minLength: 3}, "PLANE:4,LANE:5,NET:7,TIS:9,EAR:15"},
+		{"The planet is near!", hiddenRequest{minLength: 3, length: 4}, "LANE:5"},
+		{"The planet is near!", hiddenRequest{minLength: 3, spanning: true}, "TIS:9"},
+		{"The planet is near!", hiddenRequest{minLength: 3, spanning: true, reversed: true}, "TIS:9,SIT:9"},
+		{"The planet is near!", hiddenRequest{minLength: 3, reversed: true}, "PLANE:4,LANE:5,NET:7,TIS:9,SIT:9,EAR:15"},
+	}
+	for _, currentTest := range tests {
+		described := make([]string, 0)
+		for _, hidden := range findHiddenWords(trie, currentTest.phrase, currentTest.request) {
+			described = append(described, fmt.Sprintf("%s:%d", hidden.word, showHiddenOffset(currentTest.phrase, hidden)))
+		}
+		if actual := strings.Join(described, ","); actual != currentTest.expected {
+			test.Errorf("Expected %s for %v but got %s", currentTest.expected, currentTest.request, actual)
+		}
+	}
+}
+
+// showHiddenOffset is where in phrase the first capital showHiddenWord puts for hidden is
+func showHiddenOffset(phrase string, hidden hiddenWord) int {
+	return strings.IndexFunc(showHiddenWord(phrase, hidden), func(character rune) bool {
+		return character >= 'A' && character <= 'Z'
+	})
+}
+
+func TestShowHiddenWord(test *testing.T) {
+	hidden := hiddenWord{"DAMA", 2, 6, false}
+	if actual, expected := showHiddenWord("Mad amateur", hidden), "maD AMAteur"; actual != expected {
+		test.Errorf("Expected %s but got %s", expected, actual)
+	}
+}
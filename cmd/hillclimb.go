@@ -17,8 +17,12 @@ package cmd
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"math"
 	"math/rand"
 	"os"
 	"sort"
@@ -36,6 +40,14 @@ var mutations int
 var regenAfter int
 var candidateCount int
 var localLookaround int
+var targetFitness float64
+var showProgress bool
+var startKeyString string
+var saveBestFile string
+var checkpointFile string
+var checkpointEvery int
+var resumeFile string
+var hillclimbSeed int64
 
 // hillclimbCmd represents the hillclimb command
 var hillclimbCmd = &cobra.Command{
@@ -50,8 +62,6 @@ var hillclimbCmd = &cobra.Command{
 	Run: hillClimbSubstitutionSolve,
 }
 
-var lettersInOrder = []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L", "M", "N", "O", "P", "Q", "R", "S", "T", "U", "V", "W", "X", "Y", "Z"}
-
 type substitutionHillclimbCandidate struct {
 	fitness float64
 	key     []string
@@ -71,7 +81,7 @@ func (h substitutionHillclimbCandidates) Less(i, j int) bool {
 	return h[i].fitness > h[j].fitness
 }
 
-func newHillclimbCandidate(key []string, ciphertext string, frequencyMap map[string]float64) *substitutionHillclimbCandidate {
+func newHillclimbCandidate(key []string, ciphertext string, frequencyMap *ngramFrequencyMap) *substitutionHillclimbCandidate {
 	plainText := decipherStringFromKey(ciphertext, key)
 	fitness := calculateNgramFitness(plainText, frequencyMap)
 	return &substitutionHillclimbCandidate{fitness, key}
@@ -81,7 +91,7 @@ func (c *substitutionHillclimbCandidate) String() string {
 	var builder strings.Builder
 	builder.WriteString(fmt.Sprintf("fitness: %.8f\n", c.fitness))
 
-	builder.WriteString(strings.Join(lettersInOrder, " "))
+	builder.WriteString(strings.Join(activeAlphabet.letters(), " "))
 	builder.WriteString("\n")
 	for _, plainLetter := range c.key {
 		builder.WriteString(plainLetter)
@@ -92,9 +102,6 @@ func (c *substitutionHillclimbCandidate) String() string {
 }
 
 func hillClimbSubstitutionSolve(cmd *cobra.Command, args []string) {
-
-	candidates := substitutionHillclimbCandidates(make([]*substitutionHillclimbCandidate, 0, candidateCount))
-
 	rawInputText := strings.Join(args, " ")
 	justLetters := make([]string, 0, len(rawInputText))
 	letterScanner := NewNgramScanner(strings.NewReader(rawInputText), 1, false)
@@ -102,34 +109,226 @@ func hillClimbSubstitutionSolve(cmd *cobra.Command, args []string) {
 		justLetters = append(justLetters, letterScanner.Text())
 	}
 
-	var inReader io.Reader
-	var err error
-	if ngramFrequencyFile == "-" {
-		inReader = os.Stdin
-	} else {
-		inReader, err = os.Open(ngramFrequencyFile)
+	frequencyMap, err := loadFrequencyMap(ngramFrequencyFile)
+	if err != nil {
+		fmt.Printf("Error with tetragram file: %v", err)
+		os.Exit(1)
+	}
+
+	var startKey []string
+	if startKeyString != "" {
+		startKey, err = parseHillclimbKey(startKeyString)
+		if err != nil {
+			fmt.Printf("Invalid start key: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	cipherText := strings.Join(justLetters, "")
+	var resume *hillclimbCheckpoint
+	if resumeFile != "" {
+		resume, err = loadHillclimbCheckpoint(resumeFile)
+		if err != nil {
+			fmt.Printf("Could not load checkpoint %s: %v\n", resumeFile, err)
+			os.Exit(1)
+		}
+		if resume.CipherText != cipherText {
+			fmt.Printf("Checkpoint %s is for a different ciphertext: %s\n", resumeFile, resume.CipherText)
+			os.Exit(1)
+		}
+	}
+
+	progressFunc := func(progress hillclimbProgress) {
+		if showProgress {
+			printHillclimbProgress(progress)
+		}
+		if checkpointFile != "" && checkpointEvery > 0 && progress.generation%checkpointEvery == 0 {
+			err := progress.checkpoint.save(checkpointFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Could not write checkpoint to %s: %v\n", checkpointFile, err)
+			}
+		}
+	}
+	ctx, cancel := solveContext()
+	defer cancel()
+	candidates := performHillclimbSolve(ctx, hillclimbSettingsFromFlags(), cipherText, frequencyMap, startKey, nil, resume, progressFunc)
+	if showProgress {
+		fmt.Fprintln(os.Stderr)
+	}
+
+	if saveBestFile != "" && len(candidates) > 0 {
+		err = ioutil.WriteFile(saveBestFile, []byte(strings.Join(candidates[0].key, "")+"\n"), 0644)
 		if err != nil {
-			fmt.Printf("Error with tetragram file: %v", err)
+			fmt.Printf("Could not save best key to %s: %v\n", saveBestFile, err)
 			os.Exit(1)
 		}
 	}
 
-	frequencyMap := populateFrequencyMapFromReader(inReader)
+	printer := newResultPrinter("fitness", "key", "plaintext")
+	for _, candidate := range candidates {
+		plainText := decipherStringFromKey(activeAlphabet.foldString(rawInputText), candidate.key)
+		printer.explained(fmt.Sprintf("%v%s\n", candidate, plainText), explainNgramFitness(decipherStringFromKey(cipherText, candidate.key), frequencyMap), fmt.Sprintf("%.4f", candidate.fitness), strings.Join(candidate.key, ""), plainText)
+	}
+	printer.finish(ctx.Err() != nil)
 
-	justCipherText := strings.Join(justLetters, "")
+}
 
-	currentCandidate := newHillclimbCandidate(generateRandomKey(), justCipherText, frequencyMap)
-	bestOfGeneration := currentCandidate
-	candidates = append(candidates, bestOfGeneration)
+// hillclimbSettings control a hillclimb search. The hillclimb command takes them from its flags, and other
+// callers like the hillclimb solver fill in their own
+type hillclimbSettings struct {
+	generations     int
+	mutations       int
+	regenAfter      int
+	candidates      int
+	localLookaround int
+	// targetFitness stops the search once a candidate reaches it. 0 never stops early
+	targetFitness float64
+	// seed makes runs repeatable. 0 picks one at random
+	seed int64
+}
 
-	fitnessGenerations := 1
+func hillclimbSettingsFromFlags() hillclimbSettings {
+	return hillclimbSettings{generations, mutations, regenAfter, candidateCount, localLookaround, targetFitness, hillclimbSeed}
+}
+
+// hillclimbProgress describes the state of a hillclimb run at the end of a generation
+type hillclimbProgress struct {
+	generation int
+	best       *substitutionHillclimbCandidate
+	plainText  string
+	// checkpoint can be saved and passed back to performHillclimbSolve to carry on from the next generation
+	checkpoint *hillclimbCheckpoint
+}
+
+// hillclimbProgressFunc is called by performHillclimbSolve each time a generation finishes
+type hillclimbProgressFunc func(progress hillclimbProgress)
+
+// printHillclimbProgress overwrites a single status line on stderr so it doesn't get mixed in with results
+func printHillclimbProgress(progress hillclimbProgress) {
+	plainText := progress.plainText
+	if len(plainText) > 50 {
+		plainText = plainText[:50] + "..."
+	}
+	fmt.Fprintf(os.Stderr, "\rgeneration %d/%d fitness: %.4f %s\u001b[0K", progress.generation, generations, progress.best.fitness, plainText)
+}
+
+// hillclimbCheckpoint is everything needed to pick a run back up at the start of a generation. Each
+// generation gets its own random source seeded from Seed and the generation number, so a resumed run
+// makes the same choices the original run would have.
+type hillclimbCheckpoint struct {
+	CipherText string                         `json:"cipherText"`
+	Seed       int64                          `json:"seed"`
+	Generation int                            `json:"generation"`
+	Candidates []hillclimbCheckpointCandidate `json:"candidates"`
+}
+
+type hillclimbCheckpointCandidate struct {
+	Key     string  `json:"key"`
+	Fitness float64 `json:"fitness"`
+}
+
+func newHillclimbCheckpoint(cipherText string, seed int64, generation int, candidates substitutionHillclimbCandidates) *hillclimbCheckpoint {
+	checkpoint := &hillclimbCheckpoint{cipherText, seed, generation, make([]hillclimbCheckpointCandidate, 0, len(candidates))}
+	for _, candidate := range candidates {
+		checkpoint.Candidates = append(checkpoint.Candidates, hillclimbCheckpointCandidate{strings.Join(candidate.key, ""), candidate.fitness})
+	}
+	return checkpoint
+}
+
+func (checkpoint *hillclimbCheckpoint) save(path string) error {
+	contents, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, contents, 0644)
+}
+
+// loadHillclimbCheckpoint reads a checkpoint saved by an earlier run. A checkpoint without candidates, or
+// with keys that don't cover the alphabet, can't be carried on from and is an error
+func loadHillclimbCheckpoint(path string) (*hillclimbCheckpoint, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := &hillclimbCheckpoint{}
+	err = json.Unmarshal(contents, checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	if len(checkpoint.Candidates) == 0 {
+		return nil, fmt.Errorf("the checkpoint has no candidates to carry on from")
+	}
+	for _, candidate := range checkpoint.Candidates {
+		if len(candidate.Key) != activeAlphabet.size() {
+			return nil, fmt.Errorf("the checkpoint's key %s should have %d letters but has %d", candidate.Key, activeAlphabet.size(), len(candidate.Key))
+		}
+	}
+	return checkpoint, nil
+}
+
+// generationRand returns the random source for one generation of a run
+func generationRand(seed int64, generation int) *rand.Rand {
+	return rand.New(rand.NewSource(seed + int64(generation)))
+}
+
+// performHillclimbSolve runs the hillclimb search over cipherText, which should be uppercase letters only,
+// as settings say, and returns the best candidates it found in order of fitness. The first generation starts from startKey,
+// or from a random key if it's nil. fixed maps cipher letters to the plain letters they're known to be, and
+// every key tried keeps them. If resume is non-nil, the run carries on from it instead and startKey
+// is ignored. If progress is non-nil, it's called at the end of every generation.
+// If ctx is cancelled, the search stops and the best candidates so far are returned.
+func performHillclimbSolve(ctx context.Context, settings hillclimbSettings, cipherText string, frequencyMap *ngramFrequencyMap, startKey []string,
+	fixed map[byte]byte, resume *hillclimbCheckpoint, progress hillclimbProgressFunc) substitutionHillclimbCandidates {
+
+	freePositions := make([]int, 0, activeAlphabet.size())
+	for position, cipherLetter := range activeAlphabet.symbols {
+		if _, isFixed := fixed[cipherLetter]; !isFixed {
+			freePositions = append(freePositions, position)
+		}
+	}
+
+	candidates := substitutionHillclimbCandidates(make([]*substitutionHillclimbCandidate, 0, settings.candidates))
+
+	seed := settings.seed
 	currentGeneration := 1
-	for currentGeneration <= generations {
+	fitnessGenerations := 1
+	var rng *rand.Rand
+	var currentCandidate *substitutionHillclimbCandidate
+	if resume != nil {
+		seed = resume.Seed
+		currentGeneration = resume.Generation
+		fitnessGenerations = 0
+		for _, saved := range resume.Candidates {
+			// the frequency file might have changed since the checkpoint, so fitness is worked out again
+			candidates = append(candidates, newHillclimbCandidate(strings.Split(saved.Key, ""), cipherText, frequencyMap))
+		}
+		// a stable sort keeps the saved order when the fitnesses haven't changed
+		sort.Stable(candidates)
+		rng = generationRand(seed, currentGeneration)
+		currentCandidate = newHillclimbCandidate(fixKey(generateRandomKey(rng), fixed), cipherText, frequencyMap)
+	} else {
+		if seed == 0 {
+			seed = rand.Int63()
+		}
+		rng = generationRand(seed, currentGeneration)
+		if startKey == nil {
+			startKey = generateRandomKey(rng)
+		}
+		currentCandidate = newHillclimbCandidate(fixKey(startKey, fixed), cipherText, frequencyMap)
+		candidates = append(candidates, currentCandidate)
+	}
+	bestOfGeneration := currentCandidate
+
+	for currentGeneration <= settings.generations && ctx.Err() == nil {
+		if settings.targetFitness != 0 && len(candidates) > 0 && candidates[0].fitness >= settings.targetFitness {
+			// good enough; no need to burn through the remaining generations
+			break
+		}
 		if currentCandidate.fitness > bestOfGeneration.fitness {
 			bestOfGeneration = currentCandidate
 			fitnessGenerations = 0
 
-			if len(candidates) < candidateCount {
+			if len(candidates) < settings.candidates {
 				candidates = append(candidates, bestOfGeneration)
 				sort.Sort(candidates)
 			} else {
@@ -145,90 +344,172 @@ func hillClimbSubstitutionSolve(cmd *cobra.Command, args []string) {
 		}
 
 		// we've gone too long without finding a better fitness
-		if fitnessGenerations > regenAfter {
-			bestOfGeneration = newHillclimbCandidate(generateRandomKey(), justCipherText, frequencyMap)
+		if fitnessGenerations > settings.regenAfter {
+			if progress != nil && len(candidates) > 0 {
+				checkpoint := newHillclimbCheckpoint(cipherText, seed, currentGeneration+1, candidates)
+				progress(hillclimbProgress{currentGeneration, candidates[0], decipherStringFromKey(cipherText, candidates[0].key), checkpoint})
+			}
+			currentGeneration++
+			rng = generationRand(seed, currentGeneration)
+			bestOfGeneration = newHillclimbCandidate(fixKey(generateRandomKey(rng), fixed), cipherText, frequencyMap)
 			currentCandidate = bestOfGeneration
 			fitnessGenerations = 0
-			currentGeneration++
 			continue
 		}
 
 		// look around and choose the best of a random set of nearby paths
 		bestNewCandidate := currentCandidate
-		for localIndex := 0; localIndex < localLookaround; localIndex++ {
+		for localIndex := 0; localIndex < settings.localLookaround; localIndex++ {
 
-			checkCandidate := newHillclimbCandidate(mutateKeyNTimes(mutations, currentCandidate.key), justCipherText, frequencyMap)
+			checkCandidate := newHillclimbCandidate(mutateKeyNTimes(rng, settings.mutations, currentCandidate.key, freePositions), cipherText, frequencyMap)
 			if checkCandidate.fitness > bestNewCandidate.fitness {
 				bestNewCandidate = checkCandidate
 			}
 		}
 		currentCandidate = bestNewCandidate
 	}
-
-	for _, candidate := range candidates {
-		fmt.Printf("%v%s\n\n", candidate, decipherStringFromKey(strings.ToUpper(rawInputText), candidate.key))
-	}
-
+	return candidates
 }
 
-func mutateKeyNTimes(n int, plainLetters []string) []string {
+// mutateKeyNTimes swaps n random pairs of letters in a copy of plainLetters, only picking from the
+// positions in freePositions
+func mutateKeyNTimes(rng *rand.Rand, n int, plainLetters []string, freePositions []int) []string {
 	// make a copy
 	newKey := make([]string, len(plainLetters), len(plainLetters))
 	for index, letter := range plainLetters {
 		newKey[index] = letter
 	}
+	if len(freePositions) < 2 {
+		return newKey
+	}
 
 	for i := 0; i < n; i++ {
-		swap1 := rand.Intn(len(newKey))
-		swap2 := rand.Intn(len(newKey))
+		swap1 := freePositions[rng.Intn(len(freePositions))]
+		swap2 := freePositions[rng.Intn(len(freePositions))]
 		newKey[swap1], newKey[swap2] = newKey[swap2], newKey[swap1]
 	}
 	return newKey
 }
 
 // calculateNgramFitness takes in a deciphered string and calculates its fitness based on trie that maps ngrams to frequency
-func calculateNgramFitness(deciphered string, frequencyMap map[string]float64) float64 {
+func calculateNgramFitness(deciphered string, frequencyMap *ngramFrequencyMap) float64 {
 	var fitness float64
 	scanner := NewNgramScanner(strings.NewReader(deciphered), ngramSize, true)
 	for scanner.Scan() {
-		log10probability, isPresent := frequencyMap[scanner.Text()]
-		if isPresent {
-			fitness += log10probability
-		} else {
-			fitness += -1000
-		}
+		log10probability, _ := frequencyMap.score(scanner.Text())
+		fitness += log10probability
 	}
 	return fitness
 }
 
-func populateFrequencyMapFromReader(reader io.Reader) map[string]float64 {
+// explainNgramFitness breaks calculateNgramFitness down by how common the ngrams are: for each
+// band of log10 frequency, how many of the text's ngrams fall in it and what they add to the fitness
+func explainNgramFitness(deciphered string, frequencyMap *ngramFrequencyMap) []string {
+	counts := make(map[int]int)
+	totals := make(map[int]float64)
+	missing := 0
+	scanner := NewNgramScanner(strings.NewReader(deciphered), ngramSize, true)
+	for scanner.Scan() {
+		log10probability, isPresent := frequencyMap.score(scanner.Text())
+		if !isPresent {
+			missing++
+			continue
+		}
+		band := int(math.Floor(log10probability))
+		counts[band]++
+		totals[band] += log10probability
+	}
+
+	bands := make([]int, 0, len(counts))
+	for band := range counts {
+		bands = append(bands, band)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(bands)))
+	reasons := make([]string, 0, len(bands)+1)
+	for _, band := range bands {
+		reasons = append(reasons, fmt.Sprintf("%d ngrams from %d to %d: %.4f", counts[band], band+1, band, totals[band]))
+	}
+	if missing > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d ngrams not in the table: %.4f", missing, float64(missing)*frequencyMap.unseen))
+	}
+	return reasons
+}
+
+// the most bad lines of a frequency file to describe before just counting the rest
+const maxReportedFrequencyLines = 10
+
+// populateFrequencyMapFromReader reads a frequency file of ngram, tab, log10 frequency lines. Unseen ngrams
+// get the frequency of the rarest one in the file until it's smoothed some other way. Lines that can't be
+// read are skipped and described in the returned slice; the error is for a file that can't be read at all
+// or has no ngrams in it
+func populateFrequencyMapFromReader(reader io.Reader) (*ngramFrequencyMap, []string, error) {
 	result := make(map[string]float64)
+	rarest := math.Inf(1)
+	skipped := make([]string, 0)
+	skippedCount := 0
+	fileNgramSize := 0
 	now := time.Now().UnixNano()
-	for scanner := bufio.NewScanner(reader); scanner.Scan(); {
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
 		line := scanner.Text()
-		fields := strings.Split(line, "\t")
-		ngramSize = len(fields[0])
-		frequency, err := strconv.ParseFloat(fields[1], 64)
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		ngram, frequency, err := parseFrequencyLine(line, fileNgramSize)
 		if err != nil {
-			fmt.Printf("Invalid float in frequency file: %s\n", fields[1])
-			os.Exit(1)
+			skippedCount++
+			if len(skipped) < maxReportedFrequencyLines {
+				skipped = append(skipped, fmt.Sprintf("line %d: %v", lineNumber, err))
+			}
+			continue
 		}
-		result[fields[0]] = frequency
+
+		fileNgramSize = len(ngram)
+		result[ngram] = frequency
+		rarest = math.Min(rarest, frequency)
+	}
+	if skippedCount > len(skipped) {
+		skipped = append(skipped, fmt.Sprintf("and %d more", skippedCount-len(skipped)))
 	}
 	if profile {
 		fmt.Printf("Reading into trie took: %.8fms\n", float64(time.Now().UnixNano()-now)/float64(1000000))
 	}
-	return result
+
+	if scanner.Err() != nil {
+		return nil, skipped, scanner.Err()
+	}
+	if len(result) == 0 {
+		return nil, skipped, fmt.Errorf("there are no ngram frequencies in the file")
+	}
+	ngramSize = fileNgramSize
+	return &ngramFrequencyMap{result, rarest, rarest}, skipped, nil
 }
 
-// decipherStringFromKey decrypts cipherText by using the byte of the cipher letter as an index into plainLetters
+// parseFrequencyLine splits a frequency file line into its ngram and frequency. Once the file's ngram
+// size is known, every ngram has to be that long
+func parseFrequencyLine(line string, ngramSize int) (string, float64, error) {
+	fields := strings.Split(line, "\t")
+	if len(fields) != 2 || fields[0] == "" {
+		return "", 0, fmt.Errorf("should be an ngram and a frequency separated by a tab")
+	}
+	frequency, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || math.IsNaN(frequency) || math.IsInf(frequency, 0) {
+		return "", 0, fmt.Errorf("invalid frequency %s", fields[1])
+	}
+	if ngramSize != 0 && len(fields[0]) != ngramSize {
+		return "", 0, fmt.Errorf("%s isn't %d letters long like the ngrams before it", fields[0], ngramSize)
+	}
+	return fields[0], frequency, nil
+}
+
+// decipherStringFromKey decrypts cipherText by using the position of the cipher letter in the alphabet as an index into plainLetters
 func decipherStringFromKey(cipherText string, plainLetters []string) string {
 	plainText := strings.Builder{}
 	plainText.Grow(len(cipherText))
 	for _, currentCipherLetter := range strings.Split(cipherText, "") {
-		curByte := []byte(currentCipherLetter)[0]
-		index := curByte - ASCII_A
-		if index < 0 || index > 25 {
+		index := activeAlphabet.position([]byte(currentCipherLetter)[0])
+		if index < 0 {
 			plainText.WriteString(currentCipherLetter)
 		} else {
 			plainText.WriteString(plainLetters[index])
@@ -237,19 +518,131 @@ func decipherStringFromKey(cipherText string, plainLetters []string) string {
 	return plainText.String()
 }
 
-func generateRandomKey() []string {
-	letters := []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L", "M", "N", "O", "P", "Q", "R", "S", "T", "U", "V", "W", "X", "Y", "Z"}
-	rand.Shuffle(len(letters), func(i, j int) { letters[i], letters[j] = letters[j], letters[i] })
+// parseHillclimbKey turns a string with one letter for each symbol of the alphabet into a key, where
+// the first letter is the plaintext for ciphertext A and so on. Every letter has to be used exactly once.
+func parseHillclimbKey(keyString string) ([]string, error) {
+	keyString = strings.ToUpper(keyString)
+	if len(keyString) != activeAlphabet.size() {
+		return nil, fmt.Errorf("key must be %d letters, but %s is %d", activeAlphabet.size(), keyString, len(keyString))
+	}
+
+	seen := make(map[byte]bool)
+	key := make([]string, 0, activeAlphabet.size())
+	for _, keyByte := range []byte(keyString) {
+		if !activeAlphabet.contains(keyByte) {
+			return nil, fmt.Errorf("%c is not a letter", keyByte)
+		}
+		if seen[keyByte] {
+			return nil, fmt.Errorf("%c appears more than once", keyByte)
+		}
+		seen[keyByte] = true
+		key = append(key, string(keyByte))
+	}
+	return key, nil
+}
+
+// fixKey returns a copy of key with letters swapped around so that every cipher letter in fixed
+// deciphers to its plain letter
+func fixKey(key []string, fixed map[byte]byte) []string {
+	key = append([]string{}, key...)
+	for cipherLetter, plainLetter := range fixed {
+		position := activeAlphabet.position(cipherLetter)
+		if position < 0 {
+			continue
+		}
+		for index, letter := range key {
+			if letter == string(plainLetter) {
+				key[index], key[position] = key[position], key[index]
+				break
+			}
+		}
+	}
+	return key
+}
+
+func generateRandomKey(rng *rand.Rand) []string {
+	letters := activeAlphabet.letters()
+	rng.Shuffle(len(letters), func(i, j int) { letters[i], letters[j] = letters[j], letters[i] })
 	return letters
 }
 
+// runHillclimbSolver is substitution hillclimb for the solver registry. It scores with the built-in
+// tetragrams, since requests can't name files for the server to read
+func runHillclimbSolver(ctx context.Context, input solverInput) (*resultTable, error) {
+	rawText := input.getString("text")
+	cipherText := lettersOnly(rawText)
+	if cipherText == "" {
+		return nil, fmt.Errorf("there are no letters to solve")
+	}
+	settings := hillclimbSettings{
+		generations:     input.getInt("generations"),
+		mutations:       1,
+		regenAfter:      input.getInt("regen_after"),
+		candidates:      input.getInt("candidates"),
+		localLookaround: 1,
+		targetFitness:   input.getFloat("target_fitness"),
+		seed:            int64(input.getInt("seed")),
+	}
+	if settings.generations < 1 || settings.candidates < 1 {
+		return nil, fmt.Errorf("generations and candidates have to be at least 1")
+	}
+
+	var startKey []string
+	if keyString := input.getString("start_key"); keyString != "" {
+		var err error
+		startKey, err = parseHillclimbKey(keyString)
+		if err != nil {
+			return nil, err
+		}
+	}
+	frequencyMap, err := loadFrequencyMap("")
+	if err != nil {
+		return nil, err
+	}
+
+	progress := func(progress hillclimbProgress) {
+		reportSolverProgress(ctx, float64(progress.generation), float64(settings.generations),
+			fmt.Sprintf("generation %d fitness %.4f %s", progress.generation, progress.best.fitness, progress.plainText))
+	}
+	table := newResultTable("fitness", "key", "plaintext")
+	for _, candidate := range performHillclimbSolve(ctx, settings, cipherText, frequencyMap, startKey, nil, nil, progress) {
+		plainText := decipherStringFromKey(activeAlphabet.foldString(rawText), candidate.key)
+		table.addRow(fmt.Sprintf("%.4f", candidate.fitness), strings.Join(candidate.key, ""), plainText)
+	}
+	return table, nil
+}
+
 func init() {
-	hillclimbCmd.Flags().StringVarP(&ngramFrequencyFile, "frequency-file", "f", "", "the path to the frequency file to use. Use - for stdin. The chunking of the input text will use the same ngram size from the first line of the file, and the file is assumed to be ngram tab log10 of frequency")
-	hillclimbCmd.MarkFlagRequired("frequency-file")
+	mustRegisterSolver(&solver{
+		name:        "hillclimb",
+		description: "Keys for a simple substitution cipher found by hill climbing on English tetragram frequencies, best first",
+		parameters: []solverParameter{
+			solverParameter{name: "text", kind: solverString, description: "the ciphertext", required: true},
+			solverParameter{name: "generations", kind: solverInt, description: "how many times to start over from a random key", defaultValue: "50"},
+			solverParameter{name: "regen_after", kind: solverInt, description: "how many tries without a better key before starting over", defaultValue: "1000"},
+			solverParameter{name: "candidates", kind: solverInt, description: "how many of the best keys to return", defaultValue: "10"},
+			solverParameter{name: "target_fitness", kind: solverNumber, description: "stop as soon as a key's fitness reaches this. Fitness is negative, so 0 never stops early"},
+			solverParameter{name: "start_key", kind: solverString, description: "the plaintext for each letter of the alphabet, to start the first generation from"},
+			solverParameter{name: "seed", kind: solverInt, description: "seed for the random choices, so runs can be repeated. 0 picks one at random"},
+		},
+		run: runHillclimbSolver,
+	})
+
+	hillclimbCmd.Flags().StringVarP(&ngramFrequencyFile, "frequency-file", "f", "", "the path to the frequency file to use, instead of the built-in English tetragrams. Use - for stdin. The chunking of the input text will use the same ngram size from the first line of the file, and the file is assumed to be ngram tab log10 of frequency")
+	hillclimbCmd.Flags().StringVarP(&ngramSmoothing, "smoothing", "", smoothingFloor, "how to score ngrams that aren't in the frequency file: floor (as the rarest one that is), add-k, or fixed (the old flat -1000)")
+	hillclimbCmd.Flags().Float64VarP(&ngramSmoothingK, "smoothing-k", "", 0.5, "the k to add to every ngram's count for --smoothing add-k")
 	hillclimbCmd.Flags().IntVarP(&generations, "generations", "g", 50, "the number of generations to run for - generations happen based on the regen-after setting")
 	hillclimbCmd.Flags().IntVarP(&mutations, "mutations", "m", 1, "the number of mutations to do on the key during each iteration")
 	hillclimbCmd.Flags().IntVarP(&regenAfter, "regen-after", "r", 1000, "how long a fitness can survive before the program starts with a new random key")
 	hillclimbCmd.Flags().IntVarP(&candidateCount, "candidates", "c", 10, "the number of top performing candidates to display")
 	hillclimbCmd.Flags().IntVarP(&localLookaround, "local-lookaround", "l", 1, "when picking a new path, evaluate this many local candidates and choose the best of them")
+	hillclimbCmd.Flags().Float64VarP(&targetFitness, "target-fitness", "t", 0, "stop as soon as a candidate's fitness reaches this value. fitness is always negative, so the default of 0 never stops early")
+	hillclimbCmd.Flags().BoolVarP(&showProgress, "progress", "p", false, "show a live status line with the current generation and best candidate on stderr")
+	hillclimbCmd.Flags().StringVarP(&startKeyString, "start-key", "", "", "a key (the plaintext for each letter of the alphabet, A through Z by default) to start the first generation from, such as one saved with --save-best")
+	hillclimbCmd.Flags().StringVarP(&saveBestFile, "save-best", "", "", "write the best key found to this file when the run finishes")
+	hillclimbCmd.Flags().StringVarP(&checkpointFile, "checkpoint", "", "", "write the candidate pool and random state to this file as the run goes, so it can be picked up with --resume")
+	hillclimbCmd.Flags().IntVarP(&checkpointEvery, "checkpoint-every", "", 1, "how many generations to run between checkpoints")
+	hillclimbCmd.Flags().StringVarP(&resumeFile, "resume", "", "", "carry on a run from a checkpoint file. --generations is the total, including the generations already run")
+	hillclimbCmd.Flags().Int64VarP(&hillclimbSeed, "seed", "", 0, "seed for the random choices, so runs can be repeated. 0 picks one at random")
 	substitutionCmd.AddCommand(hillclimbCmd)
 }
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setHillclimbParameters sets the package-level hillclimb settings the way the command flags would
+func setHillclimbParameters(testGenerations, testRegenAfter, testCandidates int) {
+	generations = testGenerations
+	regenAfter = testRegenAfter
+	candidateCount = testCandidates
+	mutations = 1
+	localLookaround = 1
+	targetFitness = 0
+}
+
+func TestPerformHillclimbSolveProgress(test *testing.T) {
+	frequencyMap, _, _ := populateFrequencyMapFromReader(strings.NewReader("THEQ\t-1.0\nHEQU\t-1.5\nEQUI\t-2.0"))
+	setHillclimbParameters(3, 5, 2)
+
+	seenGenerations := make([]int, 0, generations)
+	candidates := performHillclimbSolve(context.Background(), hillclimbSettingsFromFlags(), "GURDHVPX", frequencyMap, nil, nil, nil, func(progress hillclimbProgress) {
+		seenGenerations = append(seenGenerations, progress.generation)
+		if progress.best == nil || progress.plainText == "" {
+			test.Errorf("Expected a best candidate and its plaintext in progress, got %v", progress)
+		}
+	})
+
+	if len(seenGenerations) != generations {
+		test.Errorf("Expected progress for %d generations but got %v", generations, seenGenerations)
+	}
+	for index, generation := range seenGenerations {
+		if generation != index+1 {
+			test.Errorf("Expected generation %d at %d but got %d", index+1, index, generation)
+		}
+	}
+
+	if len(candidates) == 0 || len(candidates) > candidateCount {
+		test.Errorf("Expected between 1 and %d candidates but got %d", candidateCount, len(candidates))
+	}
+}
+
+func TestParseHillclimbKey(test *testing.T) {
+	key, err := parseHillclimbKey("nopqrstuvwxyzabcdefghijklm")
+	if err != nil {
+		test.Fatalf("Unexpected error parsing key: %v", err)
+	}
+	if strings.Join(key, "") != "NOPQRSTUVWXYZABCDEFGHIJKLM" {
+		test.Errorf("Expected uppercased key but got %v", key)
+	}
+
+	for _, badKey := range []string{"ABC", "AACDEFGHIJKLMNOPQRSTUVWXYZ", "ABCDEFGHIJKLMNOPQRSTUVWXY1"} {
+		if _, err := parseHillclimbKey(badKey); err == nil {
+			test.Errorf("Expected an error parsing %s", badKey)
+		}
+	}
+}
+
+func TestPerformHillclimbSolveStartKey(test *testing.T) {
+	frequencyMap, _, _ := populateFrequencyMapFromReader(strings.NewReader("THEQ\t-1.0\nHEQU\t-1.5\nEQUI\t-2.0"))
+	setHillclimbParameters(1, 0, 1)
+
+	// rot13 deciphers GURDHVPX to THEQUICK, which is as good as this frequency map gets
+	startKey, _ := parseHillclimbKey("NOPQRSTUVWXYZABCDEFGHIJKLM")
+	candidates := performHillclimbSolve(context.Background(), hillclimbSettingsFromFlags(), "GURDHVPX", frequencyMap, startKey, nil, nil, nil)
+	if strings.Join(candidates[0].key, "") != "NOPQRSTUVWXYZABCDEFGHIJKLM" {
+		test.Errorf("Expected the start key to be the best candidate but got %v", candidates[0])
+	}
+}
+
+func TestPerformHillclimbSolveCancelled(test *testing.T) {
+	frequencyMap, _, _ := populateFrequencyMapFromReader(strings.NewReader("THEQ\t-1.0\nHEQU\t-1.5\nEQUI\t-2.0"))
+	setHillclimbParameters(1000000, 1000, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	candidates := performHillclimbSolve(ctx, hillclimbSettingsFromFlags(), "GURDHVPX", frequencyMap, nil, nil, nil, func(progress hillclimbProgress) {
+		test.Errorf("Expected no generations to run after cancellation but got %d", progress.generation)
+	})
+	if len(candidates) != 1 {
+		test.Errorf("Expected just the starting candidate after cancellation but got %d", len(candidates))
+	}
+}
+
+func TestPerformHillclimbSolveResume(test *testing.T) {
+	frequencyMap, _, _ := populateFrequencyMapFromReader(strings.NewReader("THEQ\t-1.0\nHEQU\t-1.5\nEQUI\t-2.0\nQUIC\t-2.5\nUICK\t-3.0"))
+	hillclimbSeed = 42
+	defer func() { hillclimbSeed = 0 }()
+	keysOf := func(candidates substitutionHillclimbCandidates) string {
+		keys := make([]string, 0, len(candidates))
+		for _, candidate := range candidates {
+			keys = append(keys, strings.Join(candidate.key, ""))
+		}
+		return strings.Join(keys, ",")
+	}
+
+	setHillclimbParameters(4, 20, 3)
+	uninterrupted := performHillclimbSolve(context.Background(), hillclimbSettingsFromFlags(), "GURDHVPX", frequencyMap, nil, nil, nil, nil)
+
+	setHillclimbParameters(2, 20, 3)
+	var checkpoint *hillclimbCheckpoint
+	performHillclimbSolve(context.Background(), hillclimbSettingsFromFlags(), "GURDHVPX", frequencyMap, nil, nil, nil, func(progress hillclimbProgress) {
+		checkpoint = progress.checkpoint
+	})
+	if checkpoint == nil || checkpoint.Generation != 3 {
+		test.Fatalf("Expected a checkpoint for generation 3 but got %v", checkpoint)
+	}
+
+	// the checkpoint has to survive being written out
+	path := filepath.Join(test.TempDir(), "checkpoint.json")
+	if err := checkpoint.save(path); err != nil {
+		test.Fatalf("Could not save checkpoint: %v", err)
+	}
+	loaded, err := loadHillclimbCheckpoint(path)
+	if err != nil {
+		test.Fatalf("Could not load checkpoint: %v", err)
+	}
+
+	setHillclimbParameters(4, 20, 3)
+	resumed := performHillclimbSolve(context.Background(), hillclimbSettingsFromFlags(), "GURDHVPX", frequencyMap, nil, nil, loaded, nil)
+	if keysOf(resumed) != keysOf(uninterrupted) {
+		test.Errorf("Expected the resumed run to match the uninterrupted one: %s vs %s", keysOf(resumed), keysOf(uninterrupted))
+	}
+
+	// checkpoints that can't be carried on from are refused when they're read
+	for _, bad := range []string{`{"cipherText": "GURDHVPX", "candidates": []}`, `{"cipherText": "GURDHVPX", "candidates": [{"key": "ABC"}]}`} {
+		os.WriteFile(path, []byte(bad), 0644)
+		if _, err := loadHillclimbCheckpoint(path); err == nil {
+			test.Errorf("Expected an error loading %s", bad)
+		}
+	}
+	// and a run handed one anyway carries on without a best candidate to report until it finds one
+	empty := &hillclimbCheckpoint{CipherText: "GURDHVPX", Generation: 1}
+	performHillclimbSolve(context.Background(), hillclimbSettingsFromFlags(), "GURDHVPX", frequencyMap, nil, nil, empty, func(progress hillclimbProgress) {})
+}
+
+func TestLoadFrequencyMapEmbedded(test *testing.T) {
+	ngramSize = 0
+	frequencyMap, err := loadFrequencyMap("")
+	if err != nil {
+		test.Fatalf("Expected the built-in table to load but got %v", err)
+	}
+	if ngramSize != 4 {
+		test.Errorf("Expected an ngram size of 4 but got %d", ngramSize)
+	}
+	tion, hasTion := frequencyMap.score("TION")
+	zzzq, hasZzzq := frequencyMap.score("ZZZQ")
+	if !hasTion || (hasZzzq && tion <= zzzq) {
+		test.Errorf("Expected TION to be in the table and outscore ZZZQ but got %f and %f", tion, zzzq)
+	}
+
+	dir := test.TempDir()
+	path := filepath.Join(dir, "trigrams.txt")
+	if err := os.WriteFile(path, []byte("THE\t-1.0\nAND\t-1.5\n"), 0644); err != nil {
+		test.Fatal(err)
+	}
+	frequencyMap, err = loadFrequencyMap(path)
+	if err != nil || len(frequencyMap.frequencies) != 2 || ngramSize != 3 {
+		test.Errorf("Expected the file's 2 trigrams but got %v, %d, %v", frequencyMap, ngramSize, err)
+	}
+
+	if _, err := loadFrequencyMap(filepath.Join(dir, "missing.txt")); err == nil {
+		test.Errorf("Expected an error for a missing file")
+	}
+}
+
+func TestPopulateFrequencyMapFromReader(test *testing.T) {
+	input := "THE\t-1.0\nAND -1.5\n\nING\tlots\nTHEN\t-2.0\nION\t-2.5\nENT\tNaN"
+	frequencyMap, skipped, err := populateFrequencyMapFromReader(strings.NewReader(input))
+	if err != nil {
+		test.Fatalf("Expected the good lines to load but got %v", err)
+	}
+	if len(frequencyMap.frequencies) != 2 || frequencyMap.rarest != -2.5 || ngramSize != 3 {
+		test.Errorf("Expected THE and ION with a rarest of -2.5 but got %v", frequencyMap)
+	}
+	expected := []string{
+		"line 2: should be an ngram and a frequency separated by a tab",
+		"line 4: invalid frequency lots",
+		"line 5: THEN isn't 3 letters long like the ngrams before it",
+		"line 7: invalid frequency NaN",
+	}
+	if strings.Join(skipped, "|") != strings.Join(expected, "|") {
+		test.Errorf("Expected skipped lines %v but got %v", expected, skipped)
+	}
+
+	// only the first few bad lines are described
+	_, skipped, _ = populateFrequencyMapFromReader(strings.NewReader("THE\t-1\n" + strings.Repeat("bad\n", 15)))
+	if len(skipped) != maxReportedFrequencyLines+1 || skipped[maxReportedFrequencyLines] != "and 5 more" {
+		test.Errorf("Expected %d described lines and 5 more but got %v", maxReportedFrequencyLines, skipped)
+	}
+
+	if _, _, err := populateFrequencyMapFromReader(strings.NewReader("not a frequency file\n")); err == nil {
+		test.Errorf("Expected an error for a file with no frequencies")
+	}
+}
+
+func TestExplainNgramFitness(test *testing.T) {
+	frequencyMap, _, _ := populateFrequencyMapFromReader(strings.NewReader("THE\t-1.5\nHEQ\t-3.25\nEQU\t-3.5"))
+	reasons := explainNgramFitness("THEQUX", frequencyMap)
+	expected := []string{"1 ngrams from -1 to -2: -1.5000", "2 ngrams from -3 to -4: -6.7500", "1 ngrams not in the table: -3.5000"}
+	if strings.Join(reasons, "|") != strings.Join(expected, "|") {
+		test.Errorf("Expected %v but got %v", expected, reasons)
+	}
+}
+
+func TestPerformHillclimbSolveTargetFitness(test *testing.T) {
+	frequencyMap, _, _ := populateFrequencyMapFromReader(strings.NewReader("THEQ\t-1.0\nHEQU\t-1.5\nEQUI\t-2.0"))
+	setHillclimbParameters(1000000, 5, 2)
+	settings := hillclimbSettingsFromFlags()
+	// THEQUICK scores -8.5, with QUIC and UICK as rare as the rarest ngram in the table
+	settings.targetFitness = -9
+
+	startKey, _ := parseHillclimbKey("NOPQRSTUVWXYZABCDEFGHIJKLM")
+	candidates := performHillclimbSolve(context.Background(), settings, "GURDHVPX", frequencyMap, startKey, nil, nil, func(progress hillclimbProgress) {
+		test.Fatalf("Expected the search to stop before finishing generation %d", progress.generation)
+	})
+	if len(candidates) != 1 || candidates[0].fitness != -8.5 {
+		test.Errorf("Expected just the start key with a fitness of -8.5 but got %v", candidates)
+	}
+}
+
+func TestHillclimbSolverTargetFitness(test *testing.T) {
+	table, err := runSolver(context.Background(), lookupSolver("hillclimb"), map[string]string{
+		"text":           "Gur dhvpx oebja sbk",
+		"generations":    "1000000",
+		"start_key":      "NOPQRSTUVWXYZABCDEFGHIJKLM",
+		"target_fitness": "-1000",
+	})
+	if err != nil {
+		test.Fatalf("Unexpected error: %v", err)
+	}
+	if len(table.rows) != 1 || table.truncated || table.rows[0][2] != "THE QUICK BROWN FOX" {
+		test.Errorf("Expected to stop at the start key with THE QUICK BROWN FOX but got %v", table)
+	}
+
+	for _, fitness := range []string{"close", "NaN"} {
+		if _, err := lookupSolver("hillclimb").parseInput(map[string]string{"text": "Gur", "target_fitness": fitness}); err == nil {
+			test.Errorf("Expected an error for a target fitness of %s", fitness)
+		}
+	}
+}
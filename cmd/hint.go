@@ -0,0 +1,134 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var hintRuns int
+
+// hintCmd represents the hint command
+var hintCmd = &cobra.Command{
+	Use:   "hint",
+	Short: "Reveals one likely letter of a substitution cipher without giving away the rest",
+	Long: `Runs the hillclimb solver several times, keeping any letters passed in with --key, and
+	reveals the one mapping the runs agree on most. Add it to --key and run hint again for the next one.
+	Ties go to the cipher letter that appears most often, since it helps the most.
+	`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  printHint,
+}
+
+// substitutionHint is a single cipher to plain mapping and how many hillclimb runs agreed on it
+type substitutionHint struct {
+	cipher    byte
+	plain     byte
+	agreement int
+	runs      int
+}
+
+func printHint(cmd *cobra.Command, args []string) {
+	frequencyMap, err := loadFrequencyMap(ngramFrequencyFile)
+	if err != nil {
+		fmt.Printf("Error with frequency file: %v\n", err)
+		os.Exit(1)
+	}
+
+	known := make(map[byte]byte)
+	if substitutionKey != "" {
+		known, err = parseSubstitutionKey(substitutionKey)
+		if err != nil {
+			fmt.Printf("Invalid key: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	ctx, cancel := solveContext()
+	defer cancel()
+	cipherText := string(justUppercaseLetters(strings.Join(args, " ")))
+	hint, err := findHint(ctx, cipherText, frequencyMap, known, hintRuns)
+	if err != nil {
+		fmt.Printf("No hint: %v\n", err)
+		os.Exit(1)
+	}
+	printer := newResultPrinter("cipher", "plain", "agreement", "runs")
+	printer.result(fmt.Sprintf("%c=%c (%d of %d runs agree)", hint.cipher, hint.plain, hint.agreement, hint.runs),
+		string(hint.cipher), string(hint.plain), strconv.Itoa(hint.agreement), strconv.Itoa(hint.runs))
+	printer.finish(false)
+}
+
+// findHint runs hillclimb runs times over cipherText with the known mappings (cipher to lowercase plain)
+// fixed, and returns the unknown cipher letter whose plain letter the most runs agree on
+func findHint(ctx context.Context, cipherText string, frequencyMap *ngramFrequencyMap, known map[byte]byte, runs int) (substitutionHint, error) {
+	fixed := make(map[byte]byte)
+	for cipherByte, plainByte := range known {
+		fixed[cipherByte] = upperCaseByte(plainByte)
+	}
+
+	letterCounts := frequencyCountInString(cipherText)
+	votes := make(map[byte]map[byte]int)
+	for cipherByte := range letterCounts {
+		if _, isKnown := fixed[cipherByte]; !isKnown {
+			votes[cipherByte] = make(map[byte]int)
+		}
+	}
+	if len(votes) == 0 {
+		return substitutionHint{}, fmt.Errorf("every letter is already known")
+	}
+
+	completedRuns := 0
+	for run := 0; run < runs && ctx.Err() == nil; run++ {
+		best := performHillclimbSolve(ctx, hillclimbSettingsFromFlags(), cipherText, frequencyMap, nil, fixed, nil, nil)[0]
+		for cipherByte := range votes {
+			votes[cipherByte][best.key[activeAlphabet.position(cipherByte)][0]]++
+		}
+		completedRuns++
+	}
+	if completedRuns == 0 {
+		return substitutionHint{}, fmt.Errorf("the search was stopped before any runs finished")
+	}
+
+	// go through the letters in order so that ties always come out the same way
+	hint := substitutionHint{runs: completedRuns}
+	for _, cipherByte := range activeAlphabet.symbols {
+		for _, plainByte := range activeAlphabet.symbols {
+			count := votes[cipherByte][plainByte]
+			if count > hint.agreement || (count == hint.agreement && count > 0 && letterCounts[cipherByte] > letterCounts[hint.cipher]) {
+				hint.cipher = cipherByte
+				hint.plain = plainByte
+				hint.agreement = count
+			}
+		}
+	}
+	hint.plain = strings.ToLower(string(hint.plain))[0]
+	return hint, nil
+}
+
+func init() {
+	hintCmd.Flags().StringVarP(&ngramFrequencyFile, "frequency-file", "f", "", "the ngram frequency file to score with, in the same format hillclimb uses. Defaults to the built-in English tetragrams")
+	hintCmd.Flags().StringVarP(&ngramSmoothing, "smoothing", "", smoothingFloor, "how to score ngrams that aren't in the frequency file: floor, add-k, or fixed, as for hillclimb")
+	hintCmd.Flags().Float64VarP(&ngramSmoothingK, "smoothing-k", "", 0.5, "the k for --smoothing add-k")
+	hintCmd.Flags().StringVarP(&substitutionKey, "key", "k", "", "letters that are already known, as comma-separated A=b mappings or a 26-letter key")
+	hintCmd.Flags().IntVarP(&hintRuns, "runs", "", 5, "how many hillclimb runs to take a consensus from")
+	substitutionCmd.AddCommand(hintCmd)
+}
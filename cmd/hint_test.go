@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFindHint(test *testing.T) {
+	frequencyMap, _, _ := populateFrequencyMapFromReader(strings.NewReader("THEQ\t-1.0\nHEQU\t-1.5\nEQUI\t-2.0"))
+	setHillclimbParameters(2, 20, 1)
+
+	known, _ := parseSubstitutionKey("G=t,U=h,R=e,D=q,H=u,V=i,P=c")
+	hint, err := findHint(context.Background(), "GURDHVPX", frequencyMap, known, 3)
+	if err != nil {
+		test.Fatalf("Unexpected error: %v", err)
+	}
+	// X is the only letter left, so its hint has to come from it
+	if hint.cipher != 'X' || hint.runs != 3 || hint.agreement < 1 {
+		test.Errorf("Expected a hint for X from 3 runs but got %+v", hint)
+	}
+	if !isLowercaseAscii(hint.plain) || strings.IndexByte("thequic", hint.plain) >= 0 {
+		test.Errorf("Expected a lowercase plain letter not already used but got %c", hint.plain)
+	}
+
+	known['X'] = 'k'
+	if _, err := findHint(context.Background(), "GURDHVPX", frequencyMap, known, 3); err == nil {
+		test.Errorf("Expected an error when every letter is known")
+	}
+}
@@ -0,0 +1,228 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// where to log commands as they're run. Empty (the default) means history isn't kept
+var historyFile string
+
+// how many results the running command produced, for the history log. -1 means the command doesn't say
+var historyResultCount = -1
+
+// when the running command started, for timing it in the history log
+var historyStartedAt time.Time
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history [SEARCH]",
+	Short: "Lists the commands recorded in the history file, optionally only the ones containing SEARCH",
+	Long: `History is opt in: pass --history-file on each run, or set history-file in the config file
+	($HOME/.puzzle_helper.yaml), and every command that finishes is added to it along with its
+	parameters, how long it took, and how many results it produced.
+
+	Examples:
+	  puzzle_helper history caesar
+	  puzzle_helper history rerun 12`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  showHistory,
+}
+
+var historyRerunCmd = &cobra.Command{
+	Use:   "rerun NUMBER",
+	Short: "Runs a command from the history again, using its number from the history list",
+	Args:  cobra.ExactArgs(1),
+	Run:   rerunHistory,
+}
+
+// historyEntry is one line of the history file
+type historyEntry struct {
+	Command    string            `json:"command"`
+	Args       []string          `json:"args"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+	RanAt      time.Time         `json:"ranAt"`
+	DurationMs int64             `json:"durationMs"`
+	// Results is left out for commands that don't report a count
+	Results *int `json:"results,omitempty"`
+}
+
+// recordResults adds count to the number of results the running command reports to the history
+func recordResults(count int) {
+	if historyResultCount < 0 {
+		historyResultCount = 0
+	}
+	historyResultCount += count
+}
+
+// newHistoryEntry describes a finished run of cmd
+func newHistoryEntry(cmd *cobra.Command, startedAt time.Time, results int) historyEntry {
+	entry := historyEntry{
+		Command:    cmd.CommandPath(),
+		Args:       os.Args[1:],
+		Parameters: make(map[string]string),
+		RanAt:      startedAt,
+		DurationMs: time.Since(startedAt).Milliseconds(),
+	}
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		if flag.Name == "history-file" {
+			return
+		}
+		// slices like several --dictionary files are recorded as a comma-separated list rather than [a,b]
+		if slice, isSlice := flag.Value.(pflag.SliceValue); isSlice {
+			entry.Parameters[flag.Name] = strings.Join(slice.GetSlice(), ",")
+		} else {
+			entry.Parameters[flag.Name] = flag.Value.String()
+		}
+	})
+	if results >= 0 {
+		entry.Results = &results
+	}
+	return entry
+}
+
+// appendHistory adds entry to the end of the history file at path, creating it if needed
+func appendHistory(path string, entry historyEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// readHistory reads every entry in the history file. Lines that can't be parsed are skipped
+// rather than losing the rest of the history
+func readHistory(reader io.Reader) []historyEntry {
+	entries := make([]historyEntry, 0)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// searchHistory returns the numbers (starting at 1) of the entries whose command line contains search
+func searchHistory(entries []historyEntry, search string) []int {
+	numbers := make([]int, 0, len(entries))
+	for index, entry := range entries {
+		if strings.Contains(strings.ToLower(strings.Join(entry.Args, " ")), strings.ToLower(search)) {
+			numbers = append(numbers, index+1)
+		}
+	}
+	return numbers
+}
+
+// String formats the entry the way the history command lists it
+func (entry historyEntry) String() string {
+	results := "-"
+	if entry.Results != nil {
+		results = strconv.Itoa(*entry.Results)
+	}
+	return fmt.Sprintf("%s  %6dms  %5s results  %s", entry.RanAt.Format("2006-01-02 15:04:05"), entry.DurationMs, results, strings.Join(entry.Args, " "))
+}
+
+// recordHistory is called after every command finishes and logs it if there's a history file
+func recordHistory(cmd *cobra.Command) {
+	path := viper.GetString("history-file")
+	if path == "" || cmd == historyCmd || cmd == historyRerunCmd {
+		return
+	}
+	if err := appendHistory(path, newHistoryEntry(cmd, historyStartedAt, historyResultCount)); err != nil {
+		fmt.Fprintf(os.Stderr, "Could not write history to %s: %v\n", path, err)
+	}
+}
+
+func mustReadHistory() []historyEntry {
+	path := viper.GetString("history-file")
+	if path == "" {
+		fmt.Println("No history file; pass --history-file or set history-file in the config file")
+		os.Exit(1)
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []historyEntry{}
+	}
+	if err != nil {
+		fmt.Printf("Could not read history: %v\n", err)
+		os.Exit(1)
+	}
+	defer file.Close()
+	return readHistory(file)
+}
+
+func showHistory(cmd *cobra.Command, args []string) {
+	entries := mustReadHistory()
+	search := ""
+	if len(args) > 0 {
+		search = args[0]
+	}
+	for _, number := range searchHistory(entries, search) {
+		fmt.Printf("%4d  %v\n", number, entries[number-1])
+	}
+}
+
+func rerunHistory(cmd *cobra.Command, args []string) {
+	entries := mustReadHistory()
+	number, err := strconv.Atoi(args[0])
+	if err != nil || number < 1 || number > len(entries) {
+		fmt.Printf("%s is not one of the %d history entries\n", args[0], len(entries))
+		os.Exit(1)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		fmt.Printf("Could not find the puzzle_helper executable: %v\n", err)
+		os.Exit(1)
+	}
+	rerun := exec.Command(executable, entries[number-1].Args...)
+	rerun.Stdin = os.Stdin
+	rerun.Stdout = os.Stdout
+	rerun.Stderr = os.Stderr
+	if err := rerun.Run(); err != nil {
+		fmt.Printf("Command failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&historyFile, "history-file", "", "", "log every command run, with its parameters, runtime, and result count, to this file")
+	viper.BindPFlag("history-file", rootCmd.PersistentFlags().Lookup("history-file"))
+	historyCmd.AddCommand(historyRerunCmd)
+	rootCmd.AddCommand(historyCmd)
+}
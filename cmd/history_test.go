@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistoryRoundTrip(test *testing.T) {
+	path := filepath.Join(test.TempDir(), "history.jsonl")
+	three := 3
+	entries := []historyEntry{
+		historyEntry{Command: "puzzle_helper cryptogram caesar", Args: []string{"cryptogram", "caesar", "URYYB"}, RanAt: time.Unix(0, 0), DurationMs: 4, Results: &three},
+		historyEntry{Command: "puzzle_helper cryptogram ngrams", Args: []string{"cryptogram", "ngrams", "-c", "corpus.txt"}, RanAt: time.Unix(60, 0), DurationMs: 1200},
+		historyEntry{Command: "puzzle_helper letterbank", Args: []string{"letterbank", "BEAST", "-d", "words.txt"}, Parameters: map[string]string{"dictionary": "words.txt"}, RanAt: time.Unix(120, 0)},
+	}
+	for _, entry := range entries {
+		if err := appendHistory(path, entry); err != nil {
+			test.Fatalf("Could not append history: %v", err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		test.Fatalf("Could not open history: %v", err)
+	}
+	defer file.Close()
+	read := readHistory(file)
+	if len(read) != 3 {
+		test.Fatalf("Expected 3 entries but got %d", len(read))
+	}
+	if read[0].Results == nil || *read[0].Results != 3 || read[1].Results != nil {
+		test.Errorf("Expected results to survive the round trip but got %v and %v", read[0].Results, read[1].Results)
+	}
+	if read[2].Parameters["dictionary"] != "words.txt" {
+		test.Errorf("Expected the dictionary parameter but got %v", read[2].Parameters)
+	}
+
+	tests := []struct {
+		search   string
+		expected []int
+	}{
+		{"", []int{1, 2, 3}},
+		{"cryptogram", []int{1, 2}},
+		{"beast", []int{3}},
+		{"transposal", []int{}},
+	}
+	for _, testCase := range tests {
+		actual := searchHistory(read, testCase.search)
+		if len(actual) != len(testCase.expected) {
+			test.Errorf("Expected %v searching for %q but got %v", testCase.expected, testCase.search, actual)
+			continue
+		}
+		for index := range actual {
+			if actual[index] != testCase.expected[index] {
+				test.Errorf("Expected %v searching for %q but got %v", testCase.expected, testCase.search, actual)
+			}
+		}
+	}
+}
+
+func TestHistoryEntryString(test *testing.T) {
+	two := 2
+	entry := historyEntry{Args: []string{"cryptogram", "caesar", "ABC"}, RanAt: time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC), DurationMs: 15, Results: &two}
+	expected := "2021-03-04 05:06:07      15ms      2 results  cryptogram caesar ABC"
+	if entry.String() != expected {
+		test.Errorf("Expected %q but got %q", expected, entry.String())
+	}
+	entry.Results = nil
+	if entry.String() != "2021-03-04 05:06:07      15ms      - results  cryptogram caesar ABC" {
+		test.Errorf("Expected - for a missing result count but got %q", entry.String())
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// isAnagramCmd represents the isanagram command
+var isAnagramCmd = &cobra.Command{
+	Use:   "isanagram PHRASE1 PHRASE2",
+	Short: "Checks whether two phrases are anagrams",
+	Long: `Says whether two phrases use exactly the same letters, ignoring case, spaces, and punctuation. If they
+	don't, it lists the letters each one has left over, and exits with 1.
+
+	Example:
+	  puzzle_helper isanagram "Dormitory" "Dirty room"
+	  puzzle_helper isanagram "Clint Eastwood" "Old west action"`,
+	Args: cobra.ExactArgs(2),
+	Run:  printIsAnagram,
+}
+
+// letterDifference is how many more of a letter the first phrase has than the second. It's negative when the
+// second phrase has more
+type letterDifference struct {
+	letter byte
+	count  int
+}
+
+func printIsAnagram(cmd *cobra.Command, args []string) {
+	differences := anagramDifferences(args[0], args[1])
+	first, second := describeLeftovers(differences)
+	printer := newResultPrinter("anagram", "first_leftovers", "second_leftovers")
+	if len(differences) == 0 {
+		printer.result(fmt.Sprintf("%s and %s are anagrams", args[0], args[1]), "true", "", "")
+		printer.finish(false)
+		return
+	}
+	printer.result(fmt.Sprintf("%s and %s are not anagrams", args[0], args[1]), "false", first, second)
+	if first != "" {
+		printer.text("%s has %s left over", args[0], first)
+	}
+	if second != "" {
+		printer.text("%s has %s left over", args[1], second)
+	}
+	printer.finish(false)
+	os.Exit(1)
+}
+
+// anagramDifferences compares the letters of two phrases and lists the ones they have different numbers of, in
+// alphabetical order. They're anagrams if there aren't any
+func anagramDifferences(first string, second string) []letterDifference {
+	counts := make(map[byte]int)
+	for _, letter := range justUppercaseLetters(first) {
+		counts[letter]++
+	}
+	for _, letter := range justUppercaseLetters(second) {
+		counts[letter]--
+	}
+
+	differences := make([]letterDifference, 0)
+	for _, letter := range []byte(upperAlphabet) {
+		if counts[letter] != 0 {
+			differences = append(differences, letterDifference{letter, counts[letter]})
+		}
+	}
+	return differences
+}
+
+// describeLeftovers spells out the letters each phrase has that the other doesn't, as in EES for two Es and an S
+func describeLeftovers(differences []letterDifference) (string, string) {
+	var first, second strings.Builder
+	for _, difference := range differences {
+		if difference.count > 0 {
+			first.WriteString(strings.Repeat(string(difference.letter), difference.count))
+		} else {
+			second.WriteString(strings.Repeat(string(difference.letter), -difference.count))
+		}
+	}
+	return first.String(), second.String()
+}
+
+// runIsAnagramSolver is isanagram for the solver registry
+func runIsAnagramSolver(ctx context.Context, input solverInput) (*resultTable, error) {
+	differences := anagramDifferences(input.getString("first"), input.getString("second"))
+	first, second := describeLeftovers(differences)
+	table := newResultTable("anagram", "first_leftovers", "second_leftovers")
+	table.addRow(strconv.FormatBool(len(differences) == 0), first, second)
+	return table, nil
+}
+
+func init() {
+	rootCmd.AddCommand(isAnagramCmd)
+
+	mustRegisterSolver(&solver{
+		name:        "isanagram",
+		description: "Whether two phrases are anagrams, ignoring case, spaces, and punctuation, and the letters each has left over if not",
+		parameters: []solverParameter{
+			solverParameter{name: "first", kind: solverString, description: "the first phrase", required: true},
+			solverParameter{name: "second", kind: solverString, description: "the second phrase", required: true},
+		},
+		run: runIsAnagramSolver,
+	})
+}
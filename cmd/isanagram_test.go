@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestAnagramDifferences(test *testing.T) {
+	tests := []struct {
+		first          string
+		second         string
+		expectedFirst  string
+		expectedSecond string
+	}{
+		{"Dormitory", "Dirty room", "", ""},
+		{"Clint Eastwood", "Old west action!", "", ""},
+		{"Listen", "Silent", "", ""},
+		{"Stressed", "Desserts!!", "", ""},
+		{"Teases", "Steady", "ES", "DY"},
+		{"apple", "", "AELPP", ""},
+	}
+	for _, currentTest := range tests {
+		differences := anagramDifferences(currentTest.first, currentTest.second)
+		first, second := describeLeftovers(differences)
+		if first != currentTest.expectedFirst || second != currentTest.expectedSecond {
+			test.Errorf("Expected %q and %q left over from %s and %s but got %q and %q", currentTest.expectedFirst, currentTest.expectedSecond,
+				currentTest.first, currentTest.second, first, second)
+		}
+		if anagram := len(differences) == 0; anagram != (first == "" && second == "") {
+			test.Errorf("Expected %s and %s to be anagrams only without leftovers", currentTest.first, currentTest.second)
+		}
+	}
+}
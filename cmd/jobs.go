@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The job manager runs long solves in the background on a fixed number of workers. Each job gets
+// its own context so it can be cancelled on its own, and can report progress as it goes. Limiting
+// the workers keeps memory in check when several big searches are asked for at once.
+
+type jobStatus string
+
+// errTooManyJobs is what submit returns when the queue is full
+var errTooManyJobs = errors.New("too many jobs are waiting; try again once some have finished")
+
+// how long a finished job can still be looked up, so that whoever submitted it has time to collect the result
+const jobRetention = time.Hour
+
+const (
+	jobQueued    jobStatus = "queued"
+	jobRunning   jobStatus = "running"
+	jobDone      jobStatus = "done"
+	jobFailed    jobStatus = "failed"
+	jobCancelled jobStatus = "cancelled"
+)
+
+// jobFunc does the work for a job. It should stop when ctx is cancelled and can call report
+// with a description of how far along it is
+type jobFunc func(ctx context.Context, report func(progress string)) (interface{}, error)
+
+type job struct {
+	id       string
+	work     jobFunc
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan bool
+	status   jobStatus
+	progress string
+	result   interface{}
+	err      error
+	finished time.Time
+}
+
+// jobSnapshot is a copy of a job's state at one point in time
+type jobSnapshot struct {
+	ID       string
+	Status   jobStatus
+	Progress string
+	Result   interface{}
+	Err      error
+}
+
+type jobManager struct {
+	lock    sync.Mutex
+	jobs    map[string]*job
+	queue   chan *job
+	nextID  int
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+}
+
+// newJobManager starts maxConcurrent workers. Up to queueSize jobs can wait for a worker before
+// submit starts turning them away
+func newJobManager(maxConcurrent, queueSize int) *jobManager {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	manager := &jobManager{jobs: make(map[string]*job), queue: make(chan *job, queueSize), ctx: ctx, cancel: cancel}
+	for worker := 0; worker < maxConcurrent; worker++ {
+		manager.workers.Add(1)
+		go manager.runWorker()
+	}
+	return manager
+}
+
+func (manager *jobManager) runWorker() {
+	defer manager.workers.Done()
+	for {
+		select {
+		case <-manager.ctx.Done():
+			return
+		case nextJob := <-manager.queue:
+			manager.runJob(nextJob)
+		}
+	}
+}
+
+func (manager *jobManager) runJob(current *job) {
+	manager.lock.Lock()
+	if current.ctx.Err() != nil {
+		// cancelled while it was still in the queue
+		manager.lock.Unlock()
+		return
+	}
+	current.status = jobRunning
+	manager.lock.Unlock()
+
+	result, err := current.work(current.ctx, func(progress string) {
+		manager.lock.Lock()
+		current.progress = progress
+		manager.lock.Unlock()
+	})
+
+	manager.lock.Lock()
+	current.result = result
+	current.err = err
+	switch {
+	case current.ctx.Err() != nil:
+		current.status = jobCancelled
+	case err != nil:
+		current.status = jobFailed
+	default:
+		current.status = jobDone
+	}
+	current.cancel()
+	current.finished = time.Now()
+	manager.lock.Unlock()
+	close(current.done)
+}
+
+// submit queues work and returns the id to look it up by, or an error if the queue is full
+func (manager *jobManager) submit(work jobFunc) (string, error) {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	if manager.ctx.Err() != nil {
+		return "", fmt.Errorf("the job manager has been shut down")
+	}
+
+	for id, old := range manager.jobs {
+		if !old.finished.IsZero() && time.Since(old.finished) > jobRetention {
+			delete(manager.jobs, id)
+		}
+	}
+
+	manager.nextID++
+	ctx, cancel := context.WithCancel(manager.ctx)
+	newJob := &job{id: strconv.Itoa(manager.nextID), work: work, ctx: ctx, cancel: cancel, done: make(chan bool), status: jobQueued}
+	select {
+	case manager.queue <- newJob:
+	default:
+		cancel()
+		return "", errTooManyJobs
+	}
+	manager.jobs[newJob.id] = newJob
+	return newJob.id, nil
+}
+
+// snapshot returns the current state of the job with the given id
+func (manager *jobManager) snapshot(id string) (jobSnapshot, bool) {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	found, exists := manager.jobs[id]
+	if !exists {
+		return jobSnapshot{}, false
+	}
+	return jobSnapshot{found.id, found.status, found.progress, found.result, found.err}, true
+}
+
+// cancelJob stops the job if it's running, or keeps it from starting if it's queued.
+// It returns false if there's no job with that id
+func (manager *jobManager) cancelJob(id string) bool {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	found, exists := manager.jobs[id]
+	if !exists {
+		return false
+	}
+	found.cancel()
+	if found.status == jobQueued {
+		found.status = jobCancelled
+		found.finished = time.Now()
+		close(found.done)
+	}
+	return true
+}
+
+// forget drops the job with the given id, so it can't be looked up anymore. Its work carries on if it's
+// still running
+func (manager *jobManager) forget(id string) {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	delete(manager.jobs, id)
+}
+
+// wait blocks until the job with the given id has finished, one way or another
+func (manager *jobManager) wait(id string) (jobSnapshot, bool) {
+	manager.lock.Lock()
+	found, exists := manager.jobs[id]
+	manager.lock.Unlock()
+	if !exists {
+		return jobSnapshot{}, false
+	}
+	<-found.done
+	return manager.snapshot(id)
+}
+
+// run submits work and waits for its result. The work stops when either ctx or the job is cancelled,
+// and a job still in the queue when ctx is done never starts. Nobody can look the job up afterward
+func (manager *jobManager) run(ctx context.Context, work jobFunc) (interface{}, error) {
+	id, err := manager.submit(func(jobCtx context.Context, report func(progress string)) (interface{}, error) {
+		workCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go func() {
+			select {
+			case <-jobCtx.Done():
+				cancel()
+			case <-workCtx.Done():
+			}
+		}()
+		return work(workCtx, report)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	finished := make(chan bool)
+	go func() {
+		select {
+		case <-ctx.Done():
+			manager.cancelJob(id)
+		case <-finished:
+		}
+	}()
+	snapshot, _ := manager.wait(id)
+	close(finished)
+	manager.forget(id)
+	if snapshot.Result == nil && snapshot.Err == nil && snapshot.Status == jobCancelled {
+		return nil, fmt.Errorf("cancelled before it started")
+	}
+	return snapshot.Result, snapshot.Err
+}
+
+// shutdown cancels every job and waits for the workers to stop
+func (manager *jobManager) shutdown() {
+	manager.lock.Lock()
+	manager.cancel()
+	for _, current := range manager.jobs {
+		if current.status == jobQueued {
+			current.status = jobCancelled
+			close(current.done)
+		}
+	}
+	manager.lock.Unlock()
+	manager.workers.Wait()
+}
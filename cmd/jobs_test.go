@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJobManagerRunsJobs(test *testing.T) {
+	manager := newJobManager(2, 10)
+	defer manager.shutdown()
+
+	var lock sync.Mutex
+	running, mostRunning := 0, 0
+	ids := make([]string, 0, 6)
+	for jobNumber := 0; jobNumber < 6; jobNumber++ {
+		jobNumber := jobNumber
+		id, err := manager.submit(func(ctx context.Context, report func(string)) (interface{}, error) {
+			lock.Lock()
+			running++
+			if running > mostRunning {
+				mostRunning = running
+			}
+			lock.Unlock()
+
+			report("halfway")
+			time.Sleep(10 * time.Millisecond)
+
+			lock.Lock()
+			running--
+			lock.Unlock()
+			if jobNumber == 5 {
+				return nil, fmt.Errorf("job %d failed", jobNumber)
+			}
+			return jobNumber * 10, nil
+		})
+		if err != nil {
+			test.Fatalf("Unexpected error submitting job: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	for jobNumber, id := range ids {
+		snapshot, found := manager.wait(id)
+		if !found {
+			test.Fatalf("Expected job %s to exist", id)
+		}
+		if jobNumber == 5 {
+			if snapshot.Status != jobFailed || snapshot.Err == nil {
+				test.Errorf("Expected job %s to fail but got %v", id, snapshot)
+			}
+			continue
+		}
+		if snapshot.Status != jobDone || snapshot.Result != jobNumber*10 || snapshot.Progress != "halfway" {
+			test.Errorf("Expected job %s to be done with %d but got %v", id, jobNumber*10, snapshot)
+		}
+	}
+	if mostRunning > 2 {
+		test.Errorf("Expected at most 2 jobs at once but saw %d", mostRunning)
+	}
+}
+
+func TestJobManagerCancel(test *testing.T) {
+	manager := newJobManager(1, 1)
+	defer manager.shutdown()
+
+	started := make(chan bool)
+	blocking := func(ctx context.Context, report func(string)) (interface{}, error) {
+		started <- true
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	runningID, _ := manager.submit(blocking)
+	<-started
+	queuedID, err := manager.submit(blocking)
+	if err != nil {
+		test.Fatalf("Expected room in the queue but got %v", err)
+	}
+	if _, err := manager.submit(blocking); err == nil {
+		test.Errorf("Expected an error once the queue was full")
+	}
+
+	manager.cancelJob(queuedID)
+	if snapshot, _ := manager.wait(queuedID); snapshot.Status != jobCancelled {
+		test.Errorf("Expected the queued job to be cancelled but got %v", snapshot.Status)
+	}
+
+	manager.cancelJob(runningID)
+	if snapshot, _ := manager.wait(runningID); snapshot.Status != jobCancelled {
+		test.Errorf("Expected the running job to be cancelled but got %v", snapshot.Status)
+	}
+
+	if manager.cancelJob("missing") {
+		test.Errorf("Expected cancelling a missing job to return false")
+	}
+}
+
+func TestJobManagerRun(test *testing.T) {
+	manager := newJobManager(1, 1)
+	defer manager.shutdown()
+
+	result, err := manager.run(context.Background(), func(ctx context.Context, report func(string)) (interface{}, error) {
+		return "solved", nil
+	})
+	if err != nil || result != "solved" {
+		test.Errorf("Expected solved but got %v (%v)", result, err)
+	}
+	if len(manager.jobs) != 0 {
+		test.Errorf("Expected finished jobs to be forgotten but %d are left", len(manager.jobs))
+	}
+
+	// a job that only stops when the caller gives up
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	result, err = manager.run(ctx, func(ctx context.Context, report func(string)) (interface{}, error) {
+		<-ctx.Done()
+		return "partial", nil
+	})
+	if err != nil || result != "partial" {
+		test.Errorf("Expected the partial result once the caller's context was done but got %v (%v)", result, err)
+	}
+
+	started := make(chan bool)
+	release := make(chan bool)
+	go manager.run(context.Background(), func(ctx context.Context, report func(string)) (interface{}, error) {
+		started <- true
+		<-release
+		return nil, nil
+	})
+	<-started
+	queuedCtx, cancelQueued := context.WithCancel(context.Background())
+	ran := false
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancelQueued()
+	}()
+	if _, err := manager.run(queuedCtx, func(ctx context.Context, report func(string)) (interface{}, error) {
+		ran = true
+		return nil, nil
+	}); err == nil {
+		test.Errorf("Expected an error for a job cancelled in the queue")
+	}
+	close(release)
+	if ran {
+		test.Errorf("Expected the cancelled job never to start")
+	}
+}
+
+func TestJobManagerForget(test *testing.T) {
+	manager := newJobManager(1, 10)
+	defer manager.shutdown()
+
+	quick := func(ctx context.Context, report func(string)) (interface{}, error) {
+		return "done", nil
+	}
+	forgotten, _ := manager.submit(quick)
+	manager.wait(forgotten)
+	manager.forget(forgotten)
+	if _, found := manager.snapshot(forgotten); found {
+		test.Errorf("Expected job %s to be forgotten", forgotten)
+	}
+
+	// finished jobs are only kept for so long, and are cleared out when the next one comes in
+	old, _ := manager.submit(quick)
+	manager.wait(old)
+	manager.lock.Lock()
+	manager.jobs[old].finished = time.Now().Add(-2 * jobRetention)
+	manager.lock.Unlock()
+	recent, _ := manager.submit(quick)
+	manager.wait(recent)
+	if _, found := manager.snapshot(old); found {
+		test.Errorf("Expected job %s to be dropped once it was older than the retention", old)
+	}
+	if _, found := manager.snapshot(recent); !found {
+		test.Errorf("Expected job %s to still be there", recent)
+	}
+}
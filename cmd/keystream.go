@@ -0,0 +1,128 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var candidatePlainText string
+var keyWordMinLength int
+
+// keystreamCmd represents the keystream command
+var keystreamCmd = &cobra.Command{
+	Use:   "keystream",
+	Short: "Derives the additive key stream between ciphertext and a candidate plaintext",
+	Long: `For running-key, one-time pad, and Vigenère ciphers, ciphertext = plaintext + key (mod 26).
+	Given the ciphertext in the arguments and a guessed plaintext with --plaintext, this prints the
+	implied key. If a dictionary is passed in with --dictionary, it also lists any words that appear
+	in the key, which is a strong sign the guess is right.
+
+	Only letters are used; if the texts are different lengths, the key is only derived for the shorter one.
+	`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  printKeyStream,
+}
+
+func printKeyStream(cmd *cobra.Command, args []string) {
+	keyStream := deriveKeyStream(strings.Join(args, ""), candidatePlainText)
+	fmt.Println(keyStream)
+
+	if len(dictionaryFiles) == 0 {
+		return
+	}
+
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+
+	words := findWordsInString(rootTrie, keyStream, keyWordMinLength)
+	if len(words) == 0 {
+		fmt.Println("No dictionary words found in the key")
+		return
+	}
+	for _, word := range words {
+		fmt.Printf("%d: %s\n", word.offset, word.word)
+	}
+}
+
+// deriveKeyStream subtracts each letter of plainText from the corresponding letter of cipherText.
+// Non-letters are ignored in both, and the result is as long as the shorter of the two.
+func deriveKeyStream(cipherText, plainText string) string {
+	cipherLetters := justUppercaseLetters(cipherText)
+	plainLetters := justUppercaseLetters(plainText)
+
+	length := len(cipherLetters)
+	if len(plainLetters) < length {
+		length = len(plainLetters)
+	}
+
+	key := make([]byte, 0, length)
+	for index := 0; index < length; index++ {
+		key = append(key, upperRing.symbolAt(upperRing.position(cipherLetters[index])-upperRing.position(plainLetters[index])))
+	}
+	return string(key)
+}
+
+// justUppercaseLetters strips everything but letters out of input and uppercases what's left
+func justUppercaseLetters(input string) []byte {
+	letters := make([]byte, 0, len(input))
+	for _, curByte := range []byte(input) {
+		curByte = upperCaseByte(curByte)
+		if isUppercaseAscii(curByte) {
+			letters = append(letters, curByte)
+		}
+	}
+	return letters
+}
+
+// foundWord is a dictionary word found inside a longer string, along with where it starts
+type foundWord struct {
+	offset int
+	word   string
+}
+
+// findWordsInString walks the trie from every position in text and returns each dictionary
+// word at least minLength long that appears as a run of consecutive letters
+func findWordsInString(trie *trieNode, text string, minLength int) []foundWord {
+	words := make([]foundWord, 0)
+	textBytes := []byte(text)
+	for start := range textBytes {
+		currentNode := trie
+		for end := start; end < len(textBytes); end++ {
+			if !isUppercaseAscii(textBytes[end]) {
+				break
+			}
+			currentNode = currentNode.Child(textBytes[end])
+			if currentNode == nil {
+				break
+			}
+			if currentNode.IsWord() && end-start+1 >= minLength {
+				words = append(words, foundWord{start, string(textBytes[start : end+1])})
+			}
+		}
+	}
+	return words
+}
+
+func init() {
+	keystreamCmd.Flags().StringVarP(&candidatePlainText, "plaintext", "p", "", "the guessed plaintext")
+	keystreamCmd.MarkFlagRequired("plaintext")
+	keystreamCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use for finding words in the key, or - to use stdin. Repeat it or separate files with commas to merge several")
+	keystreamCmd.Flags().IntVarP(&keyWordMinLength, "min-word-length", "m", 3, "the shortest word to report from the key")
+	cryptogramCmd.AddCommand(keystreamCmd)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestDeriveKeyStream(test *testing.T) {
+	// ATTACKATDAWN enciphered with the key LEMON
+	tests := map[[2]string]string{
+		{"LXFOPVEFRNHR", "ATTACKATDAWN"}: "LEMONLEMONLE",
+		{"LXF OPV", "at tac k"}:          "LEMONL",
+		{"LXFOPV", "ATT"}:                "LEM",
+	}
+
+	for texts, expected := range tests {
+		actual := deriveKeyStream(texts[0], texts[1])
+		if actual != expected {
+			test.Errorf("Expected key %s from %s and %s but got %s", expected, texts[0], texts[1], actual)
+		}
+	}
+}
+
+func TestFindWordsInString(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"LEMON", "MON", "ON", "LEMONADE"} {
+		trie.Add(word, nil)
+	}
+
+	words := findWordsInString(trie, "XLEMONLE", 3)
+	expected := []foundWord{{1, "LEMON"}, {3, "MON"}}
+	if len(words) != len(expected) {
+		test.Fatalf("Expected %v but got %v", expected, words)
+	}
+	for index, word := range expected {
+		if words[index] != word {
+			test.Errorf("Expected %v at %d but got %v", word, index, words[index])
+		}
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var ladderMaxSteps int
+var ladderAddRemove bool
+
+// ladderCmd represents the ladder command
+var ladderCmd = &cobra.Command{
+	Use:   "ladder FROM TO",
+	Short: "Finds the shortest word ladder from one word to another",
+	Long: `Finds a shortest chain of dictionary words from FROM to TO where each word changes one letter
+	of the word before it, as in COLD, CORD, CARD, WARD, WARM. --max-steps gives up on ladders longer than
+	that many steps, and --add-remove also allows steps that add or take away a letter, so FROM and TO can be
+	different lengths. FROM doesn't have to be in the dictionary, but TO does.
+
+	Example:
+	  puzzle_helper ladder COLD WARM --dictionary words.txt`,
+	Args: cobra.ExactArgs(2),
+	Run:  printWordLadder,
+}
+
+func printWordLadder(cmd *cobra.Command, args []string) {
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+
+	ctx, cancel := solveContext()
+	defer cancel()
+
+	ladder, err := findWordLadder(ctx, rootTrie, strings.ToUpper(args[0]), strings.ToUpper(args[1]), ladderMaxSteps, ladderAddRemove)
+	if err != nil {
+		fmt.Printf("Could not find a ladder: %v\n", err)
+		os.Exit(1)
+	}
+	if ladder == nil && ctx.Err() == nil {
+		fmt.Printf("There's no ladder from %s to %s\n", strings.ToUpper(args[0]), strings.ToUpper(args[1]))
+	}
+	printer := newResultPrinter("step", "word")
+	for step, word := range ladder {
+		printer.result(word, strconv.Itoa(step), word)
+	}
+	printer.finish(ctx.Err() != nil)
+}
+
+// findWordLadder searches outward from from, one step at a time, until it reaches to, and returns the words
+// along the way, from and to included. Steps change one letter, or add or remove one if addRemove is set. It
+// returns nil if to can't be reached within maxSteps steps (or at all, if maxSteps isn't above 0) or ctx is
+// cancelled first
+func findWordLadder(ctx context.Context, rootTrie *trieNode, from string, to string, maxSteps int, addRemove bool) ([]string, error) {
+	if len(from) != len(to) && !addRemove {
+		return nil, fmt.Errorf("%s and %s are different lengths; use --add-remove to allow that", from, to)
+	}
+	if _, found := rootTrie.Get(to); !found {
+		return nil, fmt.Errorf("%s isn't in the dictionary", to)
+	}
+
+	previous := map[string]string{from: ""}
+	frontier := []string{from}
+	for steps := 0; len(frontier) > 0 && (maxSteps < 1 || steps <= maxSteps); steps++ {
+		if _, reached := previous[to]; reached {
+			ladder := []string{to}
+			for word := previous[to]; word != ""; word = previous[word] {
+				ladder = append([]string{word}, ladder...)
+			}
+			return ladder, nil
+		}
+		if ctx.Err() != nil {
+			return nil, nil
+		}
+
+		next := make([]string, 0)
+		for _, word := range frontier {
+			for _, neighbor := range ladderNeighbors(rootTrie, word, addRemove) {
+				if _, seen := previous[neighbor]; !seen {
+					previous[neighbor] = word
+					next = append(next, neighbor)
+				}
+			}
+		}
+		frontier = next
+	}
+	return nil, nil
+}
+
+// ladderNeighbors is the dictionary words one step from word: the words with one letter changed, and if addRemove
+// is set, those with one added or taken away. They're ordered by where the change is, then alphabetically
+func ladderNeighbors(rootTrie *trieNode, word string, addRemove bool) []string {
+	neighbors := make([]string, 0)
+	prefixNode := rootTrie
+	for index := 0; index <= len(word) && prefixNode != nil; index++ {
+		for _, child := range prefixNode.Children() {
+			if index < len(word) && child.Letter()[0] != word[index] {
+				if _, found := child.Get(word[index+1:]); found {
+					neighbors = append(neighbors, word[:index]+child.Letter()+word[index+1:])
+				}
+			}
+			if addRemove {
+				if _, found := child.Get(word[index:]); found {
+					neighbors = append(neighbors, word[:index]+child.Letter()+word[index:])
+				}
+			}
+		}
+		if addRemove && index < len(word) {
+			if _, found := rootTrie.Get(word[:index] + word[index+1:]); found && len(word) > 1 {
+				neighbors = append(neighbors, word[:index]+word[index+1:])
+			}
+		}
+		if index < len(word) {
+			prefixNode = prefixNode.Child(word[index])
+		}
+	}
+	return neighbors
+}
+
+func init() {
+	ladderCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+	ladderCmd.MarkFlagRequired("dictionary")
+	ladderCmd.Flags().IntVarP(&ladderMaxSteps, "max-steps", "", 0, "The most steps a ladder can take. 0 means no limit")
+	ladderCmd.Flags().BoolVarP(&ladderAddRemove, "add-remove", "", false, "Allow steps that add or remove a letter as well as change one")
+	rootCmd.AddCommand(ladderCmd)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFindWordLadder(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"COLD", "CORD", "CARD", "WARD", "WARM", "WORD", "HAT", "HEAT", "HEAL", "HEALS", "CAT", "CART"} {
+		trie.Add(word, nil)
+	}
+
+	testCases := []struct {
+		from      string
+		to        string
+		maxSteps  int
+		addRemove bool
+		expected  string
+	}{
+		// changes to earlier letters are tried first, so WORD comes before CARD
+		{"COLD", "WARM", 0, false, "COLD CORD WORD WARD WARM"},
+		{"COLD", "WARM", 4, false, "COLD CORD WORD WARD WARM"},
+		{"COLD", "WARM", 3, false, ""},
+		{"COLD", "COLD", 0, false, "COLD"},
+		// FROM doesn't have to be a word
+		{"BOLD", "CORD", 0, false, "BOLD COLD CORD"},
+		{"HAT", "HEALS", 0, true, "HAT HEAT HEAL HEALS"},
+		{"CART", "HAT", 0, true, "CART CAT HAT"},
+		{"HAT", "WARM", 0, true, "HAT CAT CART CARD WARD WARM"},
+		{"HAT", "WARM", 4, true, ""},
+	}
+
+	for _, testCase := range testCases {
+		ladder, err := findWordLadder(context.Background(), trie, testCase.from, testCase.to, testCase.maxSteps, testCase.addRemove)
+		if err != nil {
+			test.Errorf("Unexpected error for %s to %s: %v", testCase.from, testCase.to, err)
+			continue
+		}
+		if strings.Join(ladder, " ") != testCase.expected {
+			test.Errorf("Expected %q from %s to %s but got %v", testCase.expected, testCase.from, testCase.to, ladder)
+		}
+	}
+
+	if _, err := findWordLadder(context.Background(), trie, "HAT", "HEAL", 0, false); err == nil {
+		test.Errorf("Expected an error for words of different lengths without add-remove")
+	}
+	if _, err := findWordLadder(context.Background(), trie, "COLD", "WARP", 0, false); err == nil {
+		test.Errorf("Expected an error for a target that isn't in the dictionary")
+	}
+}
+
+func TestLadderNeighbors(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"CAT", "COT", "CUT", "CAST", "CATS", "AT", "SCAT", "DOG"} {
+		trie.Add(word, nil)
+	}
+	if actual := strings.Join(ladderNeighbors(trie, "CAT", false), ","); actual != "COT,CUT" {
+		test.Errorf("Expected COT,CUT but got %s", actual)
+	}
+	if actual := strings.Join(ladderNeighbors(trie, "CAT", true), ","); actual != "SCAT,AT,COT,CUT,CAST,CATS" {
+		test.Errorf("Expected SCAT,AT,COT,CUT,CAST,CATS but got %s", actual)
+	}
+}
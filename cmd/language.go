@@ -0,0 +1,198 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+var languageSubstitution bool
+var languageTop int
+
+// languageCmd represents the language command
+var languageCmd = &cobra.Command{
+	Use:   "language TEXT...",
+	Short: "Guesses the language of a text by comparing its letter frequencies to other languages'",
+	Long: `Scores the text against the letter frequencies of several languages with a chi-squared test.
+	Lower scores are closer. This works for plaintext and transpositions, which keep the letters;
+	for a substitution cipher, --substitution compares the shapes of the distributions (most common
+	letter against most common letter) instead, since the letters themselves have been swapped.
+	Knowing the language first says which dictionary and ngram table to solve with.
+
+	The languages are English, French, German, Spanish, Portuguese, Italian, Dutch, Swedish,
+	Danish, Icelandic, Finnish, Polish, Czech, and Turkish. --top shows only the closest few.
+
+	Examples:
+	  puzzle_helper cryptogram language "EL PERRO DE SAN ROQUE NO TIENE RABO"
+	  puzzle_helper cryptogram language --top 3 "Hyvää huomenta, mitä kuuluu?"`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  printLanguageScores,
+}
+
+// languageLetterFrequencies are the percentages of the letters A-Z in each language, with accented
+// letters counted as their unaccented versions
+var languageLetterFrequencies = map[string][26]float64{
+	"English":    {8.167, 1.492, 2.782, 4.253, 12.702, 2.228, 2.015, 6.094, 6.966, 0.153, 0.772, 4.025, 2.406, 6.749, 7.507, 1.929, 0.095, 5.987, 6.327, 9.056, 2.758, 0.978, 2.360, 0.150, 1.974, 0.074},
+	"French":     {8.173, 0.901, 3.345, 3.669, 16.734, 1.066, 0.866, 0.737, 7.579, 0.613, 0.074, 5.456, 2.968, 7.095, 5.837, 2.521, 1.362, 6.693, 7.948, 7.244, 6.429, 1.838, 0.049, 0.427, 0.128, 0.326},
+	"German":     {7.094, 1.886, 2.732, 5.076, 16.396, 1.656, 3.009, 4.577, 6.550, 0.268, 1.417, 3.437, 2.534, 9.776, 3.037, 0.670, 0.018, 7.003, 7.884, 6.154, 5.161, 0.846, 1.921, 0.034, 0.039, 1.134},
+	"Spanish":    {12.027, 2.215, 4.019, 5.010, 12.614, 0.692, 1.768, 0.703, 6.972, 0.493, 0.011, 4.967, 3.157, 7.023, 9.510, 2.510, 0.877, 6.871, 7.977, 4.632, 3.107, 1.138, 0.017, 0.215, 1.008, 0.467},
+	"Portuguese": {16.119, 1.043, 4.412, 4.992, 13.357, 1.023, 1.303, 0.781, 6.318, 0.397, 0.015, 2.779, 4.738, 4.446, 10.706, 2.523, 1.204, 6.530, 6.805, 4.336, 3.872, 1.575, 0.037, 0.253, 0.006, 0.470},
+	"Italian":    {12.380, 0.927, 4.501, 3.736, 12.055, 1.153, 1.644, 0.636, 10.173, 0.011, 0.009, 6.510, 2.512, 6.883, 9.834, 3.056, 0.505, 6.367, 4.981, 5.623, 3.177, 2.097, 0.033, 0.003, 0.020, 1.181},
+	"Dutch":      {7.486, 1.584, 1.242, 5.933, 18.910, 0.805, 3.403, 2.380, 6.499, 1.461, 2.248, 3.568, 2.213, 10.032, 6.063, 1.570, 0.009, 6.411, 3.730, 6.790, 1.990, 2.850, 1.520, 0.036, 0.035, 1.390},
+	"Swedish":    {12.518, 1.535, 1.486, 4.702, 10.149, 2.027, 2.862, 2.090, 5.817, 0.614, 3.140, 5.275, 3.471, 8.542, 5.787, 1.839, 0.020, 8.431, 6.590, 7.691, 1.919, 2.415, 0.142, 0.159, 0.708, 0.070},
+	"Danish":     {8.087, 2.000, 0.565, 5.858, 16.325, 2.406, 4.077, 1.621, 6.000, 0.730, 3.395, 5.229, 3.237, 7.240, 5.575, 1.756, 0.007, 8.956, 5.805, 6.862, 1.979, 2.332, 0.069, 0.028, 0.698, 0.034},
+	"Icelandic":  {12.776, 1.043, 0.010, 5.968, 7.932, 3.013, 4.241, 3.326, 9.148, 1.144, 3.314, 4.532, 4.041, 7.711, 3.937, 0.789, 0.010, 8.581, 5.630, 6.408, 5.175, 2.437, 0.010, 0.046, 1.128, 0.010},
+	"Finnish":    {15.794, 0.281, 0.281, 1.043, 7.968, 0.194, 0.392, 1.851, 10.817, 2.042, 4.973, 5.761, 3.202, 8.826, 6.058, 1.842, 0.013, 2.872, 7.862, 8.750, 5.008, 2.250, 0.094, 0.031, 1.745, 0.051},
+	"Polish":     {11.202, 1.740, 4.638, 3.725, 8.387, 0.143, 1.731, 1.015, 8.328, 1.836, 2.753, 4.673, 2.515, 6.599, 7.808, 2.445, 0.010, 5.243, 6.038, 2.475, 2.062, 0.012, 5.813, 0.004, 3.206, 5.636},
+	"Czech":      {9.288, 0.822, 1.202, 3.490, 9.417, 0.084, 0.092, 1.356, 7.716, 1.433, 2.894, 3.802, 2.446, 6.475, 6.719, 1.906, 0.001, 5.179, 5.900, 5.733, 2.409, 5.344, 0.016, 0.027, 2.038, 2.224},
+	"Turkish":    {11.920, 2.844, 2.119, 4.706, 8.912, 0.461, 2.378, 1.212, 13.714, 0.034, 4.683, 5.922, 3.752, 7.487, 3.253, 0.886, 0.010, 6.722, 4.794, 3.314, 5.089, 0.959, 0.010, 0.010, 3.336, 1.500},
+}
+
+// languageScore is how far a text's letters are from a language's, as a chi-squared statistic
+type languageScore struct {
+	language   string
+	chiSquared float64
+}
+
+// letterCountsAZ counts each of the letters A-Z in text, ignoring case and anything else. Accented
+// letters count as the letters under them unless --strip-accents is off
+func letterCountsAZ(text string) ([26]float64, float64) {
+	var counts [26]float64
+	total := 0.0
+	for _, curRune := range strings.ToUpper(text) {
+		spelling := string(curRune)
+		if curRune >= utf8.RuneSelf && stripAccents {
+			spelling = strings.ToUpper(withoutAccents(curRune))
+		}
+		for _, curByte := range []byte(spelling) {
+			if isUppercaseAscii(curByte) {
+				counts[curByte-ASCII_A]++
+				total++
+			}
+		}
+	}
+	return counts, total
+}
+
+// chiSquared compares observed counts against the counts expected from percentages
+func chiSquared(observed [26]float64, total float64, percentages [26]float64) float64 {
+	statistic := 0.0
+	for index := range observed {
+		expected := total * percentages[index] / 100.0
+		if expected == 0 {
+			continue
+		}
+		difference := observed[index] - expected
+		statistic += difference * difference / expected
+	}
+	return statistic
+}
+
+// sortedDescending returns values from largest to smallest, losing which letter each belonged to
+func sortedDescending(values [26]float64) [26]float64 {
+	sorted := values
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted[:])))
+	return sorted
+}
+
+// scoreLanguages scores text against every language, closest first. With substitution set, the
+// frequencies are compared by rank rather than by letter
+func scoreLanguages(text string, substitution bool) ([]languageScore, error) {
+	counts, total := letterCountsAZ(text)
+	if total == 0 {
+		return nil, fmt.Errorf("there are no letters to count")
+	}
+	if substitution {
+		counts = sortedDescending(counts)
+	}
+
+	scores := make([]languageScore, 0, len(languageLetterFrequencies))
+	for language, percentages := range languageLetterFrequencies {
+		if substitution {
+			percentages = sortedDescending(percentages)
+		}
+		scores = append(scores, languageScore{language, chiSquared(counts, total, percentages)})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].chiSquared == scores[j].chiSquared {
+			return scores[i].language < scores[j].language
+		}
+		return scores[i].chiSquared < scores[j].chiSquared
+	})
+	return scores, nil
+}
+
+// closestLanguages keeps the top closest scores, or all of them if top isn't positive
+func closestLanguages(scores []languageScore, top int) []languageScore {
+	if top > 0 && top < len(scores) {
+		return scores[:top]
+	}
+	return scores
+}
+
+func printLanguageScores(cmd *cobra.Command, args []string) {
+	scores, err := scoreLanguages(strings.Join(args, " "), languageSubstitution)
+	if err != nil {
+		fmt.Printf("Could not score the text: %v\n", err)
+		os.Exit(1)
+	}
+	printer := newResultPrinter("language", "chi_squared")
+	for index, score := range closestLanguages(scores, languageTop) {
+		marker := ""
+		if index == 0 {
+			marker = " <- most likely"
+		}
+		printer.result(fmt.Sprintf("%-10s %10.2f%s", score.language, score.chiSquared, marker), score.language, fmt.Sprintf("%.2f", score.chiSquared))
+	}
+	printer.finish(false)
+}
+
+// runLanguageSolver is the language command for the solver registry
+func runLanguageSolver(ctx context.Context, input solverInput) (*resultTable, error) {
+	scores, err := scoreLanguages(input.getString("text"), input.getBool("substitution"))
+	if err != nil {
+		return nil, err
+	}
+	table := newResultTable("language", "chi_squared")
+	for _, score := range closestLanguages(scores, input.getInt("top")) {
+		table.addRow(score.language, fmt.Sprintf("%.2f", score.chiSquared))
+	}
+	return table, nil
+}
+
+func init() {
+	languageCmd.Flags().BoolVarP(&languageSubstitution, "substitution", "s", false, "compare the shape of the distribution rather than the letters, for substitution ciphers")
+	languageCmd.Flags().IntVarP(&languageTop, "top", "n", 0, "show only this many of the closest languages. 0 shows them all")
+	cryptogramCmd.AddCommand(languageCmd)
+
+	mustRegisterSolver(&solver{
+		name:        "language",
+		description: "Chi-squared distances from the text's letter frequencies to fourteen languages, closest first",
+		parameters: []solverParameter{
+			solverParameter{name: "text", kind: solverString, description: "the text to score", required: true},
+			solverParameter{name: "substitution", kind: solverBool, description: "compare the shape of the distribution, for substitution ciphers"},
+			solverParameter{name: "top", kind: solverInt, description: "how many of the closest languages to return; 0 returns them all"},
+		},
+		run: runLanguageSolver,
+	})
+}
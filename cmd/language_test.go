@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestScoreLanguages(test *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"It was the best of times, it was the worst of times, it was the age of wisdom, it was the age of foolishness, it was the epoch of belief, it was the epoch of incredulity", "English"},
+		{"Der schnelle braune Fuchs springt über den faulen Hund und die Katze schläft in der warmen Sonne neben dem Haus", "German"},
+		{"Hyvää huomenta, mitä kuuluu? Minulle kuuluu oikein hyvää, kiitos kysymästä. Tänään on kaunis päivä ja menemme kävelylle järven rantaan", "Finnish"},
+		{"Dzień dobry, jak się masz? Wszystko w porządku, dziękuję bardzo za pytanie. Dzisiaj jest piękna pogoda i idziemy na spacer", "Polish"},
+	}
+	for _, testCase := range tests {
+		scores, err := scoreLanguages(testCase.text, false)
+		if err != nil {
+			test.Fatalf("Unexpected error scoring %q: %v", testCase.text, err)
+		}
+		if scores[0].language != testCase.expected {
+			test.Errorf("Expected %s to score closest but got %v", testCase.expected, scores)
+		}
+		for index := 1; index < len(scores); index++ {
+			if scores[index].chiSquared < scores[index-1].chiSquared {
+				test.Errorf("Expected scores from closest to farthest but got %v", scores)
+			}
+		}
+	}
+
+	// rot13 keeps the shape of English's distribution, just not the letters
+	rotated, _ := rotate(tests[0].text, 13)
+	if englishChiSquared(rotated, true) >= englishChiSquared(rotated, false)/10 {
+		test.Errorf("Expected the rotated text to be far closer to English by shape than letter for letter")
+	}
+
+	if len(closestLanguages(englishScores(), 3)) != 3 || len(closestLanguages(englishScores(), 0)) != len(languageLetterFrequencies) {
+		test.Errorf("Expected --top to keep the closest 3, and 0 to keep all %d", len(languageLetterFrequencies))
+	}
+
+	if _, err := scoreLanguages("123 !!", false); err == nil {
+		test.Errorf("Expected an error for a text without letters")
+	}
+}
+
+func englishChiSquared(text string, substitution bool) float64 {
+	scores, _ := scoreLanguages(text, substitution)
+	for _, score := range scores {
+		if score.language == "English" {
+			return score.chiSquared
+		}
+	}
+	return 0
+}
+
+func englishScores() []languageScore {
+	scores, _ := scoreLanguages("the quick brown fox", false)
+	return scores
+}
+
+func TestLetterCountsAZ(test *testing.T) {
+	defer func(original bool) { stripAccents = original }(stripAccents)
+	stripAccents = true
+	if counts, total := letterCountsAZ("Çà ß!"); total != 4 || counts[2] != 1 || counts[0] != 1 || counts[18] != 2 {
+		test.Errorf("Expected C, A, and two S from accented letters but got %v (%v)", counts, total)
+	}
+	stripAccents = false
+	if _, total := letterCountsAZ("Çà ß!"); total != 0 {
+		test.Errorf("Expected accented letters to be skipped but counted %v", total)
+	}
+}
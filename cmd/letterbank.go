@@ -0,0 +1,299 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var letterBankMaxWords int
+var letterBankMaxLetterUses int
+var letterBankMaxTotalLetters int
+var letterBankPermutations bool
+var letterBankMaxResults int
+var letterBankSort string
+
+// letterBankCmd represents the letterbank command
+var letterBankCmd = &cobra.Command{
+	Use:   "letterbank",
+	Short: "Finds words and phrases that use every letter in the bank, repeating letters as needed",
+	Long: `
+	  In a letter bank, the answer uses every letter of the bank at least once and no other letters,
+		but letters can be reused. BEAST is a bank for BASSET, for instance.
+
+		Because letters can repeat, the search can grow very quickly. --max-letter-uses caps how many times
+		a letter can appear across a whole solution, --max-total-letters caps how many letters it has in all,
+		--max-words caps the number of words, and the search stops once --max-results solutions have been found. Results are sorted by --sort: length (shortest first)
+		or common (requires a dictionary with word<TAB>frequency lines, or one sorted from most to least common word).
+		--exclude leaves out solutions with a word you've already ruled out. Each set of words is listed once;
+		--permutations lists every ordering of them.
+  `,
+	Args: cobra.MinimumNArgs(1),
+	Run:  findLetterBanks,
+}
+
+// letterBankRequest holds the input and limits for a letter bank search. Excluded words are never used,
+// and unless permutations is set, each set of words is only given once, in the order it's found in the dictionary
+type letterBankRequest struct {
+	letters       string
+	maxWords      int
+	maxLetterUses int
+	maxLetters    int
+	maxResults    int
+	sortBy        string
+	excluded      map[string]bool
+	permutations  bool
+}
+
+// letterBankSolution is a set of words that uses up the bank. score is the sum of
+// the words' dictionary ranks, so lower scores are made of more common words. frequency is
+// their combined frequency, for dictionaries that have them
+type letterBankSolution struct {
+	words     []string
+	score     int
+	frequency float64
+}
+
+func (solution letterBankSolution) letterCount() int {
+	return len(strings.Join(solution.words, ""))
+}
+
+// explainLetterBankSolution gives each word's rank in the dictionary, which add up to the score
+func explainLetterBankSolution(rootTrie *trieNode, solution letterBankSolution) []string {
+	reasons := make([]string, 0, len(solution.words))
+	for _, word := range solution.words {
+		value, _ := rootTrie.Get(word)
+		reasons = append(reasons, fmt.Sprintf("%s is dictionary entry %d", word, dictionaryRank(value)))
+	}
+	return reasons
+}
+
+func findLetterBanks(cmd *cobra.Command, args []string) {
+
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+
+	ctx, cancel := solveContext()
+	defer cancel()
+
+	request := letterBankRequest{strings.Join(args, ""), letterBankMaxWords, letterBankMaxLetterUses, letterBankMaxTotalLetters, letterBankMaxResults, letterBankSort, excludedWordSet(excludedAnswerWords), letterBankPermutations}
+	solutions, err := performLetterBankSolve(ctx, rootTrie, request)
+	if err != nil {
+		fmt.Printf("Could not solve letter bank: %v\n", err)
+		os.Exit(1)
+	}
+	printer := newResultPrinter("words", "score")
+	for _, solution := range solutions {
+		words := strings.Join(solution.words, " ")
+		printer.explained(words, explainLetterBankSolution(rootTrie, solution), words, strconv.Itoa(solution.score))
+	}
+	printer.finish(ctx.Err() != nil)
+}
+
+// performLetterBankSolve finds up to request.maxResults solutions for the bank, sorted as requested.
+// If ctx is cancelled, whatever has been found so far is returned.
+func performLetterBankSolve(ctx context.Context, rootTrie *trieNode, request letterBankRequest) ([]letterBankSolution, error) {
+	bank := make(map[byte]bool)
+	for _, letter := range justUppercaseLetters(request.letters) {
+		bank[letter] = true
+	}
+	if len(bank) == 0 {
+		return nil, fmt.Errorf("the bank has no letters in it")
+	}
+	if request.sortBy != "length" && request.sortBy != "common" {
+		return nil, fmt.Errorf("unknown sort %s; use length or common", request.sortBy)
+	}
+
+	maxLetterUses := request.maxLetterUses
+	if maxLetterUses < 1 {
+		maxLetterUses = math.MaxInt32
+	}
+	if request.maxLetters < 1 {
+		request.maxLetters = math.MaxInt32
+	}
+	candidates := make([]letterBankSolution, 0)
+	collectBankWords(rootTrie, rootTrie, bank, make(map[byte]int), maxLetterUses, request.maxLetters, "", &candidates)
+	allowed := candidates[:0]
+	for _, candidate := range candidates {
+		if !hasExcludedWord(candidate.words, request.excluded) {
+			allowed = append(allowed, candidate)
+		}
+	}
+	candidates = allowed
+
+	// search one word solutions first, then two and so on, so that hitting maxResults cuts off the longest phrases
+	solutions := make([]letterBankSolution, 0)
+	for wordCount := 1; wordCount <= request.maxWords; wordCount++ {
+		combineBankWords(ctx, candidates, bank, request, wordCount, maxLetterUses, make(map[byte]int), letterBankSolution{}, 0, &solutions)
+	}
+	sortLetterBankSolutions(solutions, request.sortBy, hasWordFrequencies(rootTrie))
+	return solutions, nil
+}
+
+// collectBankWords walks the trie, only following letters in the bank, and adds every word it finds
+// to words as a one-word solution scored by its rank, which is looked up on rootTrie. Words longer than maxLetters aren't followed
+func collectBankWords(rootTrie *trieNode, node *trieNode, bank map[byte]bool, letterUses map[byte]int, maxLetterUses int, maxLetters int, currentWord string, words *[]letterBankSolution) {
+	if node.IsWord() && currentWord != "" {
+		value, _ := rootTrie.Get(currentWord)
+		*words = append(*words, letterBankSolution{[]string{currentWord}, dictionaryRank(value), logFrequency(value)})
+	}
+
+	for _, child := range node.Children() {
+		letter := child.Letter()[0]
+		if !bank[letter] || letterUses[letter] >= maxLetterUses || len(currentWord) >= maxLetters {
+			continue
+		}
+		letterUses[letter]++
+		collectBankWords(rootTrie, child, bank, letterUses, maxLetterUses, maxLetters, currentWord+string(letter), words)
+		letterUses[letter]--
+	}
+}
+
+// combineBankWords builds up wordCount-word solutions out of the candidate words, recording any that cover the whole bank.
+// Unless the request allows permutations, words are only taken from first onward, so each set of words is built once
+func combineBankWords(ctx context.Context, candidates []letterBankSolution, bank map[byte]bool, request letterBankRequest,
+	wordCount int, maxLetterUses int, letterUses map[byte]int, current letterBankSolution, first int, solutions *[]letterBankSolution) {
+
+	if ctx.Err() != nil || len(*solutions) >= request.maxResults {
+		return
+	}
+
+	if len(current.words) == wordCount {
+		if len(letterUses) == len(bank) {
+			*solutions = append(*solutions, current)
+		}
+		return
+	}
+
+	letterCount := current.letterCount()
+	if request.permutations {
+		first = 0
+	}
+CandidateLoop:
+	for index := first; index < len(candidates); index++ {
+		candidate := candidates[index]
+		word := candidate.words[0]
+		if letterCount+len(word) > request.maxLetters {
+			continue
+		}
+		for _, letter := range []byte(word) {
+			letterUses[letter]++
+		}
+		for _, letter := range []byte(word) {
+			if letterUses[letter] > maxLetterUses {
+				removeBankWordUses(letterUses, word)
+				continue CandidateLoop
+			}
+		}
+
+		nextWords := make([]string, 0, len(current.words)+1)
+		nextWords = append(nextWords, current.words...)
+		nextWords = append(nextWords, word)
+		next := letterBankSolution{nextWords, current.score + candidate.score, current.frequency + candidate.frequency}
+		combineBankWords(ctx, candidates, bank, request, wordCount, maxLetterUses, letterUses, next, index, solutions)
+		removeBankWordUses(letterUses, word)
+		if len(*solutions) >= request.maxResults {
+			return
+		}
+	}
+}
+
+func removeBankWordUses(letterUses map[byte]int, word string) {
+	for _, letter := range []byte(word) {
+		letterUses[letter]--
+		if letterUses[letter] == 0 {
+			delete(letterUses, letter)
+		}
+	}
+}
+
+// sortLetterBankSolutions sorts by total letters then number of words for "length",
+// or by combined dictionary rank for "common", or combined frequency if byFrequency is set. Ties are broken alphabetically
+func sortLetterBankSolutions(solutions []letterBankSolution, sortBy string, byFrequency bool) {
+	sort.SliceStable(solutions, func(i, j int) bool {
+		first, second := solutions[i], solutions[j]
+		if sortBy == "common" && byFrequency && first.frequency != second.frequency {
+			return first.frequency > second.frequency
+		}
+		if sortBy == "common" && first.score != second.score {
+			return first.score < second.score
+		}
+		if first.letterCount() != second.letterCount() {
+			return first.letterCount() < second.letterCount()
+		}
+		if len(first.words) != len(second.words) {
+			return len(first.words) < len(second.words)
+		}
+		return strings.Join(first.words, " ") < strings.Join(second.words, " ")
+	})
+}
+
+// runLetterBankSolver is letterbank for the solver registry. The search stops when ctx does,
+// so a budget gets back the solutions found in time
+func runLetterBankSolver(ctx context.Context, input solverInput) (*resultTable, error) {
+	rootTrie, _, err := openDictionaryTrie(input.getList("dictionary")...)
+	if err != nil {
+		return nil, err
+	}
+
+	request := letterBankRequest{input.getString("bank"), input.getInt("max_words"), input.getInt("max_letter_uses"), input.getInt("max_total_letters"), input.getInt("max_results"), input.getString("sort"), excludedWordSet(input.getList("exclude")), input.getBool("permutations")}
+	solutions, err := performLetterBankSolve(ctx, rootTrie, request)
+	if err != nil {
+		return nil, err
+	}
+	table := newResultTable("words")
+	for _, solution := range solutions {
+		table.addRow(strings.Join(solution.words, " "))
+	}
+	return table, nil
+}
+
+func init() {
+	mustRegisterSolver(&solver{
+		name:        "letterbank",
+		description: "Words or phrases using every letter of the bank, with letters reused as needed",
+		parameters: []solverParameter{
+			solverParameter{name: "bank", kind: solverString, description: "the letters in the bank", required: true},
+			solverParameter{name: "dictionary", kind: solverString, description: "the dictionary file to use, or several separated by commas", required: true},
+			solverParameter{name: "max_words", kind: solverInt, description: "the most words in a solution", defaultValue: "2"},
+			solverParameter{name: "max_letter_uses", kind: solverInt, description: "the most times any one letter can appear; 0 means no limit", defaultValue: "3"},
+			solverParameter{name: "max_total_letters", kind: solverInt, description: "the most letters in a solution, across all its words; 0 means no limit"},
+			solverParameter{name: "max_results", kind: solverInt, description: "stop searching after this many solutions", defaultValue: "1000"},
+			solverParameter{name: "sort", kind: solverString, description: "length or common", defaultValue: "length"},
+			solverParameter{name: "exclude", kind: solverString, description: "a word to leave out of the solutions, or several separated by commas"},
+			solverParameter{name: "permutations", kind: solverBool, description: "list every ordering of a multiword solution instead of just one"},
+		},
+		run: runLetterBankSolver,
+	})
+
+	letterBankCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+	letterBankCmd.MarkFlagRequired("dictionary")
+	letterBankCmd.Flags().IntVarP(&letterBankMaxWords, "max-words", "", 2, "The maximum number of words allowable in a solution")
+	letterBankCmd.Flags().IntVarP(&letterBankMaxLetterUses, "max-letter-uses", "", 3, "The most times any one letter can appear in a solution. 0 means no limit")
+	letterBankCmd.Flags().IntVarP(&letterBankMaxTotalLetters, "max-total-letters", "", 0, "The most letters a solution can have, across all its words. 0 means no limit")
+	letterBankCmd.Flags().IntVarP(&letterBankMaxResults, "max-results", "", 1000, "Stop searching after this many solutions")
+	letterBankCmd.Flags().StringVarP(&letterBankSort, "sort", "", "length", "How to order results: length (shortest first) or common (most common words first, for dictionaries sorted by frequency)")
+	letterBankCmd.Flags().StringSliceVarP(&excludedAnswerWords, "exclude", "", nil, "Leave out solutions with this word in them. Repeat it or separate words with commas for several")
+	letterBankCmd.Flags().BoolVarP(&letterBankPermutations, "permutations", "", false, "List every ordering of the words in a solution, not just one")
+	rootCmd.AddCommand(letterBankCmd)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func letterBankTestTrie() *trieNode {
+	trie, _ := rankedTestTrie("BASSET\nBEAST\nBEATS\nSEA\nTAB\nBEEFS\nSTAB\nBE")
+	return trie
+}
+
+func joinLetterBankSolutions(solutions []letterBankSolution) []string {
+	joined := make([]string, 0, len(solutions))
+	for _, solution := range solutions {
+		joined = append(joined, strings.Join(solution.words, " "))
+	}
+	return joined
+}
+
+func TestPerformLetterBankSolve(test *testing.T) {
+	request := letterBankRequest{"beast", 1, 0, 0, 100, "length", nil, false}
+	solutions, err := performLetterBankSolve(context.Background(), letterBankTestTrie(), request)
+	if err != nil {
+		test.Fatalf("Unexpected error: %v", err)
+	}
+	actual := strings.Join(joinLetterBankSolutions(solutions), ",")
+	if actual != "BEAST,BEATS,BASSET" {
+		test.Errorf("Expected BEAST,BEATS,BASSET but got %s", actual)
+	}
+
+	// capping letter uses removes BASSET, which uses S twice
+	request.maxLetterUses = 1
+	solutions, _ = performLetterBankSolve(context.Background(), letterBankTestTrie(), request)
+	if actual := strings.Join(joinLetterBankSolutions(solutions), ","); actual != "BEAST,BEATS" {
+		test.Errorf("Expected BEAST,BEATS with one use per letter but got %s", actual)
+	}
+
+	// two word solutions come after the single words
+	request = letterBankRequest{"beast", 2, 0, 0, 100, "length", nil, false}
+	solutions, _ = performLetterBankSolve(context.Background(), letterBankTestTrie(), request)
+	joined := joinLetterBankSolutions(solutions)
+	if joined[0] != "BEAST" || !stringInSlice("SEA TAB", joined) || !stringInSlice("BE STAB", joined) {
+		test.Errorf("Expected single and two word solutions but got %v", joined)
+	}
+
+	request.maxResults = 2
+	solutions, _ = performLetterBankSolve(context.Background(), letterBankTestTrie(), request)
+	// the trie is walked alphabetically, so BASSET and BEAST are found first
+	if actual := strings.Join(joinLetterBankSolutions(solutions), ","); actual != "BEAST,BASSET" {
+		test.Errorf("Expected the search to stop after the first 2 results but got %s", actual)
+	}
+
+	// each set of words comes once unless permutations are asked for
+	if stringInSlice("TAB SEA", joined) || stringInSlice("STAB BE", joined) {
+		test.Errorf("Expected each set of words in one order but got %v", joined)
+	}
+	request = letterBankRequest{"beast", 2, 0, 0, 100, "length", nil, true}
+	solutions, _ = performLetterBankSolve(context.Background(), letterBankTestTrie(), request)
+	if permuted := joinLetterBankSolutions(solutions); !stringInSlice("TAB SEA", permuted) || !stringInSlice("SEA TAB", permuted) {
+		test.Errorf("Expected both orders of SEA TAB with permutations but got %v", permuted)
+	}
+
+	// capping the total letters leaves out BASSET and any phrase longer than five letters
+	request = letterBankRequest{"beast", 2, 0, 5, 100, "length", nil, false}
+	solutions, _ = performLetterBankSolve(context.Background(), letterBankTestTrie(), request)
+	joined = joinLetterBankSolutions(solutions)
+	for _, solution := range joined {
+		if len(strings.ReplaceAll(solution, " ", "")) > 5 {
+			test.Errorf("Expected solutions of at most five letters but got %v", joined)
+			break
+		}
+	}
+	if !stringInSlice("BEAST", joined) || stringInSlice("BASSET", joined) {
+		test.Errorf("Expected BEAST but not BASSET with five letters at most but got %v", joined)
+	}
+
+	// an excluded word is left out of every solution, not just as a word of its own
+	request = letterBankRequest{"beast", 2, 0, 0, 100, "length", excludedWordSet([]string{"beast", "sea"}), false}
+	solutions, _ = performLetterBankSolve(context.Background(), letterBankTestTrie(), request)
+	joined = joinLetterBankSolutions(solutions)
+	if stringInSlice("BEAST", joined) || stringInSlice("SEA TAB", joined) || !stringInSlice("BEATS", joined) {
+		test.Errorf("Expected solutions without BEAST or SEA but got %v", joined)
+	}
+}
+
+func TestPerformLetterBankSolveSortCommon(test *testing.T) {
+	request := letterBankRequest{"beast", 1, 0, 0, 100, "common", nil, false}
+	solutions, _ := performLetterBankSolve(context.Background(), letterBankTestTrie(), request)
+	if actual := strings.Join(joinLetterBankSolutions(solutions), ","); actual != "BASSET,BEAST,BEATS" {
+		test.Errorf("Expected dictionary order BASSET,BEAST,BEATS but got %s", actual)
+	}
+
+	request.sortBy = "random"
+	if _, err := performLetterBankSolve(context.Background(), letterBankTestTrie(), request); err == nil {
+		test.Errorf("Expected an error for an unknown sort")
+	}
+}
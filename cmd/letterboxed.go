@@ -0,0 +1,202 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var letterBoxedMaxWords int
+
+// letterBoxedCmd represents the letterboxed command
+var letterBoxedCmd = &cobra.Command{
+	Use:   "letterboxed SIDE SIDE SIDE SIDE",
+	Short: "Solves Letter Boxed, chaining words that use every letter around the box",
+	Long: `Finds chains of words that use every letter on the sides of the box. Each word is at least three
+	letters long, no two letters in a row can come from the same side, and each word starts with the last
+	letter of the one before. The New York Times' box has four sides of three letters, but any number of
+	sides works. Chains of up to --max-words words (2 by default) are listed, the fewest words and letters first.
+
+	Example:
+	  puzzle_helper letterboxed GIY RPL OEA NTH --dictionary words.txt`,
+	Args: cobra.MinimumNArgs(2),
+	Run:  printLetterBoxedChains,
+}
+
+// letterBox is the sides of a Letter Boxed puzzle, with a bit for each letter so a word's letters
+// can be tracked as a mask
+type letterBox struct {
+	sides map[byte]int
+	bits  map[byte]uint32
+	all   uint32
+}
+
+// letterBoxedWord is a word that can be played on the box, with the mask of the letters it uses
+type letterBoxedWord struct {
+	word    string
+	letters uint32
+}
+
+func printLetterBoxedChains(cmd *cobra.Command, args []string) {
+	box, err := newLetterBox(args)
+	if err != nil {
+		fmt.Printf("Invalid box: %v\n", err)
+		os.Exit(1)
+	}
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+
+	ctx, cancel := solveContext()
+	defer cancel()
+
+	printer := newResultPrinter("words")
+	for _, chain := range findLetterBoxedChains(ctx, rootTrie, box, letterBoxedMaxWords) {
+		words := strings.Join(chain, " ")
+		printer.result(words, words)
+	}
+	printer.finish(ctx.Err() != nil)
+}
+
+// newLetterBox reads the sides of a box. There have to be at least two, and a letter can only be on one
+func newLetterBox(sides []string) (letterBox, error) {
+	box := letterBox{make(map[byte]int), make(map[byte]uint32), 0}
+	if len(sides) < 2 {
+		return box, fmt.Errorf("a box needs at least two sides")
+	}
+	for side, letters := range sides {
+		upper := justUppercaseLetters(letters)
+		if len(upper) == 0 {
+			return box, fmt.Errorf("side %d has no letters", side+1)
+		}
+		for _, letter := range upper {
+			if _, present := box.sides[letter]; present {
+				return box, fmt.Errorf("%c is on more than one side", letter)
+			}
+			if len(box.bits) == 32 {
+				return box, fmt.Errorf("a box can have at most 32 letters")
+			}
+			box.sides[letter] = side
+			box.bits[letter] = 1 << uint(len(box.bits))
+			box.all |= box.bits[letter]
+		}
+	}
+	return box, nil
+}
+
+// findLetterBoxedChains returns the chains of at most maxWords words that use every letter on the box,
+// with the fewest words first, then the fewest letters, then alphabetically
+func findLetterBoxedChains(ctx context.Context, rootTrie *trieNode, box letterBox, maxWords int) [][]string {
+	byFirstLetter := make(map[byte][]letterBoxedWord)
+	for _, child := range rootTrie.Children() {
+		letter := child.Letter()[0]
+		if _, onBox := box.sides[letter]; onBox {
+			collectLetterBoxedWords(child, box, child.Letter(), box.bits[letter], byFirstLetter)
+		}
+	}
+
+	chains := make([][]string, 0)
+	for _, words := range byFirstLetter {
+		for _, word := range words {
+			extendLetterBoxedChain(ctx, byFirstLetter, box, maxWords, []string{word.word}, word.letters, &chains)
+		}
+	}
+	sort.Slice(chains, func(i, j int) bool {
+		if len(chains[i]) != len(chains[j]) {
+			return len(chains[i]) < len(chains[j])
+		}
+		first, second := strings.Join(chains[i], " "), strings.Join(chains[j], " ")
+		if len(first) != len(second) {
+			return len(first) < len(second)
+		}
+		return first < second
+	})
+	return chains
+}
+
+// collectLetterBoxedWords walks down from node along letters on the box, never taking two in a row from the
+// same side, and files each word of three or more letters it finds under its first letter
+func collectLetterBoxedWords(node *trieNode, box letterBox, currentWord string, letters uint32, byFirstLetter map[byte][]letterBoxedWord) {
+	if node.IsWord() && len(currentWord) >= 3 {
+		byFirstLetter[currentWord[0]] = append(byFirstLetter[currentWord[0]], letterBoxedWord{currentWord, letters})
+	}
+
+	lastSide := box.sides[currentWord[len(currentWord)-1]]
+	for _, child := range node.Children() {
+		letter := child.Letter()[0]
+		if side, onBox := box.sides[letter]; onBox && side != lastSide {
+			collectLetterBoxedWords(child, box, currentWord+child.Letter(), letters|box.bits[letter], byFirstLetter)
+		}
+	}
+}
+
+// extendLetterBoxedChain records chain if it uses every letter, and otherwise tries each word that starts
+// with its last letter, as long as the chain has fewer than maxWords words
+func extendLetterBoxedChain(ctx context.Context, byFirstLetter map[byte][]letterBoxedWord, box letterBox, maxWords int, chain []string, letters uint32, chains *[][]string) {
+	if letters == box.all {
+		*chains = append(*chains, append([]string{}, chain...))
+		return
+	}
+	if len(chain) >= maxWords || ctx.Err() != nil {
+		return
+	}
+	last := chain[len(chain)-1]
+	for _, next := range byFirstLetter[last[len(last)-1]] {
+		// a word that adds no new letters can't be part of a shortest chain
+		if next.letters&^letters != 0 {
+			extendLetterBoxedChain(ctx, byFirstLetter, box, maxWords, append(chain, next.word), letters|next.letters, chains)
+		}
+	}
+}
+
+// runLetterBoxedSolver is letterboxed for the solver registry
+func runLetterBoxedSolver(ctx context.Context, input solverInput) (*resultTable, error) {
+	box, err := newLetterBox(input.getList("sides"))
+	if err != nil {
+		return nil, err
+	}
+	rootTrie, _, err := openDictionaryTrie(input.getList("dictionary")...)
+	if err != nil {
+		return nil, err
+	}
+	table := newResultTable("words")
+	for _, chain := range findLetterBoxedChains(ctx, rootTrie, box, input.getInt("max_words")) {
+		table.addRow(strings.Join(chain, " "))
+	}
+	return table, nil
+}
+
+func init() {
+	letterBoxedCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+	letterBoxedCmd.MarkFlagRequired("dictionary")
+	letterBoxedCmd.Flags().IntVarP(&letterBoxedMaxWords, "max-words", "", 2, "The most words in a chain")
+	rootCmd.AddCommand(letterBoxedCmd)
+
+	mustRegisterSolver(&solver{
+		name:        "letterboxed",
+		description: "Chains of words that use every letter around a Letter Boxed box, never taking two letters in a row from one side",
+		parameters: []solverParameter{
+			solverParameter{name: "sides", kind: solverString, description: "the letters on each side, separated by commas, such as GIY,RPL,OEA,NTH", required: true},
+			solverParameter{name: "dictionary", kind: solverString, description: "the dictionary file to use, or several separated by commas", required: true},
+			solverParameter{name: "max_words", kind: solverInt, description: "the most words in a chain", defaultValue: "2"},
+		},
+		run: runLetterBoxedSolver,
+	})
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFindLetterBoxedChains(test *testing.T) {
+	trie := newTrie()
+	// PAINT, TAIL, and PLANT take two letters in a row from one side, and LI is too short
+	for _, word := range []string{"PLAN", "NIT", "NIP", "PIT", "PAINT", "TAIL", "LI", "PITLAN", "PLANT"} {
+		trie.Add(word, nil)
+	}
+	box, err := newLetterBox([]string{"pa", "TN", "IL"})
+	if err != nil {
+		test.Fatalf("Unexpected error making the box: %v", err)
+	}
+
+	testCases := []struct {
+		maxWords int
+		expected string
+	}{
+		{1, "PITLAN"},
+		{2, "PITLAN,PLAN NIT,NIP PITLAN"},
+		{3, "PITLAN,PLAN NIT,NIP PITLAN,NIP PLAN NIT,PLAN NIP PIT,PLAN NIP PITLAN"},
+	}
+
+	for _, testCase := range testCases {
+		chains := findLetterBoxedChains(context.Background(), trie, box, testCase.maxWords)
+		joined := make([]string, 0, len(chains))
+		for _, chain := range chains {
+			joined = append(joined, strings.Join(chain, " "))
+		}
+		if actual := strings.Join(joined, ","); actual != testCase.expected {
+			test.Errorf("Expected %s with at most %d words but got %s", testCase.expected, testCase.maxWords, actual)
+		}
+	}
+
+	for _, sides := range [][]string{{"ABC"}, {"ABC", "CDE"}, {"ABC", "12"}} {
+		if _, err := newLetterBox(sides); err == nil {
+			test.Errorf("Expected an error for the sides %v", sides)
+		}
+	}
+}
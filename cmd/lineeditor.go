@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// key codes the line editor handles itself
+const (
+	keyCtrlA     byte = 1
+	keyCtrlB     byte = 2
+	keyCtrlC     byte = 3
+	keyCtrlD     byte = 4
+	keyCtrlE     byte = 5
+	keyCtrlF     byte = 6
+	keyCtrlH     byte = 8
+	keyTab       byte = 9
+	keyNewline   byte = 10
+	keyCtrlK     byte = 11
+	keyReturn    byte = 13
+	keyCtrlN     byte = 14
+	keyCtrlP     byte = 16
+	keyCtrlU     byte = 21
+	keyCtrlW     byte = 23
+	keyEscape    byte = 27
+	keyBackspace byte = 127
+)
+
+// lineEditor reads lines of input with emacs-style editing, history, and tab completion when
+// it can put the terminal into raw mode. Otherwise (such as when input is piped in) it just reads lines.
+type lineEditor struct {
+	in          *bufio.Reader
+	out         io.Writer
+	prompt      string
+	history     []string
+	completions []string
+	// makeRaw switches the terminal to raw mode and returns a function to switch it back
+	makeRaw func() (func(), error)
+}
+
+// newLineEditor creates a line editor reading from stdin and echoing to out
+func newLineEditor(out io.Writer, prompt string, completions []string) *lineEditor {
+	return &lineEditor{
+		in:          bufio.NewReader(os.Stdin),
+		out:         out,
+		prompt:      prompt,
+		completions: completions,
+		makeRaw:     func() (func(), error) { return makeTerminalRaw(int(os.Stdin.Fd())) },
+	}
+}
+
+// readLine shows the prompt and returns the next line of input without its line ending.
+// io.EOF is returned at the end of input or when the user hits Ctrl-C or Ctrl-D on an empty line
+func (editor *lineEditor) readLine() (string, error) {
+	io.WriteString(editor.out, editor.prompt+"\u001b[0K")
+	if editor.makeRaw != nil {
+		if restore, err := editor.makeRaw(); err == nil {
+			defer restore()
+			return editor.editLine()
+		}
+	}
+
+	line, err := editor.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// editLine reads keys one at a time, redrawing the line as it changes, until return is hit
+func (editor *lineEditor) editLine() (string, error) {
+	buffer := []byte{}
+	cursor := 0
+	// history entries are walked from the newest; len(history) is the line being typed
+	historyIndex := len(editor.history)
+	pending := ""
+
+	for {
+		key, err := editor.in.ReadByte()
+		if err != nil {
+			return string(buffer), err
+		}
+
+		switch key {
+		case keyReturn, keyNewline:
+			io.WriteString(editor.out, "\n")
+			line := string(buffer)
+			editor.addHistory(line)
+			return line, nil
+		case keyCtrlC:
+			return "", io.EOF
+		case keyCtrlD:
+			if len(buffer) == 0 {
+				return "", io.EOF
+			}
+			if cursor < len(buffer) {
+				buffer = append(buffer[:cursor], buffer[cursor+1:]...)
+			}
+		case keyCtrlA:
+			cursor = 0
+		case keyCtrlE:
+			cursor = len(buffer)
+		case keyCtrlB:
+			if cursor > 0 {
+				cursor--
+			}
+		case keyCtrlF:
+			if cursor < len(buffer) {
+				cursor++
+			}
+		case keyCtrlK:
+			buffer = buffer[:cursor]
+		case keyCtrlU:
+			buffer = append([]byte{}, buffer[cursor:]...)
+			cursor = 0
+		case keyCtrlW:
+			start := cursor
+			for start > 0 && buffer[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && buffer[start-1] != ' ' {
+				start--
+			}
+			buffer = append(buffer[:start], buffer[cursor:]...)
+			cursor = start
+		case keyBackspace, keyCtrlH:
+			if cursor > 0 {
+				buffer = append(buffer[:cursor-1], buffer[cursor:]...)
+				cursor--
+			}
+		case keyTab:
+			buffer, cursor = editor.complete(buffer, cursor)
+		case keyCtrlP, keyCtrlN:
+			buffer, historyIndex, pending = editor.walkHistory(buffer, historyIndex, pending, key == keyCtrlP)
+			cursor = len(buffer)
+		case keyEscape:
+			buffer, cursor, historyIndex, pending = editor.handleEscape(buffer, cursor, historyIndex, pending)
+		default:
+			if key >= ' ' {
+				buffer = append(buffer[:cursor], append([]byte{key}, buffer[cursor:]...)...)
+				cursor++
+			}
+		}
+		editor.redraw(buffer, cursor)
+	}
+}
+
+// handleEscape interprets the escape sequences terminals send for arrow, home, end, and delete keys
+func (editor *lineEditor) handleEscape(buffer []byte, cursor, historyIndex int, pending string) ([]byte, int, int, string) {
+	if next, err := editor.in.ReadByte(); err != nil || (next != '[' && next != 'O') {
+		return buffer, cursor, historyIndex, pending
+	}
+	code, err := editor.in.ReadByte()
+	if err != nil {
+		return buffer, cursor, historyIndex, pending
+	}
+
+	switch code {
+	case 'A', 'B':
+		buffer, historyIndex, pending = editor.walkHistory(buffer, historyIndex, pending, code == 'A')
+		cursor = len(buffer)
+	case 'C':
+		if cursor < len(buffer) {
+			cursor++
+		}
+	case 'D':
+		if cursor > 0 {
+			cursor--
+		}
+	case 'H':
+		cursor = 0
+	case 'F':
+		cursor = len(buffer)
+	case '3':
+		// delete is ESC [ 3 ~
+		if tilde, err := editor.in.ReadByte(); err == nil && tilde == '~' && cursor < len(buffer) {
+			buffer = append(buffer[:cursor], buffer[cursor+1:]...)
+		}
+	}
+	return buffer, cursor, historyIndex, pending
+}
+
+// walkHistory moves one entry back (older) or forward (newer) through the history,
+// holding on to whatever was being typed so coming back down restores it
+func (editor *lineEditor) walkHistory(buffer []byte, historyIndex int, pending string, older bool) ([]byte, int, string) {
+	if historyIndex == len(editor.history) {
+		pending = string(buffer)
+	}
+	if older && historyIndex > 0 {
+		historyIndex--
+	} else if !older && historyIndex < len(editor.history) {
+		historyIndex++
+	} else {
+		return buffer, historyIndex, pending
+	}
+
+	if historyIndex == len(editor.history) {
+		return []byte(pending), historyIndex, pending
+	}
+	return []byte(editor.history[historyIndex]), historyIndex, pending
+}
+
+// addHistory remembers a line unless it's blank or repeats the last one
+func (editor *lineEditor) addHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if len(editor.history) > 0 && editor.history[len(editor.history)-1] == line {
+		return
+	}
+	editor.history = append(editor.history, line)
+}
+
+// complete fills in the command being typed at the start of the line. With several possible commands
+// it fills in as much as they share and rings the bell
+func (editor *lineEditor) complete(buffer []byte, cursor int) ([]byte, int) {
+	prefix := string(buffer[:cursor])
+	if strings.Contains(prefix, " ") {
+		return buffer, cursor
+	}
+
+	matches := make([]string, 0)
+	for _, completion := range editor.completions {
+		if strings.HasPrefix(completion, prefix) {
+			matches = append(matches, completion)
+		}
+	}
+	if len(matches) == 0 {
+		io.WriteString(editor.out, "\a")
+		return buffer, cursor
+	}
+
+	completed := matches[0]
+	if len(matches) == 1 {
+		completed += " "
+	} else {
+		for _, match := range matches[1:] {
+			for !strings.HasPrefix(match, completed) {
+				completed = completed[:len(completed)-1]
+			}
+		}
+		io.WriteString(editor.out, "\a")
+	}
+
+	rest := buffer[cursor:]
+	if len(matches) == 1 && len(rest) > 0 && rest[0] == ' ' {
+		// don't double up the space before an argument that's already there
+		completed = strings.TrimSuffix(completed, " ")
+	}
+	return append([]byte(completed), rest...), len(completed)
+}
+
+// redraw rewrites the prompt and line and puts the cursor back where it belongs
+func (editor *lineEditor) redraw(buffer []byte, cursor int) {
+	io.WriteString(editor.out, "\r"+editor.prompt+string(buffer)+"\u001b[0K")
+	if back := len(buffer) - cursor; back > 0 {
+		io.WriteString(editor.out, fmt.Sprintf("\u001b[%dD", back))
+	}
+}
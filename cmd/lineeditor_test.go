@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// testLineEditor pretends the terminal is already raw so keys can be fed in from a string
+func testLineEditor(input string) *lineEditor {
+	return &lineEditor{
+		in:          bufio.NewReader(strings.NewReader(input)),
+		out:         ioutil.Discard,
+		prompt:      "? ",
+		completions: replCommands,
+		makeRaw:     func() (func(), error) { return func() {}, nil },
+	}
+}
+
+func TestLineEditorEditing(test *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"A=e\r", "A=e"},
+		{"=e\x01A\r", "A=e"},
+		{"A=\x02\x02B\x05e\r", "BA=e"},
+		{"groo\x7fup 5\r", "group 5"},
+		{"clear junk\x17\x17\r", ""},
+		{"clear junk\x01\x06\x06\x06\x06\x06\x0b\r", "clear"},
+		{"junk clear\x01\x06\x06\x06\x06\x06\x15\r", "clear"},
+		{"A=e\u001b[D\u001b[D\u001b[3~\r", "Ae"},
+		{"ze\u001b[H\u001b[3~B=\u001b[Fx\r", "B=ex"},
+		{"cip\t\r", "cipher2Plain "},
+		{"s\t\r", "s"},
+		{"sa\tx.json\r", "save x.json"},
+		{"zzz\t\r", "zzz"},
+	}
+
+	for _, testCase := range tests {
+		line, err := testLineEditor(testCase.input).readLine()
+		if err != nil {
+			test.Errorf("Unexpected error reading %q: %v", testCase.input, err)
+		}
+		if line != testCase.expected {
+			test.Errorf("Expected %q to read as %q but got %q", testCase.input, testCase.expected, line)
+		}
+	}
+}
+
+func TestLineEditorHistory(test *testing.T) {
+	editor := testLineEditor("A=e\rB=t\rB=t\r\u001b[A\u001b[A\u001b[A\r\u001b[Ax\u001b[B\r\x10\x10\x10\x0e\r")
+	expected := []string{"A=e", "B=t", "B=t", "A=e", "", "B=t"}
+	for index, expectedLine := range expected {
+		line, err := editor.readLine()
+		if err != nil {
+			test.Fatalf("Unexpected error on line %d: %v", index, err)
+		}
+		if line != expectedLine {
+			test.Errorf("Expected line %d to be %q but got %q", index, expectedLine, line)
+		}
+	}
+
+	// repeats and blank lines don't clutter the history
+	if strings.Join(editor.history, ",") != "A=e,B=t,A=e,B=t" {
+		test.Errorf("Expected history A=e,B=t,A=e,B=t but got %v", editor.history)
+	}
+
+	if _, err := editor.readLine(); err != io.EOF {
+		test.Errorf("Expected EOF at the end of input but got %v", err)
+	}
+}
+
+func TestLineEditorQuitKeys(test *testing.T) {
+	for _, input := range []string{"\x04", "clear\x03"} {
+		if _, err := testLineEditor(input).readLine(); err != io.EOF {
+			test.Errorf("Expected %q to end input but got %v", input, err)
+		}
+	}
+
+	// Ctrl-D only quits on an empty line; otherwise it deletes under the cursor
+	line, _ := testLineEditor("A=e\x01\x04\r").readLine()
+	if line != "=e" {
+		test.Errorf("Expected Ctrl-D to delete the A but got %q", line)
+	}
+}
+
+func TestLineEditorWithoutTerminal(test *testing.T) {
+	editor := testLineEditor("A=e\r\nclear\n")
+	editor.makeRaw = nil
+	for _, expected := range []string{"A=e", "clear"} {
+		if line, err := editor.readLine(); err != nil || line != expected {
+			test.Errorf("Expected %q but got %q (%v)", expected, line, err)
+		}
+	}
+}
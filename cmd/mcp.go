@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// The MCP server speaks JSON-RPC 2.0, one message per line, with just enough of the Model Context
+// Protocol for clients to list the solvers as tools and call them
+
+const mcpProtocolVersion = "2024-11-05"
+
+// JSON-RPC error codes
+const (
+	jsonRpcParseError     = -32700
+	jsonRpcInvalidRequest = -32600
+	jsonRpcMethodNotFound = -32601
+	jsonRpcInvalidParams  = -32602
+)
+
+type jsonRpcRequest struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type jsonRpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// jsonRpcNotification is a message the server sends without being asked, which gets no answer
+type jsonRpcNotification struct {
+	JsonRpc string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type jsonRpcResponse struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *jsonRpcError   `json:"error,omitempty"`
+}
+
+// mcpTextContent is a tool result's content, which for solvers is their JSON table as text
+type mcpTextContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// mcpToolResult is a tool's answer. Solver results can also come back as structuredContent, their
+// JSON table as an object rather than text
+type mcpToolResult struct {
+	Content           []mcpTextContent `json:"content"`
+	IsError           bool             `json:"isError"`
+	StructuredContent *jsonTable       `json:"structuredContent,omitempty"`
+}
+
+// every tool takes this argument, which chooses what its result holds: text for just a compact table of up
+// to mcpMaxTableRows rows, json for the whole JSON table as both text and structuredContent, or both, the
+// default, for the compact table along with the JSON as structuredContent
+const mcpFormatParameter = "format"
+
+const mcpMaxTableRows = 50
+
+// mcpTools describes the solvers as tools, which take the format argument as well as their parameters
+func mcpTools() []solverDescription {
+	tools := describeSolvers()
+	for _, tool := range tools {
+		tool.InputSchema["properties"].(map[string]interface{})[mcpFormatParameter] = map[string]interface{}{
+			"type":        "string",
+			"enum":        []string{"text", "json", "both"},
+			"description": "text for a compact table, json for every result as JSON, or both for the table with the JSON as structuredContent",
+			"default":     "both",
+		}
+	}
+	return tools
+}
+
+type mcpServer struct {
+	in *bufio.Scanner
+	// tool calls answer from their own goroutines and solvers send progress notifications, so writes take the lock
+	writeLock sync.Mutex
+	out       *json.Encoder
+	// callJobs is the served job running each tool call that hasn't been answered, by request id, so
+	// that calls can be cancelled
+	callsLock sync.Mutex
+	callJobs  map[string]string
+	calls     sync.WaitGroup
+}
+
+func newMcpServer(in io.Reader, out io.Writer) *mcpServer {
+	scanner := bufio.NewScanner(in)
+	// a long ciphertext can make for a long line
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return &mcpServer{in: scanner, out: json.NewEncoder(out), callJobs: make(map[string]string)}
+}
+
+// send writes one message as a line of JSON
+func (server *mcpServer) send(message interface{}) error {
+	server.writeLock.Lock()
+	defer server.writeLock.Unlock()
+	return server.out.Encode(message)
+}
+
+// serve answers requests until the input ends, and then waits for the tool calls still running to answer
+func (server *mcpServer) serve(ctx context.Context) error {
+	defer server.calls.Wait()
+	for server.in.Scan() {
+		line := bytes.TrimSpace(server.in.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if response := server.handle(ctx, line); response != nil {
+			if err := server.send(response); err != nil {
+				return err
+			}
+		}
+	}
+	return server.in.Err()
+}
+
+// handle answers one message. Notifications, which have no id, get no answer, and nor do tool calls,
+// which run as served jobs and answer once they're done
+func (server *mcpServer) handle(ctx context.Context, line []byte) *jsonRpcResponse {
+	var request jsonRpcRequest
+	if err := json.Unmarshal(line, &request); err != nil {
+		return &jsonRpcResponse{JsonRpc: "2.0", Id: json.RawMessage("null"), Error: &jsonRpcError{jsonRpcParseError, err.Error()}}
+	}
+	if len(request.Id) == 0 {
+		if request.Method == "notifications/cancelled" {
+			server.cancelCall(request.Params)
+		}
+		return nil
+	}
+
+	response := &jsonRpcResponse{JsonRpc: "2.0", Id: request.Id}
+	if request.JsonRpc != "2.0" || request.Method == "" {
+		response.Error = &jsonRpcError{jsonRpcInvalidRequest, "requests need jsonrpc 2.0 and a method"}
+		return response
+	}
+
+	switch request.Method {
+	case "initialize":
+		response.Result = map[string]interface{}{
+			"protocolVersion": mcpProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": "puzzle_helper", "version": "1.0"},
+		}
+	case "ping":
+		response.Result = map[string]interface{}{}
+	case "tools/list":
+		response.Result = map[string]interface{}{"tools": mcpTools()}
+	case "tools/call":
+		if err := server.startToolCall(ctx, request.Id, request.Params); err != nil {
+			response.Result = &mcpToolResult{Content: []mcpTextContent{{"text", err.Error()}}, IsError: true}
+		} else {
+			return nil
+		}
+	default:
+		response.Error = &jsonRpcError{jsonRpcMethodNotFound, fmt.Sprintf("there's no method %s", request.Method)}
+	}
+	return response
+}
+
+// startToolCall runs a tool call as one of the served jobs, so that a long solve doesn't hold up the
+// messages after it, and answers the call when the job is done. A call that's cancelled gets no answer.
+// The only error is a full queue, which the caller answers right away
+func (server *mcpServer) startToolCall(ctx context.Context, id json.RawMessage, params json.RawMessage) error {
+	jobID, err := servedJobs().submit(func(jobCtx context.Context, report func(progress string)) (interface{}, error) {
+		response := &jsonRpcResponse{JsonRpc: "2.0", Id: id}
+		result, rpcErr := server.callTool(withJobProgress(jobCtx, report), params)
+		if rpcErr != nil {
+			response.Error = rpcErr
+		} else {
+			response.Result = result
+		}
+		return response, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	key := mcpRequestKey(id)
+	server.callsLock.Lock()
+	server.callJobs[key] = jobID
+	server.callsLock.Unlock()
+	server.calls.Add(1)
+	go func() {
+		defer server.calls.Done()
+		snapshot, _ := servedJobs().wait(jobID)
+		servedJobs().forget(jobID)
+		server.callsLock.Lock()
+		delete(server.callJobs, key)
+		server.callsLock.Unlock()
+		if response, answered := snapshot.Result.(*jsonRpcResponse); answered && snapshot.Status != jobCancelled {
+			server.send(response)
+		}
+	}()
+	return nil
+}
+
+// cancelCall stops the tool call a notifications/cancelled message names, if it's still running
+func (server *mcpServer) cancelCall(rawParams json.RawMessage) {
+	var params struct {
+		RequestId json.RawMessage `json:"requestId"`
+	}
+	if err := json.Unmarshal(rawParams, &params); err != nil || len(params.RequestId) == 0 {
+		return
+	}
+	server.callsLock.Lock()
+	jobID, running := server.callJobs[mcpRequestKey(params.RequestId)]
+	server.callsLock.Unlock()
+	if running {
+		servedJobs().cancelJob(jobID)
+	}
+}
+
+// mcpRequestKey is a request id as a map key, so that 7 and 7 with space around it are the same id
+func mcpRequestKey(id json.RawMessage) string {
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, id); err != nil {
+		return string(id)
+	}
+	return compacted.String()
+}
+
+// callTool runs a solver. Problems with the solver's parameters come back as a tool result marked
+// as an error, so the client can see what went wrong and try again
+func (server *mcpServer) callTool(ctx context.Context, rawParams json.RawMessage) (*mcpToolResult, *jsonRpcError) {
+	var params struct {
+		Name      string                 `json:"name"`
+		Arguments map[string]interface{} `json:"arguments"`
+		Meta      struct {
+			ProgressToken json.RawMessage `json:"progressToken"`
+		} `json:"_meta"`
+	}
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return nil, &jsonRpcError{jsonRpcInvalidParams, err.Error()}
+	}
+	registered := lookupSolver(params.Name)
+	if registered == nil {
+		return nil, &jsonRpcError{jsonRpcInvalidParams, fmt.Sprintf("there's no tool named %s", params.Name)}
+	}
+
+	if token := params.Meta.ProgressToken; len(token) > 0 {
+		// the client asked to hear how the solve is going
+		ctx = withSolverProgress(ctx, func(progress, total float64, message string) {
+			notification := map[string]interface{}{"progressToken": token, "progress": progress, "message": message}
+			if total > 0 {
+				notification["total"] = total
+			}
+			server.send(jsonRpcNotification{"2.0", "notifications/progress", notification})
+		})
+	}
+	format := "both"
+	if value, given := params.Arguments[mcpFormatParameter]; given {
+		format, _ = value.(string)
+		delete(params.Arguments, mcpFormatParameter)
+	}
+	if format != "text" && format != "json" && format != "both" {
+		return &mcpToolResult{Content: []mcpTextContent{{"text", "format should be text, json, or both"}}, IsError: true}, nil
+	}
+
+	table, err := solveServed(ctx, registered, params.Arguments)
+	if err != nil {
+		return &mcpToolResult{Content: []mcpTextContent{{"text", err.Error()}}, IsError: true}, nil
+	}
+	var text bytes.Buffer
+	if format == "json" {
+		writeJSONTable(&text, table)
+	} else {
+		writeCompactTable(&text, table, mcpMaxTableRows)
+	}
+	result := &mcpToolResult{Content: []mcpTextContent{{"text", string(bytes.TrimSpace(text.Bytes()))}}}
+	if format != "text" {
+		structured := newJSONTable(table)
+		result.StructuredContent = &structured
+	}
+	return result, nil
+}
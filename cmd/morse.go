@@ -0,0 +1,280 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+var morseMinWordLength int
+
+// stegoCmd represents the stego command
+var stegoCmd = &cobra.Command{
+	Use:   "stego",
+	Short: "Looks for messages hidden in the features of ordinary text",
+}
+
+var stegoMorseCmd = &cobra.Command{
+	Use:   "morse TEXT...",
+	Short: "Reads two-way features of text as Morse code and reports readings that make words",
+	Long: `Some puzzles hide Morse code in text that reads normally, by making each letter a vowel
+	or a consonant, each word short or long, or each word capitalized or not. This tries each of those
+	readings, with either side as the dot, and prints the ones that decode to dictionary words.
+
+	Vowels and consonants are read with each word as one Morse letter, and also run together.
+	Word lengths and capitals are read with punctuation at the end of a word separating Morse letters
+	if there is any, and also run together. Readings that are run together are split into dictionary
+	words of at least --min-word-length letters, using as few words as possible.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  printMorseReadings,
+}
+
+var morseCode = map[byte]string{
+	'A': ".-", 'B': "-...", 'C': "-.-.", 'D': "-..", 'E': ".", 'F': "..-.", 'G': "--.", 'H': "....",
+	'I': "..", 'J': ".---", 'K': "-.-", 'L': ".-..", 'M': "--", 'N': "-.", 'O': "---", 'P': ".--.",
+	'Q': "--.-", 'R': ".-.", 'S': "...", 'T': "-", 'U': "..-", 'V': "...-", 'W': ".--", 'X': "-..-",
+	'Y': "-.--", 'Z': "--..",
+}
+
+var morseLetters = make(map[string]byte)
+
+// morseReading is one way of turning the text into dots and dashes. Each group is one Morse letter,
+// unless there's just the one group, in which case the letters are run together
+type morseReading struct {
+	name   string
+	groups []string
+}
+
+// morseDecoding is a reading that made dictionary words
+type morseDecoding struct {
+	reading string
+	code    string
+	words   []string
+}
+
+func printMorseReadings(cmd *cobra.Command, args []string) {
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+
+	ctx, cancel := solveContext()
+	defer cancel()
+	decodings := decodeMorseReadings(ctx, rootTrie, morseReadings(strings.Join(args, " ")), morseMinWordLength)
+
+	printer := newResultPrinter("reading", "code", "text")
+	if len(decodings) == 0 {
+		printer.text("No reading decoded to dictionary words")
+	}
+	for _, decoding := range decodings {
+		text := strings.Join(decoding.words, " ")
+		printer.result(fmt.Sprintf("%s: %s\n\t%s", decoding.reading, text, decoding.code), decoding.reading, decoding.code, text)
+	}
+	printer.finish(ctx.Err() != nil)
+}
+
+// morseReadings turns text into every reading the detector tries, each with dots and dashes both ways round
+func morseReadings(text string) []morseReading {
+	tokens := make([]string, 0)
+	// whether the token ends a Morse letter when punctuation separates them
+	endsGroup := make([]bool, 0)
+	anyPunctuation := false
+	for _, token := range strings.Fields(text) {
+		letters := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) {
+				return r
+			}
+			return -1
+		}, token)
+		if letters == "" {
+			continue
+		}
+		tokens = append(tokens, letters)
+		ends := unicode.IsPunct(rune(token[len(token)-1]))
+		endsGroup = append(endsGroup, ends)
+		anyPunctuation = anyPunctuation || ends
+	}
+
+	readings := make([]morseReading, 0)
+	addBothWays := func(name string, groups []string) {
+		readings = append(readings, morseReading{name, groups})
+		swapped := make([]string, len(groups))
+		for index, group := range groups {
+			swapped[index] = swapDotsAndDashes(group)
+		}
+		readings = append(readings, morseReading{name + " (swapped)", swapped})
+	}
+
+	vowelGroups := make([]string, len(tokens))
+	for index, token := range tokens {
+		vowelGroups[index] = strings.Map(func(r rune) rune {
+			if strings.ContainsRune("AEIOU", unicode.ToUpper(r)) {
+				return '.'
+			}
+			return '-'
+		}, token)
+	}
+	addBothWays("vowels are dots, one letter per word", vowelGroups)
+	addBothWays("vowels are dots", []string{strings.Join(vowelGroups, "")})
+
+	// word features give one symbol per word
+	addWordReading := func(name string, isDot func(string) bool) {
+		symbols := make([]byte, len(tokens))
+		for index, token := range tokens {
+			symbols[index] = '-'
+			if isDot(token) {
+				symbols[index] = '.'
+			}
+		}
+		if anyPunctuation {
+			groups := make([]string, 0)
+			start := 0
+			for index := range tokens {
+				if endsGroup[index] || index == len(tokens)-1 {
+					groups = append(groups, string(symbols[start:index+1]))
+					start = index + 1
+				}
+			}
+			addBothWays(name+", one letter per punctuation mark", groups)
+		}
+		addBothWays(name, []string{string(symbols)})
+	}
+
+	addWordReading("capitalized words are dots", func(token string) bool {
+		return unicode.IsUpper([]rune(token)[0])
+	})
+
+	lengths := make(map[int]bool)
+	for _, token := range tokens {
+		lengths[len([]rune(token))] = true
+	}
+	thresholds := make([]int, 0, len(lengths))
+	for length := range lengths {
+		thresholds = append(thresholds, length)
+	}
+	sort.Ints(thresholds)
+	// the longest length would make every word a dot
+	for index := 0; index < len(thresholds)-1; index++ {
+		threshold := thresholds[index]
+		addWordReading("words of "+strconv.Itoa(threshold)+" or fewer letters are dots", func(token string) bool {
+			return len([]rune(token)) <= threshold
+		})
+	}
+	return readings
+}
+
+func swapDotsAndDashes(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' {
+			return '-'
+		}
+		return '.'
+	}, code)
+}
+
+// decodeMorseReadings returns the readings that decode to dictionary words, in the order they were given
+func decodeMorseReadings(ctx context.Context, rootTrie *trieNode, readings []morseReading, minWordLength int) []morseDecoding {
+	decodings := make([]morseDecoding, 0)
+	for _, reading := range readings {
+		if ctx.Err() != nil {
+			break
+		}
+		var words []string
+		if len(reading.groups) == 1 {
+			words = splitMorseIntoWords(rootTrie, reading.groups[0], minWordLength)
+		} else {
+			words = decodeMorseLetters(rootTrie, reading.groups)
+		}
+		if words != nil {
+			decodings = append(decodings, morseDecoding{reading.name, strings.Join(reading.groups, " "), words})
+		}
+	}
+	return decodings
+}
+
+// decodeMorseLetters reads each group as one Morse letter and splits the letters into dictionary words,
+// returning nil if a group isn't a letter or the letters aren't all words
+func decodeMorseLetters(rootTrie *trieNode, groups []string) []string {
+	letters := make([]byte, len(groups))
+	for index, group := range groups {
+		letter, found := morseLetters[group]
+		if !found {
+			return nil
+		}
+		letters[index] = letter
+	}
+
+	segmentations := segmentText(rootTrie, 1, false, string(letters), 1)
+	if len(segmentations) == 0 {
+		return nil
+	}
+	for _, word := range segmentations[0].words {
+		if _, isWord := rootTrie.Get(word); !isWord {
+			return nil
+		}
+	}
+	return segmentations[0].words
+}
+
+// splitMorseIntoWords finds the fewest dictionary words whose Morse code, run together, is code.
+// Every word has to be at least minWordLength letters, since short words like E and T fit almost anything
+func splitMorseIntoWords(rootTrie *trieNode, code string, minWordLength int) []string {
+	// best[i] is the fewest words that spell out code[:i]
+	best := make([][]string, len(code)+1)
+	best[0] = []string{}
+	for start := 0; start < len(code); start++ {
+		if best[start] == nil {
+			continue
+		}
+		var walk func(node *trieNode, position int, word string)
+		walk = func(node *trieNode, position int, word string) {
+			if node.IsWord() && len(word) >= minWordLength {
+				candidate := append(append([]string{}, best[start]...), word)
+				if current := best[position]; current == nil || len(candidate) < len(current) ||
+					(len(candidate) == len(current) && strings.Join(candidate, " ") < strings.Join(current, " ")) {
+					best[position] = candidate
+				}
+			}
+			for _, child := range node.Children() {
+				letterCode := morseCode[child.Letter()[0]]
+				if strings.HasPrefix(code[position:], letterCode) {
+					walk(child, position+len(letterCode), word+child.Letter())
+				}
+			}
+		}
+		walk(rootTrie, start, "")
+	}
+	if len(best[len(code)]) == 0 {
+		return nil
+	}
+	return best[len(code)]
+}
+
+func init() {
+	for letter, code := range morseCode {
+		morseLetters[code] = letter
+	}
+
+	stegoMorseCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+	stegoMorseCmd.MarkFlagRequired("dictionary")
+	stegoMorseCmd.Flags().IntVarP(&morseMinWordLength, "min-word-length", "m", 3, "the shortest word to allow when the Morse letters are run together")
+	stegoCmd.AddCommand(stegoMorseCmd)
+	rootCmd.AddCommand(stegoCmd)
+}
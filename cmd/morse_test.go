@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMorseReadings(test *testing.T) {
+	readings := morseReadings("The cat, sat")
+	byName := make(map[string]string)
+	for _, reading := range readings {
+		byName[reading.name] = strings.Join(reading.groups, " ")
+	}
+
+	expected := map[string]string{
+		"vowels are dots, one letter per word":                        "--. -.- -.-",
+		"vowels are dots (swapped)":                                   "..-.-..-.",
+		"capitalized words are dots":                                  ".--",
+		"capitalized words are dots, one letter per punctuation mark": ".- -",
+	}
+	for name, code := range expected {
+		if byName[name] != code {
+			test.Errorf("Expected %s to be %q but got %q", name, code, byName[name])
+		}
+	}
+	// every word is three letters, so there's no length to split them at
+	for name := range byName {
+		if strings.HasPrefix(name, "words of") {
+			test.Errorf("Expected no length readings but got %s", name)
+		}
+	}
+}
+
+func TestDecodeMorseReadings(test *testing.T) {
+	rootTrie := newTrie()
+	for _, word := range []string{"SOS", "HELP", "ME", "HI"} {
+		rootTrie.Add(word, nil)
+	}
+
+	tests := []struct {
+		text     string
+		reading  string
+		expected string
+	}{
+		// S O S one letter per word with vowels as dots
+		{"eau sky eau", "vowels are dots, one letter per word", "SOS"},
+		// H E L P as word lengths, with periods between the letters
+		{"a a a a. a. a long a a. a long long a.", "words of 1 or fewer letters are dots, one letter per punctuation mark", "HELP"},
+	}
+	for _, testCase := range tests {
+		found := ""
+		for _, decoding := range decodeMorseReadings(context.Background(), rootTrie, morseReadings(testCase.text), 2) {
+			if decoding.reading == testCase.reading {
+				found = strings.Join(decoding.words, " ")
+			}
+		}
+		if found != testCase.expected {
+			test.Errorf("Expected %q to read as %s by %s but got %q", testCase.text, testCase.expected, testCase.reading, found)
+		}
+	}
+}
+
+func TestSplitMorseIntoWords(test *testing.T) {
+	rootTrie := newTrie()
+	for _, word := range []string{"HELP", "ME", "E", "T"} {
+		rootTrie.Add(word, nil)
+	}
+
+	tests := []struct {
+		code          string
+		minWordLength int
+		expected      string
+	}{
+		// HELP ME run together
+		{"......-...--.--.", 2, "HELP ME"},
+		{"...", 2, ""},
+		{"-.", 1, "T E"},
+	}
+	for _, testCase := range tests {
+		words := splitMorseIntoWords(rootTrie, testCase.code, testCase.minWordLength)
+		if strings.Join(words, " ") != testCase.expected {
+			test.Errorf("Expected %s to split into %q but got %v", testCase.code, testCase.expected, words)
+		}
+	}
+}
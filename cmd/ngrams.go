@@ -23,11 +23,18 @@ import (
 	"io"
 	"math"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
-var corpusFileName string
+var corpusFileNames []string
 var outputFileName string
-var ngramLength int
+var ngramLengths []int
+var ngramSvgFile string
+var ngramChartSize int
+var ngramWords bool
 
 // ngramsCmd represents the ngrams command
 var ngramsCmd = &cobra.Command{
@@ -36,95 +43,395 @@ var ngramsCmd = &cobra.Command{
 	Long: `This command won't be used very often, but the output can feed in to hillclimbing strategies for cryptogram solving.
 
 	The output is ngram, tab, log10(frequency within corpus).
+
+	The corpus can be a file, - for stdin, or an http(s) URL, which is counted as it downloads.
+	Project Gutenberg's license header and footer are left out of the counts.
+
+	Give --corpus more than once to merge several corpora into one table. Each corpus counts in proportion
+	to its weight, which is 1 unless it's given after a colon, as in --corpus puzzle_words.txt:0.3. The
+	weights are shares of the merged frequencies, so a small corpus counts as much as a big one of the same weight.
+
+	Several lengths, such as --ngram-length 2,3,4, are all counted in one pass over the corpus. If the
+	output file has %d in it, each length goes to its own file with the length in place of %d; otherwise
+	they're written together with each line tagged with its length first: length, tab, ngram, tab, frequency.
+
+	With --words, the ngrams are runs of words instead of letters, such as word pairs for -n 2, with the
+	words separated by spaces. Words are runs of letters; apostrophes inside them are dropped, so DON'T is DONT.
 	`,
 	Run: outputNgrams,
 }
 
 func outputNgrams(cmd *cobra.Command, args []string) {
 
-	if ngramLength < 1 {
-		fmt.Println("Only ngrams 1 or greater are allowed")
+	for _, ngramLength := range ngramLengths {
+		if ngramLength < 1 {
+			fmt.Println("Only ngrams 1 or greater are allowed")
+			os.Exit(1)
+		}
+	}
+	separateFiles := strings.Contains(outputFileName, "%d")
+	if len(ngramLengths) > 1 && ngramSvgFile != "" && !strings.Contains(ngramSvgFile, "%d") {
+		fmt.Printf("With several ngram lengths, --svg needs a %%d for where each length goes in the file name\n")
 		os.Exit(1)
 	}
 
-	var inReader io.Reader
-	if corpusFileName == "-" {
-		inReader = os.Stdin
-	} else {
-		var err error
-		inReader, err = os.Open(corpusFileName)
+	corpora := make([]weightedCorpus, 0, len(corpusFileNames))
+	for _, corpusFileName := range corpusFileNames {
+		corpus, err := parseWeightedCorpus(corpusFileName)
 		if err != nil {
-			fmt.Printf("Error opening %s: %v\n", corpusFileName, err)
+			fmt.Printf("Invalid corpus: %v\n", err)
 			os.Exit(1)
 		}
+		corpora = append(corpora, corpus)
 	}
 
 	var outWriter io.Writer
 	if outputFileName == "" {
 		outWriter = os.Stdout
-	} else {
-		var err error
-		outWriter, err = os.Create(outputFileName)
-		if err != nil {
-			fmt.Printf("Could not open %s for writing: %v", outputFileName, err)
-			os.Exit(1)
+	} else if !separateFiles {
+		outWriter = createNgramFile(outputFileName)
+	}
+
+	walkers, totalCounts := countWeightedCorpora(corpora, ngramLengths, ngramWords)
+	// a single length keeps the plain ngram, tab, frequency format that hillclimb reads
+	tagged := len(ngramLengths) > 1 && !separateFiles
+	for _, ngramLength := range uniqueNgramLengths(ngramLengths) {
+		writer := outWriter
+		if separateFiles {
+			file := createNgramFile(ngramOutputPath(outputFileName, ngramLength))
+			defer file.Close()
+			writer = file
+		}
+
+		prefix := ""
+		if tagged {
+			prefix = strconv.Itoa(ngramLength) + "\t"
+		}
+		chartPairs := make([]TrieWord[int], 0)
+		totalCount := totalCounts[ngramLength]
+		walkers[ngramLength](func(ngram string, count float64) bool {
+			if ngramSvgFile != "" {
+				chartPairs = append(chartPairs, TrieWord[int]{ngram, int(math.Round(count))})
+			}
+
+			_, err := writer.Write([]byte(fmt.Sprintf("%s%s\t%.16f\n", prefix, ngram, math.Log10(count/totalCount))))
+			if err != nil {
+				fmt.Printf("Could not write to file: %v\n", err)
+				os.Exit(1)
+			}
+			return true
+		})
+
+		if ngramSvgFile != "" {
+			labels, values := mostCommonNgrams(chartPairs, ngramChartSize)
+			writeSvgHistogramToFile(ngramOutputPath(ngramSvgFile, ngramLength), labels, values)
 		}
 	}
+}
 
-	trie, totalCount := readNgramsIntoTrie(inReader, ngramLength)
-	triePairs := make(chan trieWord)
-	go trie.feedWordsToChannel(triePairs)
-	for pair := range triePairs {
+// ngramWalker calls visit with each ngram and its count in alphabetical order, stopping if visit returns false.
+// Counts from merged corpora are weighted, so they aren't always whole numbers
+type ngramWalker func(visit func(ngram string, count float64) bool)
 
-		_, err := outWriter.Write([]byte(fmt.Sprintf("%s\t%.16f\n", pair.word, math.Log10(float64(pair.value.(int))/float64(totalCount)))))
-		if err != nil {
-			fmt.Printf("Could not write to file: %v\n", err)
-			os.Exit(1)
+// countWeightedCorpora counts the ngrams of every length in ngramLengths in each corpus. With more than one
+// corpus, each one's relative frequencies are mixed in proportion to its weight, scaled back up to the
+// total ngram count of all of them so the counts stay about the size of the real ones
+func countWeightedCorpora(corpora []weightedCorpus, ngramLengths []int, words bool) (map[int]ngramWalker, map[int]float64) {
+	if len(corpora) == 1 {
+		return countCorpusNgrams(corpora[0].name, ngramLengths, words)
+	}
+
+	merged := make(map[int]map[string]float64)
+	grandTotals := make(map[int]float64)
+	weightTotals := make(map[int]float64)
+	for _, length := range ngramLengths {
+		merged[length] = make(map[string]float64)
+	}
+	for _, corpus := range corpora {
+		walkers, totals := countCorpusNgrams(corpus.name, ngramLengths, words)
+		for length, walker := range walkers {
+			total := totals[length]
+			if total == 0 {
+				// a corpus too short for this length has nothing to add to the mix
+				continue
+			}
+			grandTotals[length] += total
+			weightTotals[length] += corpus.weight
+			lengthCounts := merged[length]
+			walker(func(ngram string, count float64) bool {
+				lengthCounts[ngram] += corpus.weight * count / total
+				return true
+			})
+		}
+	}
+
+	walkers := make(map[int]ngramWalker)
+	for length, lengthCounts := range merged {
+		for ngram, share := range lengthCounts {
+			lengthCounts[ngram] = share / weightTotals[length] * grandTotals[length]
 		}
+		walkers[length] = walkNgramCounts(lengthCounts)
 	}
+	return walkers, grandTotals
 }
 
-func readNgramsIntoTrie(inReader io.Reader, ngramSize int) (*trieNode, int) {
-	trie := newTrie()
-	scanner := NewNgramScanner(inReader, ngramSize, false)
-	totalNGrams := 0
+// countCorpusNgrams counts the letter ngrams, or word ngrams if words is set, of every length in ngramLengths
+// in the named corpus, leaving out any Project Gutenberg header and footer
+func countCorpusNgrams(name string, ngramLengths []int, words bool) (map[int]ngramWalker, map[int]float64) {
+	corpus, err := openCorpus(name)
+	if err != nil {
+		fmt.Printf("Error opening %s: %v\n", name, err)
+		os.Exit(1)
+	}
+	defer corpus.Close()
+	inReader := newGutenbergReader(corpus)
+
+	walkers := make(map[int]ngramWalker)
+	totals := make(map[int]float64)
+	if words {
+		counts, wordTotals := readWordNgrams(inReader, ngramLengths)
+		for length, lengthCounts := range counts {
+			walkers[length] = walkNgramCounts(lengthCounts)
+			totals[length] = float64(wordTotals[length])
+		}
+		return walkers, totals
+	}
+
+	tries, trieTotals := readMultipleNgramsIntoTries(inReader, ngramLengths)
+	for length, trie := range tries {
+		trie := trie
+		walkers[length] = func(visit func(ngram string, count float64) bool) {
+			trie.Walk(func(ngram string, count int) bool {
+				return visit(ngram, float64(count))
+			})
+		}
+		totals[length] = float64(trieTotals[length])
+	}
+	return walkers, totals
+}
+
+func createNgramFile(path string) *os.File {
+	file, err := os.Create(path)
+	if err != nil {
+		fmt.Printf("Could not open %s for writing: %v", path, err)
+		os.Exit(1)
+	}
+	return file
+}
+
+// ngramOutputPath puts the ngram length in place of %d in path
+func ngramOutputPath(path string, ngramLength int) string {
+	return strings.ReplaceAll(path, "%d", strconv.Itoa(ngramLength))
+}
 
+// uniqueNgramLengths drops repeated lengths, keeping the order they were asked for
+func uniqueNgramLengths(ngramLengths []int) []int {
+	seen := make(map[int]bool)
+	unique := make([]int, 0, len(ngramLengths))
+	for _, ngramLength := range ngramLengths {
+		if !seen[ngramLength] {
+			seen[ngramLength] = true
+			unique = append(unique, ngramLength)
+		}
+	}
+	return unique
+}
+
+// mostCommonNgrams sorts the ngram counts in pairs from most to least common and returns
+// the labels and counts for the top count of them
+func mostCommonNgrams(pairs []TrieWord[int], count int) ([]string, []int) {
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].value == pairs[j].value {
+			return pairs[i].word < pairs[j].word
+		}
+		return pairs[i].value > pairs[j].value
+	})
+	if count < len(pairs) {
+		pairs = pairs[:count]
+	}
+
+	labels := make([]string, 0, len(pairs))
+	values := make([]int, 0, len(pairs))
+	for _, pair := range pairs {
+		labels = append(labels, pair.word)
+		values = append(values, pair.value)
+	}
+	return labels, values
+}
+
+func readNgramsIntoTrie(inReader io.Reader, ngramSize int) (*TrieNode[int], int) {
+	tries, totals := readMultipleNgramsIntoTries(inReader, []int{ngramSize})
+	return tries[ngramSize], totals[ngramSize]
+}
+
+// readMultipleNgramsIntoTries counts the ngrams of every size in ngramSizes in one pass over inReader.
+// It keeps a window of the latest letters as long as the biggest size, and each new letter ends one
+// ngram of each size once there are enough letters, just as separate ngram scanners would find them
+func readMultipleNgramsIntoTries(inReader io.Reader, ngramSizes []int) (map[int]*TrieNode[int], map[int]int) {
+	tries := make(map[int]*TrieNode[int])
+	totals := make(map[int]int)
+	longest := 0
+	for _, size := range ngramSizes {
+		tries[size] = NewTrie[int]()
+		totals[size] = 0
+		if size > longest {
+			longest = size
+		}
+	}
+
+	window := make([]byte, 0, longest)
+	scanner := NewNgramScanner(inReader, 1, false)
 	for scanner.Scan() {
 		if scanner.Err() != nil {
 			fmt.Printf("Scanning error: %v\n", scanner.Err())
 		}
-		totalNGrams += 1
-		currentNgram := scanner.Text()
-		currentCount, isPresent := trie.getValueForString(currentNgram)
-		var err error
-		if isPresent {
-			err = trie.addValueForString(currentNgram, currentCount.(int)+1)
-		} else {
-			err = trie.addValueForString(currentNgram, 1)
+		if len(window) == longest {
+			copy(window, window[1:])
+			window = window[:longest-1]
 		}
-		if err != nil {
-			fmt.Printf("Could not add %s to trie: %v\n", currentNgram, err)
-			os.Exit(1)
+		window = append(window, scanner.Bytes()[0])
+
+		for size, trie := range tries {
+			if len(window) < size {
+				continue
+			}
+			totals[size]++
+			currentNgram := string(window[len(window)-size:])
+			// a missing ngram has the zero count, so this adds new ngrams as well
+			currentCount, _ := trie.Get(currentNgram)
+			err := trie.Add(currentNgram, currentCount+1)
+			if err != nil {
+				fmt.Printf("Could not add %s to trie: %v\n", currentNgram, err)
+				os.Exit(1)
+			}
+		}
+	}
+	return tries, totals
+}
+
+// readWordNgrams counts the runs of words of every size in ngramSizes in one pass over inReader, the way
+// readMultipleNgramsIntoTries does for letters. Word ngrams have spaces in them, so they're counted in maps
+// rather than tries
+func readWordNgrams(inReader io.Reader, ngramSizes []int) (map[int]map[string]int, map[int]int) {
+	counts := make(map[int]map[string]int)
+	totals := make(map[int]int)
+	longest := 0
+	for _, size := range ngramSizes {
+		counts[size] = make(map[string]int)
+		totals[size] = 0
+		if size > longest {
+			longest = size
+		}
+	}
+
+	window := make([]string, 0, longest)
+	scanner := bufio.NewScanner(inReader)
+	scanner.Split(scanWordTokens)
+	for scanner.Scan() {
+		if len(window) == longest {
+			copy(window, window[1:])
+			window = window[:longest-1]
+		}
+		window = append(window, scanner.Text())
+
+		for size, sizeCounts := range counts {
+			if len(window) < size {
+				continue
+			}
+			totals[size]++
+			sizeCounts[strings.Join(window[len(window)-size:], " ")]++
+		}
+	}
+	if scanner.Err() != nil {
+		fmt.Printf("Scanning error: %v\n", scanner.Err())
+	}
+	return counts, totals
+}
+
+// scanWordTokens is a bufio.SplitFunc for words: runs of letters in the active alphabet, folded to uppercase.
+// Apostrophes between letters are dropped and everything else separates words. Letters outside ASCII are
+// read the way the ngram scanner reads them
+func scanWordTokens(data []byte, atEOF bool) (int, []byte, error) {
+	token := make([]byte, 0)
+	start := 0
+	for index := 0; index < len(data); {
+		if !atEOF && !utf8.FullRune(data[index:]) {
+			// the rest of the rune hasn't been read yet
+			break
+		}
+		r, width := utf8.DecodeRune(data[index:])
+		before := len(token)
+		if token = appendFoldedRune(token, r); len(token) > before {
+			index += width
+			continue
+		}
+		if len(token) == 0 {
+			// still looking for the start of the word
+			index += width
+			start = index
+			continue
+		}
+
+		if r == '\'' {
+			rest := data[index+width:]
+			if !atEOF && !utf8.FullRune(rest) {
+				// whether the apostrophe is inside the word depends on what comes next
+				break
+			}
+			if next, _ := utf8.DecodeRune(rest); len(rest) > 0 && len(appendFoldedRune(nil, next)) > 0 {
+				index += width
+				continue
+			}
+		}
+		return index + width, token, nil
+	}
+
+	if atEOF && len(token) > 0 {
+		return len(data), token, nil
+	}
+	// ask for more data, starting from the beginning of the word
+	return start, nil, nil
+}
+
+// walkNgramCounts walks ngram counts kept in a map in alphabetical order
+func walkNgramCounts[N int | float64](counts map[string]N) ngramWalker {
+	return func(visit func(ngram string, count float64) bool) {
+		ngrams := make([]string, 0, len(counts))
+		for ngram := range counts {
+			ngrams = append(ngrams, ngram)
+		}
+		sort.Strings(ngrams)
+		for _, ngram := range ngrams {
+			if !visit(ngram, float64(counts[ngram])) {
+				return
+			}
 		}
 	}
-	return trie, totalNGrams
 }
 
 // ngramScanner is a Scanner implementation that returns subsequent chunks
-// of uppercase four-letter long words from a Reader, ignoring non alphabetic characters
+// of uppercase four-letter long words from a Reader, ignoring characters outside the active alphabet
 // Example: "Hello, you" would generate "HELL", "ELLO", "LLOY", "LOYO", "OYOU"
-// it embeds a Scanner that it passes off most implementations to
+// it embeds a Scanner that it passes off most implementations to. The text is read a rune at a time,
+// so UTF-8 letters are skipped whole, or read without their accents when stripAccents is set
 type ngramScanner struct {
 	ngramBuffer    []byte
 	scanner        *bufio.Scanner
 	foundError     error
 	bufSize        int
 	trustSafeInput bool
+	// the symbols from the last rune that haven't gone into the ngram yet, since ß is read as SS
+	pending []byte
 }
 
 func NewNgramScanner(reader io.Reader, size int, safeInput bool) *ngramScanner {
-	scanner := &ngramScanner{make([]byte, 0, size), bufio.NewScanner(reader), nil, size, safeInput}
-	scanner.scanner.Split(bufio.ScanBytes)
+	scanner := &ngramScanner{make([]byte, 0, size), bufio.NewScanner(reader), nil, size, safeInput, make([]byte, 0, 4)}
+	if safeInput {
+		// safe input is already in the alphabet, so there's nothing to decode
+		scanner.scanner.Split(bufio.ScanBytes)
+	} else {
+		scanner.scanner.Split(bufio.ScanRunes)
+	}
 	return scanner
 }
 
@@ -141,58 +448,56 @@ func (scanner *ngramScanner) Err() error {
 	return scanner.foundError
 }
 
-func (scanner *ngramScanner) Scan() bool {
-	// use the interior scanner to scan a byte at a time
-	moreToCome := scanner.scanner.Scan()
-	if !moreToCome {
-		if scanner.scanner.Err() != nil {
-			scanner.foundError = scanner.scanner.Err()
+// nextSymbol returns the next symbol of the text in the active alphabet, skipping everything else
+func (scanner *ngramScanner) nextSymbol() (byte, bool) {
+	for len(scanner.pending) == 0 {
+		if !scanner.scanner.Scan() {
+			if scanner.scanner.Err() != nil {
+				scanner.foundError = scanner.scanner.Err()
+			}
+			return 0, false
 		}
-		return false
-	}
-
-	// if the scanned bytes aren't letters, just keep going until they are
-	// if we've been told we can trust the input however, don't bother using the regex
-	if !scanner.trustSafeInput && !lettersRegex.Match(scanner.scanner.Bytes()) {
-		return scanner.Scan()
-	}
-
-	// the happy path. just advance the ngram
-	if len(scanner.ngramBuffer) == scanner.bufSize {
-		// all the other checks are in place, so at this point just move everyone over to the left
-		for index := 1; index < scanner.bufSize; index++ {
-			scanner.ngramBuffer[index-1] = scanner.ngramBuffer[index]
+		// if we've been told we can trust the input, don't bother checking it
+		if scanner.trustSafeInput {
+			return activeAlphabet.fold(scanner.scanner.Bytes()[0]), true
 		}
-		scanner.ngramBuffer[scanner.bufSize-1] = upperCaseByte(scanner.scanner.Bytes()[0])
-		return true
+		// invalid UTF-8 comes through as utf8.RuneError, which is skipped like any other non-letter
+		r, _ := utf8.DecodeRune(scanner.scanner.Bytes())
+		scanner.pending = appendFoldedRune(scanner.pending[:0], r)
 	}
 
-	// in this case, the buffer hasn't been filled yet
-	// fill up the buffer the first time
-	for len(scanner.ngramBuffer) < scanner.bufSize {
+	symbol := scanner.pending[0]
+	scanner.pending = scanner.pending[1:]
+	return symbol, true
+}
 
-		if !scanner.trustSafeInput && !lettersRegex.Match(scanner.scanner.Bytes()) {
-			// keep ignoring non-letter characters
-			scanner.scanner.Scan()
-			continue
+func (scanner *ngramScanner) Scan() bool {
+	for {
+		symbol, found := scanner.nextSymbol()
+		if !found {
+			if len(scanner.ngramBuffer) > 0 && len(scanner.ngramBuffer) < scanner.bufSize {
+				// the text wasn't long enough!
+				scanner.foundError = errors.New("Text was not long enough to make an ngram!")
+			}
+			return false
 		}
 
-		scanner.ngramBuffer = append(scanner.ngramBuffer, upperCaseByte(scanner.scanner.Bytes()[0]))
+		// the happy path. just advance the ngram
 		if len(scanner.ngramBuffer) == scanner.bufSize {
-			// if the new append makes it the right size
+			// move everyone over to the left
+			for index := 1; index < scanner.bufSize; index++ {
+				scanner.ngramBuffer[index-1] = scanner.ngramBuffer[index]
+			}
+			scanner.ngramBuffer[scanner.bufSize-1] = symbol
 			return true
 		}
 
-		scanned := scanner.scanner.Scan()
-		if !scanned {
-			if len(scanner.ngramBuffer) < scanner.bufSize {
-				// the text wasn't long enough!
-				scanner.foundError = errors.New("Text was not long enough to make an ngram!")
-			}
-			return false
+		// in this case, the buffer hasn't been filled yet
+		scanner.ngramBuffer = append(scanner.ngramBuffer, symbol)
+		if len(scanner.ngramBuffer) == scanner.bufSize {
+			return true
 		}
 	}
-	return true
 }
 
 func upperCaseByte(inByte byte) byte {
@@ -202,6 +507,13 @@ func upperCaseByte(inByte byte) byte {
 	return inByte
 }
 
+func lowerCaseByte(inByte byte) byte {
+	if isUppercaseAscii(inByte) {
+		return inByte + 32
+	}
+	return inByte
+}
+
 func (scanner *ngramScanner) Split(split bufio.SplitFunc) {
 	// this manages its own split function
 }
@@ -211,9 +523,15 @@ func (scanner *ngramScanner) Text() string {
 }
 
 func init() {
-	ngramsCmd.Flags().StringVarP(&corpusFileName, "corpus", "c", "", "path pointing to the source text. Use - for stdin")
+	ngramsCmd.Flags().StringArrayVarP(&corpusFileNames, "corpus", "c", nil, "path or http(s) URL pointing to the source text. Use - for stdin. Give it more than once to merge corpora, with an optional :weight after each")
 	ngramsCmd.MarkFlagRequired("corpus")
-	ngramsCmd.Flags().StringVarP(&outputFileName, "output", "o", "", "path for ngram frequency output file. defaults to stdout")
-	ngramsCmd.Flags().IntVarP(&ngramLength, "ngram-length", "n", 4, "the length of the ngrams to generate")
+	ngramsCmd.Flags().StringVarP(&outputFileName, "output-file", "o", "", "path for ngram frequency output file. defaults to stdout")
+	// --output was this flag's name before it became the global output format, so scripts that use it keep working
+	ngramsCmd.Flags().StringVarP(&outputFileName, "output", "", "", "path for ngram frequency output file")
+	ngramsCmd.Flags().MarkDeprecated("output", "use --output-file instead")
+	ngramsCmd.Flags().IntSliceVarP(&ngramLengths, "ngram-length", "n", []int{4}, "the length of the ngrams to generate. Several comma-separated lengths are counted in one pass")
+	ngramsCmd.Flags().StringVarP(&ngramSvgFile, "svg", "", "", "write an SVG histogram of the most common ngrams to this path")
+	ngramsCmd.Flags().BoolVarP(&ngramWords, "words", "w", false, "count runs of words, such as word pairs for -n 2, instead of runs of letters")
+	ngramsCmd.Flags().IntVarP(&ngramChartSize, "chart-size", "", 26, "the number of ngrams to include in the --svg histogram")
 	cryptogramCmd.AddCommand(ngramsCmd)
 }
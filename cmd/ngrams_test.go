@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"bufio"
+	"math"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -41,6 +45,36 @@ func TestNgramScanner(test *testing.T) {
 	}
 }
 
+func TestNgramScannerUnicode(test *testing.T) {
+	defer func(original bool) { stripAccents = original }(stripAccents)
+	tests := []struct {
+		input     string
+		strip     bool
+		ngramSize int
+		expected  []string
+	}{
+		{"Café", true, 2, []string{"CA", "AF", "FE"}},
+		{"Café", false, 2, []string{"CA", "AF"}},
+		{"Æsir ß", true, 3, []string{"AES", "ESI", "SIR", "IRS", "RSS"}},
+		{"ＡＢ ﬁ", true, 2, []string{"AB", "BF", "FI"}},
+		// Greek has no letters under it, and invalid UTF-8 is skipped rather than read as bytes
+		{"αβ\xffA\xc3Z", true, 2, []string{"AZ"}},
+		{"naïve", false, 1, []string{"N", "A", "V", "E"}},
+	}
+
+	for _, testCase := range tests {
+		stripAccents = testCase.strip
+		scanner := NewNgramScanner(strings.NewReader(testCase.input), testCase.ngramSize, false)
+		actuals := make([]string, 0)
+		for scanner.Scan() {
+			actuals = append(actuals, scanner.Text())
+		}
+		if strings.Join(actuals, " ") != strings.Join(testCase.expected, " ") || scanner.Err() != nil {
+			test.Errorf("Expected %q (stripping accents: %v) to scan to %v but got %v (%v)", testCase.input, testCase.strip, testCase.expected, actuals, scanner.Err())
+		}
+	}
+}
+
 func TestReadNgramsIntoTrie(test *testing.T) {
 	input := "attack a Tacky Norse horse"
 	expectedCounts := map[string]int{
@@ -70,7 +104,7 @@ func TestReadNgramsIntoTrie(test *testing.T) {
 	}
 
 	for ngram, expectedCount := range expectedCounts {
-		actualCount, wasPresent := trie.getValueForString(ngram)
+		actualCount, wasPresent := trie.Get(ngram)
 		if !wasPresent {
 			test.Errorf("Expected ngram %s in trie, but it was absent", ngram)
 		}
@@ -81,3 +115,125 @@ func TestReadNgramsIntoTrie(test *testing.T) {
 	}
 
 }
+
+func TestReadMultipleNgramsIntoTries(test *testing.T) {
+	input := "attack a Tacky Norse horse"
+	tries, totals := readMultipleNgramsIntoTries(strings.NewReader(input), []int{1, 2, 4})
+
+	// one pass has to find exactly what a separate scanner for each size would
+	for _, size := range []int{1, 2, 4} {
+		expected := make(map[string]int)
+		expectedTotal := 0
+		scanner := NewNgramScanner(strings.NewReader(input), size, false)
+		for scanner.Scan() {
+			expected[scanner.Text()]++
+			expectedTotal++
+		}
+
+		if totals[size] != expectedTotal {
+			test.Errorf("Expected %d ngrams of size %d but got %d", expectedTotal, size, totals[size])
+		}
+		if tries[size].Size() != len(expected) {
+			test.Errorf("Expected %d different ngrams of size %d but got %d", len(expected), size, tries[size].Size())
+		}
+		for ngram, count := range expected {
+			if actual, _ := tries[size].Get(ngram); actual != count {
+				test.Errorf("Expected %s to be counted %d times but got %d", ngram, count, actual)
+			}
+		}
+	}
+}
+
+func TestUniqueNgramLengths(test *testing.T) {
+	if unique := uniqueNgramLengths([]int{4, 2, 4, 3, 2}); len(unique) != 3 || unique[0] != 4 || unique[1] != 2 || unique[2] != 3 {
+		test.Errorf("Expected 4, 2, 3 but got %v", unique)
+	}
+	if path := ngramOutputPath("english-%d.txt", 3); path != "english-3.txt" {
+		test.Errorf("Expected english-3.txt but got %s", path)
+	}
+}
+
+func TestScanWordTokens(test *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"The cat sat.", []string{"THE", "CAT", "SAT"}},
+		{"  didn't--won't ", []string{"DIDNT", "WONT"}},
+		{"'quoted' 42 words'", []string{"QUOTED", "WORDS"}},
+		{"", []string{}},
+		{"Café crème brûlée", []string{"CAFE", "CREME", "BRULEE"}},
+		{"Straße l'œuvre", []string{"STRASSE", "LOEUVRE"}},
+	}
+	for _, testCase := range tests {
+		scanner := bufio.NewScanner(strings.NewReader(testCase.input))
+		// a tiny buffer makes words and apostrophes fall across reads
+		scanner.Buffer(make([]byte, 2), 16)
+		scanner.Split(scanWordTokens)
+		tokens := make([]string, 0)
+		for scanner.Scan() {
+			tokens = append(tokens, scanner.Text())
+		}
+		if strings.Join(tokens, " ") != strings.Join(testCase.expected, " ") || scanner.Err() != nil {
+			test.Errorf("Expected %q to scan to %v but got %v (%v)", testCase.input, testCase.expected, tokens, scanner.Err())
+		}
+	}
+}
+
+func TestReadWordNgrams(test *testing.T) {
+	counts, totals := readWordNgrams(strings.NewReader("The cat sat. The cat ran"), []int{1, 2})
+	if totals[1] != 6 || totals[2] != 5 {
+		test.Errorf("Expected 6 words and 5 pairs but got %v", totals)
+	}
+	if counts[1]["THE"] != 2 || counts[2]["THE CAT"] != 2 || counts[2]["SAT THE"] != 1 || len(counts[2]) != 4 {
+		test.Errorf("Expected THE and THE CAT twice among 4 pairs but got %v", counts)
+	}
+
+	walked := make([]string, 0)
+	walkNgramCounts(counts[2])(func(ngram string, count float64) bool {
+		walked = append(walked, ngram)
+		return len(walked) < 2
+	})
+	if strings.Join(walked, "|") != "CAT RAN|CAT SAT" {
+		test.Errorf("Expected the first two pairs alphabetically but got %v", walked)
+	}
+}
+
+func TestCountWeightedCorpora(test *testing.T) {
+	dir := test.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	os.WriteFile(first, []byte("aaab"), 0644)
+	os.WriteFile(second, []byte("bbbbbbbb"), 0644)
+
+	tests := []struct {
+		corpora  []weightedCorpus
+		expected map[string]float64
+	}{
+		// one corpus keeps its plain counts
+		{[]weightedCorpus{{first, 1}}, map[string]float64{"A": 3, "B": 1}},
+		// A is 3/4 of the first and none of the second, so 3/8 of the 12 letters
+		{[]weightedCorpus{{first, 1}, {second, 1}}, map[string]float64{"A": 4.5, "B": 7.5}},
+		// with the first weighted 3 to 1, A is 3/4 of 3/4
+		{[]weightedCorpus{{first, 3}, {second, 1}}, map[string]float64{"A": 6.75, "B": 5.25}},
+	}
+	for _, testCase := range tests {
+		walkers, totals := countWeightedCorpora(testCase.corpora, []int{1}, false)
+		counts := make(map[string]float64)
+		walkers[1](func(ngram string, count float64) bool {
+			counts[ngram] = count
+			return true
+		})
+		for ngram, expected := range testCase.expected {
+			if math.Abs(counts[ngram]-expected) > 1e-9 {
+				test.Errorf("Expected %s to count %f with %v but got %f", ngram, expected, testCase.corpora, counts[ngram])
+			}
+		}
+		if len(counts) != len(testCase.expected) {
+			test.Errorf("Expected %d ngrams but got %v", len(testCase.expected), counts)
+		}
+		if totals[1] != counts["A"]+counts["B"] {
+			test.Errorf("Expected the total %f to add up the counts %v", totals[1], counts)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+var ordinalBase int
+var ordinalWidth int
+var ordinalAValue int
+var useCodepoints bool
+
+// ordinalCmd represents the ordinal command
+var ordinalCmd = &cobra.Command{
+	Use:   "ordinal",
+	Short: "Converts between letters and their ordinal values in any base",
+	Long: `Puzzles often hide letters as numbers: A1Z26, binary strings, hex, and so on.
+	By default A is 1 and Z is 26; use --a-value 0 to start at 0 or --codepoints to use
+	the characters' Unicode code points instead. --base can be anything from 2 to 36.`,
+}
+
+var ordinalEncodeCmd = &cobra.Command{
+	Use:   "encode",
+	Short: "Prints the ordinal of each letter in the arguments",
+	Long: `Prints the ordinal of each letter in the arguments, in the base given by --base.
+	Use --width to zero-pad each value to a fixed number of digits.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  printOrdinalEncoding,
+}
+
+var ordinalDecodeCmd = &cobra.Command{
+	Use:   "decode",
+	Short: "Turns ordinals back into letters",
+	Long: `Turns ordinals back into letters. Values separated by spaces, commas, or other
+	punctuation are decoded one at a time. A single unbroken string of digits (like a bit string)
+	is split into groups of --width digits; if no width is given, every width that produces valid
+	letters is tried and printed.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  printOrdinalDecoding,
+}
+
+var ordinalSeparators = regexp.MustCompile("[^0-9A-Za-z]+")
+
+// checkOrdinalFlags rejects the --base and --width values strconv can't work with
+func checkOrdinalFlags(base, width int) error {
+	if base < 2 || base > 36 {
+		return fmt.Errorf("--base has to be from 2 to 36, not %d", base)
+	}
+	if width < 0 {
+		return fmt.Errorf("--width can't be negative")
+	}
+	return nil
+}
+
+func exitOnBadOrdinalFlags() {
+	if err := checkOrdinalFlags(ordinalBase, ordinalWidth); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func printOrdinalEncoding(cmd *cobra.Command, args []string) {
+	exitOnBadOrdinalFlags()
+	fmt.Println(strings.Join(encodeOrdinals(strings.Join(args, " "), ordinalBase, ordinalWidth, ordinalAValue, useCodepoints), " "))
+}
+
+func printOrdinalDecoding(cmd *cobra.Command, args []string) {
+	exitOnBadOrdinalFlags()
+	tokens := ordinalSeparators.Split(strings.TrimSpace(strings.Join(args, " ")), -1)
+	if len(tokens) > 1 {
+		decoded, err := decodeOrdinals(tokens, ordinalBase, ordinalAValue, useCodepoints)
+		if err != nil {
+			fmt.Printf("Could not decode: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(decoded)
+		return
+	}
+
+	widths := []int{ordinalWidth}
+	if ordinalWidth == 0 {
+		widths = detectOrdinalWidths(tokens[0], ordinalBase, ordinalAValue, useCodepoints)
+		if len(widths) == 0 {
+			fmt.Println("No grouping of the digits decodes to valid characters")
+			os.Exit(1)
+		}
+	}
+
+	for _, width := range widths {
+		decoded, err := decodeOrdinals(splitIntoGroups(tokens[0], width), ordinalBase, ordinalAValue, useCodepoints)
+		if err != nil {
+			fmt.Printf("Could not decode with width %d: %v\n", width, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%d: %s\n", width, decoded)
+	}
+}
+
+// encodeOrdinals converts each letter in input to its ordinal in base. Non-letters are skipped
+// unless codepoints is set, in which case every character is converted
+func encodeOrdinals(input string, base, width, aValue int, codepoints bool) []string {
+	ordinals := make([]string, 0, len(input))
+	for _, curRune := range input {
+		var value int
+		if codepoints {
+			value = int(curRune)
+		} else {
+			upperRune := unicode.ToUpper(curRune)
+			if upperRune < 'A' || upperRune > 'Z' {
+				continue
+			}
+			value = int(upperRune-'A') + aValue
+		}
+
+		ordinal := strings.ToUpper(strconv.FormatInt(int64(value), base))
+		if len(ordinal) < width {
+			ordinal = strings.Repeat("0", width-len(ordinal)) + ordinal
+		}
+		ordinals = append(ordinals, ordinal)
+	}
+	return ordinals
+}
+
+// decodeOrdinals turns each token into the character it represents, returning an error
+// for tokens that aren't valid numbers in base or are out of range
+func decodeOrdinals(tokens []string, base, aValue int, codepoints bool) (string, error) {
+	var decoded strings.Builder
+	for _, token := range tokens {
+		if token == "" {
+			continue
+		}
+		curRune, isValid := ordinalToRune(token, base, aValue, codepoints)
+		if !isValid {
+			return "", fmt.Errorf("%s is not a valid ordinal in base %d", token, base)
+		}
+		decoded.WriteRune(curRune)
+	}
+	return decoded.String(), nil
+}
+
+func ordinalToRune(token string, base, aValue int, codepoints bool) (rune, bool) {
+	value, err := strconv.ParseInt(token, base, 32)
+	if err != nil {
+		return 0, false
+	}
+
+	if codepoints {
+		return rune(value), unicode.IsPrint(rune(value))
+	}
+
+	index := int(value) - aValue
+	if index < 0 || index > 25 {
+		return 0, false
+	}
+	return rune('A' + index), true
+}
+
+// detectOrdinalWidths returns every group width that evenly divides digits and
+// where every group decodes to a valid character
+func detectOrdinalWidths(digits string, base, aValue int, codepoints bool) []int {
+	widths := make([]int, 0)
+WidthLoop:
+	for width := 1; width <= len(digits); width++ {
+		if len(digits)%width != 0 {
+			continue
+		}
+		for _, group := range splitIntoGroups(digits, width) {
+			if _, isValid := ordinalToRune(group, base, aValue, codepoints); !isValid {
+				continue WidthLoop
+			}
+		}
+		widths = append(widths, width)
+	}
+	return widths
+}
+
+// splitIntoGroups chops input into consecutive pieces of size width. The last piece
+// will be shorter if len(input) isn't a multiple of width
+func splitIntoGroups(input string, width int) []string {
+	groups := make([]string, 0, len(input)/width+1)
+	for start := 0; start < len(input); start += width {
+		end := start + width
+		if end > len(input) {
+			end = len(input)
+		}
+		groups = append(groups, input[start:end])
+	}
+	return groups
+}
+
+func init() {
+	ordinalCmd.PersistentFlags().IntVarP(&ordinalBase, "base", "b", 10, "the base of the ordinals, from 2 to 36")
+	ordinalCmd.PersistentFlags().IntVarP(&ordinalWidth, "width", "w", 0, "the number of digits in each ordinal. 0 means no padding when encoding and auto-detect when decoding")
+	ordinalCmd.PersistentFlags().IntVarP(&ordinalAValue, "a-value", "a", 1, "the ordinal assigned to A")
+	ordinalCmd.PersistentFlags().BoolVarP(&useCodepoints, "codepoints", "u", false, "use Unicode code points rather than alphabet positions")
+	ordinalCmd.AddCommand(ordinalEncodeCmd)
+	ordinalCmd.AddCommand(ordinalDecodeCmd)
+	rootCmd.AddCommand(ordinalCmd)
+}
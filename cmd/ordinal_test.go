@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+type ordinalTest struct {
+	input      string
+	base       int
+	width      int
+	aValue     int
+	codepoints bool
+	expected   string
+}
+
+func TestEncodeOrdinals(test *testing.T) {
+	tests := []ordinalTest{
+		ordinalTest{"Hi!", 10, 0, 1, false, "8 9"},
+		ordinalTest{"HI", 2, 5, 1, false, "01000 01001"},
+		ordinalTest{"AZ", 16, 0, 0, false, "0 19"},
+		ordinalTest{"Hi", 16, 0, 1, true, "48 69"},
+		ordinalTest{"Z", 3, 0, 1, false, "222"},
+	}
+
+	for index, testCase := range tests {
+		actual := strings.Join(encodeOrdinals(testCase.input, testCase.base, testCase.width, testCase.aValue, testCase.codepoints), " ")
+		if actual != testCase.expected {
+			test.Errorf("Test case %d: expected %s but got %s", index, testCase.expected, actual)
+		}
+	}
+}
+
+func TestDecodeOrdinals(test *testing.T) {
+	decoded, err := decodeOrdinals([]string{"01000", "01001"}, 2, 1, false)
+	if err != nil || decoded != "HI" {
+		test.Errorf("Expected HI but got %s (%v)", decoded, err)
+	}
+
+	decoded, err = decodeOrdinals([]string{"48", "69"}, 16, 1, true)
+	if err != nil || decoded != "Hi" {
+		test.Errorf("Expected Hi but got %s (%v)", decoded, err)
+	}
+
+	_, err = decodeOrdinals([]string{"27"}, 10, 1, false)
+	if err == nil {
+		test.Errorf("Expected an error for an out of range ordinal")
+	}
+}
+
+func TestDetectOrdinalWidths(test *testing.T) {
+	widths := detectOrdinalWidths("0100001001", 2, 1, false)
+	if len(widths) != 1 || widths[0] != 5 {
+		test.Errorf("Expected only width 5 but got %v", widths)
+	}
+
+	widths = detectOrdinalWidths("2315", 10, 1, false)
+	if len(widths) != 2 || widths[0] != 1 || widths[1] != 2 {
+		test.Errorf("Expected widths 1 and 2 but got %v", widths)
+	}
+}
+
+func TestCheckOrdinalFlags(test *testing.T) {
+	tests := []struct {
+		base    int
+		width   int
+		isValid bool
+	}{
+		{10, 0, true},
+		{2, 5, true},
+		{36, 2, true},
+		{40, 0, false},
+		{1, 0, false},
+		{10, -1, false},
+	}
+	for _, testCase := range tests {
+		if err := checkOrdinalFlags(testCase.base, testCase.width); (err == nil) != testCase.isValid {
+			test.Errorf("Expected base %d and width %d to be valid: %v but got %v", testCase.base, testCase.width, testCase.isValid, err)
+		}
+	}
+}
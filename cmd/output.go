@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// Results that other programs might read, such as the solvers' answers, can be written as a compact
+// text table for people, as JSON for machines, or both. The text table is kept short for anything
+// that pays by the character: it can be truncated to a number of rows and long cells are cut off,
+// with markers saying so. The JSON form always has everything.
+
+// outputFormat is the root --output flag. With json, commands collect their results and write them
+// as a single JSON object at the end instead of printing them as they go
+var outputFormat string
+
+// explainResults is the root --explain flag. Commands that can say why a result qualified print
+// their reasons under it in text mode, or in an explanation column in json mode
+var explainResults bool
+
+// the longest a cell can be in a text table before it's cut off
+const maxTableCellWidth = 60
+
+// resultTable is a set of results with named columns. truncated is set when the search behind
+// the results stopped early, such as when it ran out of time, so there may have been more
+type resultTable struct {
+	columns   []string
+	rows      [][]string
+	truncated bool
+}
+
+func newResultTable(columns ...string) *resultTable {
+	return &resultTable{columns: columns, rows: make([][]string, 0)}
+}
+
+// addRow adds a row of values, one for each column
+func (table *resultTable) addRow(values ...string) {
+	table.rows = append(table.rows, values)
+}
+
+// outputResponse writes table in the given format: text, json, or both (the table and then the JSON).
+// maxRows limits the rows in the text table; 0 means no limit
+func outputResponse(writer io.Writer, table *resultTable, format string, maxRows int) error {
+	switch format {
+	case "text":
+		return writeCompactTable(writer, table, maxRows)
+	case "json":
+		return writeJSONTable(writer, table)
+	case "both":
+		err := writeCompactTable(writer, table, maxRows)
+		if err != nil {
+			return err
+		}
+		return writeJSONTable(writer, table)
+	}
+	return fmt.Errorf("unknown output format %s; use text, json, or both", format)
+}
+
+// writeCompactTable writes the columns and rows lined up with tabwriter. If rows are left out,
+// the last line says how many
+func writeCompactTable(writer io.Writer, table *resultTable, maxRows int) error {
+	tabs := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tabs, strings.Join(table.columns, "\t"))
+
+	rows := table.rows
+	if maxRows > 0 && len(rows) > maxRows {
+		rows = rows[:maxRows]
+	}
+	for _, row := range rows {
+		cells := make([]string, 0, len(row))
+		for _, cell := range row {
+			// cut by runes so a long cell of accented letters isn't split partway through one
+			if letters := []rune(cell); len(letters) > maxTableCellWidth {
+				cell = string(letters[:maxTableCellWidth-3]) + "..."
+			}
+			cells = append(cells, cell)
+		}
+		fmt.Fprintln(tabs, strings.Join(cells, "\t"))
+	}
+	err := tabs.Flush()
+	if err != nil {
+		return err
+	}
+
+	if len(rows) < len(table.rows) {
+		_, err = fmt.Fprintf(writer, "... %d more rows\n", len(table.rows)-len(rows))
+	}
+	if err == nil && table.truncated {
+		_, err = fmt.Fprintln(writer, "... stopped early, so there may be more results")
+	}
+	return err
+}
+
+// jsonTable is how a resultTable is written as JSON
+type jsonTable struct {
+	Columns   []string            `json:"columns"`
+	Results   []map[string]string `json:"results"`
+	Total     int                 `json:"total"`
+	Truncated bool                `json:"truncated"`
+}
+
+// newJSONTable keys the rows as objects by column name, along with the row count and whether the
+// search stopped early
+func newJSONTable(table *resultTable) jsonTable {
+	results := make([]map[string]string, 0, len(table.rows))
+	for _, row := range table.rows {
+		results = append(results, jsonRow(table.columns, row))
+	}
+	return jsonTable{table.columns, results, len(results), table.truncated}
+}
+
+// writeJSONTable writes the table as a jsonTable
+func writeJSONTable(writer io.Writer, table *resultTable) error {
+	return json.NewEncoder(writer).Encode(newJSONTable(table))
+}
+
+// jsonRow keys a row's values by column name, the way they're written as JSON
+func jsonRow(columns []string, row []string) map[string]string {
+	result := make(map[string]string)
+	for index, column := range columns {
+		if index < len(row) {
+			result[column] = row[index]
+		}
+	}
+	return result
+}
+
+// resultPrinter is how commands print results so that --output json works everywhere. In text mode
+// results print as they're found, in whatever way suits the command; in json mode they're collected
+// and written as JSON by finish. Either way each result is counted for the history
+type resultPrinter struct {
+	out   io.Writer
+	json  bool
+	table *resultTable
+}
+
+// newResultPrinter creates a printer writing to stdout in the --output format, with columns for the JSON
+func newResultPrinter(columns ...string) *resultPrinter {
+	return &resultPrinter{os.Stdout, outputFormat == "json", newResultTable(columns...)}
+}
+
+// text prints a line that isn't a result, such as a heading, in text mode only
+func (printer *resultPrinter) text(format string, args ...interface{}) {
+	if !printer.json {
+		fmt.Fprintf(printer.out, format+"\n", args...)
+	}
+}
+
+// result prints line in text mode or keeps values, one for each column, in json mode
+func (printer *resultPrinter) result(line string, values ...string) {
+	recordResults(1)
+	if printer.json {
+		printer.table.addRow(values...)
+		return
+	}
+	fmt.Fprintln(printer.out, line)
+}
+
+// explained is result for commands that can explain their results. With --explain, each reason is
+// printed on its own indented line after line, or joined into the explanation column in json mode
+func (printer *resultPrinter) explained(line string, reasons []string, values ...string) {
+	if !explainResults {
+		printer.result(line, values...)
+		return
+	}
+	if !printer.json {
+		printer.result(line, values...)
+		for _, reason := range reasons {
+			fmt.Fprintf(printer.out, "\t%s\n", reason)
+		}
+		return
+	}
+
+	columns := printer.table.columns
+	if columns[len(columns)-1] != "explanation" {
+		printer.table.columns = append(columns, "explanation")
+	}
+	printer.result(line, append(values, strings.Join(reasons, "; "))...)
+}
+
+// finish writes the collected results in json mode, marking them truncated if the search stopped early
+func (printer *resultPrinter) finish(stoppedEarly bool) {
+	if !printer.json {
+		return
+	}
+	printer.table.truncated = stoppedEarly
+	if err := writeJSONTable(printer.out, printer.table); err != nil {
+		fmt.Fprintf(os.Stderr, "Could not write results: %v\n", err)
+		os.Exit(1)
+	}
+}
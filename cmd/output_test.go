@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestOutputResponse(test *testing.T) {
+	table := newResultTable("word", "score")
+	table.addRow("BEAST", "3")
+	table.addRow("BASSET", "12")
+	table.addRow(strings.Repeat("A", 70), "1")
+
+	var text bytes.Buffer
+	err := outputResponse(&text, table, "text", 2)
+	if err != nil {
+		test.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "word    score\nBEAST   3\nBASSET  12\n... 1 more rows\n"
+	if text.String() != expected {
+		test.Errorf("Expected %q but got %q", expected, text.String())
+	}
+
+	text.Reset()
+	outputResponse(&text, table, "text", 0)
+	if !strings.Contains(text.String(), strings.Repeat("A", 57)+"...") {
+		test.Errorf("Expected the long cell to be cut off but got %q", text.String())
+	}
+
+	accented := newResultTable("word")
+	accented.addRow(strings.Repeat("É", 70))
+	text.Reset()
+	outputResponse(&text, accented, "text", 0)
+	if !strings.Contains(text.String(), strings.Repeat("É", 57)+"...\n") || !utf8.ValidString(text.String()) {
+		test.Errorf("Expected the accented cell to be cut off after 57 letters but got %q", text.String())
+	}
+
+	var jsonOutput bytes.Buffer
+	err = outputResponse(&jsonOutput, table, "json", 1)
+	if err != nil {
+		test.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(jsonOutput.String(), `{"score":"12","word":"BASSET"}`) || !strings.Contains(jsonOutput.String(), `"total":3`) {
+		test.Errorf("Expected every row in the JSON but got %s", jsonOutput.String())
+	}
+
+	table.truncated = true
+	text.Reset()
+	outputResponse(&text, table, "text", 0)
+	if !strings.HasSuffix(text.String(), "... stopped early, so there may be more results\n") {
+		test.Errorf("Expected a truncated table to say so but got %q", text.String())
+	}
+	jsonOutput.Reset()
+	outputResponse(&jsonOutput, table, "json", 0)
+	if !strings.Contains(jsonOutput.String(), `"truncated":true`) {
+		test.Errorf("Expected truncated in the JSON but got %s", jsonOutput.String())
+	}
+
+	if outputResponse(&text, table, "xml", 0) == nil {
+		test.Errorf("Expected an error for an unknown format")
+	}
+}
+
+func TestResultPrinter(test *testing.T) {
+	var text bytes.Buffer
+	printer := &resultPrinter{&text, false, newResultTable("word", "score")}
+	printer.text("Results for %s", "BEAST")
+	printer.result("BEAST: 3", "BEAST", "3")
+	printer.finish(true)
+	if text.String() != "Results for BEAST\nBEAST: 3\n" {
+		test.Errorf("Expected the heading and the result's line but got %q", text.String())
+	}
+
+	var jsonOutput bytes.Buffer
+	printer = &resultPrinter{&jsonOutput, true, newResultTable("word", "score")}
+	printer.text("Results for %s", "BEAST")
+	printer.result("BEAST: 3", "BEAST", "3")
+	printer.finish(true)
+	expected := `{"columns":["word","score"],"results":[{"score":"3","word":"BEAST"}],"total":1,"truncated":true}` + "\n"
+	if jsonOutput.String() != expected {
+		test.Errorf("Expected just the JSON %q but got %q", expected, jsonOutput.String())
+	}
+}
+
+func TestResultPrinterExplained(test *testing.T) {
+	explainResults = true
+	defer func() { explainResults = false }()
+
+	var text bytes.Buffer
+	printer := &resultPrinter{&text, false, newResultTable("word")}
+	printer.explained("BEAST", []string{"B E A S T", "rank 3"}, "BEAST")
+	if text.String() != "BEAST\n\tB E A S T\n\trank 3\n" {
+		test.Errorf("Expected the result and its indented reasons but got %q", text.String())
+	}
+
+	var jsonOutput bytes.Buffer
+	printer = &resultPrinter{&jsonOutput, true, newResultTable("word")}
+	printer.explained("BEAST", []string{"B E A S T", "rank 3"}, "BEAST")
+	printer.explained("BATES", []string{"rank 9"}, "BATES")
+	printer.finish(false)
+	expected := `{"columns":["word","explanation"],"results":[{"explanation":"B E A S T; rank 3","word":"BEAST"},{"explanation":"rank 9","word":"BATES"}],"total":2,"truncated":false}` + "\n"
+	if jsonOutput.String() != expected {
+		test.Errorf("Expected %q but got %q", expected, jsonOutput.String())
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var palindromeMinLength int
+
+// palindromeCmd represents the palindrome command
+var palindromeCmd = &cobra.Command{
+	Use:   "palindrome [PHRASE...]",
+	Short: "Finds palindromes and words that spell other words backward",
+	Long: `Without a phrase, scans the dictionary for palindromes, like LEVEL, and for semordnilaps, pairs of words
+	that are each other backward, like DESSERTS and STRESSED, listing each pair once. Words need at least
+	--min-length letters, 3 by default, and the longest come first.
+
+	Given a phrase, says whether it reads the same backward, ignoring case, spaces, and punctuation, and exits
+	with 1 if it doesn't.
+
+	Example:
+	  puzzle_helper palindrome --dictionary words.txt
+	  puzzle_helper palindrome "A man, a plan, a canal: Panama!"`,
+	Run: printPalindromes,
+}
+
+// reversalPair is a dictionary word and the word it is backward, which is itself for a palindrome
+type reversalPair struct {
+	word     string
+	reversal string
+}
+
+func printPalindromes(cmd *cobra.Command, args []string) {
+	if len(args) > 0 {
+		phrase := strings.Join(args, " ")
+		if !isPalindrome(phrase) {
+			fmt.Printf("%s is not a palindrome\n", phrase)
+			os.Exit(1)
+		}
+		fmt.Printf("%s is a palindrome\n", phrase)
+		return
+	}
+
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+	palindromes, pairs := findReversals(rootTrie, palindromeMinLength)
+
+	printer := newResultPrinter("word", "reversal")
+	printer.text("Palindromes:")
+	for _, pair := range palindromes {
+		printer.result("  "+pair.word, pair.word, pair.reversal)
+	}
+	printer.text("Reversals:")
+	for _, pair := range pairs {
+		printer.result(fmt.Sprintf("  %s %s", pair.word, pair.reversal), pair.word, pair.reversal)
+	}
+	printer.finish(false)
+}
+
+// isPalindrome reports whether the letters and digits of phrase read the same in both directions, ignoring case
+func isPalindrome(phrase string) bool {
+	characters := make([]rune, 0, len(phrase))
+	for _, character := range strings.ToUpper(phrase) {
+		if (character >= 'A' && character <= 'Z') || (character >= '0' && character <= '9') {
+			characters = append(characters, character)
+		}
+	}
+	if len(characters) == 0 {
+		return false
+	}
+	for front, back := 0, len(characters)-1; front < back; front, back = front+1, back-1 {
+		if characters[front] != characters[back] {
+			return false
+		}
+	}
+	return true
+}
+
+// findReversals finds the dictionary's palindromes and the pairs of different words that are each other backward,
+// of at least minLength letters. Each pair is listed once, with the word that comes first alphabetically first,
+// and both lists are sorted longest first and then alphabetically
+func findReversals(rootTrie *trieNode, minLength int) ([]reversalPair, []reversalPair) {
+	palindromes := make([]reversalPair, 0)
+	pairs := make([]reversalPair, 0)
+	rootTrie.Walk(func(word string, value interface{}) bool {
+		if len(word) < minLength {
+			return true
+		}
+		reversal := reverseString(word)
+		if reversal == word {
+			palindromes = append(palindromes, reversalPair{word, word})
+		} else if _, present := rootTrie.Get(reversal); present && word < reversal {
+			pairs = append(pairs, reversalPair{word, reversal})
+		}
+		return true
+	})
+
+	for _, list := range [][]reversalPair{palindromes, pairs} {
+		sort.Slice(list, func(i, j int) bool {
+			if len(list[i].word) != len(list[j].word) {
+				return len(list[i].word) > len(list[j].word)
+			}
+			return list[i].word < list[j].word
+		})
+	}
+	return palindromes, pairs
+}
+
+func init() {
+	palindromeCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+	palindromeCmd.MarkFlagRequired("dictionary")
+	palindromeCmd.Flags().IntVarP(&palindromeMinLength, "min-length", "", 3, "The fewest letters a word can have")
+	rootCmd.AddCommand(palindromeCmd)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestIsPalindrome(test *testing.T) {
+	tests := []struct {
+		phrase   string
+		expected bool
+	}{
+		{"A man, a plan, a canal: Panama!", true},
+		{"racecar", true},
+		{"Was it a car or a cat I saw?", true},
+		{"12321", true},
+		{"palindrome", false},
+		{"ab", false},
+		{"!?", false},
+	}
+	for _, currentTest := range tests {
+		if actual := isPalindrome(currentTest.phrase); actual != currentTest.expected {
+			test.Errorf("Expected %v for %s but got %v", currentTest.expected, currentTest.phrase, actual)
+		}
+	}
+}
+
+func TestFindReversals(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"LEVEL", "STRESSED", "DESSERTS", "POTS", "STOP", "TOPS", "SPOT", "EYE", "DAD", "NOON", "AA"} {
+		trie.Add(word, nil)
+	}
+	palindromes, pairs := findReversals(trie, 3)
+
+	described := make([]string, 0)
+	for _, pair := range palindromes {
+		described = append(described, pair.word)
+	}
+	// AA is too short
+	if actual, expected := strings.Join(described, ","), "LEVEL,NOON,DAD,EYE"; actual != expected {
+		test.Errorf("Expected palindromes %s but got %s", expected, actual)
+	}
+
+	described = described[:0]
+	for _, pair := range pairs {
+		described = append(described, fmt.Sprintf("%s/%s", pair.word, pair.reversal))
+	}
+	if actual, expected := strings.Join(described, ","), "DESSERTS/STRESSED,POTS/STOP,SPOT/TOPS"; actual != expected {
+		test.Errorf("Expected reversals %s but got %s", expected, actual)
+	}
+}
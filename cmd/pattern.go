@@ -0,0 +1,131 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// patternCmd represents the pattern command
+var patternCmd = &cobra.Command{
+	Use:   "pattern PATTERN...",
+	Short: "Lists the dictionary words that fit a crossword-style pattern, with ? for unknown letters",
+	Long: `Finds every word in the dictionary that has the known letters of the pattern in place and any
+	letter where the pattern has a ? (or a .). Giving several patterns lists the matches for each.
+	Matches are listed alphabetically, or from the most to the least common word for a dictionary of
+	word<TAB>frequency lines.
+
+	Examples:
+	  puzzle_helper pattern C?T?? --dictionary words.txt
+	  puzzle_helper pattern "..ZZ.." "Q???" --dictionary words.txt`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  printPatternMatches,
+}
+
+func printPatternMatches(cmd *cobra.Command, args []string) {
+	patterns := make([]string, 0, len(args))
+	for _, arg := range args {
+		pattern, err := normalizeWordPattern(arg)
+		if err != nil {
+			fmt.Printf("Invalid pattern: %v\n", err)
+			os.Exit(1)
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+	byFrequency := hasWordFrequencies(rootTrie)
+	printer := newResultPrinter("pattern", "word")
+	for _, pattern := range patterns {
+		for _, word := range patternMatches(rootTrie, pattern, byFrequency) {
+			line := word
+			if len(patterns) > 1 {
+				line = pattern + ": " + word
+			}
+			printer.result(line, pattern, word)
+		}
+	}
+	printer.finish(false)
+}
+
+// patternMatches is the words in rootTrie that fit pattern, in alphabetical order or by frequency
+func patternMatches(rootTrie *trieNode, pattern string, byFrequency bool) []string {
+	matches := rootTrie.MatchPattern(pattern)
+	if byFrequency {
+		sortWordsByFrequency(rootTrie, matches)
+	}
+	return matches
+}
+
+// sortWordsByFrequency orders words from the most to the least common, keeping the order of ties
+func sortWordsByFrequency(rootTrie *trieNode, words []string) {
+	sort.SliceStable(words, func(i, j int) bool {
+		return combinedFrequency(rootTrie, words[i:i+1]) > combinedFrequency(rootTrie, words[j:j+1])
+	})
+}
+
+// normalizeWordPattern uppercases pattern and reads . as ?, so it's ready for MatchPattern
+func normalizeWordPattern(pattern string) (string, error) {
+	normalized := activeAlphabet.foldString(strings.ToUpper(strings.ReplaceAll(pattern, ".", "?")))
+	for _, symbol := range []byte(normalized) {
+		if symbol != '?' && dictionaryAlphabet.index(symbol) < 0 {
+			return "", fmt.Errorf("%s should be letters and ?s, such as C?T??", pattern)
+		}
+	}
+	if normalized == "" {
+		return "", fmt.Errorf("the pattern is empty")
+	}
+	return normalized, nil
+}
+
+// runPatternSolver is the pattern command for the solver registry
+func runPatternSolver(ctx context.Context, input solverInput) (*resultTable, error) {
+	pattern, err := normalizeWordPattern(input.getString("pattern"))
+	if err != nil {
+		return nil, err
+	}
+	rootTrie, _, err := openDictionaryTrie(input.getList("dictionary")...)
+	if err != nil {
+		return nil, err
+	}
+	table := newResultTable("word")
+	for _, word := range patternMatches(rootTrie, pattern, hasWordFrequencies(rootTrie)) {
+		table.addRow(word)
+	}
+	return table, nil
+}
+
+func init() {
+	patternCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+	patternCmd.MarkFlagRequired("dictionary")
+	rootCmd.AddCommand(patternCmd)
+
+	mustRegisterSolver(&solver{
+		name:        "pattern",
+		description: "Dictionary words that fit a crossword-style pattern such as C?T??, with ? for unknown letters",
+		parameters: []solverParameter{
+			solverParameter{name: "pattern", kind: solverString, description: "the known letters, with ? (or .) for each unknown one", required: true},
+			solverParameter{name: "dictionary", kind: solverString, description: "path to the dictionary file, or several separated by commas", required: true},
+		},
+		run: runPatternSolver,
+	})
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestNormalizeWordPattern(test *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected string
+		valid    bool
+	}{
+		{"c?t??", "C?T??", true},
+		{"..ZZ..", "??ZZ??", true},
+		{"C T", "", false},
+		{"C*T", "", false},
+		{"", "", false},
+	}
+	for _, testCase := range tests {
+		normalized, err := normalizeWordPattern(testCase.pattern)
+		if (err == nil) != testCase.valid || normalized != testCase.expected {
+			test.Errorf("Expected %q to normalize to %q (valid %v) but got %q, %v", testCase.pattern, testCase.expected, testCase.valid, normalized, err)
+		}
+	}
+
+	previous := dictionaryAlphabet
+	defer func() { dictionaryAlphabet = previous }()
+	dictionaryAlphabet, _ = dictionaryAlphabetWith("'")
+	if normalized, err := normalizeWordPattern("don't"); err != nil || normalized != "DON'T" {
+		test.Errorf("Expected don't to normalize to DON'T with ' as a word symbol but got %q, %v", normalized, err)
+	}
+	if _, err := dictionaryAlphabetWith("?"); err == nil {
+		test.Errorf("Expected ? to be refused as a word symbol")
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var phraseLetters string
+
+// phraseCmd represents the phrase command
+var phraseCmd = &cobra.Command{
+	Use:   "phrase ENUMERATION|PATTERN",
+	Short: "Finds words and phrases in the dictionary that fit an enumeration and any known letters",
+	Long: `Searches a dictionary that can have phrases in it, one per line, for entries whose word lengths
+	match the enumeration, such as (3,4) or 5-3. Spaces and hyphens both count as word breaks, and
+	punctuation inside words is ignored. Known letters can be given with --letters, using ? for the
+	unknown ones, or the enumeration can be given as a pattern such as "?A? D???" instead.
+
+	Examples:
+	  puzzle_helper phrase "(3,4)" --letters "?A?D???" --dictionary phrases.txt
+	  puzzle_helper phrase "?A? D???" --dictionary phrases.txt`,
+	Args: cobra.ExactArgs(1),
+	Run:  findPhrases,
+}
+
+// phraseQuery is an enumeration with the letters known so far, all run together, with ? for unknowns
+type phraseQuery struct {
+	enumeration []int
+	letters     string
+}
+
+func findPhrases(cmd *cobra.Command, args []string) {
+	if len(dictionaryFiles) == 0 {
+		fmt.Println("A dictionary file is required for finding phrases")
+		os.Exit(1)
+	}
+	query, err := parsePhraseQuery(args[0], phraseLetters)
+	if err != nil {
+		fmt.Printf("Invalid search: %v\n", err)
+		os.Exit(1)
+	}
+
+	entries := make(chan string)
+	go func() {
+		feedDictionaryPaths(entries, dictionaryFiles...)
+	}()
+
+	ctx, cancel := solveContext()
+	defer cancel()
+	printer := newResultPrinter("phrase")
+	for _, phrase := range searchPhrases(ctx, entries, query) {
+		printer.result(phrase, phrase)
+	}
+	printer.finish(ctx.Err() != nil)
+}
+
+// parsePhraseQuery reads either an enumeration, with letters giving what's known, or a pattern of known
+// letters and ?s whose words give the enumeration
+func parsePhraseQuery(search string, letters string) (phraseQuery, error) {
+	var enumeration []int
+	if strings.ContainsAny(search, "?.") || strings.IndexFunc(search, isPatternLetter) >= 0 {
+		if letters != "" {
+			return phraseQuery{}, fmt.Errorf("give either a pattern or --letters, not both")
+		}
+		for _, word := range phraseWords(search, true) {
+			enumeration = append(enumeration, len(word))
+		}
+		letters = search
+	} else {
+		var err error
+		enumeration, err = parseEnumeration(search)
+		if err != nil {
+			return phraseQuery{}, err
+		}
+	}
+
+	total := 0
+	for _, length := range enumeration {
+		total += length
+	}
+	known := strings.Join(phraseWords(letters, true), "")
+	if known == "" {
+		known = strings.Repeat("?", total)
+	}
+	if len(known) != total {
+		return phraseQuery{}, fmt.Errorf("the letters %s don't fit an answer of %d letters", letters, total)
+	}
+	return phraseQuery{enumeration, known}, nil
+}
+
+func isPatternLetter(symbol rune) bool {
+	return (symbol >= 'A' && symbol <= 'Z') || (symbol >= 'a' && symbol <= 'z')
+}
+
+// phraseWords splits text into its words at spaces and hyphens, uppercased with everything but letters
+// taken out. With wildcards, ? and . are kept (as ?) for unknown letters
+func phraseWords(text string, wildcards bool) []string {
+	words := make([]string, 0, 2)
+	for _, token := range strings.FieldsFunc(text, func(separator rune) bool {
+		return separator == ' ' || separator == '-'
+	}) {
+		word := make([]byte, 0, len(token))
+		for _, symbol := range []byte(strings.ToUpper(token)) {
+			if isUppercaseAscii(symbol) {
+				word = append(word, symbol)
+			} else if wildcards && (symbol == '?' || symbol == '.') {
+				word = append(word, '?')
+			}
+		}
+		if len(word) > 0 {
+			words = append(words, string(word))
+		}
+	}
+	return words
+}
+
+// matches reports whether a dictionary entry has the query's word lengths and known letters
+func (query phraseQuery) matches(entry string) bool {
+	words := phraseWords(entry, false)
+	if len(words) != len(query.enumeration) {
+		return false
+	}
+	for index, word := range words {
+		if len(word) != query.enumeration[index] {
+			return false
+		}
+	}
+	letters := strings.Join(words, "")
+	for index := range []byte(letters) {
+		if query.letters[index] != '?' && query.letters[index] != letters[index] {
+			return false
+		}
+	}
+	return true
+}
+
+// searchPhrases returns the entries that match the query, in dictionary order and without repeats
+func searchPhrases(ctx context.Context, entries chan string, query phraseQuery) []string {
+	found := make([]string, 0)
+	seen := make(map[string]bool)
+	for entry := range entries {
+		if ctx.Err() != nil {
+			continue
+		}
+		entry = strings.TrimSpace(entry)
+		if !seen[entry] && query.matches(entry) {
+			seen[entry] = true
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+// runPhraseSolver is the phrase command for the solver registry
+func runPhraseSolver(ctx context.Context, input solverInput) (*resultTable, error) {
+	query, err := parsePhraseQuery(input.getString("search"), input.getString("letters"))
+	if err != nil {
+		return nil, err
+	}
+	entries := make(chan string)
+	failed := make(chan error, 1)
+	go func() {
+		failed <- sendDictionaryPaths(entries, input.getList("dictionary")...)
+	}()
+
+	phrases := searchPhrases(ctx, entries, query)
+	if err := <-failed; err != nil {
+		return nil, err
+	}
+	table := newResultTable("phrase")
+	for _, phrase := range phrases {
+		table.addRow(phrase)
+	}
+	return table, nil
+}
+
+func init() {
+	phraseCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, with one word or phrase per line, or - to use stdin. Repeat it or separate files with commas to merge several")
+	phraseCmd.MarkFlagRequired("dictionary")
+	phraseCmd.Flags().StringVarP(&phraseLetters, "letters", "l", "", "the letters known so far, with ? for unknown ones, such as ?A?D???")
+	rootCmd.AddCommand(phraseCmd)
+
+	mustRegisterSolver(&solver{
+		name:        "phrase",
+		description: "Dictionary words and phrases that fit an enumeration and any known letters",
+		parameters: []solverParameter{
+			solverParameter{name: "search", kind: solverString, description: "an enumeration such as (3,4), or a pattern such as ?A? D???", required: true},
+			solverParameter{name: "letters", kind: solverString, description: "the letters known so far, with ? for unknown ones"},
+			solverParameter{name: "dictionary", kind: solverString, description: "the dictionary file to search, or several separated by commas", required: true},
+		},
+		run: runPhraseSolver,
+	})
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParsePhraseQuery(test *testing.T) {
+	tests := []struct {
+		search              string
+		letters             string
+		expectedEnumeration []int
+		expectedLetters     string
+	}{
+		{"(3,4)", "", []int{3, 4}, "???????"},
+		{"3,4", "?a?d???", []int{3, 4}, "?A?D???"},
+		{"5-3", "", []int{5, 3}, "????????"},
+		{"?A? D???", "", []int{3, 4}, "?A?D???"},
+		{"s.d-d..s", "", []int{3, 4}, "S?DD??S"},
+	}
+	for _, testCase := range tests {
+		query, err := parsePhraseQuery(testCase.search, testCase.letters)
+		if err != nil {
+			test.Errorf("Unexpected error parsing %s: %v", testCase.search, err)
+			continue
+		}
+		if !intSlicesEqual(query.enumeration, testCase.expectedEnumeration) || query.letters != testCase.expectedLetters {
+			test.Errorf("Expected %v with %s but got %v with %s", testCase.expectedEnumeration, testCase.expectedLetters, query.enumeration, query.letters)
+		}
+	}
+
+	badQueries := [][]string{{"(3,4)", "??"}, {"?A?", "?A?"}, {"(0)", ""}, {"", ""}}
+	for _, bad := range badQueries {
+		if _, err := parsePhraseQuery(bad[0], bad[1]); err == nil {
+			test.Errorf("Expected an error for %v", bad)
+		}
+	}
+}
+
+func TestSearchPhrases(test *testing.T) {
+	dictionary := []string{"CAT NAP", "BAD DREAM", "BIG DEAL", "BAD-TEMPERED", "ROCK 'N' ROLL", "SAD DOGS", "BIG DEAL"}
+	tests := []struct {
+		search   string
+		expected []string
+	}{
+		{"(3,4)", []string{"BIG DEAL", "SAD DOGS"}},
+		{"?A? D???", []string{"SAD DOGS"}},
+		{"(4,1,4)", []string{"ROCK 'N' ROLL"}},
+		{"(3-8)", []string{"BAD-TEMPERED"}},
+		{"(6)", []string{}},
+	}
+	for _, testCase := range tests {
+		entries := make(chan string)
+		go func() {
+			for _, entry := range dictionary {
+				entries <- entry
+			}
+			close(entries)
+		}()
+		query, _ := parsePhraseQuery(testCase.search, "")
+		found := searchPhrases(context.Background(), entries, query)
+		if strings.Join(found, "|") != strings.Join(testCase.expected, "|") {
+			test.Errorf("Expected %v for %s but got %v", testCase.expected, testCase.search, found)
+		}
+	}
+}
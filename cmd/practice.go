@@ -0,0 +1,602 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var practiceRounds int
+var practiceTextFile string
+var practiceSeed int64
+var practiceTimesFile string
+
+const (
+	practiceCaesar     = "caesar"
+	practiceAristocrat = "aristocrat"
+	practiceAnagram    = "anagram"
+)
+
+var practiceKinds = []string{practiceCaesar, practiceAristocrat, practiceAnagram}
+
+// commands understood while a puzzle is being played; anything else is a guess
+const (
+	practiceHintCommand   = "hint"
+	practiceRevealCommand = "reveal"
+	practiceQuitCommand   = "quit"
+)
+
+// how long the solver gets to come up with an aristocrat hint
+const practiceHintTimeout = 10 * time.Second
+
+// the words anagrams are drawn from are this long
+const minPracticeAnagramLength = 5
+const maxPracticeAnagramLength = 8
+
+// practiceTexts are the plaintexts puzzles are made from when there's no --text-file
+var practiceTexts = []string{
+	"The only thing we have to fear is fear itself.",
+	"Not all those who wander are lost.",
+	"It was the best of times, it was the worst of times.",
+	"To be, or not to be, that is the question.",
+	"All that glitters is not gold; often have you heard that told.",
+	"I think, therefore I am.",
+	"The journey of a thousand miles begins with a single step.",
+	"In the middle of difficulty lies opportunity.",
+	"Brevity is the soul of wit.",
+	"A house divided against itself cannot stand.",
+	"Genius is one percent inspiration and ninety-nine percent perspiration.",
+	"We are such stuff as dreams are made on, and our little life is rounded with a sleep.",
+	"The quick brown fox jumps over the lazy dog while the farmer sleeps.",
+	"Ask not what your country can do for you; ask what you can do for your country.",
+	"Whether you think you can, or you think you can't, you're right.",
+	"An investment in knowledge pays the best interest.",
+	"Well done is better than well said.",
+	"Time you enjoy wasting is not wasted time.",
+	"Tell me and I forget. Teach me and I remember. Involve me and I learn.",
+	"The secret of getting ahead is getting started.",
+}
+
+// practiceCmd represents the practice command
+var practiceCmd = &cobra.Command{
+	Use:   "practice [caesar|aristocrat|anagram]",
+	Short: "Plays generated puzzles in the terminal, with hints and solve times, as training",
+	Long: `Makes up a puzzle, shows it, and waits for guesses. Caesar and aristocrat puzzles are made from
+	--text-file (one plaintext per line) or a built-in list of quotations, and anagrams from the words
+	in --dictionary or in those texts. Without a kind, each round picks one at random.
+
+	Type a guess to check it, hint for the next hint, reveal to give up and see the answer, and quit
+	to stop. The hints get stronger as you ask for more, and come from the same code as the solving
+	commands: letter counts and the caesar scorer, the aristocrat footholds and hint's hillclimb
+	consensus, and the dictionary for anagrams.
+
+	Each round is timed, and --times-file keeps the results so personal bests carry over between
+	sessions.
+
+	Examples:
+	  puzzle_helper practice aristocrat
+	  puzzle_helper practice --rounds 5 --times-file ~/.puzzle_helper_times.json`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: practiceKinds,
+	Run:       runPractice,
+}
+
+// practicePuzzle is one generated puzzle: what's shown, the answer, and how guesses and hints work
+type practicePuzzle struct {
+	kind   string
+	text   string
+	answer string
+	// check says whether guess is the answer, and if not, how close it was
+	check func(guess string) (bool, string)
+	// hint returns the hint for level, counting from 1, or false when there are none left
+	hint func(level int) (string, bool)
+}
+
+// practiceResult is how one round went, as kept in --times-file
+type practiceResult struct {
+	Kind    string    `json:"kind"`
+	Solved  bool      `json:"solved"`
+	Seconds float64   `json:"seconds"`
+	Hints   int       `json:"hints"`
+	At      time.Time `json:"at"`
+}
+
+// practiceSession is a run of practice rounds
+type practiceSession struct {
+	editor *lineEditor
+	out    io.Writer
+	random *rand.Rand
+	texts  []string
+	// words are the candidate anagrams, and wordSet says what counts as a word in guesses
+	words   []string
+	wordSet map[string]bool
+	// results holds earlier results from --times-file followed by this session's
+	results []practiceResult
+	// previous is how many of results came from the file
+	previous int
+	now      func() time.Time
+	// frequencyMap scores caesar shifts and aristocrat hints, loaded the first time it's needed
+	frequencyMap *ngramFrequencyMap
+}
+
+func runPractice(cmd *cobra.Command, args []string) {
+	kind := ""
+	if len(args) == 1 {
+		kind = strings.ToLower(args[0])
+		if !isPracticeKind(kind) {
+			fmt.Printf("Unknown puzzle kind %s; use %s\n", args[0], strings.Join(practiceKinds, ", "))
+			os.Exit(1)
+		}
+	}
+
+	texts := practiceTexts
+	if practiceTextFile != "" {
+		contents, err := os.ReadFile(practiceTextFile)
+		if err != nil {
+			fmt.Printf("Could not read %s: %v\n", practiceTextFile, err)
+			os.Exit(1)
+		}
+		texts = nonEmptyLines(string(contents))
+		if len(texts) == 0 {
+			fmt.Printf("There are no texts in %s\n", practiceTextFile)
+			os.Exit(1)
+		}
+	}
+
+	seed := practiceSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	session := newPracticeSession(newLineEditor(os.Stdout, "guess> ", []string{practiceHintCommand, practiceRevealCommand, practiceQuitCommand}),
+		os.Stdout, rand.New(rand.NewSource(seed)), texts)
+	if len(dictionaryFiles) > 0 {
+		session.setWords(readWordSet(dictionaryFiles...))
+	}
+	if practiceTimesFile != "" {
+		results, err := readPracticeResults(practiceTimesFile)
+		if err != nil {
+			fmt.Printf("Could not read %s: %v\n", practiceTimesFile, err)
+			os.Exit(1)
+		}
+		session.results = results
+		session.previous = len(results)
+	}
+
+	for round := 1; practiceRounds == 0 || round <= practiceRounds; round++ {
+		roundKind := kind
+		if roundKind == "" {
+			roundKind = practiceKinds[session.random.Intn(len(practiceKinds))]
+		}
+		puzzle, err := session.generate(roundKind)
+		if err != nil {
+			fmt.Printf("Could not make a puzzle: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(session.out, "\nPuzzle %d (%s):\n  %s\n", round, puzzle.kind, puzzle.text)
+		result, quit := session.play(puzzle)
+		if practiceTimesFile != "" {
+			if err := appendPracticeResult(practiceTimesFile, result); err != nil {
+				fmt.Printf("Could not save the time to %s: %v\n", practiceTimesFile, err)
+			}
+		}
+		if quit {
+			break
+		}
+	}
+	session.printSummary()
+}
+
+func newPracticeSession(editor *lineEditor, out io.Writer, random *rand.Rand, texts []string) *practiceSession {
+	session := &practiceSession{editor: editor, out: out, random: random, texts: texts, now: time.Now}
+	// the texts' own words do for anagrams until there's a dictionary
+	words := make(map[string]bool)
+	for _, text := range texts {
+		for _, word := range strings.Fields(text) {
+			if letters := string(justUppercaseLetters(word)); letters != "" {
+				words[letters] = true
+			}
+		}
+	}
+	session.setWords(words)
+	return session
+}
+
+// setWords makes wordSet what counts as a word and picks out the ones long enough to be anagrams
+func (session *practiceSession) setWords(words map[string]bool) {
+	session.wordSet = words
+	session.words = make([]string, 0)
+	for word := range words {
+		if len(word) >= minPracticeAnagramLength && len(word) <= maxPracticeAnagramLength && lettersRegex.MatchString(word) {
+			session.words = append(session.words, word)
+		}
+	}
+	// map order is random, and the same seed should make the same puzzles
+	sort.Strings(session.words)
+}
+
+// generate makes a puzzle of the given kind
+func (session *practiceSession) generate(kind string) (*practicePuzzle, error) {
+	switch kind {
+	case practiceCaesar:
+		return session.caesarPuzzle(strings.ToUpper(session.randomText())), nil
+	case practiceAristocrat:
+		return session.aristocratPuzzle(strings.ToUpper(session.randomText())), nil
+	case practiceAnagram:
+		if len(session.words) == 0 {
+			return nil, fmt.Errorf("there are no words of %d to %d letters to make anagrams from", minPracticeAnagramLength, maxPracticeAnagramLength)
+		}
+		return session.anagramPuzzle(session.words[session.random.Intn(len(session.words))]), nil
+	}
+	return nil, fmt.Errorf("unknown puzzle kind %s", kind)
+}
+
+func (session *practiceSession) randomText() string {
+	return session.texts[session.random.Intn(len(session.texts))]
+}
+
+func (session *practiceSession) caesarPuzzle(plainText string) *practicePuzzle {
+	shift := 1 + session.random.Intn(upperRing.size()-1)
+	cipherText := shiftText(plainText, shift, []*symbolRing{upperRing})
+	firstWord := strings.Fields(plainText)[0]
+	return &practicePuzzle{
+		kind:   practiceCaesar,
+		text:   cipherText,
+		answer: plainText,
+		check:  checkPracticeLetters(plainText),
+		hint: func(level int) (string, bool) {
+			switch level {
+			case 1:
+				return fmt.Sprintf("Every letter is shifted the same amount. The most common letter is %c; in English that's usually E", mostCommonLetter(cipherText)), true
+			case 2:
+				shifts := caesarShifts(cipherText, upperRing.size(), []*symbolRing{upperRing})
+				scoreCaesarShifts(shifts, ngramScorer(session.scoringMap()))
+				return fmt.Sprintf("The caesar scorer likes a shift of %d best", shifts[0].shift), true
+			case 3:
+				return fmt.Sprintf("The first word is %s", justUppercaseLetters(firstWord)), true
+			}
+			return "", false
+		},
+	}
+}
+
+func (session *practiceSession) aristocratPuzzle(plainText string) *practicePuzzle {
+	// no letter is left as itself, as in competition aristocrats
+	key := session.random.Perm(upperRing.size())
+	for hasFixedPoint(key) {
+		key = session.random.Perm(upperRing.size())
+	}
+	encrypted := []byte(plainText)
+	for index, curByte := range encrypted {
+		if isUppercaseAscii(curByte) {
+			encrypted[index] = byte(ASCII_A + key[curByte-ASCII_A])
+		}
+	}
+	cipherText := string(encrypted)
+
+	// the solver's hints build on each other, so the letters it has given so far are kept
+	known := make(map[byte]byte)
+	return &practicePuzzle{
+		kind:   practiceAristocrat,
+		text:   cipherText,
+		answer: plainText,
+		check:  checkPracticeLetters(plainText),
+		hint: func(level int) (string, bool) {
+			if level == 1 {
+				if suggestions := suggestAristocratMappings(analyzeAristocrat(cipherText)); len(suggestions) > 0 {
+					return fmt.Sprintf("%s might be %s (%s)", suggestions[0].cipher, suggestions[0].plain, suggestions[0].reason), true
+				}
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), practiceHintTimeout)
+			defer cancel()
+			hint, err := findHint(ctx, string(justUppercaseLetters(cipherText)), session.scoringMap(), known, hintRuns)
+			if err != nil {
+				return "", false
+			}
+			known[hint.cipher] = hint.plain
+			return fmt.Sprintf("The solver says %c=%c (%d of %d runs agree)", hint.cipher, hint.plain, hint.agreement, hint.runs), true
+		},
+	}
+}
+
+func (session *practiceSession) anagramPuzzle(word string) *practicePuzzle {
+	letters := []byte(word)
+	shuffled := word
+	// a word like EERIE has few orders, but none of them take long to find
+	for attempt := 0; shuffled == word && attempt < 100; attempt++ {
+		session.random.Shuffle(len(letters), func(i, j int) { letters[i], letters[j] = letters[j], letters[i] })
+		shuffled = string(letters)
+	}
+	sorted := sortedLetters(word)
+	return &practicePuzzle{
+		kind:   practiceAnagram,
+		text:   shuffled,
+		answer: word,
+		check: func(guess string) (bool, string) {
+			guessed := string(justUppercaseLetters(guess))
+			if sortedLetters(guessed) != sorted {
+				return false, fmt.Sprintf("%s doesn't use the letters %s", guessed, shuffled)
+			}
+			if guessed == word || session.wordSet[guessed] {
+				return true, ""
+			}
+			return false, fmt.Sprintf("%s isn't in the dictionary", guessed)
+		},
+		hint: func(level int) (string, bool) {
+			switch level {
+			case 1:
+				count := 0
+				for candidate := range session.wordSet {
+					if sortedLetters(candidate) == sorted {
+						count++
+					}
+				}
+				if count == 1 {
+					return "Only one dictionary word uses exactly these letters", true
+				}
+				return fmt.Sprintf("%d dictionary words use exactly these letters", count), true
+			case 2:
+				return fmt.Sprintf("It starts with %c", word[0]), true
+			case 3:
+				return fmt.Sprintf("It's %c%s%c", word[0], strings.Repeat("_", len(word)-2), word[len(word)-1]), true
+			case 4:
+				half := (len(word) + 1) / 2
+				return fmt.Sprintf("It's %s%s", word[:half], strings.Repeat("_", len(word)-half)), true
+			}
+			return "", false
+		},
+	}
+}
+
+// play takes guesses until the puzzle is solved or given up, and reports how it went.
+// The second value is true if the player wants to stop practicing
+func (session *practiceSession) play(puzzle *practicePuzzle) (practiceResult, bool) {
+	started := session.now()
+	result := practiceResult{Kind: puzzle.kind, At: started}
+	finish := func() {
+		result.Seconds = session.now().Sub(started).Seconds()
+		session.results = append(session.results, result)
+	}
+
+	for {
+		line, err := session.editor.readLine()
+		if err == io.EOF {
+			finish()
+			return result, true
+		}
+		guess := strings.TrimSpace(line)
+		switch strings.ToLower(guess) {
+		case "":
+			continue
+		case practiceHintCommand:
+			hint, more := puzzle.hint(result.Hints + 1)
+			if !more {
+				fmt.Fprintf(session.out, "No more hints; %s shows the answer\n", practiceRevealCommand)
+				continue
+			}
+			result.Hints++
+			fmt.Fprintf(session.out, "Hint %d: %s\n", result.Hints, hint)
+		case practiceRevealCommand:
+			finish()
+			fmt.Fprintf(session.out, "The answer was %s\n", puzzle.answer)
+			return result, false
+		case practiceQuitCommand:
+			finish()
+			fmt.Fprintf(session.out, "The answer was %s\n", puzzle.answer)
+			return result, true
+		default:
+			solved, feedback := puzzle.check(guess)
+			if !solved {
+				fmt.Fprintf(session.out, "Not yet: %s\n", feedback)
+				continue
+			}
+			result.Solved = true
+			finish()
+			fmt.Fprintf(session.out, "Solved in %s with %d hints%s\n", formatPracticeTime(result.Seconds), result.Hints, session.bestNote(result))
+			return result, false
+		}
+	}
+}
+
+// bestNote says how a solve compares to the best earlier one of its kind
+func (session *practiceSession) bestNote(result practiceResult) string {
+	best, found := bestPracticeTime(session.results[:len(session.results)-1], result.Kind)
+	if !found {
+		return ""
+	}
+	if result.Seconds < best {
+		return fmt.Sprintf(", a new best (was %s)", formatPracticeTime(best))
+	}
+	return fmt.Sprintf("; the best is %s", formatPracticeTime(best))
+}
+
+// scoringMap is the built-in tetragram table, smoothed the usual way
+func (session *practiceSession) scoringMap() *ngramFrequencyMap {
+	if session.frequencyMap == nil {
+		frequencyMap, err := loadFrequencyMap("")
+		if err != nil {
+			panic(fmt.Sprintf("the built-in tetragram table is unreadable: %v", err))
+		}
+		session.frequencyMap = frequencyMap
+	}
+	return session.frequencyMap
+}
+
+// printSummary lists how many puzzles of each kind were solved this session and how fast
+func (session *practiceSession) printSummary() {
+	played := session.results[session.previous:]
+	if len(played) == 0 {
+		return
+	}
+	fmt.Fprintln(session.out, "\nThis session:")
+	for _, kind := range practiceKinds {
+		total, attempts, solved, hints := 0.0, 0, 0, 0
+		for _, result := range played {
+			if result.Kind != kind {
+				continue
+			}
+			attempts++
+			if result.Solved {
+				total += result.Seconds
+				solved++
+				hints += result.Hints
+			}
+		}
+		if attempts == 0 {
+			continue
+		}
+		line := fmt.Sprintf("  %-10s solved %d of %d", kind, solved, attempts)
+		if solved > 0 {
+			best, _ := bestPracticeTime(played, kind)
+			line += fmt.Sprintf(", averaging %s with %.1f hints (best %s)", formatPracticeTime(total/float64(solved)),
+				float64(hints)/float64(solved), formatPracticeTime(best))
+		}
+		fmt.Fprintln(session.out, line)
+	}
+}
+
+// checkPracticeLetters checks guesses at the plaintext by their letters alone, counting how many
+// are in the right place when the guess is wrong
+func checkPracticeLetters(plainText string) func(string) (bool, string) {
+	answer := justUppercaseLetters(plainText)
+	return func(guess string) (bool, string) {
+		guessed := justUppercaseLetters(guess)
+		right := 0
+		for index := 0; index < len(guessed) && index < len(answer); index++ {
+			if guessed[index] == answer[index] {
+				right++
+			}
+		}
+		if right == len(answer) && len(guessed) == len(answer) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("%d of %d letters right", right, len(answer))
+	}
+}
+
+// bestPracticeTime is the fastest solve of kind in results
+func bestPracticeTime(results []practiceResult, kind string) (float64, bool) {
+	best, found := 0.0, false
+	for _, result := range results {
+		if result.Kind == kind && result.Solved && (!found || result.Seconds < best) {
+			best, found = result.Seconds, true
+		}
+	}
+	return best, found
+}
+
+func formatPracticeTime(seconds float64) string {
+	return (time.Duration(seconds*float64(time.Second)) / time.Second * time.Second).String()
+}
+
+func mostCommonLetter(text string) byte {
+	counts := frequencyCountInString(text)
+	var common byte
+	for _, letter := range []byte(upperAlphabet) {
+		if counts[letter] > counts[common] {
+			common = letter
+		}
+	}
+	return common
+}
+
+func isPracticeKind(kind string) bool {
+	for _, known := range practiceKinds {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
+
+func hasFixedPoint(permutation []int) bool {
+	for index, value := range permutation {
+		if index == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedLetters(word string) string {
+	letters := []byte(word)
+	sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+	return string(letters)
+}
+
+func nonEmptyLines(text string) []string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	return lines
+}
+
+// readPracticeResults reads the results kept in path, one JSON object per line. A missing file has none
+func readPracticeResults(path string) ([]practiceResult, error) {
+	contents, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	results := make([]practiceResult, 0)
+	for _, line := range nonEmptyLines(string(contents)) {
+		var result practiceResult
+		// a bad line shouldn't lose the rest of the times
+		if json.Unmarshal([]byte(line), &result) == nil {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// appendPracticeResult adds result to the end of the file at path, creating it if needed
+func appendPracticeResult(path string, result practiceResult) error {
+	line, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+func init() {
+	practiceCmd.Flags().IntVarP(&practiceRounds, "rounds", "n", 0, "how many puzzles to play. 0 keeps going until quit")
+	practiceCmd.Flags().StringVarP(&practiceTextFile, "text-file", "t", "", "plaintexts to make caesar and aristocrat puzzles from, one per line. Defaults to built-in quotations")
+	practiceCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "words to make anagrams from and to accept as answers. Defaults to the words of the texts. Repeat it or separate files with commas to merge several")
+	practiceCmd.Flags().Int64VarP(&practiceSeed, "seed", "", 0, "seed for making puzzles, to get the same ones again. 0 picks one at random")
+	practiceCmd.Flags().StringVarP(&practiceTimesFile, "times-file", "", "", "keep solve times in this file, so bests carry over between sessions")
+	rootCmd.AddCommand(practiceCmd)
+}
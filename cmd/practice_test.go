@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestPracticeSession plays input as the guesses, with the clock moving ten seconds each time it is read
+func newTestPracticeSession(input string, out *bytes.Buffer) *practiceSession {
+	editor := &lineEditor{in: bufio.NewReader(strings.NewReader(input)), out: ioutil.Discard, prompt: "guess> "}
+	session := newPracticeSession(editor, out, rand.New(rand.NewSource(1)), []string{"Well done is better than well said."})
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	session.now = func() time.Time {
+		clock = clock.Add(10 * time.Second)
+		return clock
+	}
+	return session
+}
+
+func TestPracticePuzzles(test *testing.T) {
+	var out bytes.Buffer
+	session := newTestPracticeSession("", &out)
+	plainText := "WELL DONE IS BETTER THAN WELL SAID."
+
+	caesar := session.caesarPuzzle(plainText)
+	if caesar.text == plainText || substitutionPattern(caesar.text) != substitutionPattern(plainText) {
+		test.Errorf("Expected a shifted version of %s but got %s", plainText, caesar.text)
+	}
+	if solved, _ := caesar.check("well done is better than well said"); !solved {
+		test.Errorf("Expected the plaintext in lowercase without punctuation to solve the caesar")
+	}
+	if solved, feedback := caesar.check("WELL DONE IS BETTER THAN WELL PAID"); solved || feedback != "27 of 28 letters right" {
+		test.Errorf("Expected 27 of 28 letters right but got %v, %s", solved, feedback)
+	}
+
+	aristocrat := session.aristocratPuzzle(plainText)
+	if substitutionPattern(aristocrat.text) != substitutionPattern(plainText) || !strings.HasSuffix(aristocrat.text, ".") {
+		test.Errorf("Expected %s to keep the letter pattern and punctuation of %s", aristocrat.text, plainText)
+	}
+	for index := range plainText {
+		if isUppercaseAscii(plainText[index]) && aristocrat.text[index] == plainText[index] {
+			test.Errorf("Expected no letter of %s to stand for itself", aristocrat.text)
+		}
+	}
+
+	anagram := session.anagramPuzzle("BETTER")
+	if anagram.text == "BETTER" || sortedLetters(anagram.text) != sortedLetters("BETTER") {
+		test.Errorf("Expected BETTER shuffled but got %s", anagram.text)
+	}
+	if solved, feedback := anagram.check("bettor"); solved || !strings.Contains(feedback, "doesn't use the letters") {
+		test.Errorf("Expected BETTOR to be the wrong letters but got %v, %s", solved, feedback)
+	}
+	if hint, _ := anagram.hint(3); hint != "It's B____R" {
+		test.Errorf("Expected the first and last letters but got %s", hint)
+	}
+	if _, more := anagram.hint(5); more {
+		test.Errorf("Expected anagrams to run out of hints")
+	}
+	if len(session.words) != 1 || session.words[0] != "BETTER" {
+		test.Errorf("Expected the text's words of 5 to 8 letters for anagrams but got %v", session.words)
+	}
+}
+
+func TestPracticePlay(test *testing.T) {
+	var out bytes.Buffer
+	session := newTestPracticeSession("hint\nbettor\n\nbetter\nhint\nreveal\n", &out)
+
+	result, quit := session.play(session.anagramPuzzle("BETTER"))
+	if !result.Solved || quit || result.Hints != 1 || result.Seconds != 10 {
+		test.Errorf("Expected a solve in 10 seconds with one hint but got %+v", result)
+	}
+	result, quit = session.play(session.anagramPuzzle("BETTER"))
+	if result.Solved || quit || result.Hints != 1 {
+		test.Errorf("Expected a reveal after a hint but got %+v", result)
+	}
+	if _, quit = session.play(session.anagramPuzzle("BETTER")); !quit {
+		test.Errorf("Expected the end of input to quit")
+	}
+
+	expected := []string{
+		"Hint 1: Only one dictionary word uses exactly these letters",
+		"Not yet: BETTOR doesn't use the letters",
+		"Solved in 10s with 1 hints",
+		"The answer was BETTER",
+	}
+	for _, line := range expected {
+		if !strings.Contains(out.String(), line) {
+			test.Errorf("Expected the output to include %q but got %s", line, out.String())
+		}
+	}
+
+	out.Reset()
+	session.printSummary()
+	if !strings.Contains(out.String(), "anagram    solved 1 of 3, averaging 10s with 1.0 hints (best 10s)") {
+		test.Errorf("Expected one of three anagrams solved in the summary but got %s", out.String())
+	}
+}
+
+func TestPracticeResults(test *testing.T) {
+	path := filepath.Join(test.TempDir(), "times.json")
+	if results, err := readPracticeResults(path); err != nil || len(results) != 0 {
+		test.Errorf("Expected no results from a missing file but got %v, %v", results, err)
+	}
+	appendPracticeResult(path, practiceResult{Kind: practiceCaesar, Solved: true, Seconds: 40})
+	appendPracticeResult(path, practiceResult{Kind: practiceCaesar, Solved: false, Seconds: 5})
+	appendPracticeResult(path, practiceResult{Kind: practiceCaesar, Solved: true, Seconds: 25})
+	results, err := readPracticeResults(path)
+	if err != nil || len(results) != 3 {
+		test.Fatalf("Expected 3 results but got %v, %v", results, err)
+	}
+	if best, found := bestPracticeTime(results, practiceCaesar); !found || best != 25 {
+		test.Errorf("Expected a best of 25 seconds, skipping the unsolved round, but got %v", best)
+	}
+	if _, found := bestPracticeTime(results, practiceAnagram); found {
+		test.Errorf("Expected no best time for a kind that hasn't been solved")
+	}
+
+	var out bytes.Buffer
+	session := newTestPracticeSession("better\n", &out)
+	session.results = results
+	session.previous = len(results)
+	session.play(session.anagramPuzzle("BETTER"))
+	session.results = append(session.results[:session.previous], practiceResult{Kind: practiceCaesar, Solved: true, Seconds: 20})
+	if note := session.bestNote(session.results[len(session.results)-1]); note != ", a new best (was 25s)" {
+		test.Errorf("Expected a new best but got %q", note)
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// embeddedTetragrams is tetragrams-en-us.txt from the top of the repo, sorted, rounded to four
+// decimal places, and gzipped, so that the solvers that score by ngrams work without a frequency file
+//
+//go:embed data/tetragrams-en-us.txt.gz
+var embeddedTetragrams []byte
+
+var embeddedFrequencyMapOnce sync.Once
+var embeddedFrequencyMapValue *ngramFrequencyMap
+
+// embeddedFrequencyMap returns the built-in English tetragram frequencies, reading them the first time
+func embeddedFrequencyMap() *ngramFrequencyMap {
+	embeddedFrequencyMapOnce.Do(func() {
+		reader, err := gzip.NewReader(bytes.NewReader(embeddedTetragrams))
+		if err != nil {
+			// the table is built in, so this can only happen if the build itself is broken
+			panic(fmt.Sprintf("the built-in tetragram table is unreadable: %v", err))
+		}
+		embeddedFrequencyMapValue, _, err = populateFrequencyMapFromReader(reader)
+		if err != nil {
+			panic(fmt.Sprintf("the built-in tetragram table is unreadable: %v", err))
+		}
+	})
+	// scoring uses the ngram size of the last table read, which might not have been this one
+	ngramSize = 4
+	return embeddedFrequencyMapValue
+}
+
+// loadFrequencyMap reads the ngram frequency file at path, or stdin for -, or falls back to the built-in
+// English tetragrams when there's no path. The frequencies are smoothed as --smoothing says
+func loadFrequencyMap(path string) (*ngramFrequencyMap, error) {
+	if path == "" {
+		return embeddedFrequencyMap().smoothed(ngramSmoothing, ngramSmoothingK)
+	}
+
+	var reader io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		reader = file
+	}
+	frequencyMap, skipped, err := populateFrequencyMapFromReader(reader)
+	if len(skipped) > 0 {
+		// stdout may be carrying results, such as for serve mcp, so this goes to stderr
+		fmt.Fprintf(os.Stderr, "Skipped lines of frequency file %s:\n  %s\n", path, strings.Join(skipped, "\n  "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return frequencyMap.smoothed(ngramSmoothing, ngramSmoothingK)
+}
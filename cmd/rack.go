@@ -0,0 +1,205 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var rackHook string
+var rackHookPosition int
+
+// rackSize is the most tiles a Scrabble rack holds, and a word that uses all of them earns rackBingoBonus
+const rackSize = 7
+const rackBingoBonus = 50
+
+// scrabbleTileValues is the points of each letter's tile in English Scrabble. Blanks are worth nothing
+var scrabbleTileValues = map[byte]int{
+	'A': 1, 'B': 3, 'C': 3, 'D': 2, 'E': 1, 'F': 4, 'G': 2, 'H': 4, 'I': 1, 'J': 8, 'K': 5, 'L': 1, 'M': 3,
+	'N': 1, 'O': 1, 'P': 3, 'Q': 10, 'R': 1, 'S': 1, 'T': 1, 'U': 1, 'V': 4, 'W': 4, 'X': 8, 'Y': 4, 'Z': 10,
+}
+
+// rackCmd represents the rack command
+var rackCmd = &cobra.Command{
+	Use:   "rack LETTERS",
+	Short: "Finds and scores the words a Scrabble rack can play",
+	Long: `Lists the words that can be made from up to seven tiles, where ? is a blank, best scoring first.
+	Words are scored by their tiles' face values, without board premiums, plus 50 for using all seven tiles;
+	letters played with a blank are lowercase and score nothing. --hook gives a letter already on the board that
+	the word has to play through, and --hook-position says where in the word it has to be, counting from 1.
+	The hook letter's points count too, since it's part of the word.
+
+	Example:
+	  puzzle_helper rack RETAINS --dictionary words.txt
+	  puzzle_helper rack QIT?E --hook U --hook-position 2`,
+	Args: cobra.ExactArgs(1),
+	Run:  printRackWords,
+}
+
+// rackRequest is a rack to find words for, with the board letter they have to use if hook isn't 0
+type rackRequest struct {
+	letterCounts map[string]int
+	tiles        int
+	hook         byte
+	hookPosition int
+}
+
+// rackWord is a playable word, shown with the letters played by blanks in lowercase
+type rackWord struct {
+	word   string
+	shown  string
+	points int
+}
+
+func printRackWords(cmd *cobra.Command, args []string) {
+	request, err := newRackRequest(args[0], rackHook, rackHookPosition)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+
+	printer := newResultPrinter("word", "points")
+	for _, found := range findRackWords(rootTrie, request) {
+		printer.result(fmt.Sprintf("%s %d", found.shown, found.points), found.shown, strconv.Itoa(found.points))
+	}
+	printer.finish(false)
+}
+
+// newRackRequest checks a rack of letters and blanks and an optional hook letter and its 1-based position
+func newRackRequest(rack string, hook string, hookPosition int) (rackRequest, error) {
+	letterCounts := createLetterCountsMap(rack)
+	tiles := tilesLeft(letterCounts)
+	if tiles == 0 || tiles > rackSize {
+		return rackRequest{}, fmt.Errorf("a rack has 1 to %d tiles, but %s has %d", rackSize, rack, tiles)
+	}
+
+	request := rackRequest{letterCounts: letterCounts, tiles: tiles, hookPosition: hookPosition}
+	if hook != "" {
+		letters := justUppercaseLetters(hook)
+		if len(letters) != 1 || len(hook) != 1 {
+			return rackRequest{}, fmt.Errorf("the hook should be a single letter, but got %s", hook)
+		}
+		request.hook = letters[0]
+	}
+	if hookPosition < 0 || (hookPosition > 0 && request.hook == 0) {
+		return rackRequest{}, fmt.Errorf("--hook-position needs a --hook and a position of at least 1")
+	}
+	return request, nil
+}
+
+// findRackWords finds the words of at least two letters that the rack can play, using the hook exactly once if there
+// is one. When a word can be played more than one way, the best scoring way is kept. The words are sorted by points,
+// most first, and then alphabetically
+func findRackWords(rootTrie *trieNode, request rackRequest) []rackWord {
+	best := make(map[string]rackWord)
+	collectRackWords(rootTrie, request, request.letterCounts, request.hook == 0, "", "", 0, best)
+
+	words := make([]rackWord, 0, len(best))
+	for _, found := range best {
+		words = append(words, found)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if words[i].points != words[j].points {
+			return words[i].points > words[j].points
+		}
+		return words[i].word < words[j].word
+	})
+	return words
+}
+
+func collectRackWords(node *trieNode, request rackRequest, letterCounts map[string]int, hookUsed bool, currentWord string, shown string, points int, best map[string]rackWord) {
+	if hookUsed && len(currentWord) >= 2 && node.IsWord() {
+		total := points
+		if tilesLeft(letterCounts) == 0 && request.tiles == rackSize {
+			total += rackBingoBonus
+		}
+		if previous, present := best[currentWord]; !present || total > previous.points {
+			best[currentWord] = rackWord{currentWord, shown, total}
+		}
+	}
+
+	for _, child := range node.Children() {
+		letter := child.Letter()
+		value := scrabbleTileValues[letter[0]]
+		if !hookUsed && letter[0] == request.hook && (request.hookPosition == 0 || request.hookPosition == len(currentWord)+1) {
+			collectRackWords(child, request, letterCounts, true, currentWord+letter, shown+letter, points+value, best)
+		}
+		if request.hookPosition == len(currentWord)+1 && !hookUsed {
+			// the board letter has to go here
+			continue
+		}
+		if letterCounts[letter] > 0 {
+			collectRackWords(child, request, decrementLetterCounts(letter, letterCounts), hookUsed, currentWord+letter, shown+letter, points+value, best)
+		}
+		if letterCounts[blankLetter] > 0 {
+			collectRackWords(child, request, decrementLetterCounts(blankLetter, letterCounts), hookUsed, currentWord+letter, shown+strings.ToLower(letter), points, best)
+		}
+	}
+}
+
+// tilesLeft is the number of tiles still in letterCounts
+func tilesLeft(letterCounts map[string]int) int {
+	tiles := 0
+	for _, count := range letterCounts {
+		tiles += count
+	}
+	return tiles
+}
+
+// runRackSolver is rack for the solver registry
+func runRackSolver(ctx context.Context, input solverInput) (*resultTable, error) {
+	request, err := newRackRequest(input.getString("letters"), input.getString("hook"), input.getInt("hook_position"))
+	if err != nil {
+		return nil, err
+	}
+	rootTrie, _, err := openDictionaryTrie(input.getList("dictionary")...)
+	if err != nil {
+		return nil, err
+	}
+	table := newResultTable("word", "points")
+	for _, found := range findRackWords(rootTrie, request) {
+		table.addRow(found.shown, strconv.Itoa(found.points))
+	}
+	return table, nil
+}
+
+func init() {
+	rackCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+	rackCmd.MarkFlagRequired("dictionary")
+	rackCmd.Flags().StringVarP(&rackHook, "hook", "", "", "A letter on the board that words have to play through")
+	rackCmd.Flags().IntVarP(&rackHookPosition, "hook-position", "", 0, "Where the hook letter has to be in the word, counting from 1. Defaults to anywhere")
+	rootCmd.AddCommand(rackCmd)
+
+	mustRegisterSolver(&solver{
+		name:        "rack",
+		description: "The words a Scrabble rack can play, by their tiles' points, with ? as a blank and optionally through a letter on the board",
+		parameters: []solverParameter{
+			solverParameter{name: "letters", kind: solverString, description: "up to seven tiles, with ? for a blank", required: true},
+			solverParameter{name: "dictionary", kind: solverString, description: "the dictionary file to use, or several separated by commas", required: true},
+			solverParameter{name: "hook", kind: solverString, description: "a letter on the board the word has to use"},
+			solverParameter{name: "hook_position", kind: solverInt, description: "where the hook has to be in the word, counting from 1, or 0 for anywhere", defaultValue: "0"},
+		},
+		run: runRackSolver,
+	})
+}
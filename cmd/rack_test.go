@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFindRackWords(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"QUIT", "QUIET", "QI", "TIE", "EXIT", "RETAINS", "A"} {
+		trie.Add(word, nil)
+	}
+
+	tests := []struct {
+		rack         string
+		hook         string
+		hookPosition int
+		expected     string
+	}{
+		// A is too short, and QUIT needs a U
+		{"QIET", "", 0, "QI:11,TIE:3"},
+		// the blank plays the U and scores nothing
+		{"QIET?", "", 0, "QuIET:13,QuIT:12,QI:11,ExIT:3,TIE:3"},
+		// the hook has to be used, so QI and TIE don't count
+		{"QIET", "U", 0, "QUIET:14,QUIT:13"},
+		{"EIT", "X", 2, "EXIT:11"},
+		{"EIT", "X", 1, ""},
+		// all seven tiles earns the bonus
+		{"RETAINS", "", 0, "RETAINS:57,TIE:3"},
+	}
+	for _, currentTest := range tests {
+		request, err := newRackRequest(currentTest.rack, currentTest.hook, currentTest.hookPosition)
+		if err != nil {
+			test.Errorf("Expected a request for %s but got %v", currentTest.rack, err)
+			continue
+		}
+		described := make([]string, 0)
+		for _, found := range findRackWords(trie, request) {
+			described = append(described, fmt.Sprintf("%s:%d", found.shown, found.points))
+		}
+		if actual := strings.Join(described, ","); actual != currentTest.expected {
+			test.Errorf("Expected %s for %s with hook %s at %d but got %s", currentTest.expected, currentTest.rack, currentTest.hook, currentTest.hookPosition, actual)
+		}
+	}
+}
+
+func TestNewRackRequestErrors(test *testing.T) {
+	tests := []struct {
+		rack         string
+		hook         string
+		hookPosition int
+	}{
+		{"", "", 0},
+		{"ABCDEFGH", "", 0},
+		{"ABC", "XY", 0},
+		{"ABC", "", 2},
+		{"ABC", "X", -1},
+	}
+	for _, currentTest := range tests {
+		if _, err := newRackRequest(currentTest.rack, currentTest.hook, currentTest.hookPosition); err == nil {
+			test.Errorf("Expected an error for %v but got none", currentTest)
+		}
+	}
+}
@@ -0,0 +1,656 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Implementations for the substitution command object
+var substitutionCommand = regexp.MustCompile("[A-Z]=[a-z_]")
+
+var replGroupSize int
+var replCipherSymbols string
+var replFile string
+var replWidth int
+
+type KeyDisplay int
+
+const (
+	cipher2Plain KeyDisplay = iota
+	plain2Cipher
+)
+
+const (
+	cipher2PlainCommand string = "cipher2Plain"
+	plain2CipherCommand string = "plain2Cipher"
+	clearCommand        string = "clear"
+	groupCommand        string = "group"
+	undoCommand         string = "undo"
+	redoCommand         string = "redo"
+	saveCommand         string = "save"
+	loadCommand         string = "load"
+	suggestCommand      string = "suggest"
+	freqCommand         string = "freq"
+	solveCommand        string = "solve"
+	applyCommand        string = "apply"
+)
+
+// replCommands are the commands tab completion knows about
+var replCommands = []string{cipher2PlainCommand, plain2CipherCommand, clearCommand, groupCommand, undoCommand, redoCommand,
+	saveCommand, loadCommand, suggestCommand, freqCommand, solveCommand, applyCommand}
+
+// the most dictionary words suggest will show
+const maxSuggestions = 10
+
+// substitutionSession holds the state of an interactive substitution solve: the ciphertext,
+// which symbols in it are cipher symbols, and the mappings worked out so far
+type substitutionSession struct {
+	cipherString   string
+	cipherSymbols  []byte
+	isCipherSymbol map[byte]bool
+	groupSize      int
+	displayType    KeyDisplay
+	cipherToPlain  map[byte]byte
+	plainToCipher  map[byte]byte
+	// undoHistory and redoHistory hold copies of cipherToPlain from before each change
+	undoHistory []map[byte]byte
+	redoHistory []map[byte]byte
+	// status is a message about the last command, such as a failed save
+	status string
+	// dictionary holds the words suggest looks through, in the order they were in the file
+	dictionary []string
+	// showFrequencies turns on the panel comparing cipher frequencies to English
+	showFrequencies bool
+	// width is where long ciphertexts wrap onto another pair of lines. 0 means they don't wrap
+	width int
+	// frequencyMap is the ngram frequencies solve scores keys with, loaded the first time it's needed
+	frequencyMap *ngramFrequencyMap
+	// solvedKey is the best key from the last solve, waiting to be applied
+	solvedKey map[byte]byte
+}
+
+// savedSubstitutionSession is the JSON form of a session written by save and read by load
+type savedSubstitutionSession struct {
+	CipherText    string            `json:"cipherText"`
+	CipherSymbols string            `json:"cipherSymbols"`
+	GroupSize     int               `json:"groupSize,omitempty"`
+	DisplayMode   string            `json:"displayMode"`
+	CipherToPlain map[string]string `json:"cipherToPlain"`
+}
+
+// newSubstitutionSession creates a session for cipherString. cipherSymbols lists the symbols that
+// stand for plaintext letters, in the order they should be shown in the key. If groupSize is more
+// than 0, the ciphertext is shown without its spaces in groups of that size, as for patristocrats.
+func newSubstitutionSession(cipherString, cipherSymbols string, groupSize int) *substitutionSession {
+	session := &substitutionSession{
+		cipherString:   cipherString,
+		isCipherSymbol: make(map[byte]bool),
+		groupSize:      groupSize,
+		displayType:    cipher2Plain,
+		cipherToPlain:  make(map[byte]byte),
+		plainToCipher:  make(map[byte]byte),
+	}
+	for _, symbol := range []byte(cipherSymbols) {
+		if !session.isCipherSymbol[symbol] {
+			session.isCipherSymbol[symbol] = true
+			session.cipherSymbols = append(session.cipherSymbols, symbol)
+		}
+	}
+	return session
+}
+
+// substitutionShell creates a loop which lets you interactively solve a substitution cipher.
+// It will prompt for commands and show the current state of cipher text and plain text.
+// Command reference:
+//
+//	A=z will replace A in ciphertext with a z in plaintext
+//	cipher2Plain will list the cipher key in alphabetical order with the plain key underneath
+//	plain2Cipher will list the plain key in alphabetical order with the cipher key underneath
+//	clear will remove any mappings
+//	group N will show the ciphertext without spaces in groups of N (0 restores the original spacing)
+//	undo will take back the last mapping change or clear, and redo will put it back
+//	save FILE writes the ciphertext, mappings, and display mode to FILE as JSON, and load FILE reads them back
+//	freq shows or hides a panel of cipher symbol frequencies next to the usual English ones
+//	solve runs hillclimb on the ciphertext, keeping the mappings made so far (scored by --frequency-file or the built-in English tetragrams), and apply uses the key it found
+//	suggest WORD lists dictionary words that fit WORD's pattern and the mappings so far (needs --dictionary)
+func substitutionShell(cmd *cobra.Command, args []string) {
+	// whether to overwrite the text on the screen (will usually be true)
+	// or just push lines onto the screen
+	overwrite := false
+	outWriter := bufio.NewWriter(os.Stdout)
+
+	cipherString := strings.Join(args, " ")
+	if replFile != "" {
+		contents, err := ioutil.ReadFile(replFile)
+		if err != nil {
+			fmt.Printf("Could not read %s: %v\n", replFile, err)
+			os.Exit(1)
+		}
+		// line breaks in the file are just treated as spaces; the display wraps on its own
+		cipherString = strings.Join(append(args, strings.Fields(string(contents))...), " ")
+	}
+	if strings.TrimSpace(cipherString) == "" {
+		fmt.Println("The REPL needs ciphertext, either as arguments or with --file")
+		os.Exit(1)
+	}
+
+	session := newSubstitutionSession(cipherString, replCipherSymbols, replGroupSize)
+	session.width = replWidth
+	if len(dictionaryFiles) > 0 {
+		session.dictionary = readSuggestionDictionary(dictionaryFiles...)
+	}
+	editor := newLineEditor(os.Stdout, "? ", replCommands)
+
+	linesShown := 0
+	for {
+		if overwrite {
+			outWriter.Write([]byte(fmt.Sprintf("\u001b[%dA", linesShown)))
+			outWriter.Write([]byte("\u001b[100D"))
+		} else {
+			outWriter.Write([]byte{'\n'})
+		}
+
+		lines := session.displayLines()
+		for _, line := range lines {
+			// clear out whatever was on the line before
+			writeLines(outWriter, line+"\u001b[0K")
+		}
+		// the screen can get shorter, such as when the frequency panel is hidden, so clear out anything left below
+		outWriter.Write([]byte("\u001b[0J"))
+		// the prompt is on a line of its own too
+		linesShown = len(lines) + 1
+
+		outWriter.Flush()
+		command, err := editor.readLine()
+		if err == io.EOF {
+			fmt.Println()
+			return
+		}
+		session.handleCommand(strings.TrimSpace(command))
+
+		overwrite = true
+	}
+}
+
+// handleCommand updates the session based on one line of REPL input. Unknown commands are ignored
+func (session *substitutionSession) handleCommand(command string) {
+	session.status = ""
+	if cipherByte, plainByte, isMapping := session.parseMapping(command); isMapping {
+		session.recordHistory()
+		session.setMapping(cipherByte, plainByte)
+		return
+	}
+
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return
+	}
+	switch fields[0] {
+	case cipher2PlainCommand:
+		session.displayType = cipher2Plain
+	case plain2CipherCommand:
+		session.displayType = plain2Cipher
+	case clearCommand:
+		session.recordHistory()
+		session.restoreMappings(make(map[byte]byte))
+	case undoCommand:
+		if len(session.undoHistory) > 0 {
+			session.redoHistory = append(session.redoHistory, copyByteMap(session.cipherToPlain))
+			session.restoreMappings(session.undoHistory[len(session.undoHistory)-1])
+			session.undoHistory = session.undoHistory[:len(session.undoHistory)-1]
+		}
+	case redoCommand:
+		if len(session.redoHistory) > 0 {
+			session.undoHistory = append(session.undoHistory, copyByteMap(session.cipherToPlain))
+			session.restoreMappings(session.redoHistory[len(session.redoHistory)-1])
+			session.redoHistory = session.redoHistory[:len(session.redoHistory)-1]
+		}
+	case groupCommand:
+		if len(fields) == 2 {
+			if size, err := strconv.Atoi(fields[1]); err == nil && size >= 0 {
+				session.groupSize = size
+			}
+		}
+	case saveCommand:
+		if len(fields) != 2 {
+			session.status = "save needs a file name"
+		} else if err := session.save(fields[1]); err != nil {
+			session.status = fmt.Sprintf("Could not save %s: %v", fields[1], err)
+		} else {
+			session.status = fmt.Sprintf("Saved %s", fields[1])
+		}
+	case loadCommand:
+		if len(fields) != 2 {
+			session.status = "load needs a file name"
+		} else if err := session.load(fields[1]); err != nil {
+			session.status = fmt.Sprintf("Could not load %s: %v", fields[1], err)
+		} else {
+			session.status = fmt.Sprintf("Loaded %s", fields[1])
+		}
+	case freqCommand:
+		session.showFrequencies = !session.showFrequencies
+	case solveCommand:
+		session.runSolve()
+	case applyCommand:
+		if session.solvedKey == nil {
+			session.status = "Nothing to apply; run solve first"
+			return
+		}
+		session.recordHistory()
+		for cipherByte, plainByte := range session.solvedKey {
+			session.setMapping(cipherByte, plainByte)
+		}
+		session.solvedKey = nil
+	case suggestCommand:
+		if len(fields) != 2 {
+			session.status = "suggest needs a cipher word"
+		} else if len(session.dictionary) == 0 {
+			session.status = "suggest needs a dictionary; start the REPL with --dictionary"
+		} else if suggestions := session.suggest(fields[1], maxSuggestions); len(suggestions) == 0 {
+			session.status = fmt.Sprintf("No dictionary words fit %s", fields[1])
+		} else {
+			session.status = fmt.Sprintf("%s: %s", fields[1], strings.Join(suggestions, " "))
+		}
+	}
+}
+
+// runSolve loads the frequency file if it hasn't been already and runs solve, reporting the result in the status line
+func (session *substitutionSession) runSolve() {
+	if session.frequencyMap == nil {
+		frequencyMap, err := loadFrequencyMap(ngramFrequencyFile)
+		if err != nil {
+			session.status = fmt.Sprintf("Could not open %s: %v", ngramFrequencyFile, err)
+			return
+		}
+		session.frequencyMap = frequencyMap
+	}
+
+	ctx, cancel := solveContext()
+	defer cancel()
+	fitness, err := session.solve(ctx)
+	if err != nil {
+		session.status = fmt.Sprintf("Could not solve: %v", err)
+		return
+	}
+
+	plainText := applySubstitutionKey(session.cipherString, session.solvedKey)
+	if len(plainText) > 50 {
+		plainText = plainText[:50] + "..."
+	}
+	session.status = fmt.Sprintf("fitness %.4f: %s (apply to use this key)", fitness, plainText)
+}
+
+// solve runs the hillclimb solver over the ciphertext with the current mappings fixed and stores the best
+// key it finds in solvedKey, returning its fitness. The cipher symbols have to be letters of the active alphabet
+func (session *substitutionSession) solve(ctx context.Context) (float64, error) {
+	cipherLetters := make([]byte, 0, len(session.cipherString))
+	for _, symbol := range []byte(session.cipherString) {
+		if !session.isCipherSymbol[symbol] {
+			continue
+		}
+		if !activeAlphabet.contains(symbol) {
+			return 0, fmt.Errorf("%c isn't a letter; solve only works on letter ciphertexts", symbol)
+		}
+		cipherLetters = append(cipherLetters, symbol)
+	}
+
+	fixed := make(map[byte]byte)
+	for cipherByte, plainByte := range session.cipherToPlain {
+		fixed[cipherByte] = upperCaseByte(plainByte)
+	}
+
+	candidates := performHillclimbSolve(ctx, hillclimbSettingsFromFlags(), string(cipherLetters), session.frequencyMap, nil, fixed, nil, nil)
+	session.solvedKey = make(map[byte]byte)
+	for _, cipherByte := range cipherLetters {
+		plainLetter := candidates[0].key[activeAlphabet.position(cipherByte)]
+		session.solvedKey[cipherByte] = strings.ToLower(plainLetter)[0]
+	}
+	return candidates[0].fitness, nil
+}
+
+// readSuggestionDictionary reads the words for suggest, dropping duplicates but keeping the file's order
+// so that a dictionary sorted by frequency suggests common words first
+func readSuggestionDictionary(paths ...string) []string {
+	entries := make(chan string)
+	go func() {
+		feedDictionaryPaths(entries, paths...)
+	}()
+
+	seen := make(map[string]bool)
+	words := make([]string, 0)
+	for entry := range entries {
+		word := stripNonLetters(entry)
+		if word != "" && !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// suggest returns up to limit dictionary words that have the same substitution pattern as the cipher
+// symbols in cipherWord and don't conflict with the mappings made so far, in lowercase like the plaintext
+func (session *substitutionSession) suggest(cipherWord string, limit int) []string {
+	cipherLetters := make([]byte, 0, len(cipherWord))
+	for _, symbol := range []byte(cipherWord) {
+		if session.isCipherSymbol[symbol] {
+			cipherLetters = append(cipherLetters, symbol)
+		}
+	}
+	cipherPattern := symbolPattern(cipherLetters)
+
+	suggestions := make([]string, 0, limit)
+WordLoop:
+	for _, word := range session.dictionary {
+		if len(suggestions) >= limit {
+			break
+		}
+		if len(word) != len(cipherLetters) || substitutionPattern(word) != cipherPattern {
+			continue
+		}
+
+		plainWord := strings.ToLower(word)
+		for index, cipherByte := range cipherLetters {
+			plainByte := plainWord[index]
+			mappedPlain, cipherMapped := session.cipherToPlain[cipherByte]
+			mappedCipher, plainMapped := session.plainToCipher[plainByte]
+			if (cipherMapped && mappedPlain != plainByte) || (plainMapped && mappedCipher != cipherByte) {
+				continue WordLoop
+			}
+		}
+		suggestions = append(suggestions, plainWord)
+	}
+	return suggestions
+}
+
+// symbolPattern works like substitutionPattern, but for any bytes, since cipher symbols in the REPL
+// don't have to be letters
+func symbolPattern(symbols []byte) string {
+	mapped := make([]byte, 0, len(symbols))
+	symbolToLetter := make(map[byte]byte)
+	for _, symbol := range symbols {
+		if _, exists := symbolToLetter[symbol]; !exists {
+			symbolToLetter[symbol] = byte(ASCII_A + len(symbolToLetter))
+		}
+		mapped = append(mapped, symbolToLetter[symbol])
+	}
+	return string(mapped)
+}
+
+// save writes the session to path as JSON. The undo history isn't saved
+func (session *substitutionSession) save(path string) error {
+	saved := savedSubstitutionSession{
+		CipherText:    session.cipherString,
+		CipherSymbols: string(session.cipherSymbols),
+		GroupSize:     session.groupSize,
+		DisplayMode:   cipher2PlainCommand,
+		CipherToPlain: make(map[string]string),
+	}
+	if session.displayType == plain2Cipher {
+		saved.DisplayMode = plain2CipherCommand
+	}
+	for cipherByte, plainByte := range session.cipherToPlain {
+		saved.CipherToPlain[string(cipherByte)] = string(plainByte)
+	}
+
+	contents, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, contents, 0644)
+}
+
+// load replaces the session with the one saved at path. undo afterwards brings back the mappings from before the load
+func (session *substitutionSession) load(path string) error {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	saved := savedSubstitutionSession{}
+	err = json.Unmarshal(contents, &saved)
+	if err != nil {
+		return err
+	}
+
+	if saved.CipherSymbols == "" {
+		saved.CipherSymbols = upperAlphabet
+	}
+	loaded := newSubstitutionSession(saved.CipherText, saved.CipherSymbols, saved.GroupSize)
+	switch saved.DisplayMode {
+	case "", cipher2PlainCommand:
+		loaded.displayType = cipher2Plain
+	case plain2CipherCommand:
+		loaded.displayType = plain2Cipher
+	default:
+		return fmt.Errorf("unknown display mode %s", saved.DisplayMode)
+	}
+	for cipher, plain := range saved.CipherToPlain {
+		if len(cipher) != 1 || len(plain) != 1 || !loaded.isCipherSymbol[cipher[0]] || !isLowercaseAscii(plain[0]) {
+			return fmt.Errorf("%s=%s is not a valid mapping", cipher, plain)
+		}
+		loaded.setMapping(cipher[0], plain[0])
+	}
+
+	session.recordHistory()
+	session.cipherString = loaded.cipherString
+	session.cipherSymbols = loaded.cipherSymbols
+	session.isCipherSymbol = loaded.isCipherSymbol
+	session.groupSize = loaded.groupSize
+	session.displayType = loaded.displayType
+	session.restoreMappings(loaded.cipherToPlain)
+	return nil
+}
+
+// parseMapping checks whether command is of the form X=y, where X is a cipher symbol and y is
+// a lowercase letter or _ to remove the mapping
+func (session *substitutionSession) parseMapping(command string) (byte, byte, bool) {
+	if len(command) != 3 || command[1] != '=' {
+		return 0, 0, false
+	}
+	if !session.isCipherSymbol[command[0]] || !(isLowercaseAscii(command[2]) || command[2] == '_') {
+		return 0, 0, false
+	}
+	return command[0], command[2], true
+}
+
+// recordHistory saves the current mappings so the next change can be undone. A new change
+// means anything that was undone can no longer be redone
+func (session *substitutionSession) recordHistory() {
+	session.undoHistory = append(session.undoHistory, copyByteMap(session.cipherToPlain))
+	session.redoHistory = nil
+}
+
+// restoreMappings replaces the current mappings with cipherToPlain
+func (session *substitutionSession) restoreMappings(cipherToPlain map[byte]byte) {
+	session.cipherToPlain = copyByteMap(cipherToPlain)
+	session.plainToCipher = make(map[byte]byte)
+	for cipherByte, plainByte := range session.cipherToPlain {
+		session.plainToCipher[plainByte] = cipherByte
+	}
+}
+
+// setMapping makes cipherByte decipher to plainByte, or removes its mapping if plainByte is _.
+// A plain letter can only come from one cipher symbol, so any older mapping to it is removed
+func (session *substitutionSession) setMapping(cipherByte, plainByte byte) {
+	if oldPlain, mapped := session.cipherToPlain[cipherByte]; mapped {
+		delete(session.plainToCipher, oldPlain)
+		delete(session.cipherToPlain, cipherByte)
+	}
+	if plainByte == '_' {
+		return
+	}
+	if oldCipher, mapped := session.plainToCipher[plainByte]; mapped {
+		delete(session.cipherToPlain, oldCipher)
+	}
+	session.cipherToPlain[cipherByte] = plainByte
+	session.plainToCipher[plainByte] = cipherByte
+}
+
+// displayedCipherText returns the ciphertext as it should be shown, grouped if groupSize is set
+func (session *substitutionSession) displayedCipherText() string {
+	if session.groupSize <= 0 {
+		return session.cipherString
+	}
+	return strings.Join(splitIntoGroups(strings.Join(strings.Fields(session.cipherString), ""), session.groupSize), " ")
+}
+
+// displayLines returns the key, a blank line, the ciphertext with the plaintext underneath it, and then the status of the last command
+func (session *substitutionSession) displayLines() []string {
+	cipherKeyBytes := make([]byte, 0, len(session.cipherSymbols))
+	plainKeyBytes := make([]byte, 0, len(session.cipherSymbols))
+	lines := make([]string, 0, 5)
+	switch session.displayType {
+	case cipher2Plain:
+		for _, curByte := range session.cipherSymbols {
+			cipherKeyBytes = append(cipherKeyBytes, curByte)
+			plainChar, mapped := session.cipherToPlain[curByte]
+			if mapped {
+				plainKeyBytes = append(plainKeyBytes, plainChar)
+			} else {
+				plainKeyBytes = append(plainKeyBytes, '_')
+			}
+		}
+		lines = append(lines, string(cipherKeyBytes), string(plainKeyBytes))
+	case plain2Cipher:
+		for curByte := byte('a'); curByte <= byte('z'); curByte = byte(curByte + 1) {
+			plainKeyBytes = append(plainKeyBytes, curByte)
+			cipherChar, mapped := session.plainToCipher[curByte]
+			if mapped {
+				cipherKeyBytes = append(cipherKeyBytes, cipherChar)
+			} else {
+				cipherKeyBytes = append(cipherKeyBytes, '?')
+			}
+		}
+		lines = append(lines, string(plainKeyBytes), string(cipherKeyBytes))
+	default:
+		// shouldn't get here
+		lines = append(lines, fmt.Sprintf("Unknown display type: %v", session.displayType), "")
+	}
+	lines = append(lines, "")
+
+	cipherString := session.displayedCipherText()
+	plainString := make([]byte, 0, len(cipherString))
+	for _, cipherByte := range []byte(cipherString) {
+		if session.isCipherSymbol[cipherByte] {
+			plainByte, solved := session.cipherToPlain[cipherByte]
+			if solved {
+				plainString = append(plainString, plainByte)
+			} else {
+				plainString = append(plainString, '_')
+			}
+		} else {
+			plainString = append(plainString, cipherByte)
+		}
+	}
+
+	// long texts are split into pairs of cipher and plain lines, with a blank line between each pair
+	for index, bounds := range wrapOffsets(cipherString, session.width) {
+		if index > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, cipherString[bounds[0]:bounds[1]], string(plainString[bounds[0]:bounds[1]]))
+	}
+	if session.showFrequencies {
+		lines = append(lines, "")
+		lines = append(lines, session.frequencyPanel()...)
+	}
+	// the status line is always there, even when empty, so the screen doesn't change height
+	return append(lines, session.status)
+}
+
+// wrapOffsets splits text into pieces no longer than width, breaking after a space where it can,
+// and returns the start and end of each piece. The spaces stay at the end of the piece before them.
+// A width of 0 or less leaves text as one piece
+func wrapOffsets(text string, width int) [][2]int {
+	if width <= 0 || len(text) <= width {
+		return [][2]int{{0, len(text)}}
+	}
+
+	offsets := make([][2]int, 0, len(text)/width+1)
+	start := 0
+	for len(text)-start > width {
+		end := start + width
+		if breakAt := strings.LastIndexByte(text[start:end], ' '); breakAt > 0 {
+			end = start + breakAt + 1
+		}
+		offsets = append(offsets, [2]int{start, end})
+		start = end
+	}
+	return append(offsets, [2]int{start, len(text)})
+}
+
+// frequencyPanel lines up the cipher symbols, most frequent first, with English letters in order of
+// frequency so likely mappings can be read off by column. Percentages are rounded to whole numbers to keep columns narrow
+func (session *substitutionSession) frequencyPanel() []string {
+	counts := make(map[byte]int)
+	total := 0
+	for _, symbol := range []byte(session.cipherString) {
+		if session.isCipherSymbol[symbol] {
+			counts[symbol]++
+			total++
+		}
+	}
+
+	cipherFrequencies := make([]letterFrequency, 0, len(counts))
+	for symbol, count := range counts {
+		cipherFrequencies = append(cipherFrequencies, letterFrequency{symbol, 100.0 * float64(count) / float64(total)})
+	}
+	sort.Slice(cipherFrequencies, func(i, j int) bool {
+		if cipherFrequencies[i].percent == cipherFrequencies[j].percent {
+			return cipherFrequencies[i].letter < cipherFrequencies[j].letter
+		}
+		return cipherFrequencies[i].percent > cipherFrequencies[j].percent
+	})
+
+	columns := len(cipherFrequencies)
+	if columns > len(englishLetterFrequencies) {
+		columns = len(englishLetterFrequencies)
+	}
+	var cipherLetters, cipherPercents, englishLetters, englishPercents strings.Builder
+	cipherLetters.WriteString("cipher   ")
+	cipherPercents.WriteString("%        ")
+	englishLetters.WriteString("english  ")
+	englishPercents.WriteString("%        ")
+	for index := 0; index < columns; index++ {
+		cipherLetters.WriteString(fmt.Sprintf("%-4c", cipherFrequencies[index].letter))
+		cipherPercents.WriteString(fmt.Sprintf("%-4.0f", cipherFrequencies[index].percent))
+		englishLetters.WriteString(fmt.Sprintf("%-4s", strings.ToLower(string(englishLetterFrequencies[index].letter))))
+		englishPercents.WriteString(fmt.Sprintf("%-4.0f", englishLetterFrequencies[index].percent))
+	}
+	return []string{
+		strings.TrimRight(cipherLetters.String(), " "),
+		strings.TrimRight(cipherPercents.String(), " "),
+		strings.TrimRight(englishLetters.String(), " "),
+		strings.TrimRight(englishPercents.String(), " "),
+	}
+}
+
+func writeLines(writer *bufio.Writer, lines ...string) {
+	for _, line := range lines {
+		writer.Write([]byte(line))
+		writer.Write([]byte{'\n'})
+	}
+	writer.Flush()
+}
+
+func init() {
+	substitutionReplCmd.Flags().IntVarP(&replGroupSize, "group", "g", 0, "show the ciphertext without spaces in groups of this size, as for patristocrats")
+	substitutionReplCmd.Flags().StringVarP(&replFile, "file", "f", "", "read the ciphertext from this file, after any ciphertext in the arguments")
+	substitutionReplCmd.Flags().IntVarP(&replWidth, "width", "", 80, "wrap the ciphertext onto more lines past this many characters. 0 turns off wrapping")
+	substitutionReplCmd.Flags().StringVarP(&ngramFrequencyFile, "frequency-file", "", "", "ngram frequency file for the solve command, in the same format hillclimb uses. Defaults to the built-in English tetragrams")
+	substitutionReplCmd.Flags().StringVarP(&ngramSmoothing, "smoothing", "", smoothingFloor, "how to score ngrams that aren't in the frequency file: floor, add-k, or fixed, as for hillclimb")
+	substitutionReplCmd.Flags().Float64VarP(&ngramSmoothingK, "smoothing-k", "", 0.5, "the k for --smoothing add-k")
+	substitutionReplCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "dictionary file for the suggest command. Repeat it or separate files with commas to merge several")
+	substitutionReplCmd.Flags().StringVarP(&replCipherSymbols, "cipher-symbols", "s", upperAlphabet, "the symbols in the ciphertext that stand for letters, such as 0123456789 for digit ciphers")
+}
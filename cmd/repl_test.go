@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSubstitutionSessionDisplay(test *testing.T) {
+	session := newSubstitutionSession("ABC ABD.", upperAlphabet, 0)
+	session.handleCommand("A=t")
+	session.handleCommand("B=h")
+
+	lines := session.displayLines()
+	if lines[0] != upperAlphabet {
+		test.Errorf("Expected key line %s but got %s", upperAlphabet, lines[0])
+	}
+	if lines[1] != "th________________________" {
+		test.Errorf("Expected plain key th________________________ but got %s", lines[1])
+	}
+	if lines[4] != "th_ th_." {
+		test.Errorf("Expected th_ th_. but got %s", lines[4])
+	}
+
+	session.handleCommand(plain2CipherCommand)
+	lines = session.displayLines()
+	if lines[1] != "???????B???????????A??????" {
+		test.Errorf("Expected ???????B???????????A?????? but got %s", lines[1])
+	}
+}
+
+func TestSubstitutionSessionGrouping(test *testing.T) {
+	session := newSubstitutionSession("ABCD EFG HI", upperAlphabet, 3)
+	lines := session.displayLines()
+	if lines[3] != "ABC DEF GHI" {
+		test.Errorf("Expected ABC DEF GHI but got %s", lines[3])
+	}
+
+	session.handleCommand("group 0")
+	lines = session.displayLines()
+	if lines[3] != "ABCD EFG HI" {
+		test.Errorf("Expected ABCD EFG HI but got %s", lines[3])
+	}
+}
+
+func TestSubstitutionSessionDigitSymbols(test *testing.T) {
+	session := newSubstitutionSession("12 31", digitAlphabet, 0)
+	session.handleCommand("1=o")
+	session.handleCommand("2=n")
+	session.handleCommand("A=z")
+
+	lines := session.displayLines()
+	if lines[0] != digitAlphabet {
+		test.Errorf("Expected key line %s but got %s", digitAlphabet, lines[0])
+	}
+	if lines[4] != "on _o" {
+		test.Errorf("Expected on _o but got %s", lines[4])
+	}
+
+	// remapping a plain letter moves it to the new cipher symbol
+	session.handleCommand("3=o")
+	if _, mapped := session.cipherToPlain['1']; mapped {
+		test.Errorf("Expected 1 to be unmapped after o was given to 3")
+	}
+	session.handleCommand("2=_")
+	if len(session.plainToCipher) != 1 {
+		test.Errorf("Expected only one mapping left but got %v", session.plainToCipher)
+	}
+}
+
+func TestSubstitutionSessionUndoRedo(test *testing.T) {
+	session := newSubstitutionSession("ABC", upperAlphabet, 0)
+	session.handleCommand("A=t")
+	session.handleCommand("B=h")
+	session.handleCommand("B=o")
+
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{undoCommand, "th_"},
+		{undoCommand, "t__"},
+		{redoCommand, "th_"},
+		{clearCommand, "___"},
+		{undoCommand, "th_"},
+		{"C=e", "the"},
+		// the new mapping replaced what could have been redone
+		{redoCommand, "the"},
+		{undoCommand, "th_"},
+		{undoCommand, "t__"},
+		{undoCommand, "___"},
+		{undoCommand, "___"},
+	}
+
+	for index, testCase := range tests {
+		session.handleCommand(testCase.command)
+		actual := session.displayLines()[4]
+		if actual != testCase.expected {
+			test.Errorf("Test case %d (%s): expected %s but got %s", index, testCase.command, testCase.expected, actual)
+		}
+	}
+}
+
+func TestSubstitutionSessionSaveLoad(test *testing.T) {
+	path := filepath.Join(test.TempDir(), "session.json")
+
+	session := newSubstitutionSession("12 3", digitAlphabet, 0)
+	session.handleCommand("1=o")
+	session.handleCommand("2=n")
+	session.handleCommand(plain2CipherCommand)
+	session.handleCommand(saveCommand + " " + path)
+	if session.status != "Saved "+path {
+		test.Errorf("Expected the save to succeed but got %s", session.status)
+	}
+
+	loaded := newSubstitutionSession("ABC", upperAlphabet, 0)
+	loaded.handleCommand(loadCommand + " " + path)
+	if loaded.status != "Loaded "+path {
+		test.Errorf("Expected the load to succeed but got %s", loaded.status)
+	}
+	if loaded.displayType != plain2Cipher {
+		test.Errorf("Expected the display mode to be loaded")
+	}
+	lines := loaded.displayLines()
+	if lines[3] != "12 3" || lines[4] != "on _" {
+		test.Errorf("Expected 12 3 over on _ but got %s over %s", lines[3], lines[4])
+	}
+
+	// loading can be undone
+	loaded.handleCommand(undoCommand)
+	if len(loaded.cipherToPlain) != 0 {
+		test.Errorf("Expected undo to remove the loaded mappings but got %v", loaded.cipherToPlain)
+	}
+
+	loaded.handleCommand(loadCommand + " " + filepath.Join(test.TempDir(), "missing.json"))
+	if loaded.status == "" || loaded.status[:5] != "Could" {
+		test.Errorf("Expected an error loading a missing file but got %q", loaded.status)
+	}
+}
+
+func TestSubstitutionSessionSuggest(test *testing.T) {
+	session := newSubstitutionSession("QXZZ 1233", upperAlphabet+digitAlphabet, 0)
+	session.dictionary = []string{"TOLL", "BELL", "HELLO", "SEEN", "CALL", "WELL"}
+
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{suggestCommand + " QXZZ", "QXZZ: toll bell call well"},
+		{"X=e", ""},
+		{suggestCommand + " QXZZ", "QXZZ: bell well"},
+		// b and e are taken by Q and X, so the digits can only be words without them
+		{"Q=b", ""},
+		{suggestCommand + " 1233", "1233: toll call"},
+		{suggestCommand + " ABCD", "No dictionary words fit ABCD"},
+		{suggestCommand, "suggest needs a cipher word"},
+	}
+
+	for index, testCase := range tests {
+		session.handleCommand(testCase.command)
+		if session.status != testCase.expected {
+			test.Errorf("Test case %d (%s): expected %q but got %q", index, testCase.command, testCase.expected, session.status)
+		}
+	}
+
+	session.dictionary = nil
+	session.handleCommand(suggestCommand + " QXZZ")
+	if session.status != "suggest needs a dictionary; start the REPL with --dictionary" {
+		test.Errorf("Expected suggest to ask for a dictionary but got %q", session.status)
+	}
+}
+
+func TestSubstitutionSessionFrequencyPanel(test *testing.T) {
+	session := newSubstitutionSession("QQQX XZ", upperAlphabet, 0)
+	lines := session.displayLines()
+	plainOnlyLength := len(lines)
+
+	session.handleCommand(freqCommand)
+	lines = session.displayLines()
+	if len(lines) != plainOnlyLength+5 {
+		test.Errorf("Expected the panel to add 5 lines but got %v", lines)
+	}
+
+	expected := []string{
+		"cipher   Q   X   Z",
+		"%        50  33  17",
+		"english  e   t   a",
+		"%        13  9   8",
+	}
+	for index, line := range expected {
+		if lines[6+index] != line {
+			test.Errorf("Expected panel line %q but got %q", line, lines[6+index])
+		}
+	}
+
+	session.handleCommand(freqCommand)
+	if len(session.displayLines()) != plainOnlyLength {
+		test.Errorf("Expected freq to hide the panel again")
+	}
+}
+
+func TestWrapOffsets(test *testing.T) {
+	tests := []struct {
+		text     string
+		width    int
+		expected [][2]int
+	}{
+		{"ABC DEF", 0, [][2]int{{0, 7}}},
+		{"ABC DEF", 7, [][2]int{{0, 7}}},
+		{"ABC DEF GHI", 8, [][2]int{{0, 8}, {8, 11}}},
+		{"ABCDEFGHIJ", 4, [][2]int{{0, 4}, {4, 8}, {8, 10}}},
+	}
+
+	for index, testCase := range tests {
+		actual := wrapOffsets(testCase.text, testCase.width)
+		if len(actual) != len(testCase.expected) {
+			test.Errorf("Test case %d: expected %v but got %v", index, testCase.expected, actual)
+			continue
+		}
+		for offsetIndex, offset := range testCase.expected {
+			if actual[offsetIndex] != offset {
+				test.Errorf("Test case %d: expected %v but got %v", index, testCase.expected, actual)
+			}
+		}
+	}
+}
+
+func TestSubstitutionSessionWrapping(test *testing.T) {
+	session := newSubstitutionSession("ABC DEF GHI", upperAlphabet, 0)
+	session.width = 8
+	session.handleCommand("A=t")
+
+	lines := session.displayLines()
+	expected := []string{"ABC DEF ", "t__ ___ ", "", "GHI", "___", ""}
+	for index, line := range expected {
+		if lines[3+index] != line {
+			test.Errorf("Expected line %d to be %q but got %q", 3+index, line, lines[3+index])
+		}
+	}
+}
+
+func TestSubstitutionSessionSolve(test *testing.T) {
+	setHillclimbParameters(3, 20, 2)
+	session := newSubstitutionSession("GURDH VPX", upperAlphabet, 0)
+	session.frequencyMap, _, _ = populateFrequencyMapFromReader(strings.NewReader("THEQ\t-1.0\nHEQU\t-1.5\nEQUI\t-2.0"))
+
+	session.handleCommand(applyCommand)
+	if session.status != "Nothing to apply; run solve first" {
+		test.Errorf("Expected apply to need a solve first but got %q", session.status)
+	}
+
+	session.handleCommand("G=z")
+	if _, err := session.solve(context.Background()); err != nil {
+		test.Fatalf("Unexpected error solving: %v", err)
+	}
+	if session.solvedKey['G'] != 'z' {
+		test.Errorf("Expected the solve to keep G=z but got G=%c", session.solvedKey['G'])
+	}
+	if len(session.solvedKey) != 8 {
+		test.Errorf("Expected a key for the 8 cipher letters but got %v", session.solvedKey)
+	}
+
+	session.handleCommand(applyCommand)
+	if strings.Contains(session.displayLines()[4], "_") {
+		test.Errorf("Expected every letter to be mapped after apply but got %s", session.displayLines()[4])
+	}
+	session.handleCommand(undoCommand)
+	if len(session.cipherToPlain) != 1 {
+		test.Errorf("Expected undo to go back to just G=z but got %v", session.cipherToPlain)
+	}
+
+	digits := newSubstitutionSession("123", digitAlphabet, 0)
+	digits.frequencyMap = session.frequencyMap
+	if _, err := digits.solve(context.Background()); err == nil {
+		test.Errorf("Expected digit ciphertexts to be rejected")
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var respaceResults int
+var rankedDictionary bool
+
+// respaceCmd represents the respace command
+var respaceCmd = &cobra.Command{
+	Use:   "respace",
+	Short: "Finds the most likely places for spaces in text that has lost them",
+	Long: `Transposition solving and many ciphers leave text without word breaks. This command
+	throws away any existing spaces and finds the best ways to split the letters into dictionary words.
+
+	By default every dictionary word is considered equally likely, so segmentations with fewer words win.
+	If the dictionary is sorted from most to least common word, pass --ranked and common words will be
+	preferred. Letters that can't be made into a word are kept as single letters, at a steep cost.
+	`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  printSegmentations,
+}
+
+func printSegmentations(cmd *cobra.Command, args []string) {
+	rootTrie, wordCount := loadDictionaryTrie(dictionaryFiles...)
+
+	text := string(justUppercaseLetters(strings.Join(args, "")))
+	printer := newResultPrinter("cost", "words")
+	for _, found := range segmentText(rootTrie, wordCount, rankedDictionary, text, respaceResults) {
+		cost := fmt.Sprintf("%.4f", found.cost)
+		words := strings.Join(found.words, " ")
+		printer.result(fmt.Sprintf("%s: %s", cost, words), cost, words)
+	}
+	printer.finish(false)
+}
+
+// readDictionaryToRankedTrie reads the dictionary channel into a trie where each word's value
+// is a dictionaryWord ranking it by its 1-based position in the dictionary, or by its frequency if the
+// dictionary has them. It also returns the number of words read.
+func readDictionaryToRankedTrie(dictionary chan dictionaryEntry) (*trieNode, int) {
+	newTrie := NewTrieWithAlphabet[interface{}](dictionaryAlphabet)
+	rank := 0
+	weighted := false
+	for entry := range dictionary {
+		weighted = weighted || entry.frequency > 0
+		if value, present := newTrie.Get(entry.word); present {
+			// a word listed twice keeps its first rank but the higher frequency
+			if existing := value.(dictionaryWord); entry.frequency > existing.frequency {
+				newTrie.Add(entry.word, dictionaryWord{existing.rank, entry.frequency})
+			}
+			continue
+		}
+		err := newTrie.Add(entry.word, dictionaryWord{rank + 1, entry.frequency})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not add %s to trie %v\n", entry.word, err)
+			continue
+		}
+		rank++
+	}
+	if weighted {
+		rankByFrequency(newTrie)
+	}
+	return newTrie, rank
+}
+
+// segmentation is one way of splitting text into words. Lower costs are more likely
+type segmentation struct {
+	cost  float64
+	words []string
+}
+
+// segmentText returns up to maxResults of the lowest-cost ways to split text into words in the trie.
+// When ranked is true, trie values are treated as frequency ranks and a word's cost follows Zipf's law;
+// otherwise every word costs the same.
+func segmentText(trie *trieNode, wordCount int, ranked bool, text string, maxResults int) []segmentation {
+	if wordCount < 1 {
+		wordCount = 1
+	}
+	flatCost := math.Log10(float64(wordCount) + 1)
+	wordCost := func(value interface{}) float64 {
+		rank := dictionaryRank(value)
+		if !ranked || rank == 0 {
+			return flatCost
+		}
+		return math.Log10(float64(rank) * math.Log(float64(wordCount)+1))
+	}
+	unknownCost := 10 * flatCost
+
+	// best[i] holds the cheapest segmentations of text[:i]
+	best := make([][]segmentation, len(text)+1)
+	best[0] = []segmentation{{0, nil}}
+	for start := 0; start < len(text); start++ {
+		if len(best[start]) == 0 {
+			continue
+		}
+
+		extensions := make(map[int]float64)
+		currentNode := trie
+		for end := start; end < len(text); end++ {
+			currentNode = currentNode.Child(text[end])
+			if currentNode == nil {
+				break
+			}
+			if currentNode.IsWord() {
+				value, _ := trie.Get(text[start : end+1])
+				extensions[end+1] = wordCost(value)
+			}
+		}
+		if _, hasSingle := extensions[start+1]; !hasSingle {
+			extensions[start+1] = unknownCost
+		}
+
+		for end, cost := range extensions {
+			word := text[start:end]
+			for _, prefix := range best[start] {
+				words := make([]string, 0, len(prefix.words)+1)
+				words = append(words, prefix.words...)
+				words = append(words, word)
+				best[end] = append(best[end], segmentation{prefix.cost + cost, words})
+			}
+			best[end] = keepCheapestSegmentations(best[end], maxResults)
+		}
+	}
+	return best[len(text)]
+}
+
+// keepCheapestSegmentations sorts by cost (then alphabetically for stability) and trims to maxResults
+func keepCheapestSegmentations(segmentations []segmentation, maxResults int) []segmentation {
+	sort.Slice(segmentations, func(i, j int) bool {
+		if segmentations[i].cost == segmentations[j].cost {
+			return strings.Join(segmentations[i].words, " ") < strings.Join(segmentations[j].words, " ")
+		}
+		return segmentations[i].cost < segmentations[j].cost
+	})
+	if len(segmentations) > maxResults {
+		return segmentations[:maxResults]
+	}
+	return segmentations
+}
+
+func init() {
+	respaceCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+	respaceCmd.MarkFlagRequired("dictionary")
+	respaceCmd.Flags().BoolVarP(&rankedDictionary, "ranked", "r", false, "the dictionary is sorted from most to least common word")
+	respaceCmd.Flags().IntVarP(&respaceResults, "results", "n", 5, "the number of segmentations to print")
+	rootCmd.AddCommand(respaceCmd)
+}
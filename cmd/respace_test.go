@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func rankedTestTrie(words string) (*trieNode, int) {
+	dictChannel := make(chan dictionaryEntry)
+	go func() {
+		feedWeightedDictionaryReaders(dictChannel, bufio.NewReader(strings.NewReader(words)))
+	}()
+	return readDictionaryToRankedTrie(dictChannel)
+}
+
+func TestReadDictionaryToRankedTrie(test *testing.T) {
+	trie, count := rankedTestTrie("THE\nCAT\nthe\nHAT")
+	if count != 3 {
+		test.Errorf("Expected duplicates to be skipped for a count of 3 but got %d", count)
+	}
+	if value, _ := trie.Get("HAT"); dictionaryRank(value) != 3 {
+		test.Errorf("Expected HAT to have rank 3 but got %v", value)
+	}
+}
+
+func TestSegmentText(test *testing.T) {
+	trie, count := rankedTestTrie("THE\nCAT\nIN\nHAT\nTHEN\nA\nT")
+
+	segmentations := segmentText(trie, count, false, "THECATINTHEHAT", 3)
+	if len(segmentations) == 0 || strings.Join(segmentations[0].words, " ") != "THE CAT IN THE HAT" {
+		test.Errorf("Expected THE CAT IN THE HAT first but got %v", segmentations)
+	}
+	if len(segmentations) > 3 {
+		test.Errorf("Expected at most 3 segmentations but got %d", len(segmentations))
+	}
+
+	// unknown letters get kept as single letters
+	segmentations = segmentText(trie, count, false, "CATQ", 1)
+	if strings.Join(segmentations[0].words, " ") != "CAT Q" {
+		test.Errorf("Expected CAT Q but got %v", segmentations[0].words)
+	}
+
+	// ranked dictionaries prefer common words even if there are more of them
+	trie, count = rankedTestTrie("A\nT\nTAT\nAT")
+	segmentations = segmentText(trie, count, true, "AT", 1)
+	if strings.Join(segmentations[0].words, " ") != "A T" {
+		test.Errorf("Expected A T for a ranked dictionary but got %v", segmentations[0].words)
+	}
+	segmentations = segmentText(trie, count, false, "AT", 1)
+	if strings.Join(segmentations[0].words, " ") != "AT" {
+		test.Errorf("Expected AT for an unranked dictionary but got %v", segmentations[0].words)
+	}
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A symbolRing is an ordered set of symbols that wraps around, such as A-Z. Caesar shifts and
+// other modular ciphers work by moving symbols around a ring, so this lets them work over digits,
+// letters and digits, or any alphabet a puzzle calls for instead of only A-Z.
+
+const upperAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+const lowerAlphabet = "abcdefghijklmnopqrstuvwxyz"
+const digitAlphabet = "0123456789"
+
+// every printable ASCII character other than space, which is what ROT47 rotates through
+const printableAsciiAlphabet = "!\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~"
+
+type symbolRing struct {
+	symbols   []byte
+	positions map[byte]int
+}
+
+var upperRing = mustSymbolRing(upperAlphabet)
+var lowerRing = mustSymbolRing(lowerAlphabet)
+var digitRing = mustSymbolRing(digitAlphabet)
+var printableAsciiRing = mustSymbolRing(printableAsciiAlphabet)
+
+// newSymbolRing creates a ring out of symbols, which can't contain any duplicates
+func newSymbolRing(symbols string) (*symbolRing, error) {
+	if len(symbols) < 2 {
+		return nil, fmt.Errorf("a ring needs at least two symbols but got %q", symbols)
+	}
+
+	ring := &symbolRing{[]byte(symbols), make(map[byte]int)}
+	for position, symbol := range ring.symbols {
+		if _, exists := ring.positions[symbol]; exists {
+			return nil, fmt.Errorf("%c appears more than once in %s", symbol, symbols)
+		}
+		ring.positions[symbol] = position
+	}
+	return ring, nil
+}
+
+func mustSymbolRing(symbols string) *symbolRing {
+	ring, err := newSymbolRing(symbols)
+	if err != nil {
+		panic(err)
+	}
+	return ring
+}
+
+func (ring *symbolRing) size() int {
+	return len(ring.symbols)
+}
+
+func (ring *symbolRing) contains(symbol byte) bool {
+	_, exists := ring.positions[symbol]
+	return exists
+}
+
+// position returns where symbol is in the ring, or -1 if it isn't there
+func (ring *symbolRing) position(symbol byte) int {
+	position, exists := ring.positions[symbol]
+	if !exists {
+		return -1
+	}
+	return position
+}
+
+// symbolAt returns the symbol at position, wrapping around in either direction
+func (ring *symbolRing) symbolAt(position int) byte {
+	position %= ring.size()
+	if position < 0 {
+		position += ring.size()
+	}
+	return ring.symbols[position]
+}
+
+// shift moves symbol amount places around the ring. Symbols that aren't in the ring are returned as is
+func (ring *symbolRing) shift(symbol byte, amount int) byte {
+	position := ring.position(symbol)
+	if position < 0 {
+		return symbol
+	}
+	return ring.symbolAt(position + amount)
+}
+
+// shiftByRings shifts symbol around the first ring that contains it
+func shiftByRings(symbol byte, amount int, rings []*symbolRing) byte {
+	for _, ring := range rings {
+		if ring.contains(symbol) {
+			return ring.shift(symbol, amount)
+		}
+	}
+	return symbol
+}
+
+// ringsForName returns the rings for one of the named ring sets: letters (A-Z and a-z, each
+// wrapping separately, or the active alphabet if it isn't the standard one), digits, alphanumeric
+// (letters followed by digits), letters+digits (letters and digits each wrapping on their own), or ascii
+// (all of printable ASCII but space). A custom alphabet, if given, takes precedence and is used as a single ring.
+func ringsForName(name, alphabet string) ([]*symbolRing, error) {
+	if alphabet != "" {
+		ring, err := newSymbolRing(alphabet)
+		if err != nil {
+			return nil, err
+		}
+		return []*symbolRing{ring}, nil
+	}
+
+	switch strings.ToLower(name) {
+	case "", "letters":
+		if activeAlphabet != standardAlphabet {
+			return []*symbolRing{activeAlphabet.symbolRing}, nil
+		}
+		return []*symbolRing{upperRing, lowerRing}, nil
+	case "digits":
+		return []*symbolRing{digitRing}, nil
+	case "letters+digits":
+		return []*symbolRing{upperRing, lowerRing, digitRing}, nil
+	case "ascii":
+		return []*symbolRing{printableAsciiRing}, nil
+	case "alphanumeric":
+		return []*symbolRing{mustSymbolRing(upperAlphabet + digitAlphabet), mustSymbolRing(lowerAlphabet + digitAlphabet)}, nil
+	}
+	return nil, fmt.Errorf("unknown ring %s", name)
+}
+
+// keyedRing builds a keyword-mixed version of ring: the keyword's symbols in order with repeats
+// dropped, followed by the rest of the ring in its usual order. Keyword symbols are matched to the
+// ring in either case, and ones that aren't in the ring at all are left out
+func keyedRing(ring *symbolRing, keyword string) *symbolRing {
+	mixed := make([]byte, 0, ring.size())
+	used := make(map[byte]bool)
+	for _, symbol := range []byte(keyword) {
+		for _, variant := range []byte{symbol, upperCaseByte(symbol), strings.ToLower(string(symbol))[0]} {
+			if ring.contains(variant) {
+				if !used[variant] {
+					used[variant] = true
+					mixed = append(mixed, variant)
+				}
+				break
+			}
+		}
+	}
+	for _, symbol := range ring.symbols {
+		if !used[symbol] {
+			mixed = append(mixed, symbol)
+		}
+	}
+	return mustSymbolRing(string(mixed))
+}
+
+// keyedRings mixes every ring with keyword. Every symbol of the keyword has to be in one of the rings
+func keyedRings(rings []*symbolRing, keyword string) ([]*symbolRing, error) {
+	for _, symbol := range []byte(keyword) {
+		if !inAnyRing(rings, symbol, upperCaseByte(symbol), strings.ToLower(string(symbol))[0]) {
+			return nil, fmt.Errorf("%c in keyword %s isn't one of the symbols being shifted", symbol, keyword)
+		}
+	}
+
+	keyed := make([]*symbolRing, 0, len(rings))
+	for _, ring := range rings {
+		keyed = append(keyed, keyedRing(ring, keyword))
+	}
+	return keyed, nil
+}
+
+// inAnyRing reports whether any of symbols is in any of the rings
+func inAnyRing(rings []*symbolRing, symbols ...byte) bool {
+	for _, ring := range rings {
+		for _, symbol := range symbols {
+			if ring.contains(symbol) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rotStep is a shift of amount around whichever of rings contains a symbol
+type rotStep struct {
+	rings  []*symbolRing
+	amount int
+}
+
+// rotSteps returns what goes into one of the standard ROT-N transforms: ROT5 (digits), ROT13 (letters),
+// ROT18 (ROT13 for letters and ROT5 for digits), or ROT47 (printable ASCII)
+func rotSteps(n int) ([]rotStep, error) {
+	letters := rotStep{[]*symbolRing{upperRing, lowerRing}, 13}
+	digits := rotStep{[]*symbolRing{digitRing}, 5}
+	switch n {
+	case 5:
+		return []rotStep{digits}, nil
+	case 13:
+		return []rotStep{letters}, nil
+	case 18:
+		return []rotStep{letters, digits}, nil
+	case 47:
+		return []rotStep{rotStep{[]*symbolRing{printableAsciiRing}, 47}}, nil
+	}
+	return nil, fmt.Errorf("ROT%d isn't one of ROT5, ROT13, ROT18, or ROT47", n)
+}
+
+// rotate applies ROT-n to text. All of these undo themselves, so the same call decodes
+func rotate(text string, n int) (string, error) {
+	steps, err := rotSteps(n)
+	if err != nil {
+		return "", err
+	}
+
+	rotated := []byte(text)
+	for index, symbol := range rotated {
+		for _, step := range steps {
+			if inAnyRing(step.rings, symbol) {
+				rotated[index] = shiftByRings(symbol, step.amount, step.rings)
+				break
+			}
+		}
+	}
+	return string(rotated), nil
+}
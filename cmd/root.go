@@ -17,9 +17,11 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"math/rand"
 	"os"
+	"os/signal"
 	"regexp"
 	"runtime/pprof"
 	"strings"
@@ -39,8 +41,26 @@ var memFilePath = "mem.prof"
 var cpuFile *os.File
 var memFile *os.File
 
-// enough of these commands use a dictionary file that we can declare it at the top level
-var dictionaryFile string
+// how long solvers are allowed to run before they stop and report what they have. 0 means no limit
+var solveTimeout time.Duration
+
+// the number of symbols in the alphabet the solvers work in, which picks activeAlphabet
+var alphabetSize int
+
+// enough of these commands use a dictionary file that we can declare it at the top level.
+// --dictionary can be repeated or given a comma-separated list, and the files are read in order as one dictionary
+var dictionaryFiles []string
+
+// the symbols besides A-Z that dictionary words can have, such as ' for DON'T, set by --word-symbols
+var wordSymbols string
+
+// dictionaryAlphabet is the trie alphabet dictionaries are read into
+var dictionaryAlphabet = UppercaseTrieAlphabet
+
+// limits on the dictionary words that are read, which make up activeDictionaryFilter
+var dictionaryMinWordLength int
+var dictionaryMaxWordLength int
+var excludeWordsFile string
 
 var lettersRegex = regexp.MustCompile("^[A-Za-z]+$")
 
@@ -55,6 +75,28 @@ Cobra is a CLI library for Go that empowers applications.
 This application is a tool to generate the needed files
 to quickly create a Cobra application.`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		historyStartedAt = time.Now()
+		if outputFormat != "text" && outputFormat != "json" {
+			fmt.Printf("Unknown output format %s; use text or json\n", outputFormat)
+			os.Exit(1)
+		}
+		var err error
+		activeAlphabet, err = alphabetForSize(alphabetSize)
+		if err != nil {
+			fmt.Printf("Invalid alphabet: %v\n", err)
+			os.Exit(1)
+		}
+		dictionaryAlphabet, err = dictionaryAlphabetWith(wordSymbols)
+		if err != nil {
+			fmt.Printf("Invalid word symbols: %v\n", err)
+			os.Exit(1)
+		}
+		activeDictionaryFilter, err = newDictionaryFilter(dictionaryMinWordLength, dictionaryMaxWordLength, excludeWordsFile)
+		if err != nil {
+			fmt.Printf("Invalid dictionary filter: %v\n", err)
+			os.Exit(1)
+		}
+
 		if profile {
 			cpuFile, err := os.Create(cpuFilePath)
 			if err != nil {
@@ -77,12 +119,14 @@ to quickly create a Cobra application.`,
 			memFile.Close()
 			pprof.StopCPUProfile()
 		}
+		recordHistory(cmd)
 	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	addSolverCommands()
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -96,6 +140,15 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.puzzle_helper.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&profile, "profile", "", false, "turn on profiling for this run")
+	rootCmd.PersistentFlags().IntVarP(&alphabetSize, "alphabet-size", "", 26, "the plaintext alphabet: 24 (I/J and U/V merged), 25 (I/J merged), 26, or 36 (A-Z and 0-9)")
+	rootCmd.PersistentFlags().StringVarP(&wordSymbols, "word-symbols", "", "", "symbols besides A-Z to keep in dictionary words, such as \"'-0123456789\" for DON'T and 7-ELEVEN. Words with other symbols are skipped")
+	rootCmd.PersistentFlags().IntVarP(&dictionaryMinWordLength, "min-dict-word-length", "", 0, "skip dictionary words shorter than this when reading dictionaries. 0 means no limit")
+	rootCmd.PersistentFlags().IntVarP(&dictionaryMaxWordLength, "max-dict-word-length", "", 0, "skip dictionary words longer than this when reading dictionaries. 0 means no limit")
+	rootCmd.PersistentFlags().StringVarP(&excludeWordsFile, "exclude-words-file", "", "", "a file of words to skip when reading dictionaries")
+	rootCmd.PersistentFlags().BoolVarP(&stripAccents, "strip-accents", "", true, "read accented letters in corpora and ciphertexts as the letters under the accents (é as E). With --strip-accents=false they're skipped")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "", "text", "how to print results: text, or json for other programs to read")
+	rootCmd.PersistentFlags().BoolVarP(&explainResults, "explain", "", false, "say why each result qualified, such as the dictionary words it matched or how its fitness breaks down")
+	rootCmd.PersistentFlags().DurationVarP(&solveTimeout, "timeout", "", 0, "stop solving after this long (e.g. 30s or 5m) and report what was found. 0 means no limit")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -129,11 +182,85 @@ func initConfig() {
 	}
 }
 
+// solveContext returns a context for the solvers that is cancelled once --timeout has passed
+// or when the user interrupts the program, so long searches can stop cleanly
+func solveContext() (context.Context, context.CancelFunc) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if solveTimeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), solveTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		select {
+		case <-interrupts:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(interrupts)
+	}()
+	return ctx, cancel
+}
+
 // feedDictionaryPaths takes a set of file paths (or - for stdin) and reads through
 // each one, feeding it to the channel. Many of the puzzle types this helps with need
 // to read from a dictionary file, so this creates a simple reusable pattern that
-// any solving functionality can use.
+// any solving functionality can use. A dictionary that can't be read ends the program
 func feedDictionaryPaths(feed chan string, files ...string) {
+	exitOnDictionaryError(withDictionaryReaders(files, func(readers []*bufio.Reader) error {
+		return feedDictionaryReaders(feed, readers...)
+	}))
+}
+
+// feedWeightedDictionaryPaths is feedDictionaryPaths for callers that want each word's frequency too
+func feedWeightedDictionaryPaths(feed chan dictionaryEntry, files ...string) {
+	exitOnDictionaryError(withDictionaryReaders(files, func(readers []*bufio.Reader) error {
+		return feedWeightedDictionaryReaders(feed, readers...)
+	}))
+}
+
+// sendDictionaryPaths is feedDictionaryPaths for callers that have to carry on, like the servers: a dictionary
+// that can't be read is returned as an error instead. The feed is closed either way, so it can still be ranged over
+func sendDictionaryPaths(feed chan string, files ...string) error {
+	err := withDictionaryReaders(files, func(readers []*bufio.Reader) error {
+		return feedDictionaryReaders(feed, readers...)
+	})
+	if err != nil {
+		close(feed)
+	}
+	return err
+}
+
+// sendWeightedDictionaryPaths is sendDictionaryPaths for callers that want each word's frequency too
+func sendWeightedDictionaryPaths(feed chan dictionaryEntry, files ...string) error {
+	err := withDictionaryReaders(files, func(readers []*bufio.Reader) error {
+		return feedWeightedDictionaryReaders(feed, readers...)
+	})
+	if err != nil {
+		close(feed)
+	}
+	return err
+}
+
+// exitOnDictionaryError is how commands handle a dictionary they couldn't read
+func exitOnDictionaryError(err error) {
+	if err != nil {
+		fmt.Printf("Could not read the dictionary: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// withDictionaryReaders opens files (or stdin for -) and passes them to read, closing them afterward.
+// If there are no files or one can't be opened, read isn't called
+func withDictionaryReaders(files []string, read func(readers []*bufio.Reader) error) error {
+	if len(files) == 0 {
+		// there's no built-in word list, since no public domain one was available to embed
+		return fmt.Errorf("no dictionary was given; dictionary fetch enable downloads one")
+	}
 	readers := make([]*bufio.Reader, 0, len(files))
 	for _, file := range files {
 		if file == "-" {
@@ -141,43 +268,89 @@ func feedDictionaryPaths(feed chan string, files ...string) {
 		} else {
 			file, err := os.Open(file)
 			if err != nil {
-				fmt.Printf("Could not access file: %v\n", err)
-				os.Exit(1)
+				return fmt.Errorf("could not access file: %v", err)
 			}
 			defer file.Close()
 			readers = append(readers, bufio.NewReader(file))
 		}
 	}
-	feedDictionaryReaders(feed, readers...)
+	return read(readers)
 }
 
 // feedDictionaryReaders reads from readers and pushes strings to the feed,
-// closing it when it's done. This is separated out from above largely to
+// closing it when it's done. If a reader fails, the feed is left open and the error returned. Readers can be word lists or tries from dictionary compile. This is separated out from above largely to
 // facilitate testing.
-func feedDictionaryReaders(feed chan string, readers ...*bufio.Reader) {
+func feedDictionaryReaders(feed chan string, readers ...*bufio.Reader) error {
+	err := readDictionaryEntries(readers, func(entry dictionaryEntry) {
+		feed <- entry.word
+	})
+	if err == nil {
+		close(feed)
+	}
+	return err
+}
+
+// feedWeightedDictionaryReaders is feedDictionaryReaders for callers that want each word's frequency too
+func feedWeightedDictionaryReaders(feed chan dictionaryEntry, readers ...*bufio.Reader) error {
+	err := readDictionaryEntries(readers, func(entry dictionaryEntry) {
+		feed <- entry
+	})
+	if err == nil {
+		close(feed)
+	}
+	return err
+}
+
+// readDictionaryEntries calls use with every word in readers, which can have a frequency after a tab.
+// Words activeDictionaryFilter leaves out are skipped
+func readDictionaryEntries(readers []*bufio.Reader, use func(entry dictionaryEntry)) error {
+	useKept := func(entry dictionaryEntry) {
+		if activeDictionaryFilter.keeps(entry.word) {
+			use(entry)
+		}
+	}
 	for _, reader := range readers {
+		if isCompiledTrie(reader) {
+			if err := feedCompiledDictionary(useKept, reader); err != nil {
+				return fmt.Errorf("could not load the compiled dictionary: %v", err)
+			}
+			continue
+		}
 		scanner := bufio.NewScanner(reader)
 		for scanner.Scan() {
-			feed <- strings.ToUpper(scanner.Text())
+			entry := splitDictionaryLine(scanner.Text())
+			entry.word = activeAlphabet.foldString(strings.ToUpper(entry.word))
+			useKept(entry)
+		}
+		if err := scanner.Err(); err != nil {
+			return err
 		}
 	}
-	close(feed)
+	return nil
+}
+
+// dictionaryAlphabetWith is A-Z followed by extraSymbols. ? and . can't be used since they're wildcards in patterns
+func dictionaryAlphabetWith(extraSymbols string) (*TrieAlphabet, error) {
+	if strings.ContainsAny(extraSymbols, "?.") {
+		return nil, fmt.Errorf("? and . stand for any letter in patterns, so words can't have them")
+	}
+	return trieAlphabetOf(upperAlphabet + extraSymbols)
 }
 
 // dictionaryChanToTrie will read the dictionary channel populated by feedDictionaryReaders
 // and will add the items to a Trie structure that it will return
 func readDictionaryToTrie(dictionary chan string) *trieNode {
 	now := time.Now().UnixNano()
-	newTrie := newTrie()
+	newTrie := NewTrieWithAlphabet[interface{}](dictionaryAlphabet)
 	for entry := range dictionary {
 		// something needs to be the value or else nodes will get ignored in walks
-		err := newTrie.addValueForString(entry, nil)
+		err := newTrie.Add(entry, nil)
 		if err != nil {
-			fmt.Printf("Could not add %s to trie %v\n", entry, err)
+			fmt.Fprintf(os.Stderr, "Could not add %s to trie %v\n", entry, err)
 		}
 	}
 	if profile {
-		fmt.Printf("Reading into trie took: %.8fms\n", float64(time.Now().UnixNano()-now)/float64(1000000))
+		fmt.Fprintf(os.Stderr, "Reading into trie took: %.8fms\n", float64(time.Now().UnixNano()-now)/float64(1000000))
 	}
 	return newTrie
 }
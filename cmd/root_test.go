@@ -32,3 +32,23 @@ func TestFeedDictionaryReaders(test *testing.T) {
 	}
 
 }
+
+func TestFeedDictionaryReadersFiltered(test *testing.T) {
+	previous := activeDictionaryFilter
+	defer func() { activeDictionaryFilter = previous }()
+	activeDictionaryFilter = dictionaryFilter{minLength: 3, maxLength: 5, excluded: map[string]bool{"CAT": true}}
+
+	entryChannel := make(chan string)
+	go feedDictionaryReaders(entryChannel, bufio.NewReader(strings.NewReader("A\nAT\ncat\nDOG\nHORSE\nGIRAFFE\n")))
+	entries := make([]string, 0)
+	for entry := range entryChannel {
+		entries = append(entries, entry)
+	}
+	if strings.Join(entries, " ") != "DOG HORSE" {
+		test.Errorf("Expected DOG HORSE to make it through the filter but got %v", entries)
+	}
+
+	if _, err := newDictionaryFilter(6, 4, ""); err == nil {
+		test.Errorf("Expected an error for a minimum length over the maximum")
+	}
+}
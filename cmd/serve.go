@@ -0,0 +1,448 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var serveAddress string
+var serveBudgetMs int
+var serveMaxConcurrent int
+
+// servedQueueSize is how many solves can wait for one of the --max-concurrent workers before requests
+// are turned away
+const servedQueueSize = 100
+
+var servedJobsOnce sync.Once
+var servedJobsValue *jobManager
+
+// servedJobs returns the job manager every served solve runs on, starting it the first time
+func servedJobs() *jobManager {
+	servedJobsOnce.Do(func() {
+		servedJobsValue = newJobManager(serveMaxConcurrent, servedQueueSize)
+	})
+	return servedJobsValue
+}
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serves the registered solvers to other programs",
+	Long: `Makes every solver in the registry available to other programs, either over HTTP or as an
+	MCP (Model Context Protocol) server on stdin and stdout. Solvers take the same parameters they take
+	under the solver command, and their results come back as the same JSON. --dictionary gives the
+	dictionaries solvers use when a request doesn't name its own, and a request can only name one of
+	those, so clients can't have the server read any other file. Without --dictionary, solvers that need a
+	dictionary can't be used. At most --max-concurrent solves run at once; the rest wait their turn, and
+	requests are turned away while 100 are waiting.`,
+}
+
+var serveHttpCmd = &cobra.Command{
+	Use:   "http",
+	Short: "Serves the solvers over HTTP",
+	Long: `Serves the solvers over HTTP. GET /solvers lists them with a JSON schema of their parameters,
+	and POST /solvers/NAME with a JSON object of parameters runs one. Errors come back as {"error": "..."}.
+	Solvers that can stream, like transposal, take POST /solvers/NAME?stream=true to send each result as
+	a line of JSON as soon as it's found, followed by a line with the total and whether it was truncated.
+
+	Long solves can run in the background instead: POST /jobs with {"solver": NAME, "parameters": {...}}
+	answers with the job's id, GET /jobs/ID says whether it's queued, running, done, failed, or cancelled,
+	with its progress and, once it's done, its results, and DELETE /jobs/ID cancels it. Finished jobs can
+	be looked up for an hour.
+
+	Examples:
+	  curl -d '{"text": "Uryyb", "shift": 13}' http://localhost:8080/solvers/caesar
+	  curl -d '{"solver": "hillclimb", "parameters": {"text": "GURDHVPX"}}' http://localhost:8080/jobs`,
+	Args: cobra.NoArgs,
+	Run:  serveHttp,
+}
+
+var serveMcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Serves the solvers as MCP tools on stdin and stdout",
+	Long: `Speaks the Model Context Protocol's JSON-RPC over stdin and stdout, one message per line,
+	with each registered solver as a tool. Anything else the server has to say goes to stderr.`,
+	Args: cobra.NoArgs,
+	Run:  serveMcp,
+}
+
+func serveHttp(cmd *cobra.Command, args []string) {
+	useServeDictionaries()
+	fmt.Fprintf(os.Stderr, "Serving %d solvers on %s\n", len(registeredSolvers()), serveAddress)
+	if err := http.ListenAndServe(serveAddress, newSolverHandler()); err != nil {
+		fmt.Printf("Could not serve: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func serveMcp(cmd *cobra.Command, args []string) {
+	useServeDictionaries()
+	if err := newMcpServer(os.Stdin, os.Stdout).serve(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "Could not serve: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// servedDictionaries are the dictionary files requests can name, which are the ones given to serve --dictionary
+var servedDictionaries = make(map[string]bool)
+
+// useServeDictionaries makes the dictionaries given to serve the default for every solver's dictionary parameter
+// and the only ones requests can ask for
+func useServeDictionaries() {
+	for _, file := range dictionaryFiles {
+		if file == "-" {
+			fmt.Fprintln(os.Stderr, "serve can't read dictionaries from stdin; give it files")
+			os.Exit(1)
+		}
+		servedDictionaries[filepath.Clean(file)] = true
+	}
+	if len(dictionaryFiles) > 0 {
+		setParameterDefault("dictionary", strings.Join(dictionaryFiles, ","))
+	}
+}
+
+// checkServedDictionaries makes sure a request only names dictionaries the server was started with. stdin is
+// never one of them, since the MCP server reads its requests from it
+func checkServedDictionaries(raw map[string]string) error {
+	for _, file := range strings.Split(raw["dictionary"], ",") {
+		if file = strings.TrimSpace(file); file == "" {
+			continue
+		}
+		if file == "-" || !servedDictionaries[filepath.Clean(file)] {
+			return fmt.Errorf("%s isn't one of the dictionaries this server was started with", file)
+		}
+	}
+	return nil
+}
+
+// solverDescription is how a solver is listed to other programs
+type solverDescription struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+func describeSolvers() []solverDescription {
+	descriptions := make([]solverDescription, 0)
+	for _, registered := range registeredSolvers() {
+		descriptions = append(descriptions, solverDescription{registered.name, registered.description, registered.inputSchema()})
+	}
+	return descriptions
+}
+
+// newSolverHandler routes GET /solvers to the list of solvers and POST /solvers/NAME to running one
+func newSolverHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/solvers", func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			writeHttpError(writer, http.StatusMethodNotAllowed, fmt.Errorf("use GET to list the solvers"))
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(writer).Encode(describeSolvers())
+	})
+	mux.HandleFunc("/solvers/", func(writer http.ResponseWriter, request *http.Request) {
+		name := strings.TrimPrefix(request.URL.Path, "/solvers/")
+		registered := lookupSolver(name)
+		if registered == nil {
+			writeHttpError(writer, http.StatusNotFound, fmt.Errorf("there's no solver named %s", name))
+			return
+		}
+		if request.Method != http.MethodPost {
+			writeHttpError(writer, http.StatusMethodNotAllowed, fmt.Errorf("use POST to run %s", name))
+			return
+		}
+
+		var arguments map[string]interface{}
+		if err := json.NewDecoder(request.Body).Decode(&arguments); err != nil {
+			writeHttpError(writer, http.StatusBadRequest, fmt.Errorf("the body should be a JSON object of parameters: %v", err))
+			return
+		}
+		if request.URL.Query().Get("stream") == "true" {
+			streamServedSolver(request.Context(), writer, registered, arguments)
+			return
+		}
+		table, err := runServedSolver(request.Context(), registered, arguments)
+		if err != nil {
+			writeHttpError(writer, servedErrorStatus(err), err)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		writeJSONTable(writer, table)
+	})
+	mux.HandleFunc("/jobs", submitServedJob)
+	mux.HandleFunc("/jobs/", handleServedJob)
+	return mux
+}
+
+// servedJob is how a job submitted to POST /jobs is described. The result is there once the job is
+// done, and the error once it has failed
+type servedJob struct {
+	ID       string     `json:"id"`
+	Status   jobStatus  `json:"status"`
+	Progress string     `json:"progress,omitempty"`
+	Result   *jsonTable `json:"result,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+func newServedJob(snapshot jobSnapshot) servedJob {
+	described := servedJob{ID: snapshot.ID, Status: snapshot.Status, Progress: snapshot.Progress}
+	if table, isTable := snapshot.Result.(*resultTable); isTable && table != nil {
+		result := newJSONTable(table)
+		described.Result = &result
+	}
+	if snapshot.Err != nil {
+		described.Error = snapshot.Err.Error()
+	}
+	return described
+}
+
+func writeServedJob(writer http.ResponseWriter, status int, snapshot jobSnapshot) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(newServedJob(snapshot))
+}
+
+// submitServedJob starts a solve in the background for POST /jobs, whose body names the solver and gives
+// its parameters, and answers with the job's id. Parameters that can't work are turned away before
+// the job is queued
+func submitServedJob(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		writeHttpError(writer, http.StatusMethodNotAllowed, fmt.Errorf("use POST to start a job"))
+		return
+	}
+	var body struct {
+		Solver     string                 `json:"solver"`
+		Parameters map[string]interface{} `json:"parameters"`
+	}
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		writeHttpError(writer, http.StatusBadRequest, fmt.Errorf("the body should be a JSON object with a solver and its parameters: %v", err))
+		return
+	}
+	registered := lookupSolver(body.Solver)
+	if registered == nil {
+		writeHttpError(writer, http.StatusNotFound, fmt.Errorf("there's no solver named %s", body.Solver))
+		return
+	}
+	raw, err := servedSolverParameters(body.Parameters)
+	if err == nil {
+		_, err = registered.parseInput(raw)
+	}
+	if err != nil {
+		writeHttpError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := servedJobs().submit(func(ctx context.Context, report func(progress string)) (interface{}, error) {
+		return solveServed(withJobProgress(ctx, report), registered, body.Parameters)
+	})
+	if err != nil {
+		writeHttpError(writer, servedErrorStatus(err), err)
+		return
+	}
+	snapshot, _ := servedJobs().snapshot(id)
+	writer.Header().Set("Location", "/jobs/"+id)
+	writeServedJob(writer, http.StatusAccepted, snapshot)
+}
+
+// handleServedJob answers GET /jobs/ID with how the job is going, and DELETE /jobs/ID by cancelling the
+// job if it hasn't finished and forgetting it
+func handleServedJob(writer http.ResponseWriter, request *http.Request) {
+	id := strings.TrimPrefix(request.URL.Path, "/jobs/")
+	snapshot, exists := servedJobs().snapshot(id)
+	if !exists {
+		writeHttpError(writer, http.StatusNotFound, fmt.Errorf("there's no job %s", id))
+		return
+	}
+
+	switch request.Method {
+	case http.MethodGet:
+		writeServedJob(writer, http.StatusOK, snapshot)
+	case http.MethodDelete:
+		servedJobs().cancelJob(id)
+		// solvers stop soon after they're cancelled, and this way the answer says how the job ended
+		snapshot, _ = servedJobs().wait(id)
+		servedJobs().forget(id)
+		writeServedJob(writer, http.StatusOK, snapshot)
+	default:
+		writeHttpError(writer, http.StatusMethodNotAllowed, fmt.Errorf("use GET to check on job %s or DELETE to cancel it", id))
+	}
+}
+
+// servedErrorStatus is the HTTP status for a solve that failed with err
+func servedErrorStatus(err error) int {
+	if err == errTooManyJobs {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadRequest
+}
+
+func writeHttpError(writer http.ResponseWriter, status int, err error) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(map[string]string{"error": err.Error()})
+}
+
+// streamServedSolver writes each of a solver's results as a line of JSON as soon as it's found, and then
+// a line with the total and whether the search stopped early. An error before the first result is an
+// ordinary error response; after it, the error is the last line instead
+func streamServedSolver(ctx context.Context, writer http.ResponseWriter, registered *solver, arguments map[string]interface{}) {
+	raw, err := servedSolverParameters(arguments)
+	if err != nil {
+		writeHttpError(writer, http.StatusBadRequest, err)
+		return
+	}
+	if solveTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, solveTimeout)
+		defer cancel()
+	}
+
+	encoder := json.NewEncoder(writer)
+	flusher, _ := writer.(http.Flusher)
+	total := 0
+	result, err := servedJobs().run(ctx, func(ctx context.Context, report func(progress string)) (interface{}, error) {
+		return streamSolver(withJobProgress(ctx, report), registered, raw, func(values ...string) {
+			if total == 0 {
+				writer.Header().Set("Content-Type", "application/x-ndjson")
+			}
+			total++
+			encoder.Encode(jsonRow(registered.columns, values))
+			if flusher != nil {
+				flusher.Flush()
+			}
+		})
+	})
+	truncated, _ := result.(bool)
+	if err != nil && total == 0 {
+		writeHttpError(writer, servedErrorStatus(err), err)
+		return
+	}
+	if err != nil {
+		encoder.Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	if total == 0 {
+		writer.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	encoder.Encode(struct {
+		Total     int  `json:"total"`
+		Truncated bool `json:"truncated"`
+	}{total, truncated})
+}
+
+// servedSolverParameters turns parameters decoded from JSON into the strings the registry parses, with
+// --budget-ms as the budget unless the parameters give their own. Dictionaries the server wasn't
+// started with are an error
+func servedSolverParameters(arguments map[string]interface{}) (map[string]string, error) {
+	raw, err := rawSolverParameters(arguments)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkServedDictionaries(raw); err != nil {
+		return nil, err
+	}
+	if _, given := raw[solverBudgetParameter]; !given && serveBudgetMs > 0 {
+		raw[solverBudgetParameter] = strconv.Itoa(serveBudgetMs)
+	}
+	return raw, nil
+}
+
+// runServedSolver runs a solver on parameters decoded from JSON as one of the served jobs, and waits for it
+func runServedSolver(ctx context.Context, registered *solver, arguments map[string]interface{}) (*resultTable, error) {
+	result, err := servedJobs().run(ctx, func(ctx context.Context, report func(progress string)) (interface{}, error) {
+		return solveServed(withJobProgress(ctx, report), registered, arguments)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*resultTable), nil
+}
+
+// solveServed runs a solver on parameters decoded from JSON, within --budget-ms unless the parameters
+// give their own budget. It's the work of a served job, so it doesn't go through the job manager itself
+func solveServed(ctx context.Context, registered *solver, arguments map[string]interface{}) (*resultTable, error) {
+	raw, err := servedSolverParameters(arguments)
+	if err != nil {
+		return nil, err
+	}
+	if solveTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, solveTimeout)
+		defer cancel()
+	}
+
+	started := time.Now()
+	table, err := runSolver(ctx, registered, raw)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(os.Stderr, "%s: %d results in %v\n", registered.name, len(table.rows), time.Since(started).Round(time.Millisecond))
+	return table, nil
+}
+
+// withJobProgress passes whatever progress a solver reports on to its job, as well as wherever ctx already sends it
+func withJobProgress(ctx context.Context, report func(progress string)) context.Context {
+	return withSolverProgress(ctx, func(progress, total float64, message string) {
+		if total > 0 {
+			report(fmt.Sprintf("%g of %g: %s", progress, total, message))
+		} else {
+			report(fmt.Sprintf("%g: %s", progress, message))
+		}
+	})
+}
+
+// rawSolverParameters turns JSON parameter values into the strings the registry parses
+func rawSolverParameters(arguments map[string]interface{}) (map[string]string, error) {
+	raw := make(map[string]string)
+	for name, value := range arguments {
+		switch typed := value.(type) {
+		case string:
+			raw[name] = typed
+		case bool:
+			raw[name] = strconv.FormatBool(typed)
+		case float64:
+			raw[name] = strconv.FormatFloat(typed, 'f', -1, 64)
+		case nil:
+		default:
+			return nil, fmt.Errorf("%s should be a string, number, or boolean", name)
+		}
+	}
+	return raw, nil
+}
+
+func init() {
+	serveHttpCmd.Flags().StringVarP(&serveAddress, "address", "a", "localhost:8080", "the address to listen on")
+	serveCmd.PersistentFlags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "dictionary files for solvers whose requests don't give one. Repeat it or separate files with commas to merge several")
+	serveCmd.PersistentFlags().IntVarP(&serveBudgetMs, "budget-ms", "", 0, "the most milliseconds a solver can spend on a request that doesn't give its own budgetMs. 0 means no limit")
+	serveCmd.PersistentFlags().IntVarP(&serveMaxConcurrent, "max-concurrent", "", runtime.NumCPU(), "the most solves to run at once. Defaults to the number of CPUs")
+	serveCmd.AddCommand(serveHttpCmd)
+	serveCmd.AddCommand(serveMcpCmd)
+	rootCmd.AddCommand(serveCmd)
+}
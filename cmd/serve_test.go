@@ -0,0 +1,372 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSolverHandler(test *testing.T) {
+	server := httptest.NewServer(newSolverHandler())
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/solvers")
+	if err != nil {
+		test.Fatalf("Unexpected error listing solvers: %v", err)
+	}
+	var descriptions []solverDescription
+	json.NewDecoder(response.Body).Decode(&descriptions)
+	response.Body.Close()
+	if len(descriptions) != len(registeredSolvers()) || descriptions[0].InputSchema["type"] != "object" {
+		test.Errorf("Expected every solver with its schema but got %v", descriptions)
+	}
+
+	tests := []struct {
+		path     string
+		body     string
+		status   int
+		expected string
+	}{
+		{"/solvers/caesar", `{"text": "Uryyb", "shift": 13}`, http.StatusOK, `"results":[{"shift":"13","text":"Hello"}]`},
+		{"/solvers/caesar", `{"shift": 13}`, http.StatusBadRequest, `"error":"caesar needs the text parameter"`},
+		{"/solvers/caesar", `[1, 2]`, http.StatusBadRequest, `"error"`},
+		{"/solvers/caesar", `{"text": ["Uryyb"]}`, http.StatusBadRequest, `"error":"text should be a string, number, or boolean"`},
+		{"/solvers/nothing", `{}`, http.StatusNotFound, `"error":"there's no solver named nothing"`},
+	}
+	for _, testCase := range tests {
+		response, err := http.Post(server.URL+testCase.path, "application/json", strings.NewReader(testCase.body))
+		if err != nil {
+			test.Errorf("Unexpected error posting to %s: %v", testCase.path, err)
+			continue
+		}
+		var body bytes.Buffer
+		body.ReadFrom(response.Body)
+		response.Body.Close()
+		if response.StatusCode != testCase.status || !strings.Contains(body.String(), testCase.expected) {
+			test.Errorf("Expected %d with %s for %s but got %d with %s", testCase.status, testCase.expected, testCase.body, response.StatusCode, body.String())
+		}
+	}
+}
+
+func TestSolverHandlerStream(test *testing.T) {
+	dictionary := filepath.Join(test.TempDir(), "words.txt")
+	os.WriteFile(dictionary, []byte("POST\nSTOP\nSPOT\nTOPS\nPOTS\n"), 0644)
+	servedDictionaries = map[string]bool{dictionary: true}
+	defer func() { servedDictionaries = make(map[string]bool) }()
+
+	server := httptest.NewServer(newSolverHandler())
+	defer server.Close()
+
+	tests := []struct {
+		path     string
+		body     string
+		status   int
+		expected []string
+	}{
+		{"/solvers/transposal?stream=true", `{"letters": "stop", "max_words": 1, "max_results": 2, "dictionary": "` + dictionary + `"}`, http.StatusOK, []string{`{"words":"`, `{"words":"`, `{"total":2,"truncated":false}`}},
+		{"/solvers/transposal?stream=true", `{"letters": "xq", "dictionary": "` + dictionary + `"}`, http.StatusOK, []string{`{"total":0,"truncated":false}`}},
+		{"/solvers/transposal?stream=true", `{"letters": "12", "dictionary": "` + dictionary + `"}`, http.StatusBadRequest, []string{`"error":"there are no letters to transpose"`}},
+		{"/solvers/caesar?stream=true", `{"text": "Uryyb"}`, http.StatusBadRequest, []string{`"error":"caesar can't stream its results"`}},
+	}
+	for _, testCase := range tests {
+		response, err := http.Post(server.URL+testCase.path, "application/json", strings.NewReader(testCase.body))
+		if err != nil {
+			test.Errorf("Unexpected error posting to %s: %v", testCase.path, err)
+			continue
+		}
+		var body bytes.Buffer
+		body.ReadFrom(response.Body)
+		response.Body.Close()
+		lines := strings.Split(strings.TrimSpace(body.String()), "\n")
+		if response.StatusCode != testCase.status || len(lines) != len(testCase.expected) {
+			test.Errorf("Expected %d with %d lines for %s but got %d with %s", testCase.status, len(testCase.expected), testCase.body, response.StatusCode, body.String())
+			continue
+		}
+		for index, line := range lines {
+			if !strings.Contains(line, testCase.expected[index]) {
+				test.Errorf("Expected line %d for %s to contain %s but got %s", index+1, testCase.body, testCase.expected[index], line)
+			}
+		}
+	}
+}
+
+func TestMcpServer(test *testing.T) {
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"rot","arguments":{"text":"Call 555","n":18}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"rot","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"nothing"}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"resources/list"}`,
+		`not json`,
+		`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"rot","arguments":{"text":"abc","format":"text"}}}`,
+		`{"jsonrpc":"2.0","id":8,"method":"tools/call","params":{"name":"rot","arguments":{"text":"abc","format":"json"}}}`,
+		`{"jsonrpc":"2.0","id":9,"method":"tools/call","params":{"name":"rot","arguments":{"text":"abc","format":"yaml"}}}`,
+	}, "\n")
+	var output bytes.Buffer
+	if err := newMcpServer(strings.NewReader(input), &output).serve(context.Background()); err != nil {
+		test.Fatalf("Unexpected error serving: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	// the notification gets no answer
+	expected := []string{
+		`"id":1,"result":{"capabilities":{"tools":{}},"protocolVersion":"2024-11-05"`,
+		`"id":2,"result":{"tools":[{"name":"aristocrat"`,
+		`"id":3,"result":{"content":[{"type":"text","text":"text\nPnyy 000"}],"isError":false,"structuredContent":{"columns":["text"],"results":[{"text":"Pnyy 000"}]`,
+		`"id":4,"result":{"content":[{"type":"text","text":"rot needs the text parameter"}],"isError":true}`,
+		`"id":5,"error":{"code":-32602,"message":"there's no tool named nothing"}`,
+		`"id":6,"error":{"code":-32601`,
+		`"id":null,"error":{"code":-32700`,
+		`"id":7,"result":{"content":[{"type":"text","text":"text\nnop"}],"isError":false}}`,
+		`"id":8,"result":{"content":[{"type":"text","text":"{\"columns\":[\"text\"],\"results\":[{\"text\":\"nop\"}],\"total\":1,\"truncated\":false}"}],"isError":false,"structuredContent":{`,
+		`"id":9,"result":{"content":[{"type":"text","text":"format should be text, json, or both"}],"isError":true}`,
+	}
+	if len(lines) != len(expected) {
+		test.Fatalf("Expected %d responses but got %d: %s", len(expected), len(lines), output.String())
+	}
+	// tool calls answer as they finish, so the answers can come in any order
+	for _, fragment := range expected {
+		if mcpLineIndex(lines, fragment) < 0 {
+			test.Errorf("Expected a response containing %s but got %s", fragment, output.String())
+		}
+	}
+	if tools := lines[mcpLineIndex(lines, `"id":2,`)]; !strings.Contains(tools, `"format":{"default":"both"`) {
+		test.Errorf("Expected every tool to take a format but got %s", tools)
+	}
+}
+
+// mcpLineIndex is the index of the first line with fragment in it, or -1 if none has it
+func mcpLineIndex(lines []string, fragment string) int {
+	for index, line := range lines {
+		if strings.Contains(line, fragment) {
+			return index
+		}
+	}
+	return -1
+}
+
+func TestServedDictionaries(test *testing.T) {
+	dir := test.TempDir()
+	allowed := filepath.Join(dir, "words.txt")
+	os.WriteFile(allowed, []byte("BEAST\nBEATS\n"), 0644)
+	missing := filepath.Join(dir, "missing.txt")
+	servedDictionaries = map[string]bool{allowed: true, missing: true}
+	defer func() { servedDictionaries = make(map[string]bool) }()
+
+	server := httptest.NewServer(newSolverHandler())
+	defer server.Close()
+
+	tests := []struct {
+		body     string
+		status   int
+		expected string
+	}{
+		{`{"bank": "BEAST", "dictionary": "` + allowed + `"}`, http.StatusOK, `"words":"BEAST"`},
+		{`{"bank": "BEAST", "dictionary": "/nonexistent.txt"}`, http.StatusBadRequest, `"error":"/nonexistent.txt isn't one of the dictionaries this server was started with"`},
+		{`{"bank": "BEAST", "dictionary": "-"}`, http.StatusBadRequest, `isn't one of the dictionaries`},
+		{`{"bank": "BEAST", "dictionary": "` + allowed + `,/etc/passwd"}`, http.StatusBadRequest, `/etc/passwd isn't one of the dictionaries`},
+		// allowed but gone since the server started: an error for this request, and the server carries on
+		{`{"bank": "BEAST", "dictionary": "` + missing + `"}`, http.StatusBadRequest, `could not access file`},
+		{`{"bank": "BEAST"}`, http.StatusBadRequest, `"error":"letterbank needs the dictionary parameter"`},
+	}
+	for _, testCase := range tests {
+		response, err := http.Post(server.URL+"/solvers/letterbank", "application/json", strings.NewReader(testCase.body))
+		if err != nil {
+			test.Errorf("Unexpected error posting %s: %v", testCase.body, err)
+			continue
+		}
+		var body bytes.Buffer
+		body.ReadFrom(response.Body)
+		response.Body.Close()
+		if response.StatusCode != testCase.status || !strings.Contains(body.String(), testCase.expected) {
+			test.Errorf("Expected %d with %s for %s but got %d with %s", testCase.status, testCase.expected, testCase.body, response.StatusCode, body.String())
+		}
+	}
+
+	stream, err := http.Post(server.URL+"/solvers/transposal?stream=true", "application/json", strings.NewReader(`{"letters": "beast", "dictionary": "`+missing+`"}`))
+	if err != nil {
+		test.Fatalf("Unexpected error streaming: %v", err)
+	}
+	stream.Body.Close()
+	if stream.StatusCode != http.StatusBadRequest {
+		test.Errorf("Expected a bad request for a missing dictionary when streaming but got %d", stream.StatusCode)
+	}
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"letterbank","arguments":{"bank":"BEAST","dictionary":"/nope"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"letterbank","arguments":{"bank":"BEAST","dictionary":"` + missing + `"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"ping"}`,
+	}, "\n")
+	var output bytes.Buffer
+	if err := newMcpServer(strings.NewReader(input), &output).serve(context.Background()); err != nil {
+		test.Fatalf("Unexpected error serving: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	expected := []string{
+		`"id":1,"result":{"content":[{"type":"text","text":"/nope isn't one of the dictionaries this server was started with"}],"isError":true}`,
+		`"id":2,"result":{"content":[{"type":"text","text":"could not access file`,
+		`"id":3,"result":{}`,
+	}
+	if len(lines) != len(expected) {
+		test.Fatalf("Expected %d responses but got %d: %s", len(expected), len(lines), output.String())
+	}
+	// tool calls answer as they finish, so the answers can come in any order
+	for _, fragment := range expected {
+		if mcpLineIndex(lines, fragment) < 0 {
+			test.Errorf("Expected a response containing %s but got %s", fragment, output.String())
+		}
+	}
+}
+
+func TestMcpProgress(test *testing.T) {
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"hillclimb","arguments":{"text":"GURDHVPX","generations":3,"regen_after":5},"_meta":{"progressToken":"climb"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"hillclimb","arguments":{"text":"GURDHVPX","generations":3,"regen_after":5}}}`,
+	}, "\n")
+	var output bytes.Buffer
+	if err := newMcpServer(strings.NewReader(input), &output).serve(context.Background()); err != nil {
+		test.Fatalf("Unexpected error serving: %v", err)
+	}
+
+	// only the call with a progress token hears about each generation
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	expected := []string{
+		`{"jsonrpc":"2.0","method":"notifications/progress","params":{"message":"generation 1 fitness`,
+		`"progress":2,"progressToken":"climb","total":3}}`,
+		`"progress":3,"progressToken":"climb","total":3}}`,
+		`"id":1,"result":{"content":[{"type":"text","text":"fitness`,
+		`"id":2,"result":{"content":[{"type":"text","text":"fitness`,
+	}
+	if len(lines) != len(expected) {
+		test.Fatalf("Expected %d messages but got %d: %s", len(expected), len(lines), output.String())
+	}
+	// the two calls can finish in either order, but each call's progress comes before its answer
+	for index, fragment := range expected {
+		found := mcpLineIndex(lines, fragment)
+		if found < 0 {
+			test.Errorf("Expected a message containing %s but got %s", fragment, output.String())
+		}
+		if index < 3 && found > mcpLineIndex(lines, `"id":1,`) {
+			test.Errorf("Expected %s before the answer to the call but got %s", fragment, output.String())
+		}
+	}
+}
+
+func TestMcpCallsRunInTheBackground(test *testing.T) {
+	in, input := io.Pipe()
+	output, out := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- newMcpServer(in, out).serve(context.Background())
+		out.Close()
+	}()
+	responses := bufio.NewScanner(output)
+
+	// a solve that would take far too long to wait for
+	io.WriteString(input, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"hillclimb","arguments":{"text":"GURDHVPX","generations":1000000000}}}`+"\n")
+	io.WriteString(input, `{"jsonrpc":"2.0","id":2,"method":"ping"}`+"\n")
+	if !responses.Scan() || !strings.Contains(responses.Text(), `"id":2,"result":{}`) {
+		test.Fatalf("Expected the ping to be answered while the solve ran but got %s", responses.Text())
+	}
+
+	io.WriteString(input, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1,"reason":"took too long"}}`+"\n")
+	input.Close()
+	rest := make([]string, 0)
+	for responses.Scan() {
+		rest = append(rest, responses.Text())
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			test.Errorf("Unexpected error serving: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		test.Fatalf("Expected the cancelled call to stop")
+	}
+	if len(rest) != 0 {
+		test.Errorf("Expected no answer to the cancelled call but got %v", rest)
+	}
+}
+
+func TestServedJobs(test *testing.T) {
+	server := httptest.NewServer(newSolverHandler())
+	defer server.Close()
+
+	submit := func(body string) (int, servedJob) {
+		response, err := http.Post(server.URL+"/jobs", "application/json", strings.NewReader(body))
+		if err != nil {
+			test.Fatalf("Unexpected error submitting %s: %v", body, err)
+		}
+		defer response.Body.Close()
+		var described servedJob
+		json.NewDecoder(response.Body).Decode(&described)
+		return response.StatusCode, described
+	}
+	check := func(method, id string) (int, servedJob) {
+		request, _ := http.NewRequest(method, server.URL+"/jobs/"+id, nil)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			test.Fatalf("Unexpected error with %s /jobs/%s: %v", method, id, err)
+		}
+		defer response.Body.Close()
+		var described servedJob
+		json.NewDecoder(response.Body).Decode(&described)
+		return response.StatusCode, described
+	}
+	// waitFor polls the job until done says to stop
+	waitFor := func(id string, done func(servedJob) bool) servedJob {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			_, described := check(http.MethodGet, id)
+			if done(described) || time.Now().After(deadline) {
+				return described
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	status, described := submit(`{"solver": "caesar", "parameters": {"text": "Uryyb", "shift": 13}}`)
+	if status != http.StatusAccepted || described.ID == "" {
+		test.Fatalf("Expected the caesar job to be accepted but got %d with %v", status, described)
+	}
+	finished := waitFor(described.ID, func(job servedJob) bool { return job.Status == jobDone })
+	if finished.Status != jobDone || finished.Result == nil || finished.Result.Results[0]["text"] != "Hello" {
+		test.Errorf("Expected the caesar job to finish with Hello but got %v", finished)
+	}
+	if status, removed := check(http.MethodDelete, described.ID); status != http.StatusOK || removed.Status != jobDone {
+		test.Errorf("Expected deleting a finished job to say it was done but got %d with %v", status, removed)
+	}
+	if status, _ := check(http.MethodGet, described.ID); status != http.StatusNotFound {
+		test.Errorf("Expected a deleted job to be gone but got %d", status)
+	}
+
+	// a long job reports its progress and can be cancelled
+	_, long := submit(`{"solver": "hillclimb", "parameters": {"text": "GURDHVPX", "generations": 1000000000}}`)
+	if progressing := waitFor(long.ID, func(job servedJob) bool { return job.Progress != "" }); !strings.Contains(progressing.Progress, "of 1e+09: generation") {
+		test.Errorf("Expected the hillclimb job to report its generations but got %v", progressing)
+	}
+	if status, cancelled := check(http.MethodDelete, long.ID); status != http.StatusOK || cancelled.Status != jobCancelled {
+		test.Errorf("Expected the hillclimb job to be cancelled but got %d with %v", status, cancelled)
+	}
+
+	if status, _ := submit(`{"solver": "nothing", "parameters": {}}`); status != http.StatusNotFound {
+		test.Errorf("Expected an unknown solver to be not found but got %d", status)
+	}
+	if status, _ := submit(`{"solver": "caesar", "parameters": {"shift": 13}}`); status != http.StatusBadRequest {
+		test.Errorf("Expected missing parameters to be turned away but got %d", status)
+	}
+	if status, _ := check(http.MethodGet, "missing"); status != http.StatusNotFound {
+		test.Errorf("Expected an unknown job to be not found but got %d", status)
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+)
+
+// Frequency files only list the ngrams their corpus had, so the rest need a score too. Scoring them as
+// impossibly rare swamps everything else on short texts, so the table is smoothed instead: either
+// unseen ngrams get the frequency of the rarest one seen (floor), or every count gets k added to it (add-k).
+
+const (
+	smoothingFloor = "floor"
+	smoothingAddK  = "add-k"
+	// fixed is the old flat score for unseen ngrams, for matching the results of earlier runs
+	smoothingFixed = "fixed"
+)
+
+const fixedUnseenScore = -1000
+
+var ngramSmoothing string
+var ngramSmoothingK float64
+
+// ngramFrequencyMap is the log10 frequency of each ngram in a frequency file, along with the log10
+// frequency to use for ngrams that aren't in it
+type ngramFrequencyMap struct {
+	frequencies map[string]float64
+	unseen      float64
+	// rarest is the lowest frequency in the file, which is the frequency of an ngram seen just once
+	rarest float64
+}
+
+// score returns the log10 frequency of ngram and whether it was in the file
+func (frequencyMap *ngramFrequencyMap) score(ngram string) (float64, bool) {
+	frequency, isPresent := frequencyMap.frequencies[ngram]
+	if !isPresent {
+		return frequencyMap.unseen, false
+	}
+	return frequency, true
+}
+
+// smoothed returns a copy of the frequencies smoothed by method. add-k works out the size of the corpus
+// from the rarest ngram, taking it to have been seen once, and adds k to the count of every possible ngram
+func (frequencyMap *ngramFrequencyMap) smoothed(method string, k float64) (*ngramFrequencyMap, error) {
+	switch method {
+	case smoothingFloor:
+		return &ngramFrequencyMap{frequencyMap.frequencies, frequencyMap.rarest, frequencyMap.rarest}, nil
+	case smoothingFixed:
+		return &ngramFrequencyMap{frequencyMap.frequencies, fixedUnseenScore, frequencyMap.rarest}, nil
+	case smoothingAddK:
+		if k <= 0 {
+			return nil, fmt.Errorf("add-k smoothing needs a k above 0 but got %v", k)
+		}
+	default:
+		return nil, fmt.Errorf("unknown smoothing %s; use floor, add-k, or fixed", method)
+	}
+
+	corpusSize := math.Pow(10, -frequencyMap.rarest)
+	possible := math.Pow(float64(activeAlphabet.size()), float64(ngramSize))
+	smoothedTotal := math.Log10(corpusSize + k*possible)
+	smoothed := &ngramFrequencyMap{
+		frequencies: make(map[string]float64, len(frequencyMap.frequencies)),
+		unseen:      math.Log10(k) - smoothedTotal,
+		rarest:      math.Inf(1),
+	}
+	for ngram, frequency := range frequencyMap.frequencies {
+		count := math.Pow(10, frequency) * corpusSize
+		smoothed.frequencies[ngram] = math.Log10(count+k) - smoothedTotal
+		smoothed.rarest = math.Min(smoothed.rarest, smoothed.frequencies[ngram])
+	}
+	return smoothed, nil
+}
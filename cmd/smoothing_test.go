@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSmoothedFrequencyMap(test *testing.T) {
+	// a corpus of 100 bigrams: AB 90 times, BA 9 times, and AA once
+	frequencyMap, _, _ := populateFrequencyMapFromReader(strings.NewReader("AB\t-0.0457574906\nBA\t-1.0457574906\nAA\t-2"))
+	if frequencyMap.unseen != -2 {
+		test.Errorf("Expected unseen bigrams to start at the rarest frequency, -2, but got %f", frequencyMap.unseen)
+	}
+
+	tests := []struct {
+		method   string
+		k        float64
+		unseen   float64
+		expected map[string]float64
+	}{
+		{smoothingFloor, 0, -2, map[string]float64{"AB": -0.0457574906, "AA": -2}},
+		{smoothingFixed, 0, -1000, map[string]float64{"AB": -0.0457574906, "AA": -2}},
+		// 676 possible bigrams with 1 added to each count makes a total of 776
+		{smoothingAddK, 1, math.Log10(1.0 / 776), map[string]float64{"AB": math.Log10(91.0 / 776), "AA": math.Log10(2.0 / 776)}},
+	}
+	for _, testCase := range tests {
+		smoothed, err := frequencyMap.smoothed(testCase.method, testCase.k)
+		if err != nil {
+			test.Errorf("Unexpected error smoothing with %s: %v", testCase.method, err)
+			continue
+		}
+		if math.Abs(smoothed.unseen-testCase.unseen) > 1e-6 {
+			test.Errorf("Expected %s to score unseen bigrams %f but got %f", testCase.method, testCase.unseen, smoothed.unseen)
+		}
+		for ngram, expected := range testCase.expected {
+			if actual, _ := smoothed.score(ngram); math.Abs(actual-expected) > 1e-6 {
+				test.Errorf("Expected %s to score %s %f but got %f", testCase.method, ngram, expected, actual)
+			}
+		}
+	}
+	// smoothing makes a copy, leaving the original alone
+	if frequencyMap.unseen != -2 || frequencyMap.frequencies["AA"] != -2 {
+		test.Errorf("Expected the original frequencies to be unchanged but got %v", frequencyMap)
+	}
+
+	for _, bad := range []struct {
+		method string
+		k      float64
+	}{{smoothingAddK, 0}, {"laplace", 1}} {
+		if _, err := frequencyMap.smoothed(bad.method, bad.k); err == nil {
+			test.Errorf("Expected an error smoothing with %s and k %f", bad.method, bad.k)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var solverFormat string
+var solverMaxRows int
+var solverBudgetMs int
+
+// solverCmd represents the solver command
+var solverCmd = &cobra.Command{
+	Use:   "solver",
+	Short: "Runs any of the registered solvers, taking their parameters as flags",
+	Long: `Every solver in the registry gets a subcommand here, with a flag for each of its parameters,
+	and its results come back as a table (or JSON with --format json).
+
+	Examples:
+	  puzzle_helper solver list
+	  puzzle_helper solver caesar --text "Uryyb jbeyq" --format json`,
+}
+
+var solverListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the registered solvers and their parameters",
+	Args:  cobra.NoArgs,
+	Run:   listSolvers,
+}
+
+func listSolvers(cmd *cobra.Command, args []string) {
+	for _, registered := range registeredSolvers() {
+		fmt.Printf("%s: %s\n", registered.name, registered.description)
+		for _, parameter := range registered.parameters {
+			required := ""
+			if parameter.required {
+				required = ", required"
+			}
+			fmt.Printf("  --%s (%s%s) %s\n", parameter.name, parameter.kind, required, parameter.description)
+		}
+	}
+}
+
+// newSolverCommand makes a subcommand for a registered solver with a flag for each parameter
+func newSolverCommand(registered *solver) *cobra.Command {
+	command := &cobra.Command{
+		Use:   registered.name,
+		Short: registered.description,
+		Args:  cobra.NoArgs,
+	}
+	for _, parameter := range registered.parameters {
+		usage := fmt.Sprintf("%s (%s)", parameter.description, parameter.kind)
+		if parameter.kind == solverBool {
+			// a real bool flag so that a bare --permutations means true
+			defaultValue, _ := strconv.ParseBool(parameter.defaultValue)
+			command.Flags().Bool(parameter.name, defaultValue, usage)
+		} else {
+			command.Flags().String(parameter.name, parameter.defaultValue, usage)
+		}
+		if parameter.required {
+			command.MarkFlagRequired(parameter.name)
+		}
+	}
+
+	command.Run = func(cmd *cobra.Command, args []string) {
+		raw := make(map[string]string)
+		for _, parameter := range registered.parameters {
+			if cmd.Flags().Changed(parameter.name) {
+				raw[parameter.name] = cmd.Flags().Lookup(parameter.name).Value.String()
+			}
+		}
+		if solverBudgetMs > 0 {
+			raw[solverBudgetParameter] = strconv.Itoa(solverBudgetMs)
+		}
+
+		ctx, cancel := solveContext()
+		defer cancel()
+		table, err := runSolver(ctx, registered, raw)
+		if err != nil {
+			fmt.Printf("Could not solve: %v\n", err)
+			os.Exit(1)
+		}
+		recordResults(len(table.rows))
+		format := solverFormat
+		if !cmd.Flags().Changed("format") && outputFormat == "json" {
+			format = "json"
+		}
+		if err := outputResponse(os.Stdout, table, format, solverMaxRows); err != nil {
+			fmt.Printf("Could not write results: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	return command
+}
+
+// addSolverCommands gives every registered solver its subcommand. It runs once every init
+// function has had its chance to register a solver
+func addSolverCommands() {
+	for _, registered := range registeredSolvers() {
+		solverCmd.AddCommand(newSolverCommand(registered))
+	}
+}
+
+func init() {
+	solverCmd.PersistentFlags().StringVarP(&solverFormat, "format", "", "text", "how to write the results: text, json, or both")
+	solverCmd.PersistentFlags().IntVarP(&solverBudgetMs, "budget-ms", "", 0, "the most milliseconds a solver can spend before returning what it has found. 0 means no limit")
+	solverCmd.PersistentFlags().IntVarP(&solverMaxRows, "max-rows", "", 0, "the most rows to show in the text table. 0 means no limit")
+	solverCmd.AddCommand(solverListCmd)
+	rootCmd.AddCommand(solverCmd)
+}
@@ -0,0 +1,420 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Solvers can be registered by name with a description of their parameters and a function that runs
+// them. Everything that lists solvers works from the registry, so a new puzzle type only has to call
+// registerSolver from its init function instead of being wired up by hand in each place. Code outside
+// this package does the same with RegisterSolver.
+
+// every solver takes this parameter: the most milliseconds it can spend before it stops and returns
+// what it has found, marked as truncated. Solvers have to watch their context for this to work
+const solverBudgetParameter = "budgetMs"
+
+type solverParameterKind string
+
+const (
+	solverString solverParameterKind = "string"
+	solverInt    solverParameterKind = "integer"
+	solverBool   solverParameterKind = "boolean"
+	solverNumber solverParameterKind = "number"
+)
+
+// solverParameter describes one input a solver takes
+type solverParameter struct {
+	name         string
+	kind         solverParameterKind
+	description  string
+	required     bool
+	defaultValue string
+}
+
+// solverInput holds a solver's parameters after they've been checked and converted to their kinds
+type solverInput map[string]interface{}
+
+func (input solverInput) getString(name string) string {
+	value, _ := input[name].(string)
+	return value
+}
+
+// getList splits a comma-separated string parameter, such as several dictionary files, dropping blank items
+func (input solverInput) getList(name string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(input.getString(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (input solverInput) getInt(name string) int {
+	value, _ := input[name].(int)
+	return value
+}
+
+func (input solverInput) getFloat(name string) float64 {
+	value, _ := input[name].(float64)
+	return value
+}
+
+func (input solverInput) getBool(name string) bool {
+	value, _ := input[name].(bool)
+	return value
+}
+
+// solverProgressFunc hears how far along a solver is: progress out of total, which is 0 when the solver
+// can't say, and a description of the best it has so far
+type solverProgressFunc func(progress, total float64, message string)
+
+type solverProgressKey struct{}
+
+// withSolverProgress gives solvers run with the returned context somewhere to report their progress,
+// on top of wherever ctx already sends it
+func withSolverProgress(ctx context.Context, report solverProgressFunc) context.Context {
+	if outer, wanted := ctx.Value(solverProgressKey{}).(solverProgressFunc); wanted {
+		inner := report
+		report = func(progress, total float64, message string) {
+			inner(progress, total, message)
+			outer(progress, total, message)
+		}
+	}
+	return context.WithValue(ctx, solverProgressKey{}, report)
+}
+
+// reportSolverProgress tells whoever is running a solver how far along it is, if they asked to know.
+// Long searches should call it now and then
+func reportSolverProgress(ctx context.Context, progress, total float64, message string) {
+	if report, wanted := ctx.Value(solverProgressKey{}).(solverProgressFunc); wanted {
+		report(progress, total, message)
+	}
+}
+
+type solverFunc func(ctx context.Context, input solverInput) (*resultTable, error)
+
+// solverStreamFunc runs a solver that can hand over its results as it finds them, calling send with
+// each one's values, one for each of the solver's columns
+type solverStreamFunc func(ctx context.Context, input solverInput, send func(values ...string)) error
+
+// solver is a registered solver. Solvers that can stream their results also have the columns
+// of those results and a stream function
+type solver struct {
+	name        string
+	description string
+	parameters  []solverParameter
+	run         solverFunc
+	columns     []string
+	stream      solverStreamFunc
+}
+
+var solverRegistryLock sync.Mutex
+var solverRegistry = make(map[string]*solver)
+
+// registerSolver adds a solver to the registry. Names have to be unique
+func registerSolver(newSolver *solver) error {
+	solverRegistryLock.Lock()
+	defer solverRegistryLock.Unlock()
+	if newSolver.name == "" || newSolver.run == nil {
+		return fmt.Errorf("a solver needs a name and a run function")
+	}
+	if _, exists := solverRegistry[newSolver.name]; exists {
+		return fmt.Errorf("a solver named %s is already registered", newSolver.name)
+	}
+	solverRegistry[newSolver.name] = newSolver
+	return nil
+}
+
+func mustRegisterSolver(newSolver *solver) {
+	if err := registerSolver(newSolver); err != nil {
+		panic(err)
+	}
+}
+
+// Solver is a puzzle type that code outside this package can add with RegisterSolver. Once it's registered
+// it appears under the solver command and is served by serve http and serve mcp, just like the built-in ones
+type Solver struct {
+	Name        string
+	Description string
+	Parameters  []SolverParameter
+	// Run solves for input, which has every parameter checked and converted to its kind. It should stop
+	// when ctx is done and return what it has found so far, which is then marked as truncated
+	Run func(ctx context.Context, input SolverInput) (*SolverResult, error)
+}
+
+// SolverParameter describes one input a Solver takes. Kind is string, integer, boolean, or number, as in
+// JSON schema, and Default is the value it gets when it isn't given, written as it would be on the command line
+type SolverParameter struct {
+	Name        string
+	Kind        string
+	Description string
+	Required    bool
+	Default     string
+}
+
+// SolverInput is a Solver's parameters, each one converted to its kind
+type SolverInput struct {
+	values solverInput
+}
+
+func (input SolverInput) String(name string) string {
+	return input.values.getString(name)
+}
+
+// List splits a comma-separated string parameter, dropping blank items
+func (input SolverInput) List(name string) []string {
+	return input.values.getList(name)
+}
+
+func (input SolverInput) Int(name string) int {
+	return input.values.getInt(name)
+}
+
+func (input SolverInput) Number(name string) float64 {
+	return input.values.getFloat(name)
+}
+
+func (input SolverInput) Bool(name string) bool {
+	return input.values.getBool(name)
+}
+
+// SolverResult is what a Solver found: a row of values for each result, one value for each column
+type SolverResult struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// RegisterSolver adds a Solver to the registry. Call it from an init function, before Execute, so the
+// solver command has it. Names have to be unique, and every parameter needs a name and a known kind
+func RegisterSolver(extension Solver) error {
+	if extension.Run == nil {
+		return fmt.Errorf("a solver needs a name and a run function")
+	}
+	parameters := make([]solverParameter, 0, len(extension.Parameters))
+	for _, parameter := range extension.Parameters {
+		kind := solverParameterKind(parameter.Kind)
+		if kind != solverString && kind != solverInt && kind != solverBool && kind != solverNumber {
+			return fmt.Errorf("%s's %s parameter should be a string, integer, boolean, or number but is %q", extension.Name, parameter.Name, parameter.Kind)
+		}
+		if parameter.Name == "" || parameter.Name == solverBudgetParameter {
+			return fmt.Errorf("%s has a parameter without a name or named %s, which every solver already takes", extension.Name, solverBudgetParameter)
+		}
+		parameters = append(parameters, solverParameter{parameter.Name, kind, parameter.Description, parameter.Required, parameter.Default})
+	}
+
+	return registerSolver(&solver{
+		name:        extension.Name,
+		description: extension.Description,
+		parameters:  parameters,
+		run: func(ctx context.Context, input solverInput) (*resultTable, error) {
+			result, err := extension.Run(ctx, SolverInput{input})
+			if err != nil {
+				return nil, err
+			}
+			if result == nil {
+				return nil, fmt.Errorf("%s returned no result", extension.Name)
+			}
+			table := newResultTable(result.Columns...)
+			for _, row := range result.Rows {
+				if len(row) != len(result.Columns) {
+					return nil, fmt.Errorf("%s returned a row of %d values for %d columns", extension.Name, len(row), len(result.Columns))
+				}
+				table.addRow(row...)
+			}
+			return table, nil
+		},
+	})
+}
+
+// setParameterDefault gives every registered solver's parameter called name a default of value,
+// so callers no longer have to pass it
+func setParameterDefault(name, value string) {
+	solverRegistryLock.Lock()
+	defer solverRegistryLock.Unlock()
+	for _, registered := range solverRegistry {
+		for index := range registered.parameters {
+			if registered.parameters[index].name == name {
+				registered.parameters[index].defaultValue = value
+				registered.parameters[index].required = false
+			}
+		}
+	}
+}
+
+// registeredSolvers returns every solver in the registry, sorted by name
+func registeredSolvers() []*solver {
+	solverRegistryLock.Lock()
+	defer solverRegistryLock.Unlock()
+	solvers := make([]*solver, 0, len(solverRegistry))
+	for _, registered := range solverRegistry {
+		solvers = append(solvers, registered)
+	}
+	sort.Slice(solvers, func(i, j int) bool {
+		return solvers[i].name < solvers[j].name
+	})
+	return solvers
+}
+
+// lookupSolver returns the solver registered under name, or nil if there isn't one
+func lookupSolver(name string) *solver {
+	solverRegistryLock.Lock()
+	defer solverRegistryLock.Unlock()
+	return solverRegistry[name]
+}
+
+// parseInput checks raw parameter values against the solver's parameters and converts them to
+// their kinds, filling in defaults. Parameters the solver doesn't know about are an error
+func (registered *solver) parseInput(raw map[string]string) (solverInput, error) {
+	input := make(solverInput)
+	known := make(map[string]bool)
+	for _, parameter := range registered.parameters {
+		known[parameter.name] = true
+		value, given := raw[parameter.name]
+		if !given {
+			if parameter.required {
+				return nil, fmt.Errorf("%s needs the %s parameter", registered.name, parameter.name)
+			}
+			value = parameter.defaultValue
+		}
+
+		converted, err := parameter.convert(value)
+		if err != nil {
+			return nil, err
+		}
+		input[parameter.name] = converted
+	}
+
+	for name := range raw {
+		if !known[name] && name != solverBudgetParameter {
+			return nil, fmt.Errorf("%s has no parameter named %s", registered.name, name)
+		}
+	}
+	return input, nil
+}
+
+// convert turns a raw value into the parameter's kind. Empty values are the kind's zero value
+func (parameter solverParameter) convert(value string) (interface{}, error) {
+	switch parameter.kind {
+	case solverInt:
+		if value == "" {
+			return 0, nil
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s should be an integer but got %s", parameter.name, value)
+		}
+		return number, nil
+	case solverNumber:
+		if value == "" {
+			return 0.0, nil
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, fmt.Errorf("%s should be a number but got %s", parameter.name, value)
+		}
+		return number, nil
+	case solverBool:
+		if value == "" {
+			return false, nil
+		}
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s should be true or false but got %s", parameter.name, value)
+		}
+		return flag, nil
+	}
+	return value, nil
+}
+
+// inputSchema describes the solver's parameters as a JSON schema object, for anything that needs
+// to tell callers what a solver takes
+func (registered *solver) inputSchema() map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := make([]string, 0)
+	for _, parameter := range registered.parameters {
+		property := map[string]interface{}{"type": string(parameter.kind), "description": parameter.description}
+		if parameter.defaultValue != "" {
+			// the default is given in the parameter's own kind
+			if defaultValue, err := parameter.convert(parameter.defaultValue); err == nil {
+				property["default"] = defaultValue
+			}
+		}
+		properties[parameter.name] = property
+		if parameter.required {
+			required = append(required, parameter.name)
+		}
+	}
+	properties[solverBudgetParameter] = map[string]interface{}{"type": string(solverInt), "description": "the most milliseconds to spend before returning what has been found"}
+	return map[string]interface{}{"type": "object", "properties": properties, "required": required}
+}
+
+// runSolver parses raw into the solver's input and runs it within the budgetMs parameter, if there
+// is one. If ctx is cancelled or the budget runs out, the results found so far come back marked as truncated
+func runSolver(ctx context.Context, registered *solver, raw map[string]string) (*resultTable, error) {
+	input, err := registered.parseInput(raw)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel, err := solverBudgetContext(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	table, err := registered.run(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		table.truncated = true
+	}
+	return table, nil
+}
+
+// streamSolver is runSolver for solvers that can stream: each result goes to send as soon as it's found
+// instead of into a table. It returns whether the search stopped early, so there may have been more
+func streamSolver(ctx context.Context, registered *solver, raw map[string]string, send func(values ...string)) (bool, error) {
+	if registered.stream == nil {
+		return false, fmt.Errorf("%s can't stream its results", registered.name)
+	}
+	input, err := registered.parseInput(raw)
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel, err := solverBudgetContext(ctx, raw)
+	if err != nil {
+		return false, err
+	}
+	defer cancel()
+
+	if err := registered.stream(ctx, input, send); err != nil {
+		return false, err
+	}
+	return ctx.Err() != nil, nil
+}
+
+// solverBudgetContext limits ctx to the budgetMs parameter in raw, if there is one
+func solverBudgetContext(ctx context.Context, raw map[string]string) (context.Context, context.CancelFunc, error) {
+	budget := 0
+	if budgetValue := raw[solverBudgetParameter]; budgetValue != "" {
+		var err error
+		budget, err = strconv.Atoi(budgetValue)
+		if err != nil || budget < 0 {
+			return nil, nil, fmt.Errorf("%s should be a number of milliseconds but got %s", solverBudgetParameter, budgetValue)
+		}
+	}
+	if budget == 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(budget)*time.Millisecond)
+	return ctx, cancel, nil
+}
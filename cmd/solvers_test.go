@@ -0,0 +1,359 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSolverRegistry(test *testing.T) {
+	if lookupSolver("caesar") == nil || lookupSolver("rot") == nil {
+		test.Fatalf("Expected the built-in solvers to be registered")
+	}
+	if err := registerSolver(&solver{name: "caesar", run: runCaesarSolver}); err == nil {
+		test.Errorf("Expected an error registering a second caesar solver")
+	}
+	if err := registerSolver(&solver{name: "nothing"}); err == nil {
+		test.Errorf("Expected an error registering a solver without a run function")
+	}
+
+	solvers := registeredSolvers()
+	for index := 1; index < len(solvers); index++ {
+		if solvers[index-1].name >= solvers[index].name {
+			test.Errorf("Expected solvers sorted by name but got %s before %s", solvers[index-1].name, solvers[index].name)
+		}
+	}
+}
+
+func TestSolverParseInput(test *testing.T) {
+	rot := lookupSolver("rot")
+	input, err := rot.parseInput(map[string]string{"text": "Call 555"})
+	if err != nil {
+		test.Fatalf("Unexpected error parsing input: %v", err)
+	}
+	if input.getString("text") != "Call 555" || input.getInt("n") != 13 {
+		test.Errorf("Expected the text and the default n of 13 but got %v", input)
+	}
+
+	badInputs := []map[string]string{
+		map[string]string{},
+		map[string]string{"text": "abc", "n": "five"},
+		map[string]string{"text": "abc", "shift": "3"},
+	}
+	for _, raw := range badInputs {
+		if _, err := rot.parseInput(raw); err == nil {
+			test.Errorf("Expected an error parsing %v", raw)
+		}
+	}
+
+	schema := rot.inputSchema()
+	properties := schema["properties"].(map[string]interface{})
+	if properties["n"].(map[string]interface{})["default"] != 13 {
+		test.Errorf("Expected n to default to the integer 13 in the schema but got %v", properties["n"])
+	}
+	if required := schema["required"].([]string); len(required) != 1 || required[0] != "text" {
+		test.Errorf("Expected text to be the only required parameter but got %v", required)
+	}
+}
+
+func TestBuiltInSolvers(test *testing.T) {
+	caesar := lookupSolver("caesar")
+	input, _ := caesar.parseInput(map[string]string{"text": "Uryyb", "shift": "13"})
+	table, err := caesar.run(context.Background(), input)
+	if err != nil {
+		test.Fatalf("Unexpected error running caesar: %v", err)
+	}
+	if len(table.rows) != 1 || strings.Join(table.rows[0], " ") != "13 Hello" {
+		test.Errorf("Expected just shift 13 but got %v", table.rows)
+	}
+
+	input, _ = caesar.parseInput(map[string]string{"text": "Uryyb"})
+	table, _ = caesar.run(context.Background(), input)
+	if len(table.rows) != 25 {
+		test.Errorf("Expected all 25 shifts but got %d", len(table.rows))
+	}
+
+	input, _ = caesar.parseInput(map[string]string{"text": "Uryyb jbeyq", "rank": "true"})
+	table, err = caesar.run(context.Background(), input)
+	if err != nil || len(table.rows) != 25 || strings.Join(table.rows[0][:2], " ") != "13 Hello world" {
+		test.Errorf("Expected shift 13 to rank first but got %v (%v)", table, err)
+	}
+
+	input, _ = caesar.parseInput(map[string]string{"text": "Hello", "shift": "3", "encode": "true"})
+	table, err = caesar.run(context.Background(), input)
+	if err != nil || len(table.columns) != 1 || len(table.rows) != 1 || table.rows[0][0] != "Khoor" {
+		test.Errorf("Expected Hello encoded as Khoor but got %v (%v)", table, err)
+	}
+	input, _ = caesar.parseInput(map[string]string{"text": "Hello", "encode": "true"})
+	if _, err := caesar.run(context.Background(), input); err == nil {
+		test.Errorf("Expected an error encoding without a shift")
+	}
+
+	rot := lookupSolver("rot")
+	input, _ = rot.parseInput(map[string]string{"text": "Call 555", "n": "18"})
+	table, _ = rot.run(context.Background(), input)
+	if table.rows[0][0] != "Pnyy 000" {
+		test.Errorf("Expected ROT18 to give Pnyy 000 but got %v", table.rows)
+	}
+}
+
+func TestRunSolverBudget(test *testing.T) {
+	slow := &solver{name: "slow", run: func(ctx context.Context, input solverInput) (*resultTable, error) {
+		table := newResultTable("count")
+		for count := 0; ctx.Err() == nil; count++ {
+			if count < 3 {
+				table.addRow(strconv.Itoa(count))
+			}
+		}
+		return table, nil
+	}}
+
+	table, err := runSolver(context.Background(), slow, map[string]string{solverBudgetParameter: "20"})
+	if err != nil {
+		test.Fatalf("Unexpected error running within a budget: %v", err)
+	}
+	if !table.truncated || len(table.rows) != 3 {
+		test.Errorf("Expected the rows found so far marked truncated but got %v (truncated %v)", table.rows, table.truncated)
+	}
+
+	table, _ = runSolver(context.Background(), lookupSolver("rot"), map[string]string{"text": "abc", solverBudgetParameter: "1000"})
+	if table.truncated {
+		test.Errorf("Expected a solver that finished in time not to be truncated")
+	}
+
+	if _, err := runSolver(context.Background(), slow, map[string]string{solverBudgetParameter: "soon"}); err == nil {
+		test.Errorf("Expected an error for a budget that isn't a number")
+	}
+
+	schema := lookupSolver("rot").inputSchema()
+	if _, present := schema["properties"].(map[string]interface{})[solverBudgetParameter]; !present {
+		test.Errorf("Expected every solver's schema to take %s", solverBudgetParameter)
+	}
+}
+
+// solverSamples is an input for every registered solver that it should be able to solve. A solver
+// added to the registry needs a sample here so TestSolversConform can check it. {dictionary} and
+// {phrases} are replaced with the paths of a small dictionary and phrase list
+var solverSamples = map[string]map[string]string{
+	"aristocrat":  {"text": "GUR PNG FNG BA GUR ZNG"},
+	"caesar":      {"text": "Uryyb"},
+	"dropquote":   {"rows": "....", "columns": "S,T,O,P", "dictionary": "{dictionary}"},
+	"fill":        {"pattern": "?o??", "crossings": "1:s*o?", "dictionary": "{dictionary}"},
+	"hillclimb":   {"text": "GURDHVPX", "generations": "1", "regen_after": "5"},
+	"isanagram":   {"first": "Dormitory", "second": "Dirty room"},
+	"language":    {"text": "THE QUICK BROWN FOX JUMPS OVER THE LAZY DOG"},
+	"letterbank":  {"bank": "OPST", "dictionary": "{dictionary}"},
+	"letterboxed": {"sides": "P,O,S,T", "dictionary": "{dictionary}"},
+	"pattern":     {"pattern": "s?o?", "dictionary": "{dictionary}"},
+	"phrase":      {"search": "(3,3)", "dictionary": "{phrases}"},
+	"rack":        {"letters": "OPST", "dictionary": "{dictionary}"},
+	"rot":         {"text": "Call 555"},
+	"t9":          {"digits": "7867", "dictionary": "{dictionary}"},
+	"transposal":  {"letters": "stop", "dictionary": "{dictionary}"},
+}
+
+// TestSolversConform checks everything in the registry behaves the way the things that list and
+// run solvers expect: parameters that describe themselves, required parameters that are enforced,
+// rows as wide as the columns, and stopping with what they have when they're cancelled
+func TestSolversConform(test *testing.T) {
+	dir := test.TempDir()
+	dictionary := filepath.Join(dir, "words.txt")
+	os.WriteFile(dictionary, []byte("POST\nSTOP\nSPOT\nTOPS\nPOTS\n"), 0644)
+	phrases := filepath.Join(dir, "phrases.txt")
+	os.WriteFile(phrases, []byte("THE CAT\nTHE END\nTHE\n"), 0644)
+
+	for _, registered := range registeredSolvers() {
+		sample, present := solverSamples[registered.name]
+		if !present {
+			test.Errorf("Expected a sample input for the %s solver in solverSamples", registered.name)
+			continue
+		}
+		raw := make(map[string]string)
+		for name, value := range sample {
+			raw[name] = strings.NewReplacer("{dictionary}", dictionary, "{phrases}", phrases).Replace(value)
+		}
+		checkSolverConforms(test, registered, raw)
+	}
+}
+
+func checkSolverConforms(test *testing.T, registered *solver, sample map[string]string) {
+	if registered.description == "" {
+		test.Errorf("Expected %s to have a description", registered.name)
+	}
+	required := make([]string, 0)
+	for _, parameter := range registered.parameters {
+		if parameter.name == "" || parameter.description == "" {
+			test.Errorf("Expected every %s parameter to have a name and description but got %v", registered.name, parameter)
+		}
+		if parameter.kind != solverString && parameter.kind != solverInt && parameter.kind != solverBool && parameter.kind != solverNumber {
+			test.Errorf("Expected %s's %s to be a string, integer, number, or boolean but got %s", registered.name, parameter.name, parameter.kind)
+		}
+		if _, err := parameter.convert(parameter.defaultValue); err != nil {
+			test.Errorf("Expected %s's default for %s to be a valid %s but got %v", registered.name, parameter.name, parameter.kind, err)
+		}
+		if parameter.required {
+			required = append(required, parameter.name)
+			without := make(map[string]string)
+			for name, value := range sample {
+				if name != parameter.name {
+					without[name] = value
+				}
+			}
+			if _, err := registered.parseInput(without); err == nil {
+				test.Errorf("Expected %s to need %s", registered.name, parameter.name)
+			}
+		}
+	}
+
+	schema := registered.inputSchema()
+	if properties := schema["properties"].(map[string]interface{}); len(properties) != len(registered.parameters)+1 {
+		test.Errorf("Expected %s's schema to have its %d parameters and %s but got %v", registered.name, len(registered.parameters), solverBudgetParameter, properties)
+	}
+	if schemaRequired := schema["required"].([]string); strings.Join(schemaRequired, ",") != strings.Join(required, ",") {
+		test.Errorf("Expected %s's schema to require %v but got %v", registered.name, required, schemaRequired)
+	}
+
+	table, err := runSolver(context.Background(), registered, sample)
+	if err != nil {
+		test.Errorf("Unexpected error running %s on %v: %v", registered.name, sample, err)
+		return
+	}
+	if len(table.rows) == 0 || table.truncated {
+		test.Errorf("Expected %s to finish with results for %v but got %v (truncated %v)", registered.name, sample, table.rows, table.truncated)
+	}
+	for _, row := range table.rows {
+		if len(row) != len(table.columns) {
+			test.Errorf("Expected each of %s's rows to have a value for each of %v but got %v", registered.name, table.columns, row)
+		}
+	}
+
+	if registered.stream != nil {
+		streamed := 0
+		truncated, err := streamSolver(context.Background(), registered, sample, func(values ...string) {
+			if streamed++; len(values) != len(registered.columns) {
+				test.Errorf("Expected each of %s's streamed results to have a value for each of %v but got %v", registered.name, registered.columns, values)
+			}
+		})
+		if err != nil || streamed == 0 || truncated {
+			test.Errorf("Expected %s to stream results for %v but got %d (truncated %v, error %v)", registered.name, sample, streamed, truncated, err)
+		}
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	done := make(chan bool)
+	go func() {
+		table, err := runSolver(cancelled, registered, sample)
+		if err == nil && !table.truncated {
+			test.Errorf("Expected %s to mark its results truncated when it's cancelled", registered.name)
+		}
+		done <- true
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		test.Errorf("Expected %s to stop when it's cancelled", registered.name)
+	}
+}
+
+func TestSolverListParameters(test *testing.T) {
+	phrase := lookupSolver("phrase")
+	if _, err := phrase.parseInput(map[string]string{"search": "(3)"}); err == nil {
+		test.Errorf("Expected the dictionary to be required")
+	}
+
+	saved := make(map[*solver][]solverParameter)
+	for _, registered := range registeredSolvers() {
+		saved[registered] = append([]solverParameter(nil), registered.parameters...)
+	}
+	defer func() {
+		for registered, parameters := range saved {
+			registered.parameters = parameters
+		}
+	}()
+	setParameterDefault("dictionary", "first.txt, second.txt,")
+	input, err := phrase.parseInput(map[string]string{"search": "(3)"})
+	if err != nil {
+		test.Fatalf("Unexpected error with a default dictionary: %v", err)
+	}
+	if dictionaries := input.getList("dictionary"); strings.Join(dictionaries, " ") != "first.txt second.txt" {
+		test.Errorf("Expected first.txt and second.txt but got %v", dictionaries)
+	}
+}
+
+func TestSolverCommandFlags(test *testing.T) {
+	command := newSolverCommand(lookupSolver("transposal"))
+	if err := command.ParseFlags([]string{"--letters", "APT", "--permutations"}); err != nil {
+		test.Fatalf("Expected a bare --permutations to parse but got %v", err)
+	}
+	if permutations, err := command.Flags().GetBool("permutations"); err != nil || !permutations {
+		test.Errorf("Expected --permutations to be a bool flag set to true but got %v (%v)", permutations, err)
+	}
+	if letters := command.Flags().Lookup("letters").Value.String(); letters != "APT" {
+		test.Errorf("Expected --letters to stay a string flag but got %s", letters)
+	}
+}
+
+func TestRegisterSolver(test *testing.T) {
+	extension := Solver{
+		Name:        "repeat",
+		Description: "Says a word over and over",
+		Parameters: []SolverParameter{
+			{Name: "word", Kind: "string", Description: "the word to say", Required: true},
+			{Name: "times", Kind: "integer", Description: "how many times", Default: "2"},
+			{Name: "shout", Kind: "boolean", Description: "whether to shout it"},
+		},
+		Run: func(ctx context.Context, input SolverInput) (*SolverResult, error) {
+			word := input.String("word")
+			if input.Bool("shout") {
+				word = strings.ToUpper(word)
+			}
+			result := &SolverResult{Columns: []string{"word"}}
+			for count := 0; count < input.Int("times"); count++ {
+				result.Rows = append(result.Rows, []string{word})
+			}
+			return result, nil
+		},
+	}
+	if err := RegisterSolver(extension); err != nil {
+		test.Fatalf("Unexpected error registering: %v", err)
+	}
+	defer func() {
+		solverRegistryLock.Lock()
+		delete(solverRegistry, "repeat")
+		solverRegistryLock.Unlock()
+	}()
+
+	registered := lookupSolver("repeat")
+	if registered == nil {
+		test.Fatalf("Expected the registered solver to be in the registry")
+	}
+	table, err := runSolver(context.Background(), registered, map[string]string{"word": "hi", "shout": "true"})
+	if err != nil || len(table.rows) != 2 || table.rows[0][0] != "HI" {
+		test.Errorf("Expected HI twice but got %v (%v)", table, err)
+	}
+	if _, err := runSolver(context.Background(), registered, map[string]string{"times": "3"}); err == nil {
+		test.Errorf("Expected the word to be required")
+	}
+	if schema := registered.inputSchema()["properties"].(map[string]interface{}); schema["times"].(map[string]interface{})["default"] != 2 {
+		test.Errorf("Expected times to default to 2 in the schema but got %v", schema["times"])
+	}
+	if command := newSolverCommand(registered); command.Flags().Lookup("shout").Value.Type() != "bool" {
+		test.Errorf("Expected the solver command to take --shout as a bool flag")
+	}
+
+	if err := RegisterSolver(extension); err == nil {
+		test.Errorf("Expected an error registering the same name twice")
+	}
+	badKind := Solver{Name: "bad", Run: extension.Run, Parameters: []SolverParameter{{Name: "when", Kind: "date"}}}
+	if err := RegisterSolver(badKind); err == nil || lookupSolver("bad") != nil {
+		test.Errorf("Expected a parameter of an unknown kind to be refused")
+	}
+	if err := RegisterSolver(Solver{Name: "empty"}); err == nil {
+		test.Errorf("Expected a solver without a run function to be refused")
+	}
+}
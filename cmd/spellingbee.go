@@ -0,0 +1,116 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var spellingBeeMinLength int
+
+// spellingBeeCmd represents the spellingbee command
+var spellingBeeCmd = &cobra.Command{
+	Use:   "spellingbee CENTER OTHERLETTERS",
+	Short: "Finds the Spelling Bee words for a center letter and the letters around it",
+	Long: `Lists the dictionary words of at least --min-length letters (4 by default) that use only the
+	center letter and the other letters, as often as they like, and use the center letter at least once.
+	Pangrams, which use every letter, come first. Each word's points are given the way the New York Times
+	counts them: 1 for a four letter word, a point a letter for longer ones, and 7 more for a pangram.
+
+	Example:
+	  puzzle_helper spellingbee T ACYILP --dictionary words.txt`,
+	Args: cobra.ExactArgs(2),
+	Run:  printSpellingBeeWords,
+}
+
+// spellingBeeWord is a word that fits the hive and what it scores
+type spellingBeeWord struct {
+	word    string
+	pangram bool
+	points  int
+}
+
+func printSpellingBeeWords(cmd *cobra.Command, args []string) {
+	center := justUppercaseLetters(args[0])
+	others := justUppercaseLetters(args[1])
+	if len(center) != 1 || len(others) == 0 {
+		fmt.Printf("The center should be one letter, with at least one other letter around it\n")
+		os.Exit(1)
+	}
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+
+	printer := newResultPrinter("word", "pangram", "points")
+	total := 0
+	for _, found := range findSpellingBeeWords(rootTrie, center[0], others, spellingBeeMinLength) {
+		line := found.word
+		if found.pangram {
+			line += " (pangram)"
+		}
+		total += found.points
+		printer.result(line, found.word, strconv.FormatBool(found.pangram), strconv.Itoa(found.points))
+	}
+	printer.text("%d points in all", total)
+	printer.finish(false)
+}
+
+// findSpellingBeeWords walks rootTrie along only the hive's letters and returns the words of at least minLength
+// letters that use center, pangrams first and then alphabetically
+func findSpellingBeeWords(rootTrie *trieNode, center byte, others []byte, minLength int) []spellingBeeWord {
+	hive := map[byte]bool{center: true}
+	for _, letter := range others {
+		hive[letter] = true
+	}
+
+	found := make([]spellingBeeWord, 0)
+	collectSpellingBeeWords(rootTrie, hive, center, minLength, "", &found)
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].pangram && !found[j].pangram
+	})
+	return found
+}
+
+func collectSpellingBeeWords(node *trieNode, hive map[byte]bool, center byte, minLength int, currentWord string, found *[]spellingBeeWord) {
+	if node.IsWord() && len(currentWord) >= minLength && strings.IndexByte(currentWord, center) >= 0 {
+		pangram := len(distinctLetters(currentWord)) == len(hive)
+		points := len(currentWord)
+		if points == 4 {
+			points = 1
+		}
+		if pangram {
+			points += 7
+		}
+		*found = append(*found, spellingBeeWord{currentWord, pangram, points})
+	}
+
+	for _, child := range node.Children() {
+		if hive[child.Letter()[0]] {
+			collectSpellingBeeWords(child, hive, center, minLength, currentWord+child.Letter(), found)
+		}
+	}
+}
+
+func init() {
+	spellingBeeCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+	spellingBeeCmd.MarkFlagRequired("dictionary")
+	spellingBeeCmd.Flags().IntVarP(&spellingBeeMinLength, "min-length", "", 4, "The fewest letters a word can have")
+	rootCmd.AddCommand(spellingBeeCmd)
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFindSpellingBeeWords(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"TYPICAL", "TACIT", "PLAIT", "CLAP", "TILT", "CAT", "ITALIC", "TYPICALLY", "TOPICAL"} {
+		trie.Add(word, nil)
+	}
+
+	described := make([]string, 0)
+	for _, found := range findSpellingBeeWords(trie, 'T', []byte("ACYILP"), 4) {
+		described = append(described, fmt.Sprintf("%s:%v:%d", found.word, found.pangram, found.points))
+	}
+	// CLAP has no T, CAT is too short, and TOPICAL has an O
+	expected := "TYPICAL:true:14,TYPICALLY:true:16,ITALIC:false:6,PLAIT:false:5,TACIT:false:5,TILT:false:1"
+	if actual := strings.Join(described, ","); actual != expected {
+		test.Errorf("Expected %s but got %s", expected, actual)
+	}
+
+	if found := findSpellingBeeWords(trie, 'T', []byte("ACYILP"), 3); len(found) != 7 {
+		test.Errorf("Expected CAT too with a minimum of three letters but got %v", found)
+	}
+}
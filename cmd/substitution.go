@@ -1,149 +1,17 @@
 package cmd
 
 import (
-	"bufio"
+	"context"
 	"fmt"
 	"os"
-	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/spf13/cobra"
 )
 
-// Implementations for the substitution command object
-var substitutionCommand = regexp.MustCompile("[A-Z]=[a-z_]")
-
-type KeyDisplay int
-
-const (
-	cipher2Plain KeyDisplay = iota
-	plain2Cipher
-)
-
-const (
-	cipher2PlainCommand string = "cipher2Plain"
-	plain2CipherCommand string = "plain2Cipher"
-	clearCommand        string = "clear"
-)
-
-// substitutionShell creates a loop which lets you interactively solve a substitution cipher.
-// It will prompt for commands and show the current state of cipher text and plain text.
-// Command reference:
-//
-//	A=z will replace A in ciphertext with a z in plaintext
-//	cipher2Plain will list the cipher key in alphabetical order with the plain key underneath
-//	plain2Cipher will list the plain key in alphabetical order with the cipher key underneath
-//	clear will remove any mappings
-func substitutionShell(cmd *cobra.Command, args []string) {
-	// whether to overwrite the text on the screen (will usually be true)
-	// or just push lines onto the screen
-	overwrite := false
-	outWriter := bufio.NewWriter(os.Stdout)
-
-	cipherString := strings.Join(args, " ")
-	displayType := cipher2Plain
-
-	cipherToPlain := make(map[byte]byte)
-	plainToCipher := make(map[byte]byte)
-
-	reader := bufio.NewReader(os.Stdin)
-
-	for {
-		if overwrite {
-			outWriter.Write([]byte("\u001b[6A"))
-			outWriter.Write([]byte("\u001b[100D"))
-		} else {
-			outWriter.Write([]byte{'\n'})
-		}
-
-		cipherKeyBytes := make([]byte, 0, 26)
-		plainKeyBytes := make([]byte, 0, 26)
-		switch displayType {
-		case cipher2Plain:
-			for curByte := byte('A'); curByte <= byte('Z'); curByte = byte(curByte + 1) {
-				cipherKeyBytes = append(cipherKeyBytes, curByte)
-				plainChar, mapped := cipherToPlain[curByte]
-				if mapped {
-					plainKeyBytes = append(plainKeyBytes, plainChar)
-				} else {
-					plainKeyBytes = append(plainKeyBytes, '_')
-				}
-			}
-			writeLines(outWriter, string(cipherKeyBytes), string(plainKeyBytes))
-		case plain2Cipher:
-			for curByte := byte('a'); curByte <= byte('z'); curByte = byte(curByte + 1) {
-				plainKeyBytes = append(plainKeyBytes, curByte)
-				cipherChar, mapped := plainToCipher[curByte]
-				if mapped {
-					cipherKeyBytes = append(cipherKeyBytes, cipherChar)
-				} else {
-					cipherKeyBytes = append(cipherKeyBytes, '?')
-				}
-			}
-			writeLines(outWriter, string(plainKeyBytes), string(cipherKeyBytes))
-		default:
-			// shouldn't get here
-			writeLines(outWriter)
-			fmt.Printf("Unknown display type: %v\n", displayType)
-		}
-		writeLines(outWriter, "")
-
-		plainString := ""
-		for _, cipherByte := range []byte(cipherString) {
-			if isUppercaseAscii(cipherByte) {
-				plainByte, solved := cipherToPlain[cipherByte]
-				if solved {
-					plainString += string(plainByte)
-				} else {
-					plainString += "_"
-				}
-			} else {
-				plainString += string(cipherByte)
-			}
-		}
-
-		writeLines(outWriter, cipherString, plainString)
-
-		outWriter.Write([]byte("? "))
-		outWriter.Write([]byte("\u001b[0K"))
-		outWriter.Flush()
-		command, _ := reader.ReadString('\n')
-		command = strings.TrimSpace(command)
-		commandAsBytes := []byte(command)
-		if substitutionCommand.Match(commandAsBytes) {
-			// 0 will be cipher character, 1 will be = and 2 will be plaintext
-			if commandAsBytes[2] == '_' {
-				// deleting from the plainToCipher map means figuring out the current cipherToPlain mapping
-				delete(plainToCipher, cipherToPlain[commandAsBytes[0]])
-				delete(cipherToPlain, commandAsBytes[0])
-			} else {
-				cipherToPlain[commandAsBytes[0]] = commandAsBytes[2]
-				plainToCipher[commandAsBytes[2]] = commandAsBytes[0]
-			}
-		} else if command == cipher2PlainCommand {
-			displayType = cipher2Plain
-		} else if command == plain2CipherCommand {
-			displayType = plain2Cipher
-		} else if command == clearCommand {
-			cipherToPlain = make(map[byte]byte)
-			plainToCipher = make(map[byte]byte)
-		}
-
-		overwrite = true
-	}
-}
-
-func writeLines(writer *bufio.Writer, lines ...string) {
-	for _, line := range lines {
-		writer.Write([]byte(line))
-		writer.Write([]byte{'\n'})
-	}
-	writer.Flush()
-}
-
 type substitutionWordMatches struct {
 	word           string
 	cryptPattern   string
@@ -161,28 +29,200 @@ func (matchData *substitutionWordMatches) addMatch(word string) {
 func substitutionSolve(cmd *cobra.Command, args []string) {
 	// the user could pass in "abcd efg" rather than ABCD EFG, so clean up the data
 	oneString := strings.ToUpper(strings.Join(args, " "))
-	matchesData := buildSubstitutionData(oneString, dictionaryFile)
+	if strings.ContainsAny(solveCipherSymbols, " -") {
+		fmt.Println("Spaces and hyphens separate words, so they can't be cipher symbols")
+		os.Exit(1)
+	}
+	matchesData := buildSubstitutionData(oneString, dictionaryFiles)
+	seedMap, err := applyCribs(matchesData, cribs)
+	if err != nil {
+		fmt.Printf("Invalid crib: %v\n", err)
+		os.Exit(1)
+	}
+	if substitutionKey != "" {
+		keyMap, err := parseSubstitutionKey(substitutionKey)
+		if err != nil {
+			fmt.Printf("Invalid key: %v\n", err)
+			os.Exit(1)
+		}
+		err = mergePartialKey(seedMap, keyMap)
+		if err != nil {
+			fmt.Printf("Invalid key: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	constrainMatchesToKey(matchesData, seedMap)
+	matchesData, skipped, err := dropUnmatchedWords(matchesData, maxUnmatched)
+	if err != nil {
+		fmt.Printf("Could not solve: %v\n", err)
+		os.Exit(1)
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "Skipping words with no dictionary matches: %s\n", strings.Join(skipped, " "))
+	}
 
+	// the explanations go through the words in the order they're in the ciphertext
+	wordsInOrder := append([]*substitutionWordMatches{}, matchesData...)
 	// sort such that items with shorter lists are evaluated first to prune earlier
 	sort.Slice(matchesData, func(i, j int) bool {
 		return len(matchesData[i].patternMatches) < len(matchesData[j].patternMatches)
 	})
 
+	ctx, cancel := solveContext()
+	defer cancel()
+
 	resultsChannel := make(chan map[byte]byte)
+	printed := make(chan bool)
+	printer := newResultPrinter("plaintext", "key")
 	go func() {
 		for validMap := range resultsChannel {
-			printDecodedString(oneString, validMap)
+			plainText := decodeString(oneString, validMap)
+			printer.explained(plainText, explainSubstitutionSolution(wordsInOrder, seedMap, skipped, validMap), plainText, formatSubstitutionKey(validMap))
 		}
+		printed <- true
 	}()
-	partitionMapCollection(matchesData, resultsChannel)
-	// ensure the channel has time to be cleared
-	time.Sleep(2 * time.Second)
+	partitionMapCollection(ctx, matchesData, seedMap, resultsChannel)
+	close(resultsChannel)
+	<-printed
+	printer.finish(ctx.Err() != nil)
+}
+
+// explainSubstitutionSolution says which mappings were given by cribs or --key, which dictionary word
+// each cipher word became out of how many fit its pattern, and which words were skipped
+func explainSubstitutionSolution(matchesData []*substitutionWordMatches, seedMap map[byte]byte, skipped []string, solution map[byte]byte) []string {
+	reasons := make([]string, 0, len(matchesData)+2)
+	if len(seedMap) > 0 {
+		reasons = append(reasons, "given: "+formatSubstitutionKey(seedMap))
+	}
+	for _, matchData := range matchesData {
+		plainWord := decodeString(matchData.word, solution)
+		if len(matchData.patternMatches) == 1 {
+			reasons = append(reasons, fmt.Sprintf("%s is %s, the only dictionary word that fits", matchData.word, plainWord))
+		} else {
+			reasons = append(reasons, fmt.Sprintf("%s is %s, one of %d dictionary words that fit", matchData.word, plainWord, len(matchData.patternMatches)))
+		}
+	}
+	for _, word := range skipped {
+		reasons = append(reasons, fmt.Sprintf("%s was skipped since no dictionary word fits", word))
+	}
+	return reasons
+}
+
+// applyCribs takes cribs of the form N=WORD, meaning the Nth (starting at 1) word of the ciphertext is WORD,
+// and narrows the matching words in matchesData accordingly. It returns the cipher to plain mappings the
+// cribs imply so they can seed the search.
+func applyCribs(matchesData []*substitutionWordMatches, cribs []string) (map[byte]byte, error) {
+	seedMap := make(map[byte]byte)
+	for _, crib := range cribs {
+		parts := strings.SplitN(crib, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s is not of the form N=WORD", crib)
+		}
+		wordNumber, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil || wordNumber < 1 || wordNumber > len(matchesData) {
+			return nil, fmt.Errorf("%s does not refer to one of the %d words", parts[0], len(matchesData))
+		}
+
+		matchData := matchesData[wordNumber-1]
+		plainLetters := stripNonLetters(activeAlphabet.foldString(strings.TrimSpace(parts[1])))
+		if substitutionPattern(plainLetters) != substitutionPattern(stripNonCipherSymbols(matchData.word)) {
+			return nil, fmt.Errorf("%s does not fit the pattern of %s", parts[1], matchData.word)
+		}
+		plainWord := alignMatchToWord(matchData.word, plainLetters)
+
+		plainBytes := []byte(plainWord)
+		for index, cipherByte := range []byte(matchData.word) {
+			if !isCipherSymbol(cipherByte) {
+				continue
+			}
+			existing, mapped := seedMap[cipherByte]
+			if mapped && existing != plainBytes[index] {
+				return nil, fmt.Errorf("%s maps %c to %c but an earlier crib maps it to %c", crib, cipherByte, plainBytes[index], existing)
+			}
+			seedMap[cipherByte] = plainBytes[index]
+		}
+		// the crib might not be in the dictionary, so it has to become the only match
+		matchData.patternMatches = []string{plainWord}
+	}
+	return seedMap, nil
+}
+
+// mergePartialKey adds the mappings in keyMap (which has lowercase plaintext, like the REPL) to seedMap,
+// returning an error if one of them disagrees with what's already there
+func mergePartialKey(seedMap map[byte]byte, keyMap map[byte]byte) error {
+	for cipherByte, plainByte := range keyMap {
+		plainByte = activeAlphabet.fold(plainByte)
+		existing, mapped := seedMap[cipherByte]
+		if mapped && existing != plainByte {
+			return fmt.Errorf("%c=%c conflicts with the crib mapping %c=%c", cipherByte, plainByte, cipherByte, existing)
+		}
+		seedMap[cipherByte] = plainByte
+	}
+	return nil
+}
+
+// constrainMatchesToKey removes any pattern matches that disagree with key. A match disagrees if it maps a
+// cipher letter in the key to a different plain letter, or maps some other cipher letter to a plain letter
+// the key has already used
+func constrainMatchesToKey(matchesData []*substitutionWordMatches, key map[byte]byte) {
+	if len(key) == 0 {
+		return
+	}
+	usedPlain := make(map[byte]bool)
+	for _, plainByte := range key {
+		usedPlain[plainByte] = true
+	}
+
+	for _, matchData := range matchesData {
+		cipherBytes := []byte(matchData.word)
+		keptMatches := make([]string, 0, len(matchData.patternMatches))
+	MatchLoop:
+		for _, match := range matchData.patternMatches {
+			for index, plainByte := range []byte(match) {
+				keyByte, inKey := key[cipherBytes[index]]
+				if (inKey && keyByte != plainByte) || (!inKey && usedPlain[plainByte]) {
+					continue MatchLoop
+				}
+			}
+			keptMatches = append(keptMatches, match)
+		}
+		matchData.patternMatches = keptMatches
+	}
+}
+
+// dropUnmatchedWords removes the words that have no pattern matches left, such as proper nouns, so they don't
+// stop every solution. It returns the remaining words and the ones it removed, or an error if more than
+// maxUnmatched words would have to be removed
+func dropUnmatchedWords(matchesData []*substitutionWordMatches, maxUnmatched int) ([]*substitutionWordMatches, []string, error) {
+	kept := make([]*substitutionWordMatches, 0, len(matchesData))
+	skipped := make([]string, 0)
+	for _, matchData := range matchesData {
+		if len(matchData.patternMatches) == 0 {
+			skipped = append(skipped, matchData.word)
+		} else {
+			kept = append(kept, matchData)
+		}
+	}
+	if len(skipped) > maxUnmatched {
+		return nil, nil, fmt.Errorf("%d words have no dictionary matches (%s) but --max-unmatched is %d",
+			len(skipped), strings.Join(skipped, " "), maxUnmatched)
+	}
+	return kept, skipped, nil
 }
 
 // partitionMapCollection splits up matchesData so that the work can
 // be partitioned among goroutines that push their results to resultsChannel.
-// it returns when waitGroup.Wait() finishes.
-func partitionMapCollection(matchData []*substitutionWordMatches, resultsChannel chan map[byte]byte) {
+// Every goroutine starts from a copy of seedMap. it returns when waitGroup.Wait() finishes,
+// which happens early if ctx is cancelled.
+func partitionMapCollection(ctx context.Context, matchData []*substitutionWordMatches, seedMap map[byte]byte, resultsChannel chan map[byte]byte) {
+	if len(matchData) == 0 {
+		// every word was skipped, so the seed map is all there is
+		select {
+		case resultsChannel <- copyByteMap(seedMap):
+		case <-ctx.Done():
+		}
+		return
+	}
 
 	// build partitioned slices of substitutionWordMatches objects off of the first one
 	// in the list. The matches in the head of the group will be split up to create
@@ -209,9 +249,9 @@ func partitionMapCollection(matchData []*substitutionWordMatches, resultsChannel
 
 		waitGroup.Add(1)
 		go func(matches []*substitutionWordMatches, currentMap map[byte]byte) {
-			collectValidMaps(matchData, currentMap, resultsChannel)
+			collectValidMaps(ctx, matches, currentMap, resultsChannel)
 			waitGroup.Done()
-		}(newMatchData, make(map[byte]byte))
+		}(newMatchData, copyByteMap(seedMap))
 	}
 	waitGroup.Wait()
 }
@@ -235,25 +275,37 @@ func partitionMatches(count int, source *substitutionWordMatches) []*substitutio
 	return partitions
 }
 
-// printDecodedString uses cipherToPlain to decode cipherText
-func printDecodedString(cipherText string, cipherToPlain map[byte]byte) {
+// decodeString uses cipherToPlain to decode cipherText. Letters that only appear in
+// skipped words have no mapping and are shown as _
+func decodeString(cipherText string, cipherToPlain map[byte]byte) string {
+	decoded := make([]byte, 0, len(cipherText))
 	for _, cipherChar := range []byte(cipherText) {
 		plainChar, mapped := cipherToPlain[cipherChar]
-		if !mapped {
-			fmt.Printf("%c", cipherChar)
+		if !mapped && isCipherSymbol(cipherChar) {
+			decoded = append(decoded, '_')
+		} else if !mapped {
+			decoded = append(decoded, cipherChar)
 		} else {
-			fmt.Printf("%c", plainChar)
+			decoded = append(decoded, plainChar)
 		}
 	}
-	fmt.Print("\n")
+	return string(decoded)
 }
 
 // collectValidMaps builds a slice of valid byte -> byte mappings that work for all the
-// matches it's looked at so far. this method is called  recursively to build the list
-func collectValidMaps(matches []*substitutionWordMatches, currentMap map[byte]byte, resultsChannel chan map[byte]byte) {
+// matches it's looked at so far. this method is called  recursively to build the list.
+// It stops looking as soon as ctx is cancelled.
+func collectValidMaps(ctx context.Context, matches []*substitutionWordMatches, currentMap map[byte]byte, resultsChannel chan map[byte]byte) {
+	if ctx.Err() != nil {
+		return
+	}
+
 	if len(matches) == 0 {
 		// we've reached the end of the matches to check, which means the currentMap is valid
-		resultsChannel <- currentMap
+		select {
+		case resultsChannel <- currentMap:
+		case <-ctx.Done():
+		}
 		return
 	}
 
@@ -291,7 +343,7 @@ func collectValidMaps(matches []*substitutionWordMatches, currentMap map[byte]by
 
 		// at this point, every byte in the current match doesn't conflict with the existing byte map, so we can gather up the results
 		// from the recursive call
-		collectValidMaps(matches[1:], copyMap, resultsChannel)
+		collectValidMaps(ctx, matches[1:], copyMap, resultsChannel)
 	}
 }
 
@@ -306,18 +358,24 @@ func copyByteMap(input map[byte]byte) map[byte]byte {
 }
 
 // buildSubstitutionData creates the full data needed to try and solve the substitution.
-// When dictionaryFile is parsed, it's no longer needed and can be closed.
-func buildSubstitutionData(solveString, dictionaryFile string) []*substitutionWordMatches {
-	words := strings.Split(solveString, " ")
+// Words are split on spaces and hyphens, and words without any cipher symbols in them are skipped.
+// When dictionaryFiles are parsed, they're no longer needed and can be closed.
+func buildSubstitutionData(solveString string, dictionaryFiles []string) []*substitutionWordMatches {
+	words := strings.FieldsFunc(solveString, func(char rune) bool {
+		return char == ' ' || char == '-'
+	})
 
 	wordMatches := make([]*substitutionWordMatches, 0, len(words))
 	for _, curWord := range words {
+		if stripNonCipherSymbols(curWord) == "" {
+			continue
+		}
 		wordMatches = append(wordMatches, &substitutionWordMatches{curWord, substitutionPattern(curWord), make([]string, 0, 1)})
 	}
 
 	results := make(chan string)
 	go func() {
-		feedDictionaryPaths(results, dictionaryFile)
+		feedDictionaryPaths(results, dictionaryFiles...)
 	}()
 
 	findMatchesFromDictionary(wordMatches, results)
@@ -325,26 +383,91 @@ func buildSubstitutionData(solveString, dictionaryFile string) []*substitutionWo
 }
 
 // findMatchesFromDictionary populates each item in substitutionWordMatches with matching
-// entries from the passed-in Reader. This mutates the structures that are passed in
+// entries from the passed-in Reader. This mutates the structures that are passed in.
+// Punctuation is ignored when comparing patterns, so DONT and DON'T in the dictionary will both
+// match a crypt word like ABC'D. Matches are stored with the crypt word's punctuation in place
+// so they line up byte for byte with it.
 func findMatchesFromDictionary(matchSets []*substitutionWordMatches, feed chan string) {
+	letterPatterns := make([]string, 0, len(matchSets))
+	seenMatches := make([]map[string]bool, 0, len(matchSets))
+	for _, testMatch := range matchSets {
+		letterPatterns = append(letterPatterns, substitutionPattern(stripNonCipherSymbols(testMatch.word)))
+		seenMatches = append(seenMatches, make(map[string]bool))
+	}
+
 	for entry := range feed {
-		pattern := substitutionPattern(entry)
-		for _, testMatch := range matchSets {
-			if testMatch.cryptPattern == pattern {
-				testMatch.addMatch(entry)
+		letters := stripNonLetters(entry)
+		pattern := substitutionPattern(letters)
+		for index, testMatch := range matchSets {
+			if letterPatterns[index] != pattern {
+				continue
+			}
+			aligned := alignMatchToWord(testMatch.word, letters)
+			if !seenMatches[index][aligned] {
+				seenMatches[index][aligned] = true
+				testMatch.addMatch(aligned)
 			}
 		}
 	}
 }
 
+// stripNonLetters removes anything that isn't in the alphabet, such as apostrophes and hyphens
+func stripNonLetters(word string) string {
+	letters := make([]byte, 0, len(word))
+	for _, curByte := range []byte(word) {
+		if activeAlphabet.contains(curByte) {
+			letters = append(letters, curByte)
+		}
+	}
+	return string(letters)
+}
+
+// stripNonCipherSymbols removes anything from a cipher word that isn't standing in for a letter
+func stripNonCipherSymbols(word string) string {
+	symbols := make([]byte, 0, len(word))
+	for _, curByte := range []byte(word) {
+		if isCipherSymbol(curByte) {
+			symbols = append(symbols, curByte)
+		}
+	}
+	return string(symbols)
+}
+
+// isCipherSymbol reports whether a byte of ciphertext stands for a letter: anything in the
+// alphabet, plus any digits or punctuation passed to solve with --cipher-symbols
+func isCipherSymbol(symbol byte) bool {
+	return activeAlphabet.contains(symbol) || strings.IndexByte(solveCipherSymbols, symbol) >= 0
+}
+
+// alignMatchToWord takes a letters-only match and puts cryptWord's non-cipher symbols back in
+// at the same positions, so "DONT" aligned to "QRS'T" becomes "DON'T"
+func alignMatchToWord(cryptWord, letters string) string {
+	aligned := make([]byte, 0, len(cryptWord))
+	letterIndex := 0
+	for _, cryptByte := range []byte(cryptWord) {
+		if isCipherSymbol(cryptByte) && letterIndex < len(letters) {
+			aligned = append(aligned, letters[letterIndex])
+			letterIndex++
+		} else {
+			aligned = append(aligned, cryptByte)
+		}
+	}
+	return string(aligned)
+}
+
 // substitutionPattern takes in a string and creates the pattern of its letters.
-// For instance, substitutionPattern("HELLO") produces "ABCCD"
+// For instance, substitutionPattern("HELLO") produces "ABCCD". Anything that isn't a
+// cipher symbol is left as is, so substitutionPattern("DON'T") produces "ABC'D"
 func substitutionPattern(input string) string {
 	returnBytes := make([]byte, 0, len(input))
 	textToPattern := make(map[byte]byte)
 	maxByte := 65 // capital A ascii
 
 	for _, inputByte := range []byte(input) {
+		if !isCipherSymbol(inputByte) {
+			returnBytes = append(returnBytes, inputByte)
+			continue
+		}
 		// if we don't already have a mapping, create one
 		if _, exists := textToPattern[inputByte]; !exists {
 			textToPattern[inputByte] = byte(maxByte)
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"strings"
 	"testing"
 	"time"
@@ -9,8 +10,10 @@ import (
 
 func TestSubstitutionPattern(test *testing.T) {
 	tests := map[string]string{
-		"HELLO": "ABCCD",
-		"A":     "A",
+		"HELLO":  "ABCCD",
+		"A":      "A",
+		"DON'T":  "ABC'D",
+		"XYZ'XS": "ABC'AD",
 	}
 
 	for input, expected := range tests {
@@ -125,7 +128,7 @@ func TestCollectValidMaps(test *testing.T) {
 		feedDictionaryReaders(dictChannel, bufio.NewReader(strings.NewReader(dictionary)))
 	}()
 	findMatchesFromDictionary(matchesData, dictChannel)
-	collectValidMaps(matchesData, make(map[byte]byte), resultsChannel)
+	collectValidMaps(context.Background(), matchesData, make(map[byte]byte), resultsChannel)
 	byteMap := <-resultsChannel
 	close(resultsChannel)
 
@@ -165,7 +168,7 @@ func TestCollectValidMaps(test *testing.T) {
 			feedDictionaryReaders(dictChannel, bufio.NewReader(strings.NewReader(testDict)))
 		}()
 		findMatchesFromDictionary(matchesData, dictChannel)
-		go collectValidMaps(matchesData, make(map[byte]byte), resultsChannel)
+		go collectValidMaps(context.Background(), matchesData, make(map[byte]byte), resultsChannel)
 
 		byteMaps := make([]map[byte]byte, 0, expectedLength)
 	Loop:
@@ -191,3 +194,210 @@ func TestCollectValidMaps(test *testing.T) {
 		}
 	}
 }
+
+func TestApplyCribs(test *testing.T) {
+	newMatchesData := func() []*substitutionWordMatches {
+		return []*substitutionWordMatches{
+			&substitutionWordMatches{"BUXXUDR", "ABCCBDE", []string{"WILLING", "KILLING"}},
+			&substitutionWordMatches{"TIZP", "ABCD", []string{"SOME", "GASH"}},
+		}
+	}
+
+	matchesData := newMatchesData()
+	seedMap, err := applyCribs(matchesData, []string{"2=some"})
+	if err != nil {
+		test.Fatalf("Unexpected error applying crib: %v", err)
+	}
+	expectedMap := map[byte]byte{'T': 'S', 'I': 'O', 'Z': 'M', 'P': 'E'}
+	if len(seedMap) != len(expectedMap) {
+		test.Errorf("Expected %d mappings but got %d", len(expectedMap), len(seedMap))
+	}
+	for cipher, plain := range expectedMap {
+		if seedMap[cipher] != plain {
+			test.Errorf("Expected %c to map to %c but it maps to %c", cipher, plain, seedMap[cipher])
+		}
+	}
+	if len(matchesData[1].patternMatches) != 1 || matchesData[1].patternMatches[0] != "SOME" {
+		test.Errorf("Expected the crib to be the only match but got %v", matchesData[1].patternMatches)
+	}
+	if len(matchesData[0].patternMatches) != 2 {
+		test.Errorf("Expected uncribbed word to keep its matches but got %v", matchesData[0].patternMatches)
+	}
+
+	badCribs := [][]string{
+		{"THE"},
+		{"3=SOME"},
+		{"2=SOMETIMES"},
+		{"2=SEEM"},
+		{"2=SOME", "2=GASH"},
+	}
+	for _, cribSet := range badCribs {
+		if _, err := applyCribs(newMatchesData(), cribSet); err == nil {
+			test.Errorf("Expected an error applying %v", cribSet)
+		}
+	}
+}
+
+func TestConstrainMatchesToKey(test *testing.T) {
+	matchesData := []*substitutionWordMatches{
+		&substitutionWordMatches{"TIZP", "ABCD", []string{"SOME", "GASH", "SAME"}},
+		&substitutionWordMatches{"QX", "AB", []string{"MY", "BY"}},
+	}
+
+	key := map[byte]byte{}
+	if err := mergePartialKey(key, map[byte]byte{'T': 's', 'Z': 'm'}); err != nil {
+		test.Fatalf("Unexpected error merging key: %v", err)
+	}
+	constrainMatchesToKey(matchesData, key)
+
+	if len(matchesData[0].patternMatches) != 2 || matchesData[0].patternMatches[0] != "SOME" || matchesData[0].patternMatches[1] != "SAME" {
+		test.Errorf("Expected SOME and SAME to survive the key but got %v", matchesData[0].patternMatches)
+	}
+	// M is already taken by Z, so Q can't be M
+	if len(matchesData[1].patternMatches) != 1 || matchesData[1].patternMatches[0] != "BY" {
+		test.Errorf("Expected only BY to survive the key but got %v", matchesData[1].patternMatches)
+	}
+
+	if err := mergePartialKey(key, map[byte]byte{'T': 'g'}); err == nil {
+		test.Errorf("Expected an error merging a conflicting mapping")
+	}
+}
+
+func TestFindMatchesWithPunctuation(test *testing.T) {
+	matchesData := []*substitutionWordMatches{
+		&substitutionWordMatches{"QRS'T", substitutionPattern("QRS'T"), make([]string, 0, 2)},
+		&substitutionWordMatches{"WXYZ", substitutionPattern("WXYZ"), make([]string, 0, 2)},
+	}
+
+	dictionary := "DON'T\nDONT\nCAN'T\nWON'T"
+	dictChannel := make(chan string)
+	go func() {
+		feedDictionaryReaders(dictChannel, bufio.NewReader(strings.NewReader(dictionary)))
+	}()
+	findMatchesFromDictionary(matchesData, dictChannel)
+
+	expected := []string{"DON'T", "CAN'T", "WON'T"}
+	if len(matchesData[0].patternMatches) != len(expected) {
+		test.Fatalf("Expected matches %v but got %v", expected, matchesData[0].patternMatches)
+	}
+	for index, match := range expected {
+		if matchesData[0].patternMatches[index] != match {
+			test.Errorf("Expected %s at %d but got %s", match, index, matchesData[0].patternMatches[index])
+		}
+	}
+
+	// DON'T has four distinct letters, so it also fits a four letter crypt word without the apostrophe
+	if len(matchesData[1].patternMatches) != 3 || matchesData[1].patternMatches[0] != "DONT" {
+		test.Errorf("Expected letters-only matches for WXYZ but got %v", matchesData[1].patternMatches)
+	}
+}
+
+func TestAlignMatchToWord(test *testing.T) {
+	tests := map[[2]string]string{
+		{"QRS'T", "DONT"}: "DON'T",
+		{"ABC,", "DOG"}:   "DOG,",
+		{"'ABC'", "CAT"}:  "'CAT'",
+		{"ABCD", "WORD"}:  "WORD",
+	}
+
+	for inputs, expected := range tests {
+		actual := alignMatchToWord(inputs[0], inputs[1])
+		if actual != expected {
+			test.Errorf("Expected %s aligning %s to %s but got %s", expected, inputs[1], inputs[0], actual)
+		}
+	}
+}
+
+func TestCollectValidMapsCancelled(test *testing.T) {
+	matchesData := []*substitutionWordMatches{
+		&substitutionWordMatches{"TIZP", "ABCD", []string{"SOME", "GASH"}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// nothing reads from the channel, so this would block forever if cancellation were ignored
+	resultsChannel := make(chan map[byte]byte)
+	done := make(chan bool)
+	go func() {
+		partitionMapCollection(ctx, matchesData, make(map[byte]byte), resultsChannel)
+		done <- true
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		test.Errorf("partitionMapCollection did not return after its context was cancelled")
+	}
+}
+
+func TestDropUnmatchedWords(test *testing.T) {
+	matchesData := []*substitutionWordMatches{
+		&substitutionWordMatches{"TIZP", "ABCD", []string{"SOME", "GASH"}},
+		&substitutionWordMatches{"QXYZ", "ABCD", []string{}},
+	}
+
+	_, _, err := dropUnmatchedWords(matchesData, 0)
+	if err == nil {
+		test.Errorf("Expected an error when an unmatched word isn't allowed")
+	}
+
+	kept, skipped, err := dropUnmatchedWords(matchesData, 1)
+	if err != nil {
+		test.Errorf("Expected no error but got %v", err)
+	}
+	if len(kept) != 1 || kept[0].word != "TIZP" {
+		test.Errorf("Expected only TIZP to be kept but got %v", kept)
+	}
+	if len(skipped) != 1 || skipped[0] != "QXYZ" {
+		test.Errorf("Expected QXYZ to be skipped but got %v", skipped)
+	}
+}
+
+func TestSolveWithCipherSymbols(test *testing.T) {
+	solveCipherSymbols = "0123456789'"
+	defer func() { solveCipherSymbols = "" }()
+
+	if substitutionPattern("3'1AA") != "ABCDD" {
+		test.Errorf("Expected digits and apostrophes to be patterned like letters but got %s", substitutionPattern("3'1AA"))
+	}
+
+	// the 7 and ' are cipher letters here, so only five-letter words fit
+	matchesData := []*substitutionWordMatches{
+		&substitutionWordMatches{"7'XX5", substitutionPattern("7'XX5"), make([]string, 0, 2)},
+	}
+	dictChannel := make(chan string)
+	go func() {
+		feedDictionaryReaders(dictChannel, bufio.NewReader(strings.NewReader("HELLO\nDON'T\nBELL")))
+	}()
+	findMatchesFromDictionary(matchesData, dictChannel)
+	if strings.Join(matchesData[0].patternMatches, ",") != "HELLO" {
+		test.Errorf("Expected only HELLO to match 7'XX5 but got %v", matchesData[0].patternMatches)
+	}
+
+	seedMap, err := applyCribs(matchesData, []string{"1=hello"})
+	if err != nil {
+		test.Fatalf("Unexpected error applying crib: %v", err)
+	}
+	if seedMap['7'] != 'H' || seedMap['\''] != 'E' || seedMap['5'] != 'O' {
+		test.Errorf("Expected the crib to map 7, ', and 5 but got %v", seedMap)
+	}
+}
+
+func TestExplainSubstitutionSolution(test *testing.T) {
+	matchesData := []*substitutionWordMatches{
+		{word: "XYZ", cryptPattern: "ABC", patternMatches: []string{"THE"}},
+		{word: "QAX", cryptPattern: "ABC", patternMatches: []string{"CAT", "SAT"}},
+	}
+	solution := map[byte]byte{'X': 'T', 'Y': 'H', 'Z': 'E', 'Q': 'C', 'A': 'A'}
+	reasons := explainSubstitutionSolution(matchesData, map[byte]byte{'X': 'T'}, []string{"MMM"}, solution)
+	expected := []string{
+		"given: X=t",
+		"XYZ is THE, the only dictionary word that fits",
+		"QAX is CAT, one of 2 dictionary words that fit",
+		"MMM was skipped since no dictionary word fits",
+	}
+	if strings.Join(reasons, "|") != strings.Join(expected, "|") {
+		test.Errorf("Expected %v but got %v", expected, reasons)
+	}
+}
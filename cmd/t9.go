@@ -0,0 +1,206 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var t9MaxWords int
+var t9MaxResults int
+
+// keypadLetters is the letters on each key of a phone keypad
+var keypadLetters = map[byte]string{
+	'2': "ABC", '3': "DEF", '4': "GHI", '5': "JKL", '6': "MNO", '7': "PQRS", '8': "TUV", '9': "WXYZ",
+}
+
+// keypadSeparators splits digits into the groups decoded separately. 0 and 1 have no letters, and 0
+// is the space key
+var keypadSeparators = regexp.MustCompile("[^2-9]+")
+
+// t9Cmd represents the t9 command
+var t9Cmd = &cobra.Command{
+	Use:   "t9",
+	Short: "Converts between words and phone keypad digits",
+	Long: `Phone keypads put ABC on 2, DEF on 3, and so on up to WXYZ on 9, so 843 could be THE, TIE, or VIE.
+	decode finds the dictionary words a string of digits could be, and encode turns text into its digits.`,
+}
+
+var t9DecodeCmd = &cobra.Command{
+	Use:   "decode DIGITS...",
+	Short: "Finds the words that a string of keypad digits could spell",
+	Long: `Finds the dictionary words each group of digits could be typed as, the most common first. Groups are
+	separated by spaces, punctuation, or the 0 and 1 keys, which have no letters. --max-words also splits a group
+	into up to that many words, for digits typed without spaces, and --max-results limits how many are printed
+	for each group.
+
+	Example:
+	  puzzle_helper t9 decode 843 78678
+	  puzzle_helper t9 decode 4663 --max-words 2 --dictionary words.txt`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  printKeypadDecoding,
+}
+
+var t9EncodeCmd = &cobra.Command{
+	Use:   "encode TEXT...",
+	Short: "Turns text into keypad digits",
+	Long: `Prints the key for each letter in the text, keeping the spaces between words and leaving out everything else.
+
+	Example:
+	  puzzle_helper t9 encode "the light"`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  printKeypadEncoding,
+}
+
+func printKeypadDecoding(cmd *cobra.Command, args []string) {
+	groups := keypadGroups(strings.Join(args, " "))
+	if len(groups) == 0 {
+		fmt.Println("There are no digits from 2 to 9 to decode")
+		os.Exit(1)
+	}
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+
+	ctx, cancel := solveContext()
+	defer cancel()
+
+	printer := newResultPrinter("digits", "words")
+	for _, digits := range groups {
+		if len(groups) > 1 {
+			printer.text("%s:", digits)
+		}
+		for _, words := range decodeKeypadDigits(ctx, rootTrie, digits, t9MaxWords, t9MaxResults) {
+			text := strings.Join(words, " ")
+			printer.result(text, digits, text)
+		}
+	}
+	printer.finish(ctx.Err() != nil)
+}
+
+func printKeypadEncoding(cmd *cobra.Command, args []string) {
+	encoded := encodeKeypadDigits(strings.Join(args, " "))
+	printer := newResultPrinter("digits")
+	printer.result(encoded, encoded)
+	printer.finish(false)
+}
+
+// keypadGroups splits text into the runs of digits that have letters
+func keypadGroups(text string) []string {
+	groups := make([]string, 0)
+	for _, group := range keypadSeparators.Split(text, -1) {
+		if group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// encodeKeypadDigits replaces each letter in text with its key. Words are kept apart by single spaces
+func encodeKeypadDigits(text string) string {
+	words := make([]string, 0)
+	for _, word := range strings.Fields(text) {
+		digits := make([]byte, 0, len(word))
+		for _, letter := range justUppercaseLetters(word) {
+			for key, letters := range keypadLetters {
+				if strings.IndexByte(letters, letter) >= 0 {
+					digits = append(digits, key)
+				}
+			}
+		}
+		if len(digits) > 0 {
+			words = append(words, string(digits))
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// decodeKeypadDigits finds the ways digits could be typed as up to maxWords dictionary words, following the trie so
+// that only letters that keep the current word possible are tried. They're ranked the way transposals are, and the
+// first maxResults are returned, or all of them if maxResults isn't above 0. The search stops early if ctx is done
+func decodeKeypadDigits(ctx context.Context, rootTrie *trieNode, digits string, maxWords int, maxResults int) [][]string {
+	decodings := make([][]string, 0)
+	var decode func(position int, node *trieNode, words []string, currentWord string)
+	decode = func(position int, node *trieNode, words []string, currentWord string) {
+		if ctx.Err() != nil {
+			return
+		}
+		for _, letter := range []byte(keypadLetters[digits[position]]) {
+			child := node.Child(letter)
+			if child == nil {
+				continue
+			}
+			word := currentWord + string(letter)
+			if position == len(digits)-1 {
+				if child.IsWord() {
+					decodings = append(decodings, append(append([]string{}, words...), word))
+				}
+				continue
+			}
+			decode(position+1, child, words, word)
+			if child.IsWord() && len(words)+1 < maxWords {
+				decode(position+1, rootTrie, append(append([]string{}, words...), word), "")
+			}
+		}
+	}
+	decode(0, rootTrie, nil, "")
+	return rankTransposals(rootTrie, decodings, maxResults)
+}
+
+// runKeypadSolver is t9 decode for the solver registry
+func runKeypadSolver(ctx context.Context, input solverInput) (*resultTable, error) {
+	groups := keypadGroups(input.getString("digits"))
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("there are no digits from 2 to 9 to decode")
+	}
+	rootTrie, _, err := openDictionaryTrie(input.getList("dictionary")...)
+	if err != nil {
+		return nil, err
+	}
+	table := newResultTable("digits", "words")
+	for _, digits := range groups {
+		for _, words := range decodeKeypadDigits(ctx, rootTrie, digits, input.getInt("max_words"), input.getInt("max_results")) {
+			table.addRow(digits, strings.Join(words, " "))
+		}
+	}
+	return table, nil
+}
+
+func init() {
+	t9DecodeCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+	t9DecodeCmd.MarkFlagRequired("dictionary")
+	t9DecodeCmd.Flags().IntVarP(&t9MaxWords, "max-words", "w", 1, "The most words to split each group of digits into")
+	t9DecodeCmd.Flags().IntVarP(&t9MaxResults, "max-results", "", 20, "The most decodings to print for each group of digits. 0 prints them all")
+	t9Cmd.AddCommand(t9DecodeCmd)
+	t9Cmd.AddCommand(t9EncodeCmd)
+	rootCmd.AddCommand(t9Cmd)
+
+	mustRegisterSolver(&solver{
+		name:        "t9",
+		description: "The dictionary words that phone keypad digits could spell, such as THE, TIE, or VIE for 843",
+		parameters: []solverParameter{
+			solverParameter{name: "digits", kind: solverString, description: "the digits, with spaces, 0, or 1 between groups decoded separately", required: true},
+			solverParameter{name: "dictionary", kind: solverString, description: "the dictionary file to use, or several separated by commas", required: true},
+			solverParameter{name: "max_words", kind: solverInt, description: "the most words to split each group into", defaultValue: "1"},
+			solverParameter{name: "max_results", kind: solverInt, description: "the most decodings for each group", defaultValue: "20"},
+		},
+		run: runKeypadSolver,
+	})
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDecodeKeypadDigits(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"THE", "TIE", "VIE", "GOOD", "HOME", "GONE", "IN", "GO", "TO", "HOE", "TIED"} {
+		trie.Add(word, nil)
+	}
+
+	tests := []struct {
+		digits   string
+		maxWords int
+		expected string
+	}{
+		{"843", 1, "THE,TIE,VIE"},
+		{"4663", 1, "GONE,GOOD,HOME"},
+		{"9999", 1, ""},
+		// 46 is GO or IN, and the 463 left is HOE
+		{"46463", 2, "GO HOE,IN HOE"},
+		{"4686", 2, "GO TO,IN TO"},
+		{"4686", 1, ""},
+	}
+	for _, currentTest := range tests {
+		decoded := make([]string, 0)
+		for _, words := range decodeKeypadDigits(context.Background(), trie, currentTest.digits, currentTest.maxWords, 0) {
+			decoded = append(decoded, strings.Join(words, " "))
+		}
+		if actual := strings.Join(decoded, ","); actual != currentTest.expected {
+			test.Errorf("Expected %s for %s in %d words but got %s", currentTest.expected, currentTest.digits, currentTest.maxWords, actual)
+		}
+	}
+
+	if decoded := decodeKeypadDigits(context.Background(), trie, "843", 1, 2); len(decoded) != 2 {
+		test.Errorf("Expected two decodings with a limit of 2 but got %v", decoded)
+	}
+}
+
+func TestKeypadGroups(test *testing.T) {
+	if actual, expected := strings.Join(keypadGroups("843 0 54448, 1263"), ","), "843,54448,263"; actual != expected {
+		test.Errorf("Expected %s but got %s", expected, actual)
+	}
+}
+
+func TestEncodeKeypadDigits(test *testing.T) {
+	if actual, expected := encodeKeypadDigits("The light, of 2 suns!"), "843 54448 63 7867"; actual != expected {
+		test.Errorf("Expected %s but got %s", expected, actual)
+	}
+}
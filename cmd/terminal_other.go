@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package cmd
+
+import "errors"
+
+// makeTerminalRaw isn't supported here, so the line editor falls back to reading whole lines
+func makeTerminalRaw(fd int) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
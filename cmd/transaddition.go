@@ -0,0 +1,142 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var letterChangeMaxWords int
+
+// transadditionCmd represents the transaddition command
+var transadditionCmd = &cobra.Command{
+	Use:   "transaddition LETTERS...",
+	Short: "Finds the anagrams of the letters plus one more",
+	Long: `Adds each letter of the alphabet in turn to the letters and lists the transposals that makes,
+	grouped by the letter that was added. In a transaddition, HORSE plus T gives OTHERS and THROES.
+	--max-words allows phrases of up to that many words; the default is single words.
+
+	Example:
+	  puzzle_helper transaddition HORSE --dictionary words.txt`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		printLetterChanges(args, true)
+	},
+}
+
+// transdeletionCmd represents the transdeletion command
+var transdeletionCmd = &cobra.Command{
+	Use:   "transdeletion LETTERS...",
+	Short: "Finds the anagrams of the letters less one",
+	Long: `Takes each of the letters away in turn and lists the transposals of the ones that are left,
+	grouped by the letter that was taken. In a transdeletion, STRANGE less G gives ANTSER, ASTERN, and
+	STERNA. --max-words allows phrases of up to that many words; the default is single words.
+
+	Example:
+	  puzzle_helper transdeletion STRANGE --dictionary words.txt`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		printLetterChanges(args, false)
+	},
+}
+
+// letterChange is a letter added to or taken away from the input, along with the transposals that makes
+type letterChange struct {
+	letter      string
+	transposals [][]string
+}
+
+func printLetterChanges(args []string, adding bool) {
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+	letterCounts := createLetterCountsMap(strings.ToUpper(strings.Join(args, "")))
+
+	ctx, cancel := solveContext()
+	defer cancel()
+
+	sign := "-"
+	if adding {
+		sign = "+"
+	}
+	printer := newResultPrinter("letter", "words")
+	for _, change := range performLetterChangeSolve(ctx, rootTrie, letterCounts, adding, letterChangeMaxWords) {
+		for _, wordSet := range change.transposals {
+			words := strings.Join(wordSet, " ")
+			printer.result(sign+change.letter+": "+words, change.letter, words)
+		}
+	}
+	printer.finish(ctx.Err() != nil)
+}
+
+// performLetterChangeSolve transposes letterCounts with each letter of the alphabet added to them, or with
+// each of their letters taken away if adding is false. Each letter that gives transposals of at most maxWords
+// words (or any number, if maxWords isn't above 0) is returned in alphabetical order, with the transposals
+// ranked as rankTransposals orders them
+func performLetterChangeSolve(ctx context.Context, rootTrie *trieNode, letterCounts map[string]int, adding bool, maxWords int) []letterChange {
+	letters := make([]string, 0, len(upperAlphabet))
+	if adding {
+		letters = strings.Split(upperAlphabet, "")
+	} else {
+		for letter := range letterCounts {
+			if letter != blankLetter {
+				letters = append(letters, letter)
+			}
+		}
+		sort.Strings(letters)
+	}
+
+	changes := make([]letterChange, 0)
+	for _, letter := range letters {
+		changedCounts := decrementLetterCounts(letter, letterCounts)
+		if adding {
+			changedCounts = make(map[string]int, len(letterCounts)+1)
+			for currentLetter, count := range letterCounts {
+				changedCounts[currentLetter] = count
+			}
+			changedCounts[letter]++
+		}
+		if len(changedCounts) == 0 {
+			continue
+		}
+
+		found := make([][]string, 0)
+		for wordSet := range streamTransposals(ctx, rootTrie, transposalRequest{letterCounts: changedCounts}) {
+			if maxWords < 1 || len(wordSet) <= maxWords {
+				found = append(found, wordSet)
+			}
+		}
+		// the search finds them in no particular order, so ties in the ranking are left alphabetical
+		sort.Slice(found, func(i, j int) bool {
+			return strings.Join(found[i], " ") < strings.Join(found[j], " ")
+		})
+		if len(found) > 0 {
+			changes = append(changes, letterChange{letter, rankTransposals(rootTrie, found, 0)})
+		}
+	}
+	return changes
+}
+
+func init() {
+	for _, command := range []*cobra.Command{transadditionCmd, transdeletionCmd} {
+		command.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+		command.MarkFlagRequired("dictionary")
+		command.Flags().IntVarP(&letterChangeMaxWords, "max-words", "", 1, "The most words in a transposal. 0 means no limit")
+		rootCmd.AddCommand(command)
+	}
+}
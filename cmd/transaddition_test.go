@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPerformLetterChangeSolve(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"OTHERS", "THROES", "HEROES", "HOSE", "SHOE", "HOES", "HER", "SO"} {
+		trie.Add(word, nil)
+	}
+
+	testCases := []struct {
+		letters  string
+		adding   bool
+		maxWords int
+		expected string
+	}{
+		{"HORSE", true, 1, "E:HEROES T:OTHERS,THROES"},
+		{"HORSE", false, 1, "R:HOES,HOSE,SHOE"},
+		{"HORSESO", false, 1, ""},
+		{"HORSESO", false, 2, "R:HOES SO,HOSE SO,SHOE SO"},
+		{"Q", false, 0, ""},
+	}
+
+	for _, testCase := range testCases {
+		changes := performLetterChangeSolve(context.Background(), trie, createLetterCountsMap(testCase.letters), testCase.adding, testCase.maxWords)
+		described := make([]string, 0, len(changes))
+		for _, change := range changes {
+			transposals := make([]string, 0, len(change.transposals))
+			for _, wordSet := range change.transposals {
+				transposals = append(transposals, strings.Join(wordSet, " "))
+			}
+			described = append(described, fmt.Sprintf("%s:%s", change.letter, strings.Join(transposals, ",")))
+		}
+		if strings.Join(described, " ") != testCase.expected {
+			test.Errorf("Expected %q for %s (adding %v) but got %q", testCase.expected, testCase.letters, testCase.adding, strings.Join(described, " "))
+		}
+	}
+}
@@ -16,10 +16,13 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"os"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
 )
@@ -28,6 +31,14 @@ var minWordLength int
 var maxWordLength int
 var maxNumberOfWords int
 var minNumberOfWords int
+var transposalPermutations bool
+var mustIncludeWords []string
+var transposalMaxResults int
+var transposalStream bool
+var excludedAnswerWords []string
+
+// blankLetter stands for an unknown letter in a transposal's input, like a blank tile in Scrabble
+const blankLetter = "?"
 
 // transposalCmd represents the transposal command
 var transposalCmd = &cobra.Command{
@@ -37,7 +48,15 @@ var transposalCmd = &cobra.Command{
 	  Transposals, or anagrams, can be multiword responses. Use -m or --min-word-length
 		to put a lower bound on the length of a given word. The default is 4. Use -w or --max-words
 		to put an upper bound on the number of words that will be searched for. The default is 3.
-		Lower word lengths or higher numbers of allowed strings will take longer
+		Lower word lengths or higher numbers of allowed strings will take longer. With a dictionary of
+		word<TAB>frequency lines, the transposals made of the most common words are listed first.
+		Multiword transposals are listed once, with their words in alphabetical order; use --permutations
+		to list every ordering of them. --must-include takes a word you're sure is in the answer; its letters
+		are taken out first and only the rest are searched, while --exclude leaves out transposals with a word
+		you've already ruled out. --max-results lists only that many transposals, the
+		likeliest first: those made of the most common words, or of the words nearest the top of the dictionary
+		if it has no frequencies. Those wait until the search is done; --stream prints transposals as they're found
+		instead, in no particular order. A ? in the letters is a blank that can be any letter, as in Scrabble
   `,
 	Args: cobra.MinimumNArgs(1),
 	Run:  findTransposals,
@@ -46,39 +65,158 @@ var transposalCmd = &cobra.Command{
 // findTransposals joins the strings passed in args and hunts for any and all transposals
 // it can find in the dictionary file that was passed in
 func findTransposals(cmd *cobra.Command, args []string) {
-	if dictionaryFile == "" {
-		fmt.Println("A dictionary file is required for finding transposals")
-		os.Exit(1)
-	}
 	// convert args to one long string. since it's a transposal, we can just smush them together
 	fullString := strings.ToUpper(strings.Join(args, ""))
-	results := make(chan string)
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+	required, letterCounts, err := removeRequiredWords(createLetterCountsMap(fullString), mustIncludeWords)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+	// with word frequencies or a limit, the likeliest transposals are printed first once the search is done
+	var rankTrie *trieNode
+	if !transposalStream && (transposalMaxResults > 0 || hasWordFrequencies(rootTrie)) {
+		rankTrie = rootTrie
+	}
+
+	ctx, cancel := solveContext()
+	defer cancel()
+
+	printer := newResultPrinter("words")
+	request := transposalRequest{letterCounts, required, transposalPermutations, excludedWordSet(excludedAnswerWords)}
+	parseTransposals(streamTransposals(ctx, rootTrie, request), printer, rankTrie, transposalMaxResults)
+	printer.finish(ctx.Err() != nil)
+}
+
+// streamTransposals runs performTransposalRequest in the background and returns the channel it writes
+// solutions to as they're found. The channel is closed once the search finishes or ctx is cancelled, so
+// anyone who stops reading early should cancel ctx
+func streamTransposals(ctx context.Context, rootTrie *trieNode, request transposalRequest) <-chan []string {
+	solutions := make(chan []string)
 	go func() {
-		feedDictionaryPaths(results, dictionaryFile)
+		performTransposalRequest(ctx, rootTrie, request, solutions)
+		close(solutions)
 	}()
-	rootTrie := readDictionaryToTrie(results)
-	letterCounts := createLetterCountsMap(fullString)
-	solutions := make(chan []string)
-	go parseTransposals(solutions)
-	for letter, _ := range letterCounts {
-		childIndex := []byte(letter)[0] - ASCII_A
-		if rootTrie.children[childIndex] != nil {
-			recursiveFindTransposals(rootTrie, rootTrie.children[childIndex], decrementLetterCounts(letter, letterCounts), make([]string, 0), letter, solutions)
+	return solutions
+}
+
+// performTransposalSolve writes every set of dictionary words that uses up exactly the letters in
+// letterCounts to solutions. The letters the first word can start with are split up among as many as
+// concurrency goroutines, since each one's search is independent. Unless permutations is set, each set
+// of words is written once, in alphabetical order. It returns when the search is finished or ctx is cancelled.
+func performTransposalSolve(ctx context.Context, rootTrie *trieNode, letterCounts map[string]int, permutations bool, solutions chan []string) {
+	startingLetters := make([]string, 0, len(letterCounts))
+	for letter := range letterCounts {
+		if letter != blankLetter && rootTrie.Child(letter[0]) != nil {
+			startingLetters = append(startingLetters, letter)
+		}
+	}
+	// a blank can start the first word with any other letter
+	if letterCounts[blankLetter] > 0 {
+		for _, child := range rootTrie.Children() {
+			if letterCounts[child.Letter()] == 0 {
+				startingLetters = append(startingLetters, child.Letter())
+			}
+		}
+	}
+	sort.Strings(startingLetters)
+
+	partitionCount := concurrency
+	if len(startingLetters) < partitionCount {
+		partitionCount = len(startingLetters)
+	}
+	if partitionCount < 1 {
+		partitionCount = 1
+	}
+	// deal the letters out like cards so every goroutine gets a mix of common and rare ones
+	partitions := make([][]string, partitionCount)
+	for index, letter := range startingLetters {
+		partitions[index%partitionCount] = append(partitions[index%partitionCount], letter)
+	}
+
+	var waitGroup sync.WaitGroup
+	for _, letters := range partitions {
+		waitGroup.Add(1)
+		go func(letters []string) {
+			for _, letter := range letters {
+				remainingCounts, _ := useLetter(letter, letterCounts)
+				recursiveFindTransposals(ctx, rootTrie, rootTrie.Child(letter[0]), remainingCounts, make([]string, 0), letter, permutations, solutions)
+			}
+			waitGroup.Done()
+		}(letters)
+	}
+	waitGroup.Wait()
+}
+
+// transposalRequest is a transposal search. letterCounts are the letters left once the required words' letters
+// have been taken out, and every solution starts with the required words. Solutions with an excluded word are
+// left out
+type transposalRequest struct {
+	letterCounts map[string]int
+	required     []string
+	permutations bool
+	excluded     map[string]bool
+}
+
+// performTransposalRequest runs performTransposalSolve for the request, writing each solution it allows to solutions
+func performTransposalRequest(ctx context.Context, rootTrie *trieNode, request transposalRequest, solutions chan []string) {
+	// the required words might use up every letter on their own
+	if len(request.letterCounts) == 0 {
+		if len(request.required) > 0 && !hasExcludedWord(request.required, request.excluded) {
+			select {
+			case solutions <- append([]string{}, request.required...):
+			case <-ctx.Done():
+			}
+		}
+		return
+	}
+
+	remainders := make(chan []string)
+	go func() {
+		performTransposalSolve(ctx, rootTrie, request.letterCounts, request.permutations, remainders)
+		close(remainders)
+	}()
+	for remainder := range remainders {
+		wordSet := make([]string, 0, len(request.required)+len(remainder))
+		wordSet = append(wordSet, request.required...)
+		wordSet = append(wordSet, remainder...)
+		if hasExcludedWord(wordSet, request.excluded) {
+			continue
+		}
+		select {
+		case solutions <- wordSet:
+		case <-ctx.Done():
+			// keep draining so the search can see it's been cancelled and finish
 		}
 	}
-	close(solutions)
 }
 
 // recursiveFindTransposals crawls tries and decrements letterCounts if childTrie is still a valid search path
-// results are written to the solutions channel
-func recursiveFindTransposals(rootTrie *trieNode, currentTrie *trieNode, letterCounts map[string]int, currentWordList []string, currentWord string, solutions chan []string) {
+// results are written to the solutions channel. Unless permutations is set, words have to come in alphabetical
+// order, so each set of words is only found once
+func recursiveFindTransposals(ctx context.Context, rootTrie *trieNode, currentTrie *trieNode, letterCounts map[string]int, currentWordList []string, currentWord string, permutations bool, solutions chan []string) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	previousWord := ""
+	if !permutations && len(currentWordList) > 0 {
+		previousWord = currentWordList[len(currentWordList)-1]
+	}
+	// a word that sorts before the one ahead of it can only end here if permutations are allowed,
+	// though longer words starting with it might still come after
+	atWordBoundary := currentTrie.IsWord() && currentWord >= previousWord
+
 	// we have no more letters and we're at a word break
-	if len(letterCounts) == 0 && (currentTrie.atWordBoundary) {
+	if len(letterCounts) == 0 && atWordBoundary {
 		// make a copy to avoid messing with the slice
 		finalWordList := make([]string, 0, len(currentWordList)+1)
 		finalWordList = append(finalWordList, currentWordList...)
 		finalWordList = append(finalWordList, currentWord)
-		solutions <- finalWordList
+		select {
+		case solutions <- finalWordList:
+		case <-ctx.Done():
+		}
 		return
 	}
 
@@ -90,31 +228,45 @@ func recursiveFindTransposals(rootTrie *trieNode, currentTrie *trieNode, letterC
 	// this flow  ensures that we handle word breaks as well as continuations
 	// root could become to or or toro, so we need to handle the word break
 	// _and_ other children
-	for index, childTrie := range currentTrie.children {
-		if childTrie == nil {
-			continue
-		}
-		// special case for word breaks, which require us to recurse but we  don't want to return afterward
-		// because then we'd skip words. e.g., HAT and HATE. If this only checked word boundary, it would return
-		// before finding HATE
-		if index == len(currentTrie.children)-1 {
-			if currentTrie.atWordBoundary {
-				newWordList := make([]string, 0, len(currentWordList)+1)
-				newWordList = append(newWordList, currentWordList...)
-				newWordList = append(newWordList, currentWord)
-				recursiveFindTransposals(rootTrie, rootTrie, letterCounts, newWordList, "", solutions)
-			}
-			break
+	for _, childTrie := range currentTrie.Children() {
+		childLetter := childTrie.Letter()
+		remainingCounts, hasLetter := useLetter(childLetter, letterCounts)
+		if hasLetter && canFollowWord(currentWord+childLetter, previousWord) {
+			recursiveFindTransposals(ctx, rootTrie, childTrie, remainingCounts, currentWordList, currentWord+childLetter, permutations, solutions)
 		}
+	}
 
-		childLetter := string(index + ASCII_A)
-		_, hasCount := letterCounts[childLetter]
-		if hasCount {
-			recursiveFindTransposals(rootTrie, childTrie, decrementLetterCounts(childLetter, letterCounts), currentWordList, currentWord+childLetter, solutions)
-		}
+	// special case for word breaks, which require us to recurse but we  don't want to return afterward
+	// because then we'd skip words. e.g., HAT and HATE. If this only checked word boundary, it would return
+	// before finding HATE
+	if atWordBoundary {
+		newWordList := make([]string, 0, len(currentWordList)+1)
+		newWordList = append(newWordList, currentWordList...)
+		newWordList = append(newWordList, currentWord)
+		recursiveFindTransposals(ctx, rootTrie, rootTrie, letterCounts, newWordList, "", permutations, solutions)
 	}
 }
 
+// useLetter takes letter out of letterCounts, using up a blank if the letter itself has run out. It reports
+// false if there's neither. Only using blanks for letters that are gone means each transposal is found once
+func useLetter(letter string, letterCounts map[string]int) (map[string]int, bool) {
+	if letterCounts[letter] > 0 {
+		return decrementLetterCounts(letter, letterCounts), true
+	}
+	if letterCounts[blankLetter] > 0 {
+		return decrementLetterCounts(blankLetter, letterCounts), true
+	}
+	return letterCounts, false
+}
+
+// canFollowWord reports whether a word starting with prefix could come at or after previous in alphabetical order
+func canFollowWord(prefix string, previous string) bool {
+	if len(previous) > len(prefix) {
+		previous = previous[:len(prefix)]
+	}
+	return prefix >= previous
+}
+
 // decrementLetterCounts decrements the count of letter in currentCounts (and deletes the key if it's decremented to 0)
 // and returns a new letter count map
 func decrementLetterCounts(letter string, currentCounts map[string]int) map[string]int {
@@ -142,8 +294,11 @@ func decrementLetterCounts(letter string, currentCounts map[string]int) map[stri
 }
 
 // parseTransposals reads off a channel and prints out any results that are in accordance with the arguments specified by the user,
-// such as number of words and so forth
-func parseTransposals(solutions chan []string) {
+// such as number of words and so forth. The search runs concurrently, so results come in no particular order; they're
+// held until the channel closes and printed alphabetically, or from the likeliest down as rankTransposals orders them
+// if rankTrie isn't nil. Either way it stops after maxResults if it's above 0
+func parseTransposals(solutions <-chan []string, printer *resultPrinter, rankTrie *trieNode, maxResults int) {
+	held := make([][]string, 0)
 ChannelLoop:
 	for wordSet := range solutions {
 		if len(wordSet) < minNumberOfWords || len(wordSet) > maxNumberOfWords {
@@ -156,19 +311,104 @@ ChannelLoop:
 				continue ChannelLoop
 			}
 		}
-		fmt.Println(strings.Join(wordSet, " "))
+		held = append(held, wordSet)
+	}
+
+	// sorting alphabetically first also breaks ties between equally ranked sets the same way every run
+	sort.Slice(held, func(i, j int) bool {
+		return strings.Join(held[i], " ") < strings.Join(held[j], " ")
+	})
+	if rankTrie != nil {
+		held = rankTransposals(rankTrie, held, maxResults)
+	} else if maxResults > 0 && len(held) > maxResults {
+		held = held[:maxResults]
+	}
+	for _, wordSet := range held {
+		words := strings.Join(wordSet, " ")
+		printer.result(words, words)
+	}
+}
+
+// removeRequiredWords takes the letters of each word in required out of letterCounts, returning the uppercased
+// words and the letters that are left. Blanks fill in for letters that run out, but it's an error if a word
+// needs more letters than there are
+func removeRequiredWords(letterCounts map[string]int, required []string) ([]string, map[string]int, error) {
+	words := make([]string, 0, len(required))
+	for _, word := range required {
+		word = strings.ToUpper(word)
+		for _, letter := range strings.Split(string(justUppercaseLetters(word)), "") {
+			var hasLetter bool
+			if letterCounts, hasLetter = useLetter(letter, letterCounts); !hasLetter {
+				return nil, nil, fmt.Errorf("%s can't be made from the letters that are left", word)
+			}
+		}
+		words = append(words, word)
 	}
+	return words, letterCounts, nil
+}
+
+// excludedWordSet is the uppercased words to leave out of results, from --exclude or an exclude parameter
+func excludedWordSet(words []string) map[string]bool {
+	excluded := make(map[string]bool, len(words))
+	for _, word := range words {
+		excluded[strings.ToUpper(strings.TrimSpace(word))] = true
+	}
+	return excluded
+}
+
+// hasExcludedWord reports whether any of words is in excluded
+func hasExcludedWord(words []string, excluded map[string]bool) bool {
+	for _, word := range words {
+		if excluded[word] {
+			return true
+		}
+	}
+	return false
+}
+
+// rankTransposals orders word sets from the likeliest answer down and keeps the first maxResults of them, or all
+// of them if maxResults isn't above 0. Sets made of more common words come first, going by the dictionary's word
+// frequencies if it has them and by the sum of the words' ranks in it otherwise
+func rankTransposals(rootTrie *trieNode, wordSets [][]string, maxResults int) [][]string {
+	if hasWordFrequencies(rootTrie) {
+		sortByFrequency(rootTrie, wordSets)
+	} else {
+		scores := make([]int, len(wordSets))
+		for index, words := range wordSets {
+			for _, word := range words {
+				value, _ := rootTrie.Get(word)
+				scores[index] += dictionaryRank(value)
+			}
+		}
+		order := make([]int, len(wordSets))
+		for index := range order {
+			order[index] = index
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return scores[order[i]] < scores[order[j]]
+		})
+		sorted := make([][]string, len(wordSets))
+		for index, original := range order {
+			sorted[index] = wordSets[original]
+		}
+		wordSets = sorted
+	}
+
+	if maxResults > 0 && len(wordSets) > maxResults {
+		wordSets = wordSets[:maxResults]
+	}
+	return wordSets
 }
 
 // createLetterCountsMap takes in a string and returns a map of letter to count.
 // this can then be used when walking the trie to keep track of whether the path
-// we're on represents a transposal
+// we're on represents a transposal. Blanks are counted under blankLetter
 func createLetterCountsMap(input string) map[string]int {
 	counts := make(map[string]int)
 	letters := strings.Split(input, "")
 
 	for _, letter := range letters {
-		if !lettersRegex.Match([]byte(letter)) {
+		if letter != blankLetter && !lettersRegex.Match([]byte(letter)) {
 			continue
 		}
 		upperLetter := strings.ToUpper(letter)
@@ -182,13 +422,105 @@ func createLetterCountsMap(input string) map[string]int {
 	return counts
 }
 
+// transposalSolverRequest is the search a transposal solver's input asks for
+func transposalSolverRequest(input solverInput) (transposalRequest, error) {
+	letterCounts := createLetterCountsMap(input.getString("letters"))
+	if len(letterCounts) == 0 {
+		return transposalRequest{}, fmt.Errorf("there are no letters to transpose")
+	}
+	required, letterCounts, err := removeRequiredWords(letterCounts, input.getList("must_include"))
+	if err != nil {
+		return transposalRequest{}, err
+	}
+	return transposalRequest{letterCounts, required, input.getBool("permutations"), excludedWordSet(input.getList("exclude"))}, nil
+}
+
+// runTransposalSolver is transposal for the solver registry. The search stops when ctx does,
+// so a budget gets back the transposals found in time
+func runTransposalSolver(ctx context.Context, input solverInput) (*resultTable, error) {
+	request, err := transposalSolverRequest(input)
+	if err != nil {
+		return nil, err
+	}
+	rootTrie, _, err := openDictionaryTrie(input.getList("dictionary")...)
+	if err != nil {
+		return nil, err
+	}
+
+	maxWords := input.getInt("max_words")
+	found := make([][]string, 0)
+	for wordSet := range streamTransposals(ctx, rootTrie, request) {
+		if maxWords < 1 || len(wordSet) <= maxWords {
+			found = append(found, wordSet)
+		}
+	}
+	found = rankTransposals(rootTrie, found, input.getInt("max_results"))
+
+	table := newResultTable("words")
+	for _, wordSet := range found {
+		table.addRow(strings.Join(wordSet, " "))
+	}
+	return table, nil
+}
+
+// streamTransposalSolver is the transposal solver's stream function, which sends transposals as they're found
+// rather than ranking them, stopping after max_results
+func streamTransposalSolver(ctx context.Context, input solverInput, send func(values ...string)) error {
+	request, err := transposalSolverRequest(input)
+	if err != nil {
+		return err
+	}
+	rootTrie, _, err := openDictionaryTrie(input.getList("dictionary")...)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	maxWords, maxResults := input.getInt("max_words"), input.getInt("max_results")
+	sent := 0
+	for wordSet := range streamTransposals(ctx, rootTrie, request) {
+		if maxWords > 0 && len(wordSet) > maxWords {
+			continue
+		}
+		send(strings.Join(wordSet, " "))
+		if sent++; sent == maxResults {
+			break
+		}
+	}
+	return nil
+}
+
 func init() {
-	transposalCmd.Flags().StringVarP(&dictionaryFile, "dictionary", "d", "", "Dictionary file to use, or - to use stdin")
+	mustRegisterSolver(&solver{
+		name:        "transposal",
+		description: "Anagrams of the letters, as one or more dictionary words",
+		parameters: []solverParameter{
+			solverParameter{name: "letters", kind: solverString, description: "the letters to rearrange", required: true},
+			solverParameter{name: "dictionary", kind: solverString, description: "the dictionary file to use, or several separated by commas", required: true},
+			solverParameter{name: "max_words", kind: solverInt, description: "the most words in a transposal; 0 means no limit", defaultValue: "3"},
+			solverParameter{name: "must_include", kind: solverString, description: "a word that has to be in the transposal, or several separated by commas"},
+			solverParameter{name: "max_results", kind: solverInt, description: "return only this many transposals, the likeliest first; 0 returns them all", defaultValue: "100"},
+			solverParameter{name: "exclude", kind: solverString, description: "a word to leave out of the transposals, or several separated by commas"},
+			solverParameter{name: "permutations", kind: solverBool, description: "list every ordering of a multiword transposal instead of just the alphabetical one"},
+		},
+		run:     runTransposalSolver,
+		columns: []string{"words"},
+		stream:  streamTransposalSolver,
+	})
+
+	transposalCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
 	transposalCmd.MarkFlagRequired("dictionary")
 
+	transposalCmd.Flags().IntVarP(&concurrency, "concurrency", "c", 10, "The maximum goroutines to create for solving. Defaults to 10.")
 	transposalCmd.Flags().IntVarP(&minWordLength, "min-word-length", "", 0, "The minimum length a word in the transposal can be")
 	transposalCmd.Flags().IntVarP(&maxWordLength, "max-word-length", "", math.MaxUint32, "The maximum length a word in the transposal can be")
 	transposalCmd.Flags().IntVarP(&minNumberOfWords, "min-words", "", 0, "The minimum number of words allowable in a solution")
 	transposalCmd.Flags().IntVarP(&maxNumberOfWords, "max-words", "", math.MaxUint32, "The maximum number of words allowable in a solution")
+	transposalCmd.Flags().StringSliceVarP(&mustIncludeWords, "must-include", "", nil, "A word the transposal has to include. Repeat it or separate words with commas for several")
+	transposalCmd.Flags().IntVarP(&transposalMaxResults, "max-results", "", 0, "List only this many transposals, the likeliest first. 0 lists them all")
+	transposalCmd.Flags().StringSliceVarP(&excludedAnswerWords, "exclude", "", nil, "Leave out transposals with this word in them. Repeat it or separate words with commas for several")
+	transposalCmd.Flags().BoolVarP(&transposalStream, "stream", "", false, "Print transposals as they're found instead of ranking them first")
+	transposalCmd.Flags().BoolVarP(&transposalPermutations, "permutations", "", false, "List every ordering of the words in a transposal, not just the alphabetical one")
 	rootCmd.AddCommand(transposalCmd)
 }
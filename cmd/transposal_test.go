@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -71,3 +75,266 @@ func TestDecrementLetterCounts(test *testing.T) {
 	}
 
 }
+
+func TestPerformTransposalSolve(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"CAT", "ACT", "DOG", "GOD", "DOGMA"} {
+		trie.Add(word, nil)
+	}
+
+	solutions := make(chan []string)
+	go func() {
+		performTransposalSolve(context.Background(), trie, createLetterCountsMap("TAC"), false, solutions)
+		close(solutions)
+	}()
+
+	found := make([]string, 0)
+	for solution := range solutions {
+		found = append(found, strings.Join(solution, " "))
+	}
+	sort.Strings(found)
+	if strings.Join(found, ",") != "ACT,CAT" {
+		test.Errorf("Expected ACT and CAT but got %v", found)
+	}
+
+	// a cancelled search finds nothing, even though nobody is reading the channel
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	performTransposalSolve(ctx, trie, createLetterCountsMap("TAC"), false, make(chan []string))
+}
+
+func TestPerformTransposalSolveConcurrency(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"TO", "OT", "DO", "GOD", "DOG", "GOT", "TOG", "DOT"} {
+		trie.Add(word, nil)
+	}
+	previous := concurrency
+	defer func() { concurrency = previous }()
+
+	results := make(map[int]string)
+	for _, workers := range []int{1, 2, 10} {
+		concurrency = workers
+		solutions := make(chan []string)
+		go func() {
+			performTransposalSolve(context.Background(), trie, createLetterCountsMap("DOGOT"), false, solutions)
+			close(solutions)
+		}()
+		found := make([]string, 0)
+		for solution := range solutions {
+			found = append(found, strings.Join(solution, " "))
+		}
+		sort.Strings(found)
+		results[workers] = strings.Join(found, ",")
+	}
+	if results[1] == "" || results[1] != results[2] || results[1] != results[10] {
+		test.Errorf("Expected the same transposals however many goroutines search but got %v", results)
+	}
+}
+
+func TestPerformTransposalSolvePermutations(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"CAT", "CATS", "DOG", "DO", "GOD", "TAG", "SO"} {
+		trie.Add(word, nil)
+	}
+
+	testCases := []struct {
+		letters      string
+		permutations bool
+		expected     string
+	}{
+		{"CATDOG", false, "CAT DOG,CAT GOD"},
+		{"CATDOG", true, "CAT DOG,CAT GOD,DOG CAT,GOD CAT"},
+		// after CAT, the search has to get past CA and CAT to find CATS
+		{"CATSCAT", false, "CAT CATS"},
+		{"CATSCAT", true, "CAT CATS,CATS CAT"},
+	}
+
+	for _, testCase := range testCases {
+		solutions := make(chan []string)
+		go func() {
+			performTransposalSolve(context.Background(), trie, createLetterCountsMap(testCase.letters), testCase.permutations, solutions)
+			close(solutions)
+		}()
+		found := make([]string, 0)
+		for solution := range solutions {
+			found = append(found, strings.Join(solution, " "))
+		}
+		sort.Strings(found)
+		if strings.Join(found, ",") != testCase.expected {
+			test.Errorf("Expected %s for %s with permutations %v but got %v", testCase.expected, testCase.letters, testCase.permutations, found)
+		}
+	}
+}
+
+func TestRemoveRequiredWords(test *testing.T) {
+	words, remaining, err := removeRequiredWords(createLetterCountsMap("LISTENTOME"), []string{"silent", "to"})
+	if err != nil {
+		test.Fatalf("Expected SILENT and TO to fit in LISTENTOME but got %v", err)
+	}
+	if strings.Join(words, " ") != "SILENT TO" {
+		test.Errorf("Expected the required words uppercased but got %v", words)
+	}
+	if len(remaining) != 2 || remaining["M"] != 1 || remaining["E"] != 1 {
+		test.Errorf("Expected M and E to be left but got %v", remaining)
+	}
+
+	if _, _, err := removeRequiredWords(createLetterCountsMap("LISTEN"), []string{"SILENT", "A"}); err == nil {
+		test.Errorf("Expected an error for a word without enough letters left but got none")
+	}
+}
+
+func TestPerformTransposalRequest(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"ME", "EM", "ON", "NO"} {
+		trie.Add(word, nil)
+	}
+
+	testCases := []struct {
+		letters  string
+		required []string
+		expected string
+	}{
+		{"SILENTME", []string{"SILENT"}, "SILENT EM,SILENT ME"},
+		{"SILENT", []string{"SILENT"}, "SILENT"},
+		{"SILENTX", []string{"SILENT"}, ""},
+	}
+
+	for _, testCase := range testCases {
+		required, letterCounts, _ := removeRequiredWords(createLetterCountsMap(testCase.letters), testCase.required)
+		solutions := make(chan []string)
+		go func() {
+			performTransposalRequest(context.Background(), trie, transposalRequest{letterCounts: letterCounts, required: required}, solutions)
+			close(solutions)
+		}()
+		found := make([]string, 0)
+		for solution := range solutions {
+			found = append(found, strings.Join(solution, " "))
+		}
+		sort.Strings(found)
+		if strings.Join(found, ",") != testCase.expected {
+			test.Errorf("Expected %q for %s including %v but got %v", testCase.expected, testCase.letters, testCase.required, found)
+		}
+	}
+}
+
+func TestRankTransposals(test *testing.T) {
+	trie := newTrie()
+	for rank, word := range []string{"THE", "CAT", "ACT", "TA", "C"} {
+		trie.Add(word, dictionaryWord{rank: rank + 1})
+	}
+	wordSets := [][]string{{"ACT"}, {"C", "TA"}, {"CAT"}}
+
+	testCases := []struct {
+		maxResults int
+		expected   string
+	}{
+		{0, "CAT,ACT,C TA"},
+		{2, "CAT,ACT"},
+		{5, "CAT,ACT,C TA"},
+	}
+
+	for _, testCase := range testCases {
+		ranked := rankTransposals(trie, append([][]string{}, wordSets...), testCase.maxResults)
+		joined := make([]string, 0, len(ranked))
+		for _, words := range ranked {
+			joined = append(joined, strings.Join(words, " "))
+		}
+		if strings.Join(joined, ",") != testCase.expected {
+			test.Errorf("Expected %s for at most %d results but got %v", testCase.expected, testCase.maxResults, joined)
+		}
+	}
+
+	frequencies, _ := rankedTestTrie("ACT\t50\nCAT\t10\nTA\t100\nC\t80\n")
+	ranked := rankTransposals(frequencies, [][]string{{"CAT"}, {"C", "TA"}, {"ACT"}}, 1)
+	if len(ranked) != 1 || ranked[0][0] != "ACT" {
+		test.Errorf("Expected the most frequent transposal, ACT, but got %v", ranked)
+	}
+}
+
+func TestPerformTransposalSolveBlanks(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"CAT", "ACT", "COT", "TACO", "A"} {
+		trie.Add(word, nil)
+	}
+
+	testCases := []struct {
+		letters  string
+		expected string
+	}{
+		{"CA?", "ACT,CAT"},
+		{"C??", "ACT,CAT,COT"},
+		{"???", "A A A,ACT,CAT,COT"},
+		{"TACO?", "A A COT,A TACO"},
+	}
+
+	for _, testCase := range testCases {
+		if counts := createLetterCountsMap(testCase.letters); counts[blankLetter] != strings.Count(testCase.letters, "?") {
+			test.Errorf("Expected the blanks in %s to be counted but got %v", testCase.letters, counts)
+		}
+		solutions := make(chan []string)
+		go func() {
+			performTransposalSolve(context.Background(), trie, createLetterCountsMap(testCase.letters), false, solutions)
+			close(solutions)
+		}()
+		found := make([]string, 0)
+		for solution := range solutions {
+			found = append(found, strings.Join(solution, " "))
+		}
+		sort.Strings(found)
+		if strings.Join(found, ",") != testCase.expected {
+			test.Errorf("Expected %s for %s but got %v", testCase.expected, testCase.letters, found)
+		}
+	}
+
+	_, remaining, err := removeRequiredWords(createLetterCountsMap("SILEN?ME"), []string{"SILENT"})
+	if err != nil || len(remaining) != 2 || remaining["M"] != 1 || remaining["E"] != 1 {
+		test.Errorf("Expected a blank to stand in for the T of SILENT, leaving M and E, but got %v (%v)", remaining, err)
+	}
+}
+
+func TestPerformTransposalRequestExcluded(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"CAT", "ACT", "DOG", "GOD"} {
+		trie.Add(word, nil)
+	}
+
+	testCases := []struct {
+		letters  string
+		required []string
+		exclude  []string
+		expected string
+	}{
+		{"CATDOG", nil, []string{"dog"}, "ACT GOD,CAT GOD"},
+		{"CATDOG", nil, []string{"DOG", "GOD"}, ""},
+		{"CATDOG", []string{"CAT"}, []string{"CAT"}, ""},
+		{"CAT", []string{"CAT"}, []string{"ACT"}, "CAT"},
+	}
+
+	for _, testCase := range testCases {
+		required, letterCounts, _ := removeRequiredWords(createLetterCountsMap(testCase.letters), testCase.required)
+		request := transposalRequest{letterCounts: letterCounts, required: required, excluded: excludedWordSet(testCase.exclude)}
+		found := make([]string, 0)
+		for solution := range streamTransposals(context.Background(), trie, request) {
+			found = append(found, strings.Join(solution, " "))
+		}
+		sort.Strings(found)
+		if strings.Join(found, ",") != testCase.expected {
+			test.Errorf("Expected %q for %s excluding %v but got %v", testCase.expected, testCase.letters, testCase.exclude, found)
+		}
+	}
+}
+
+func TestParseTransposalsSortsResults(test *testing.T) {
+	solutions := make(chan []string, 4)
+	for _, wordSet := range [][]string{{"TOP", "SLAW"}, {"ALP", "STOW"}, {"OWL", "PAST"}, {"LAW", "POST"}} {
+		solutions <- wordSet
+	}
+	close(solutions)
+
+	var text bytes.Buffer
+	printer := &resultPrinter{&text, false, newResultTable("words")}
+	parseTransposals(solutions, printer, nil, 3)
+	if text.String() != "ALP STOW\nLAW POST\nOWL PAST\n" {
+		test.Errorf("Expected the first three results alphabetically but got %q", text.String())
+	}
+}
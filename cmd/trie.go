@@ -2,51 +2,145 @@ package cmd
 
 import (
 	"fmt"
-	"regexp"
 	"strings"
 )
 
 // Implements a basic trie system, which ends up being by used by a number of word puzzles
-// this trie only accepts upper case, alphabetic strings
+// by default this trie only accepts upper case, alphabetic strings, but it can be given a bigger alphabet
+// for dictionaries with entries like DON'T or 7-ELEVEN. Code outside the trie should stick to the
+// exported methods (NewTrie, Add, Get, Walk, MatchPattern, Child, Children) rather than reaching into the nodes
 
 const ASCII_A = 65
 
-type trieNode struct {
+// maxTrieAlphabetSize is the most symbols a trie can hold, since saved tries keep a node's children in one uvarint
+const maxTrieAlphabetSize = 63
+
+// TrieAlphabet is the set of symbols a trie can hold. index maps a symbol to the position of its child,
+// or -1 if the trie can't hold it, and symbol maps a position back to its symbol
+type TrieAlphabet struct {
+	size   int
+	index  func(symbol byte) int
+	symbol func(index int) byte
+}
+
+// NewTrieAlphabet creates an alphabet of size symbols. index and symbol have to agree with each other
+func NewTrieAlphabet(size int, index func(symbol byte) int, symbol func(index int) byte) (*TrieAlphabet, error) {
+	if size < 1 || size > maxTrieAlphabetSize {
+		return nil, fmt.Errorf("a trie alphabet needs between 1 and %d symbols but got %d", maxTrieAlphabetSize, size)
+	}
+	for position := 0; position < size; position++ {
+		if index(symbol(position)) != position {
+			return nil, fmt.Errorf("symbol %c is at position %d but indexes to %d", symbol(position), position, index(symbol(position)))
+		}
+	}
+	return &TrieAlphabet{size, index, symbol}, nil
+}
+
+// UppercaseTrieAlphabet is A-Z, which is what most word puzzles need
+var UppercaseTrieAlphabet = &TrieAlphabet{26,
+	func(symbol byte) int {
+		if !isUppercaseAscii(symbol) {
+			return -1
+		}
+		return int(symbol - ASCII_A)
+	},
+	func(index int) byte { return byte(ASCII_A + index) },
+}
+
+// trieAlphabetOf makes an alphabet out of symbols, in the order they're given
+func trieAlphabetOf(symbols string) (*TrieAlphabet, error) {
+	if symbols == upperAlphabet {
+		return UppercaseTrieAlphabet, nil
+	}
+	ring, err := newSymbolRing(symbols)
+	if err != nil {
+		return nil, err
+	}
+	return NewTrieAlphabet(ring.size(),
+		func(symbol byte) int {
+			if !ring.contains(symbol) {
+				return -1
+			}
+			return ring.position(symbol)
+		},
+		func(index int) byte { return ring.symbols[index] },
+	)
+}
+
+// symbols lists the alphabet's symbols in order
+func (alphabet *TrieAlphabet) symbols() string {
+	symbols := make([]byte, alphabet.size)
+	for index := range symbols {
+		symbols[index] = alphabet.symbol(index)
+	}
+	return string(symbols)
+}
+
+// TrieNode is a trie whose words carry a value of type T, such as an ngram count
+type TrieNode[T any] struct {
 	letter         string
 	atWordBoundary bool
-	value          interface{}
+	value          T
+	alphabet       *TrieAlphabet
 	// each node's children is just a slice of childNodes. the position of each childNode represents its letter
-	// i.e., A= 0 and so on
-	children [27]*trieNode
+	// in the alphabet, i.e., A= 0 and so on, with one extra at the end
+	children []*TrieNode[T]
+	// finalized nodes may be shared by several words, so they can't change
+	finalized bool
+	// words is how many words end at or below this node, which Finalize counts up so that a word's place
+	// in alphabetical order can be found on the way down to it
+	words int
+	// values are the words' values in alphabetical order once the trie is finalized. Only the root has them,
+	// since the nodes below it can be shared by words with different values
+	values []T
 }
 
+// trieNode is the untyped trie used by the dictionary code, where values are whatever the caller stores
+type trieNode = TrieNode[interface{}]
+
 func newTrie() *trieNode {
-	return newTrieWithLetter("")
+	return NewTrie[interface{}]()
 }
 
-func newTrieWithLetter(letter string) *trieNode {
-	var children [27]*trieNode
-	trie := &trieNode{letter, false, nil, children}
+// NewTrie creates an empty trie whose values are of type T and whose words are upper case letters
+func NewTrie[T any]() *TrieNode[T] {
+	return NewTrieWithAlphabet[T](UppercaseTrieAlphabet)
+}
+
+// NewTrieWithAlphabet creates an empty trie whose values are of type T and whose words are made of alphabet's symbols
+func NewTrieWithAlphabet[T any](alphabet *TrieAlphabet) *TrieNode[T] {
+	return newTrieWithLetter[T]("", alphabet)
+}
+
+func newTrieWithLetter[T any](letter string, alphabet *TrieAlphabet) *TrieNode[T] {
+	var zero T
+	trie := &TrieNode[T]{letter: letter, value: zero, alphabet: alphabet, children: make([]*TrieNode[T], alphabet.size+1)}
 	// a special character at the end so that transposals can check if they're at a word boundary _and_ traverse the children
-	trie.children[26] = &trieNode{"", false, nil, children}
+	trie.children[alphabet.size] = &TrieNode[T]{value: zero, alphabet: alphabet}
 	return trie
 }
 
-var allUppercase = regexp.MustCompile("^[A-Z]+$")
-
-func (node *trieNode) addValueForString(input string, value interface{}) error {
+// Add puts input in the trie with value, replacing the value if input is already there
+func (node *TrieNode[T]) Add(input string, value T) error {
 
-	if !allUppercase.MatchString(input) {
-		return fmt.Errorf("This trie only accepts upper case. String %s is invalid", input)
+	if input == "" {
+		return fmt.Errorf("This trie can't hold an empty string")
+	}
+	for _, curByte := range []byte(input) {
+		if node.alphabet.index(curByte) < 0 {
+			return fmt.Errorf("This trie doesn't accept %q. String %s is invalid", curByte, input)
+		}
+	}
+	if node.finalized {
+		return fmt.Errorf("This trie has been finalized, so %s can't be added", input)
 	}
 
 	curChild := node
 	for _, curLetter := range strings.Split(input, "") {
-		// because the trie only accepts uppercase letters, we can just subtract 65 to find the index
-		childIndex := []byte(curLetter)[0] - ASCII_A
+		childIndex := node.alphabet.index(curLetter[0])
 		nextChild := curChild.children[childIndex]
 		if nextChild == nil {
-			nextChild = newTrieWithLetter(curLetter)
+			nextChild = newTrieWithLetter[T](curLetter, node.alphabet)
 			curChild.children[childIndex] = nextChild
 		}
 		curChild = nextChild
@@ -56,60 +150,240 @@ func (node *trieNode) addValueForString(input string, value interface{}) error {
 	return nil
 }
 
-// getSize returns the number of items in the trie
-func (node *trieNode) getSize() int {
+// dawgKey identifies a node by everything below it, so nodes with the same key can stand in for each other
+type dawgKey[T any] struct {
+	letter         string
+	atWordBoundary bool
+	// slices can't be map keys, so the children are copied into an array big enough for any alphabet
+	children [maxTrieAlphabetSize + 1]*TrieNode[T]
+}
+
+// Finalize turns the trie into a DAWG by merging subtrees that hold the same suffixes, which can save a
+// lot of memory for big word lists. The values move out of the nodes into a list on the root, so words
+// share their endings whatever their values are. Get and Walk on the root work as before, but no more
+// words can be added, and the nodes below the root no longer know their words' values
+func (node *TrieNode[T]) Finalize() {
+	if node.finalized {
+		return
+	}
+	values := make([]T, 0)
+	node.Walk(func(word string, value T) bool {
+		values = append(values, value)
+		return true
+	})
+	node.countWords()
+	node.finalizeChildren(make(map[dawgKey[T]]*TrieNode[T]))
+	node.values = values
+}
+
+// countWords sets how many words end at or below each node, clearing the values Finalize has moved to the root
+func (node *TrieNode[T]) countWords() int {
+	var zero T
+	node.value = zero
+	node.words = 0
+	if node.atWordBoundary {
+		node.words = 1
+	}
+	for _, child := range node.Children() {
+		node.words += child.countWords()
+	}
+	return node.words
+}
+
+func (node *TrieNode[T]) finalizeChildren(registry map[dawgKey[T]]*TrieNode[T]) {
+	if node.finalized {
+		return
+	}
+	// children are merged before their parents so that equal subtrees end up with identical children
+	for index, child := range node.children {
+		if child == nil {
+			continue
+		}
+		child.finalizeChildren(registry)
+		key := dawgKey[T]{letter: child.letter, atWordBoundary: child.atWordBoundary}
+		copy(key.children[:], child.children)
+		if existing, exists := registry[key]; exists {
+			node.children[index] = existing
+		} else {
+			registry[key] = child
+		}
+	}
+	node.finalized = true
+}
+
+// nodeCount is the number of distinct nodes in the trie, counting shared nodes once
+func (node *TrieNode[T]) nodeCount() int {
+	seen := make(map[*TrieNode[T]]bool)
+	var visit func(*TrieNode[T])
+	visit = func(current *TrieNode[T]) {
+		if current == nil || seen[current] {
+			return
+		}
+		seen[current] = true
+		for _, child := range current.children {
+			visit(child)
+		}
+	}
+	visit(node)
+	return len(seen)
+}
+
+// Size returns the number of items in the trie
+func (node *TrieNode[T]) Size() int {
 	size := 0
-	wordChannel := make(chan trieWord)
-	go node.feedWordsToChannel(wordChannel)
-	for _ = range wordChannel {
+	node.Walk(func(word string, value T) bool {
 		size++
-	}
+		return true
+	})
 	return size
 }
 
-// getValueForString retrieves the value set for the string. It does not assume
-// the string is in the trie; it will return nil, false if the string wasn't there
-func (node *trieNode) getValueForString(input string) (interface{}, bool) {
+// Get retrieves the value set for the string. It does not assume
+// the string is in the trie; it will return the zero value and false if the string wasn't there.
+// Below the root of a finalized trie, it only says whether the string is there
+func (node *TrieNode[T]) Get(input string) (T, bool) {
 	currentNode := node
+	var zero T
+	// the word's place in alphabetical order, for finalized tries
+	position := 0
 
-	for _, curChar := range strings.Split(input, "") {
-		childIndex := []byte(curChar)[0] - ASCII_A
-		nextNode := currentNode.children[childIndex]
+	for _, curChar := range []byte(input) {
+		nextNode := currentNode.Child(curChar)
 		if nextNode == nil {
-			return nil, false
+			return zero, false
+		}
+		if node.values != nil {
+			// everything ending here or under an earlier letter comes before the word
+			if currentNode.atWordBoundary {
+				position++
+			}
+			for _, sibling := range currentNode.children[:node.alphabet.index(curChar)] {
+				if sibling != nil {
+					position += sibling.words
+				}
+			}
 		}
 		currentNode = nextNode
 	}
 	// you could be at the end of a requested key but not actually at a word boundary
-	if currentNode.atWordBoundary {
-		return currentNode.value, true
-	} else {
-		return nil, false
+	if !currentNode.atWordBoundary {
+		return zero, false
 	}
+	if node.values != nil {
+		return node.values[position], true
+	}
+	return currentNode.value, true
 }
 
-type trieWord struct {
-	word  string
-	value interface{}
+// Child returns the node for letter below this one, or nil if no word continues with it
+func (node *TrieNode[T]) Child(letter byte) *TrieNode[T] {
+	index := node.alphabet.index(letter)
+	if index < 0 {
+		return nil
+	}
+	return node.children[index]
 }
 
-func (node *trieNode) feedWordsToChannel(channel chan trieWord) {
-	node.recursiveFindWords("", channel)
-	close(channel)
+// Children returns the nodes below this one in alphabetical order, leaving out letters no word continues with
+func (node *TrieNode[T]) Children() []*TrieNode[T] {
+	children := make([]*TrieNode[T], 0, len(node.children)-1)
+	for _, child := range node.children[:len(node.children)-1] {
+		if child != nil {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// Letter is the letter that leads to this node; it's empty for the root
+func (node *TrieNode[T]) Letter() string {
+	return node.letter
+}
+
+// IsWord reports whether a word ends at this node
+func (node *TrieNode[T]) IsWord() bool {
+	return node.atWordBoundary
+}
+
+// Value is the value stored with the word ending at this node. Finalize moves values to the root, so
+// use Get on the root to look them up in a finalized trie
+func (node *TrieNode[T]) Value() T {
+	return node.value
 }
 
-func (node *trieNode) recursiveFindWords(currentWord string, channel chan trieWord) {
+// Walk calls visit with every word in the trie and its value, in alphabetical order.
+// It stops early if visit returns false. Below the root of a finalized trie, the values are all zero
+func (node *TrieNode[T]) Walk(visit func(word string, value T) bool) {
+	position := 0
+	node.walkFrom("", node.values, &position, visit)
+}
+
+// walkFrom visits the words at and below node. values are the finalized root's, if there are any, and
+// position counts the words visited so far to find each one's value in them
+func (node *TrieNode[T]) walkFrom(currentWord string, values []T, position *int, visit func(word string, value T) bool) bool {
 	if node.atWordBoundary {
-		channel <- trieWord{currentWord, node.value}
+		value := node.value
+		if values != nil {
+			value = values[*position]
+		}
+		*position++
+		if !visit(currentWord, value) {
+			return false
+		}
 	}
 
-	for index, currentNode := range node.children {
-		if currentNode != nil {
-			currentNode.recursiveFindWords(currentWord+(string(index+ASCII_A)), channel)
+	for _, child := range node.Children() {
+		if !child.walkFrom(currentWord+child.letter, values, position, visit) {
+			return false
 		}
 	}
+	return true
+}
+
+// MatchPattern returns the words in the trie that fit pattern one letter at a time, in alphabetical
+// order. A ? in the pattern stands for any letter, as in C?T?? for crossword answers
+func (node *TrieNode[T]) MatchPattern(pattern string) []string {
+	matches := make([]string, 0)
+	node.matchFrom(pattern, "", &matches)
+	return matches
+}
+
+func (node *TrieNode[T]) matchFrom(pattern string, currentWord string, matches *[]string) {
+	if pattern == "" {
+		if node.atWordBoundary {
+			*matches = append(*matches, currentWord)
+		}
+		return
+	}
+
+	if pattern[0] != '?' {
+		if child := node.Child(pattern[0]); child != nil {
+			child.matchFrom(pattern[1:], currentWord+child.letter, matches)
+		}
+		return
+	}
+	for _, child := range node.Children() {
+		child.matchFrom(pattern[1:], currentWord+child.letter, matches)
+	}
+}
+
+// TrieWord is a word from a trie along with its value
+type TrieWord[T any] struct {
+	word  string
+	value T
+}
+
+type trieWord = TrieWord[interface{}]
+
+// feedWordsToChannel sends every word in the trie to channel and closes it
+func (node *TrieNode[T]) feedWordsToChannel(channel chan TrieWord[T]) {
+	node.Walk(func(word string, value T) bool {
+		channel <- TrieWord[T]{word, value}
+		return true
+	})
+	close(channel)
 }
 
-func (node *trieNode) String() string {
-	return fmt.Sprintf("%s (%s): [%v]", node.letter, node.value, node.children)
+func (node *TrieNode[T]) String() string {
+	return fmt.Sprintf("%s (%v): [%v]", node.letter, node.value, node.children)
 }
@@ -1,6 +1,13 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -8,12 +15,12 @@ import (
 func TestAdds(test *testing.T) {
 	trie := newTrie()
 
-	err := trie.addValueForString("hello", nil)
+	err := trie.Add("hello", nil)
 	if err == nil {
 		test.Errorf("Trie should have rejected 'hello'")
 	}
 
-	trie.addValueForString("HELLO", nil)
+	trie.Add("HELLO", nil)
 
 	childTrie := trie.children['H'-ASCII_A]
 	if childTrie == nil {
@@ -41,10 +48,10 @@ func TestAddingRetrieving(test *testing.T) {
 	for index, testCase := range tests {
 		trie := newTrie()
 		if testCase.shouldBePresent {
-			trie.addValueForString(testCase.input, testCase.value)
+			trie.Add(testCase.input, testCase.value)
 		}
 
-		value, stringWasPresent := trie.getValueForString(testCase.input)
+		value, stringWasPresent := trie.Get(testCase.input)
 		if stringWasPresent != testCase.shouldBePresent {
 			test.Errorf("Test case %d: expected %v for string's presence, got %v", index, testCase.shouldBePresent, stringWasPresent)
 		}
@@ -57,11 +64,11 @@ func TestAddingRetrieving(test *testing.T) {
 
 func TestGetSize(test *testing.T) {
 	trie := newTrie()
-	trie.addValueForString("HELLO", nil)
-	trie.addValueForString("HELL", nil)
-	trie.addValueForString("HE", nil)
-	trie.addValueForString("GOODBYE", nil)
-	actualSize := trie.getSize()
+	trie.Add("HELLO", nil)
+	trie.Add("HELL", nil)
+	trie.Add("HE", nil)
+	trie.Add("GOODBYE", nil)
+	actualSize := trie.Size()
 	if actualSize != 4 {
 		test.Errorf("Expected trie size of 4 but got %d", actualSize)
 	}
@@ -75,7 +82,7 @@ func TestIterateWords(test *testing.T) {
 
 	trie := newTrie()
 	for testWord, testValue := range tests {
-		trie.addValueForString(testWord, testValue)
+		trie.Add(testWord, testValue)
 	}
 
 	words := make(chan trieWord)
@@ -100,3 +107,234 @@ func TestIterateWords(test *testing.T) {
 		break
 	}
 }
+
+func TestTypedTrie(test *testing.T) {
+	trie := NewTrie[int]()
+	trie.Add("ABC", 3)
+
+	value, found := trie.Get("ABC")
+	if !found || value != 3 {
+		test.Errorf("Expected 3 for ABC but got %d (found: %v)", value, found)
+	}
+
+	value, found = trie.Get("AB")
+	if found || value != 0 {
+		test.Errorf("Expected AB to be missing with a zero value but got %d (found: %v)", value, found)
+	}
+}
+
+func TestTrieAccessors(test *testing.T) {
+	trie := NewTrie[int]()
+	for index, word := range []string{"HELLO", "HELP", "HE", "GOODBYE"} {
+		trie.Add(word, index)
+	}
+
+	walked := make([]string, 0)
+	trie.Walk(func(word string, value int) bool {
+		walked = append(walked, fmt.Sprintf("%s=%d", word, value))
+		return true
+	})
+	if strings.Join(walked, ",") != "GOODBYE=3,HE=2,HELLO=0,HELP=1" {
+		test.Errorf("Expected the words in alphabetical order but got %v", walked)
+	}
+
+	// stopping early
+	count := 0
+	trie.Walk(func(word string, value int) bool {
+		count++
+		return count < 2
+	})
+	if count != 2 {
+		test.Errorf("Expected Walk to stop after 2 words but it visited %d", count)
+	}
+
+	he := trie.Child('H').Child('E')
+	if he == nil || !he.IsWord() || he.Value() != 2 || he.Letter() != "E" {
+		test.Fatalf("Expected the HE node to be the end of a word with value 2 but got %v", he)
+	}
+	if trie.Child('Z') != nil || trie.Child('h') != nil {
+		test.Errorf("Expected no child for Z or a lowercase letter")
+	}
+
+	letters := make([]string, 0)
+	for _, child := range he.Child('L').Children() {
+		letters = append(letters, child.Letter())
+	}
+	if strings.Join(letters, "") != "LP" {
+		test.Errorf("Expected HEL to continue with L and P but got %v", letters)
+	}
+
+	if _, found := trie.Get("he"); found {
+		test.Errorf("Expected lowercase lookups to miss rather than panic")
+	}
+}
+
+func TestFinalize(test *testing.T) {
+	words := []string{"BAKING", "BAKED", "MAKING", "MAKED", "TAKING", "TAKE"}
+	trie := newTrie()
+	for _, word := range words {
+		trie.Add(word, nil)
+	}
+	nodes := trie.nodeCount()
+	walked := make([]string, 0, len(words))
+	trie.Walk(func(word string, value interface{}) bool {
+		walked = append(walked, word)
+		return true
+	})
+
+	trie.Finalize()
+	if trie.nodeCount() >= nodes/2 {
+		test.Errorf("Expected the shared suffixes to more than halve the %d nodes but got %d", nodes, trie.nodeCount())
+	}
+	afterWalk := make([]string, 0, len(words))
+	trie.Walk(func(word string, value interface{}) bool {
+		afterWalk = append(afterWalk, word)
+		return true
+	})
+	if strings.Join(afterWalk, " ") != strings.Join(walked, " ") {
+		test.Errorf("Expected the same words after finalizing but got %v instead of %v", afterWalk, walked)
+	}
+	for _, missing := range []string{"MAKE", "BAKE", "TAKED"} {
+		if _, present := trie.Get(missing); present {
+			test.Errorf("Expected sharing suffixes not to add %s", missing)
+		}
+	}
+	if err := trie.Add("CAKE", nil); err == nil {
+		test.Errorf("Expected a finalized trie to refuse new words")
+	}
+
+	// values are kept off the nodes, so words with different values still share their endings
+	ranked := NewTrie[int]()
+	ranked.Add("BAKED", 1)
+	ranked.Add("MAKED", 2)
+	rankedNodes := ranked.nodeCount()
+	ranked.Finalize()
+	if ranked.nodeCount() >= rankedNodes {
+		test.Errorf("Expected BAKED and MAKED to share AKED but the trie still has %d nodes", ranked.nodeCount())
+	}
+	if rank, _ := ranked.Get("MAKED"); rank != 2 {
+		test.Errorf("Expected MAKED to keep its rank of 2 but got %d", rank)
+	}
+	if rank, _ := ranked.Get("BAKED"); rank != 1 {
+		test.Errorf("Expected BAKED to keep its rank of 1 but got %d", rank)
+	}
+}
+
+func TestFinalizeRankedDictionary(test *testing.T) {
+	// the built-in tetragrams, ranked by how common they are, stand in for a large ranked word list
+	reader, err := gzip.NewReader(bytes.NewReader(embeddedTetragrams))
+	if err != nil {
+		test.Fatalf("Expected to read the built-in tetragrams but got %v", err)
+	}
+	type ranked struct {
+		ngram     string
+		frequency float64
+	}
+	entries := make([]ranked, 0)
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		frequency, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			test.Fatalf("Expected a frequency in %s but got %v", scanner.Text(), err)
+		}
+		entries = append(entries, ranked{fields[0], frequency})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].frequency > entries[j].frequency })
+
+	trie := NewTrie[int]()
+	ranks := make(map[string]int, len(entries))
+	for rank, entry := range entries {
+		trie.Add(entry.ngram, rank+1)
+		ranks[entry.ngram] = rank + 1
+	}
+	nodes := trie.nodeCount()
+	trie.Finalize()
+	if trie.nodeCount() >= nodes/10 {
+		test.Errorf("Expected finalizing %d ranked words to cut the %d nodes tenfold but got %d", len(entries), nodes, trie.nodeCount())
+	}
+
+	walked := 0
+	trie.Walk(func(word string, rank int) bool {
+		walked++
+		if ranks[word] != rank {
+			test.Errorf("Expected Walk to give %s rank %d but got %d", word, ranks[word], rank)
+		}
+		return true
+	})
+	if walked != len(entries) {
+		test.Errorf("Expected to walk %d words but got %d", len(entries), walked)
+	}
+	for word, expected := range ranks {
+		if rank, found := trie.Get(word); !found || rank != expected {
+			test.Errorf("Expected %s to have rank %d but got %d (found: %v)", word, expected, rank, found)
+		}
+	}
+}
+
+func TestMatchPattern(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"CAT", "CATS", "COT", "CUT", "CART", "ACT", "CATTY"} {
+		trie.Add(word, nil)
+	}
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"C?T", []string{"CAT", "COT", "CUT"}},
+		{"CAT", []string{"CAT"}},
+		{"???", []string{"ACT", "CAT", "COT", "CUT"}},
+		{"C?T??", []string{"CATTY"}},
+		{"?A?T", []string{"CART"}},
+		{"DOG", []string{}},
+		{"", []string{}},
+	}
+	for _, testCase := range tests {
+		if matches := trie.MatchPattern(testCase.pattern); strings.Join(matches, " ") != strings.Join(testCase.expected, " ") {
+			test.Errorf("Expected %s to match %v but got %v", testCase.pattern, testCase.expected, matches)
+		}
+	}
+}
+
+func TestTrieAlphabet(test *testing.T) {
+	if err := newTrie().Add("DON'T", nil); err == nil {
+		test.Errorf("Expected an uppercase trie to refuse DON'T")
+	}
+
+	alphabet, err := trieAlphabetOf(upperAlphabet + "'-0123456789")
+	if err != nil {
+		test.Fatalf("Unexpected error making the alphabet: %v", err)
+	}
+	trie := NewTrieWithAlphabet[interface{}](alphabet)
+	for _, word := range []string{"DON'T", "DONT", "7-ELEVEN", "CATCH-22", "DONE"} {
+		if err := trie.Add(word, nil); err != nil {
+			test.Errorf("Expected %s to be added but got %v", word, err)
+		}
+	}
+	if err := trie.Add("DON!T", nil); err == nil {
+		test.Errorf("Expected DON!T to be refused")
+	}
+	if _, present := trie.Get("DON'T"); !present {
+		test.Errorf("Expected DON'T to be in the trie")
+	}
+
+	words := make([]string, 0)
+	trie.Walk(func(word string, value interface{}) bool {
+		words = append(words, word)
+		return true
+	})
+	// words are walked in the alphabet's order, so letters come before the other symbols
+	if strings.Join(words, " ") != "CATCH-22 DONE DONT DON'T 7-ELEVEN" {
+		test.Errorf("Expected CATCH-22 DONE DONT DON'T 7-ELEVEN but got %v", words)
+	}
+	if matches := trie.MatchPattern("DON?T"); strings.Join(matches, " ") != "DON'T" {
+		test.Errorf("Expected DON?T to match DON'T but got %v", matches)
+	}
+
+	if _, err := NewTrieAlphabet(2, func(symbol byte) int { return 0 }, func(index int) byte { return 'A' }); err == nil {
+		test.Errorf("Expected an error for an alphabet whose index and symbol disagree")
+	}
+	if _, err := trieAlphabetOf("AA"); err == nil {
+		test.Errorf("Expected an error for an alphabet with a repeated symbol")
+	}
+}
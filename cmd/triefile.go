@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tries can be saved in a compact binary form so big dictionaries don't have to be rebuilt on every run.
+// After a header line and a line listing the trie's alphabet, each node is written before its children as a uvarint whose low bit says whether
+// a word ends there and whose other bits say which letters have children, followed by the word's value
+// if there is one. A node's children follow it in alphabetical order.
+
+// compiledTriePrefix starts every saved trie, so dictionary readers can tell one from a word list
+var compiledTriePrefix = []byte("puzzle_helper trie ")
+
+// compiledTrieMagic also says which version of the format the trie was saved in
+var compiledTrieMagic = append(compiledTriePrefix, "3\n"...)
+
+// Save writes the trie to writer, using writeValue for the value of each word
+func (node *TrieNode[T]) Save(writer io.Writer, writeValue func(io.Writer, T) error) error {
+	buffered := bufio.NewWriter(writer)
+	if _, err := buffered.Write(compiledTrieMagic); err != nil {
+		return err
+	}
+	if _, err := buffered.WriteString(node.alphabet.symbols() + "\n"); err != nil {
+		return err
+	}
+	// a finalized trie's nodes don't hold their values, so they're gathered in the order the nodes are written
+	values := make([]T, 0)
+	node.Walk(func(word string, value T) bool {
+		values = append(values, value)
+		return true
+	})
+	position := 0
+	if err := node.saveNode(buffered, values, &position, writeValue); err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+// saveNode writes node and the nodes below it. values are every word's value in alphabetical order, and
+// position is how many words have been written so far
+func (node *TrieNode[T]) saveNode(writer *bufio.Writer, values []T, position *int, writeValue func(io.Writer, T) error) error {
+	var header uint64
+	if node.atWordBoundary {
+		header = 1
+	}
+	for index, child := range node.children[:len(node.children)-1] {
+		if child != nil {
+			header |= 1 << (index + 1)
+		}
+	}
+	if err := writeUvarint(writer, header); err != nil {
+		return err
+	}
+	if node.atWordBoundary {
+		if err := writeValue(writer, values[*position]); err != nil {
+			return err
+		}
+		*position++
+	}
+	for _, child := range node.Children() {
+		if err := child.saveNode(writer, values, position, writeValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load reads a trie written by Save into this one, which should be empty, using readValue for the value of each word.
+// The trie takes on the alphabet it was saved with
+func (node *TrieNode[T]) Load(reader io.Reader, readValue func(*bufio.Reader) (T, error)) error {
+	buffered := bufio.NewReader(reader)
+	magic := make([]byte, len(compiledTrieMagic))
+	if _, err := io.ReadFull(buffered, magic); err != nil || !bytes.HasPrefix(magic, compiledTriePrefix) {
+		return fmt.Errorf("this isn't a compiled trie")
+	}
+	if !bytes.Equal(magic, compiledTrieMagic) {
+		return fmt.Errorf("this trie was compiled by another version; compile it again")
+	}
+	symbols, err := buffered.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("the trie is cut short: %v", err)
+	}
+	alphabet, err := trieAlphabetOf(strings.TrimSuffix(symbols, "\n"))
+	if err != nil {
+		return fmt.Errorf("the trie has a bad alphabet: %v", err)
+	}
+	*node = *newTrieWithLetter[T]("", alphabet)
+	return node.loadNode(buffered, readValue)
+}
+
+func (node *TrieNode[T]) loadNode(reader *bufio.Reader, readValue func(*bufio.Reader) (T, error)) error {
+	header, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return fmt.Errorf("the trie is cut short: %v", err)
+	}
+	if header>>(len(node.children)) != 0 {
+		return fmt.Errorf("the trie has a node with letters past the end of its alphabet")
+	}
+	if header&1 == 1 {
+		node.atWordBoundary = true
+		if node.value, err = readValue(reader); err != nil {
+			return fmt.Errorf("the trie has an unreadable value: %v", err)
+		}
+	}
+	for index := 0; index < len(node.children)-1; index++ {
+		if header&(1<<(index+1)) == 0 {
+			continue
+		}
+		child := newTrieWithLetter[T](string(node.alphabet.symbol(index)), node.alphabet)
+		node.children[index] = child
+		if err := child.loadNode(reader, readValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeUvarint(writer io.Writer, value uint64) error {
+	buffer := make([]byte, binary.MaxVarintLen64)
+	_, err := writer.Write(buffer[:binary.PutUvarint(buffer, value)])
+	return err
+}
+
+// writeDictionaryWord and readDictionaryWord store the dictionaryWords readDictionaryToRankedTrie gives words,
+// as a uvarint of the rank with its low bit set if a frequency follows it as a float64. Words without a rank are stored as 0
+func writeDictionaryWord(writer io.Writer, value interface{}) error {
+	word, _ := value.(dictionaryWord)
+	header := uint64(word.rank) << 1
+	if word.frequency > 0 {
+		header |= 1
+	}
+	if err := writeUvarint(writer, header); err != nil {
+		return err
+	}
+	if word.frequency > 0 {
+		return binary.Write(writer, binary.LittleEndian, word.frequency)
+	}
+	return nil
+}
+
+func readDictionaryWord(reader *bufio.Reader) (interface{}, error) {
+	header, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, err
+	}
+	word := dictionaryWord{rank: int(header >> 1)}
+	if header&1 == 1 {
+		err = binary.Read(reader, binary.LittleEndian, &word.frequency)
+	}
+	return word, err
+}
+
+// isCompiledTrie reports whether reader starts with a trie written by Save, without reading past the check
+func isCompiledTrie(reader *bufio.Reader) bool {
+	start, _ := reader.Peek(len(compiledTriePrefix))
+	return bytes.Equal(start, compiledTriePrefix)
+}
+
+// readCompiledDictionary loads a dictionary compiled by dictionary compile, returning the trie and its word count
+func readCompiledDictionary(reader io.Reader) (*trieNode, int, error) {
+	now := time.Now().UnixNano()
+	trie := newTrie()
+	if err := trie.Load(reader, readDictionaryWord); err != nil {
+		return nil, 0, err
+	}
+	if profile {
+		fmt.Fprintf(os.Stderr, "Loading the compiled trie took: %.8fms\n", float64(time.Now().UnixNano()-now)/float64(1000000))
+	}
+	return trie, trie.Size(), nil
+}
+
+// loadDictionaryTrie reads the dictionaries at paths (or stdin for -) into a finalized trie ranking each word by
+// where it was in the files, along with the number of words. Words in more than one file keep their first rank.
+// A single compiled trie is loaded as it is instead of being rebuilt, unless it has to be filtered. A dictionary
+// that can't be read ends the program
+func loadDictionaryTrie(paths ...string) (*trieNode, int) {
+	trie, count, err := openDictionaryTrie(paths...)
+	exitOnDictionaryError(err)
+	return trie, count
+}
+
+// openDictionaryTrie is loadDictionaryTrie for callers that have to carry on, like the solvers behind the
+// servers: a dictionary that can't be read is returned as an error
+func openDictionaryTrie(paths ...string) (*trieNode, int, error) {
+	trie, count, err := readDictionaryTrie(paths...)
+	if err != nil {
+		return nil, 0, err
+	}
+	trie.Finalize()
+	return trie, count, nil
+}
+
+func readDictionaryTrie(paths ...string) (*trieNode, int, error) {
+	if len(paths) == 1 && paths[0] != "-" && !activeDictionaryFilter.active() {
+		path := paths[0]
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, 0, fmt.Errorf("could not access file: %v", err)
+		}
+		defer file.Close()
+		reader := bufio.NewReader(file)
+		if isCompiledTrie(reader) {
+			trie, count, err := readCompiledDictionary(reader)
+			if err != nil {
+				return nil, 0, fmt.Errorf("could not load %s: %v", path, err)
+			}
+			return trie, count, nil
+		}
+	}
+
+	entries := make(chan dictionaryEntry)
+	failed := make(chan error, 1)
+	go func() {
+		failed <- sendWeightedDictionaryPaths(entries, paths...)
+	}()
+	trie, count := readDictionaryToRankedTrie(entries)
+	if err := <-failed; err != nil {
+		return nil, 0, err
+	}
+	return trie, count, nil
+}
+
+// feedCompiledDictionary passes the words of a compiled trie to use in the order of their ranks
+func feedCompiledDictionary(use func(entry dictionaryEntry), reader io.Reader) error {
+	trie, _, err := readCompiledDictionary(reader)
+	if err != nil {
+		return err
+	}
+	words := make([]TrieWord[interface{}], 0)
+	trie.Walk(func(word string, value interface{}) bool {
+		words = append(words, trieWord{word, value})
+		return true
+	})
+	sort.SliceStable(words, func(i, j int) bool {
+		return dictionaryRank(words[i].value) < dictionaryRank(words[j].value)
+	})
+	for _, word := range words {
+		entry, _ := word.value.(dictionaryWord)
+		use(dictionaryEntry{word.word, entry.frequency})
+	}
+	return nil
+}
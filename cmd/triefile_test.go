@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTrieSaveLoad(test *testing.T) {
+	trie := NewTrie[int]()
+	words := map[string]int{"A": 1, "AT": 20, "ATE": 300, "BEE": 4000, "ZZZ": 5}
+	for word, value := range words {
+		trie.Add(word, value)
+	}
+
+	writeInt := func(writer io.Writer, value int) error { return writeUvarint(writer, uint64(value)) }
+	readInt := func(reader *bufio.Reader) (int, error) {
+		value, err := binary.ReadUvarint(reader)
+		return int(value), err
+	}
+	var saved bytes.Buffer
+	if err := trie.Save(&saved, writeInt); err != nil {
+		test.Fatalf("Unexpected error saving: %v", err)
+	}
+
+	loaded := NewTrie[int]()
+	if err := loaded.Load(bytes.NewReader(saved.Bytes()), readInt); err != nil {
+		test.Fatalf("Unexpected error loading: %v", err)
+	}
+	if loaded.Size() != len(words) {
+		test.Errorf("Expected %d words but got %d", len(words), loaded.Size())
+	}
+	for word, value := range words {
+		if actual, present := loaded.Get(word); !present || actual != value {
+			test.Errorf("Expected %s to load with %d but got %d, %v", word, value, actual, present)
+		}
+	}
+	if _, present := loaded.Get("BE"); present {
+		test.Errorf("Expected BE to stay a prefix rather than a word")
+	}
+	if loaded.Child('B').Letter() != "B" {
+		test.Errorf("Expected loaded nodes to know their letters")
+	}
+
+	// a finalized trie keeps its values on the root, and saves them all the same
+	loaded.Finalize()
+	var resaved bytes.Buffer
+	if err := loaded.Save(&resaved, writeInt); err != nil {
+		test.Fatalf("Unexpected error saving a finalized trie: %v", err)
+	}
+	if !bytes.Equal(resaved.Bytes(), saved.Bytes()) {
+		test.Errorf("Expected a finalized trie to save the same bytes as before it was finalized")
+	}
+
+	if err := NewTrie[int]().Load(strings.NewReader("HELLO\nWORLD\n"), readInt); err == nil {
+		test.Errorf("Expected an error loading a word list")
+	}
+	if err := NewTrie[int]().Load(bytes.NewReader(saved.Bytes()[:saved.Len()-2]), readInt); err == nil {
+		test.Errorf("Expected an error loading a cut-off trie")
+	}
+}
+
+func TestCompiledDictionary(test *testing.T) {
+	trie, _ := readDictionaryToRankedTrie(feedLines("THE", "OF", "AND", "OF", "ZEBRA"))
+	path := filepath.Join(test.TempDir(), "words.trie")
+	file, _ := os.Create(path)
+	if err := trie.Save(file, writeDictionaryWord); err != nil {
+		test.Fatal(err)
+	}
+	file.Close()
+
+	loaded, count := loadDictionaryTrie(path)
+	if value, _ := loaded.Get("AND"); count != 4 || dictionaryRank(value) != 3 {
+		test.Errorf("Expected 4 words with AND ranked 3 but got %d words and %v", count, value)
+	}
+
+	// word lists and compiled tries can be mixed, and the compiled words come out in rank order
+	entries := make(chan string)
+	compiled, _ := os.Open(path)
+	defer compiled.Close()
+	go feedDictionaryReaders(entries, bufio.NewReader(compiled), bufio.NewReader(strings.NewReader("cat\n")))
+	fed := make([]string, 0)
+	for entry := range entries {
+		fed = append(fed, entry)
+	}
+	if strings.Join(fed, " ") != "THE OF AND ZEBRA CAT" {
+		test.Errorf("Expected the compiled words by rank then the list but got %v", fed)
+	}
+}
+
+func feedLines(words ...string) chan dictionaryEntry {
+	feed := make(chan dictionaryEntry, len(words))
+	for _, word := range words {
+		feed <- splitDictionaryLine(word)
+	}
+	close(feed)
+	return feed
+}
+
+func TestTrieSaveLoadAlphabet(test *testing.T) {
+	alphabet, _ := trieAlphabetOf(upperAlphabet + "'-")
+	trie := NewTrieWithAlphabet[interface{}](alphabet)
+	trie.Add("DON'T", dictionaryWord{1, 0})
+	trie.Add("X-RAY", dictionaryWord{2, 0})
+
+	var saved bytes.Buffer
+	if err := trie.Save(&saved, writeDictionaryWord); err != nil {
+		test.Fatalf("Unexpected error saving: %v", err)
+	}
+	// the trie picks up the saved alphabet even though it starts out as A-Z
+	loaded := newTrie()
+	if err := loaded.Load(bytes.NewReader(saved.Bytes()), readDictionaryWord); err != nil {
+		test.Fatalf("Unexpected error loading: %v", err)
+	}
+	for _, word := range []string{"DON'T", "X-RAY"} {
+		if _, present := loaded.Get(word); !present {
+			test.Errorf("Expected %s to load", word)
+		}
+	}
+
+	older := bytes.Replace(saved.Bytes(), compiledTrieMagic, []byte("puzzle_helper trie 2\n"), 1)
+	if err := newTrie().Load(bytes.NewReader(older), readDictionaryWord); err == nil {
+		test.Errorf("Expected an error loading a trie saved in another version")
+	}
+}
+
+func TestLoadSeveralDictionaries(test *testing.T) {
+	directory := test.TempDir()
+	first, second := filepath.Join(directory, "first.txt"), filepath.Join(directory, "second.txt")
+	os.WriteFile(first, []byte("THE\nCAT\n"), 0644)
+	os.WriteFile(second, []byte("cat\nHAT\n"), 0644)
+
+	trie, count := loadDictionaryTrie(first, second)
+	if count != 3 {
+		test.Errorf("Expected CAT to be counted once for 3 words but got %d", count)
+	}
+	if value, _ := trie.Get("HAT"); dictionaryRank(value) != 3 {
+		test.Errorf("Expected HAT to be ranked 3 after the first file's words but got %v", value)
+	}
+}
+
+func TestOpenNoDictionary(test *testing.T) {
+	if _, _, err := openDictionaryTrie(); err == nil || !strings.Contains(err.Error(), "dictionary fetch enable") {
+		test.Errorf("Expected an error pointing to dictionary fetch without a dictionary but got %v", err)
+	}
+}
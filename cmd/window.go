@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A message made of two ciphers stuck together, or one that changes cipher partway through, has statistics
+// that jump where the change is. Sliding a window along the letters and comparing each window with the
+// next one that doesn't overlap it finds those jumps.
+
+// how far apart the index of coincidence of two windows has to be to count as a shift. It's about half
+// the distance from English to random
+const windowIocShift = 0.014
+
+// how many times larger one window's chi-squared has to be than the other's to count as a shift
+const windowChiSquaredShift = 3.0
+
+// windowStats are the statistics of the letters from start up to end
+type windowStats struct {
+	start            int
+	end              int
+	ioc              float64
+	chiSquared       float64
+	sortedChiSquared float64
+	// shift says what jumped between shiftFrom (an index into the windows) and this window, if anything did
+	shift     string
+	shiftFrom int
+}
+
+// slidingWindowStats computes the statistics of every window of size letters, starting every step letters.
+// The last window is moved back to end at the last letter so the end of the text is always covered
+func slidingWindowStats(letters []byte, size, step int) []windowStats {
+	windows := make([]windowStats, 0)
+	if size > len(letters) {
+		size = len(letters)
+	}
+	english := languageLetterFrequencies["English"]
+	sortedEnglish := sortedDescending(english)
+	start := 0
+	for ; start+size <= len(letters); start += step {
+		windows = append(windows, windowStatsFor(letters, start, start+size, english, sortedEnglish))
+	}
+	if start-step+size < len(letters) {
+		windows = append(windows, windowStatsFor(letters, len(letters)-size, len(letters), english, sortedEnglish))
+	}
+	return windows
+}
+
+func windowStatsFor(letters []byte, start, end int, english, sortedEnglish [26]float64) windowStats {
+	counts, total := letterCountsAZ(string(letters[start:end]))
+	return windowStats{
+		start:            start,
+		end:              end,
+		ioc:              indexOfCoincidence(letters[start:end]),
+		chiSquared:       chiSquared(counts, total, english),
+		sortedChiSquared: chiSquared(sortedDescending(counts), total, sortedEnglish),
+	}
+}
+
+// markWindowShifts compares each window with the first one starting where it ends and marks the later
+// window when the index of coincidence or the chi-squared against English jumps. Only the biggest jump in a
+// run of neighboring windows is marked, since overlapping windows all see the same change
+func markWindowShifts(windows []windowStats) {
+	type jump struct {
+		from  int
+		index int
+		size  float64
+		what  string
+	}
+	jumps := make([]jump, 0)
+	for index, window := range windows {
+		next := index + 1
+		for next < len(windows) && windows[next].start < window.end {
+			next++
+		}
+		if next == len(windows) {
+			break
+		}
+
+		later := windows[next]
+		reasons := make([]string, 0, 2)
+		size := 0.0
+		if difference := math.Abs(window.ioc - later.ioc); difference >= windowIocShift {
+			reasons = append(reasons, "ioc")
+			size += difference / windowIocShift
+		}
+		if ratio := chiSquaredRatio(window.chiSquared, later.chiSquared); ratio >= windowChiSquaredShift {
+			reasons = append(reasons, "letters")
+			size += ratio / windowChiSquaredShift
+		}
+		if len(reasons) > 0 {
+			jumps = append(jumps, jump{index, next, size, strings.Join(reasons, "+")})
+		}
+	}
+
+	for _, current := range jumps {
+		biggest := true
+		for _, other := range jumps {
+			// jumps whose windows overlap are the same shift
+			if other.index != current.index && windows[other.index].start < windows[current.index].end &&
+				windows[current.index].start < windows[other.index].end &&
+				(other.size > current.size || (other.size == current.size && other.index < current.index)) {
+				biggest = false
+				break
+			}
+		}
+		if biggest {
+			windows[current.index].shift = current.what
+			windows[current.index].shiftFrom = current.from
+		}
+	}
+}
+
+// chiSquaredRatio is how many times bigger the larger chi-squared is than the smaller one
+func chiSquaredRatio(first, second float64) float64 {
+	smaller, larger := math.Min(first, second), math.Max(first, second)
+	if smaller <= 0 {
+		if larger <= 0 {
+			return 1
+		}
+		return math.Inf(1)
+	}
+	return larger / smaller
+}
+
+// printWindowAnalysis prints the statistics of each window and calls out the places they shift
+func printWindowAnalysis(text string, size, step int) {
+	letters := []byte(frequencyLetters(text))
+	windows := slidingWindowStats(letters, size, step)
+	markWindowShifts(windows)
+
+	printer := newResultPrinter("start", "end", "ioc", "chi_squared", "sorted_chi_squared", "shift")
+	title := fmt.Sprintf("Sliding Window, %d Letters Every %d", size, step)
+	printer.text(title)
+	printer.text(strings.Repeat("-", len(title)))
+	printer.text("Total letters: %v", len(letters))
+	printer.text("Letters\tIoC\tChi-squared\tSorted chi-squared")
+	for _, window := range windows {
+		ioc := fmt.Sprintf("%.4f", window.ioc)
+		chi := fmt.Sprintf("%.1f", window.chiSquared)
+		sortedChi := fmt.Sprintf("%.1f", window.sortedChiSquared)
+		line := fmt.Sprintf("%d-%d\t%s\t%s\t%s", window.start+1, window.end, ioc, chi, sortedChi)
+		if window.shift != "" {
+			earlier := windows[window.shiftFrom]
+			line += fmt.Sprintf("\t<- shift (%s) from %d-%d", window.shift, earlier.start+1, earlier.end)
+		}
+		printer.result(line, strconv.Itoa(window.start+1), strconv.Itoa(window.end), ioc, chi, sortedChi, window.shift)
+	}
+	printer.finish(false)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import "testing"
+
+func TestSlidingWindowStats(test *testing.T) {
+	letters := []byte("ABCDEFGHIJ")
+	tests := []struct {
+		size     int
+		step     int
+		expected [][2]int
+	}{
+		{4, 2, [][2]int{{0, 4}, {2, 6}, {4, 8}, {6, 10}}},
+		// the last window is moved back to cover the end
+		{4, 3, [][2]int{{0, 4}, {3, 7}, {6, 10}}},
+		{6, 5, [][2]int{{0, 6}, {4, 10}}},
+		{20, 5, [][2]int{{0, 10}}},
+	}
+	for _, testCase := range tests {
+		windows := slidingWindowStats(letters, testCase.size, testCase.step)
+		if len(windows) != len(testCase.expected) {
+			test.Errorf("Expected %d windows of %d every %d but got %v", len(testCase.expected), testCase.size, testCase.step, windows)
+			continue
+		}
+		for index, window := range windows {
+			if window.start != testCase.expected[index][0] || window.end != testCase.expected[index][1] {
+				test.Errorf("Expected window %d of %d every %d to be %v but got %d-%d", index, testCase.size, testCase.step, testCase.expected[index], window.start, window.end)
+			}
+		}
+	}
+}
+
+func TestSlidingWindowStatsFoldLowercase(test *testing.T) {
+	lower := slidingWindowStats([]byte(frequencyLetters("the quick brown fox jumps")), 8, 4)
+	upper := slidingWindowStats([]byte(frequencyLetters("THEQUICKBROWNFOXJUMPS")), 8, 4)
+	if len(lower) != len(upper) {
+		test.Fatalf("Expected %d windows for lowercase input but got %d", len(upper), len(lower))
+	}
+	for index, window := range lower {
+		if window != upper[index] {
+			test.Errorf("Expected lowercase window %d to be %v but got %v", index, upper[index], window)
+		}
+	}
+}
+
+func TestMarkWindowShifts(test *testing.T) {
+	plain := justUppercaseLetters("It was the best of times, it was the worst of times, it was the age of wisdom, it was the age of foolishness, " +
+		"it was the epoch of belief, it was the epoch of incredulity, it was the season of light, it was the season of darkness")
+	// the same text again, enciphered with a Vigenere key, which flattens its letter frequencies
+	key := []byte("LEMONADE")
+	cipher := make([]byte, len(plain))
+	for index, letter := range plain {
+		cipher[index] = (letter-ASCII_A+key[index%len(key)]-ASCII_A)%26 + ASCII_A
+	}
+
+	windows := slidingWindowStats(append(append([]byte{}, plain...), cipher...), 100, 25)
+	markWindowShifts(windows)
+	shifts := make([]windowStats, 0)
+	for _, window := range windows {
+		if window.shift != "" {
+			shifts = append(shifts, window)
+		}
+	}
+	if len(shifts) != 1 {
+		test.Fatalf("Expected one shift but got %v", shifts)
+	}
+	earlier := windows[shifts[0].shiftFrom]
+	// the windows move 25 letters at a time, so that's as close as they can find it
+	if distance := shifts[0].start - len(plain); distance < -25 || distance > 25 {
+		test.Errorf("Expected the shift near letter %d but got %d-%d to %d-%d", len(plain), earlier.start, earlier.end, shifts[0].start, shifts[0].end)
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Dictionary files can give a word's frequency after a tab, as in THE<TAB>23135851162, so that solvers can put
+// answers made of common words ahead of obscure ones. Frequencies are usually counts from a corpus, but any
+// positive score works; they're stored as each word's share of the total. Lines without one are plain words,
+// and words in a list that has no frequencies at all are ranked by where they are in the file, as before.
+
+// dictionaryEntry is a word read from a dictionary, with its frequency if the line had one and 0 otherwise
+type dictionaryEntry struct {
+	word      string
+	frequency float64
+}
+
+// dictionaryWord is the trie value readDictionaryToRankedTrie gives each word: its 1-based rank, most common
+// first, and its share of the dictionary's frequencies if it had them
+type dictionaryWord struct {
+	rank      int
+	frequency float64
+}
+
+// unknownLogFrequency is what a word without a frequency counts for, which is rarer than any real word
+const unknownLogFrequency = -20.0
+
+// splitDictionaryLine separates a dictionary line into its word and frequency. A frequency that isn't
+// a positive number is ignored
+func splitDictionaryLine(line string) dictionaryEntry {
+	word, frequencyText, hasFrequency := strings.Cut(line, "\t")
+	if !hasFrequency {
+		return dictionaryEntry{word, 0}
+	}
+	frequency, err := strconv.ParseFloat(strings.TrimSpace(frequencyText), 64)
+	if err != nil || frequency <= 0 || math.IsInf(frequency, 0) {
+		frequency = 0
+	}
+	return dictionaryEntry{word, frequency}
+}
+
+// dictionaryRank is the rank a dictionary trie stored for a word, or 0 if it didn't store one
+func dictionaryRank(value interface{}) int {
+	word, _ := value.(dictionaryWord)
+	return word.rank
+}
+
+// rankByFrequency reassigns the ranks of a trie read from a dictionary with frequencies, so that the most
+// frequent word is rank 1, and turns the frequencies into shares of their total. Words without a frequency
+// get half the smallest one and keep their order after the others
+func rankByFrequency(trie *trieNode) {
+	words := make([]trieWord, 0)
+	total, smallest := 0.0, math.Inf(1)
+	trie.Walk(func(word string, value interface{}) bool {
+		words = append(words, trieWord{word, value})
+		if frequency := value.(dictionaryWord).frequency; frequency > 0 {
+			total += frequency
+			smallest = math.Min(smallest, frequency)
+		}
+		return true
+	})
+	sort.SliceStable(words, func(i, j int) bool {
+		first, second := words[i].value.(dictionaryWord), words[j].value.(dictionaryWord)
+		if first.frequency != second.frequency {
+			return first.frequency > second.frequency
+		}
+		return first.rank < second.rank
+	})
+	missing := smallest / 2
+	for _, word := range words {
+		if word.value.(dictionaryWord).frequency == 0 {
+			total += missing
+		}
+	}
+	for rank, word := range words {
+		frequency := word.value.(dictionaryWord).frequency
+		if frequency == 0 {
+			frequency = missing
+		}
+		trie.Add(word.word, dictionaryWord{rank + 1, frequency / total})
+	}
+}
+
+// hasWordFrequencies reports whether any word in the dictionary trie has a frequency
+func hasWordFrequencies(trie *trieNode) bool {
+	found := false
+	trie.Walk(func(word string, value interface{}) bool {
+		entry, _ := value.(dictionaryWord)
+		found = entry.frequency > 0
+		return !found
+	})
+	return found
+}
+
+// combinedFrequency scores words by how likely they are to appear together, as the sum of the logs of their
+// shares of the dictionary's frequencies. Higher scores are more common
+func combinedFrequency(trie *trieNode, words []string) float64 {
+	score := 0.0
+	for _, word := range words {
+		value, _ := trie.Get(word)
+		score += logFrequency(value)
+	}
+	return score
+}
+
+// logFrequency is the log of the frequency a dictionary trie stored for a word, or unknownLogFrequency
+func logFrequency(value interface{}) float64 {
+	if entry, _ := value.(dictionaryWord); entry.frequency > 0 {
+		return math.Log10(entry.frequency)
+	}
+	return unknownLogFrequency
+}
+
+// sortByFrequency orders word sets from the most to the least common, keeping the order of ties
+func sortByFrequency(trie *trieNode, wordSets [][]string) {
+	scores := make(map[string]float64, len(wordSets))
+	for _, words := range wordSets {
+		scores[strings.Join(words, " ")] = combinedFrequency(trie, words)
+	}
+	sort.SliceStable(wordSets, func(i, j int) bool {
+		return scores[strings.Join(wordSets[i], " ")] > scores[strings.Join(wordSets[j], " ")]
+	})
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSplitDictionaryLine(test *testing.T) {
+	tests := []struct {
+		line     string
+		expected dictionaryEntry
+	}{
+		{"THE", dictionaryEntry{"THE", 0}},
+		{"THE\t23135851162", dictionaryEntry{"THE", 23135851162}},
+		{"of\t 0.5 ", dictionaryEntry{"of", 0.5}},
+		{"CAT\tlots", dictionaryEntry{"CAT", 0}},
+		{"CAT\t-3", dictionaryEntry{"CAT", 0}},
+	}
+	for _, testCase := range tests {
+		if actual := splitDictionaryLine(testCase.line); actual != testCase.expected {
+			test.Errorf("Expected %q to split into %v but got %v", testCase.line, testCase.expected, actual)
+		}
+	}
+}
+
+func TestWeightedDictionary(test *testing.T) {
+	trie, count := rankedTestTrie("ZEBRA\t5\nTHE\t1000\nCAT\nOF\t700\nzebra\t10")
+	if count != 4 {
+		test.Errorf("Expected 4 words but got %d", count)
+	}
+	expectedRanks := map[string]int{"THE": 1, "OF": 2, "ZEBRA": 3, "CAT": 4}
+	for word, rank := range expectedRanks {
+		if value, _ := trie.Get(word); dictionaryRank(value) != rank {
+			test.Errorf("Expected %s to be ranked %d by frequency but got %v", word, rank, value)
+		}
+	}
+	// the second ZEBRA's higher frequency wins, and CAT gets half of it. The frequencies add up to 1715
+	if value, _ := trie.Get("ZEBRA"); value.(dictionaryWord).frequency != 10.0/1715 {
+		test.Errorf("Expected ZEBRA to have a frequency of 10/1715 but got %v", value)
+	}
+	if value, _ := trie.Get("CAT"); value.(dictionaryWord).frequency != 5.0/1715 {
+		test.Errorf("Expected CAT to have a frequency of 5/1715 but got %v", value)
+	}
+	if !hasWordFrequencies(trie) {
+		test.Errorf("Expected the trie to have frequencies")
+	}
+	if plain, _ := rankedTestTrie("THE\nOF"); hasWordFrequencies(plain) {
+		test.Errorf("Expected a plain word list not to have frequencies")
+	}
+
+	wordSets := [][]string{{"ZEBRA", "CAT"}, {"OF", "THE"}, {"CAT"}, {"ZEBRA", "OF"}}
+	sortByFrequency(trie, wordSets)
+	joined := make([]string, 0, len(wordSets))
+	for _, words := range wordSets {
+		joined = append(joined, strings.Join(words, " "))
+	}
+	if strings.Join(joined, ",") != "OF THE,CAT,ZEBRA OF,ZEBRA CAT" {
+		test.Errorf("Expected OF THE,CAT,ZEBRA OF,ZEBRA CAT but got %v", joined)
+	}
+
+	// frequencies survive compiling
+	var saved bytes.Buffer
+	if err := trie.Save(&saved, writeDictionaryWord); err != nil {
+		test.Fatalf("Unexpected error saving: %v", err)
+	}
+	loaded := newTrie()
+	if err := loaded.Load(bytes.NewReader(saved.Bytes()), readDictionaryWord); err != nil {
+		test.Fatalf("Unexpected error loading: %v", err)
+	}
+	if value, _ := loaded.Get("OF"); value != (dictionaryWord{2, 700.0 / 1715}) {
+		test.Errorf("Expected OF to load with rank 2 and frequency 700/1715 but got %v", value)
+	}
+}
+
+func TestFrequencySortedResults(test *testing.T) {
+	trie, _ := rankedTestTrie("BEATS\t10\nBEAST\t500\nBASSET\t20\nCOT\t3\nCAT\t90\nCUT\t40")
+	if matches := patternMatches(trie, "C?T", true); strings.Join(matches, " ") != "CAT CUT COT" {
+		test.Errorf("Expected CAT CUT COT by frequency but got %v", matches)
+	}
+	if matches := patternMatches(trie, "C?T", false); strings.Join(matches, " ") != "CAT COT CUT" {
+		test.Errorf("Expected CAT COT CUT alphabetically but got %v", matches)
+	}
+
+	request := letterBankRequest{"beast", 1, 0, 0, 100, "common", nil, false}
+	solutions, _ := performLetterBankSolve(context.Background(), trie, request)
+	if actual := strings.Join(joinLetterBankSolutions(solutions), ","); actual != "BEAST,BASSET,BEATS" {
+		test.Errorf("Expected BEAST,BASSET,BEATS by frequency but got %s", actual)
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var wordleLength int
+var wordleGreens []string
+var wordleYellows []string
+var wordleGrays string
+
+// wordleCmd represents the wordle command
+var wordleCmd = &cobra.Command{
+	Use:   "wordle",
+	Short: "Lists the Wordle answers still possible after your guesses, best guess first",
+	Long: `Narrows the dictionary's words of --length letters (5 by default) down to those that fit what the
+	guesses so far have shown. --green POSITION=LETTER is a letter in the right square, counting from 1.
+	--yellow LETTER is a letter that's in the word somewhere, and --yellow POSITION=LETTER says it isn't in
+	that square. --gray gives the letters that aren't in the word at all. Since a guess with a letter twice
+	can color one copy gray, a gray letter that's also yellow is left alone, and one that's also green only
+	rules out the other squares. Each flag can be repeated or take several values separated by commas.
+
+	The candidates are ranked by how much guessing them would tell you: a word scores well when its
+	letters are each in about half of the remaining candidates, so whatever colors come back, a lot of
+	them are ruled out.
+
+	Example:
+	  puzzle_helper wordle --green 2=A --yellow R --yellow 4=E --gray STLN --dictionary words.txt`,
+	Args: cobra.NoArgs,
+	Run:  printWordleCandidates,
+}
+
+// wordleConstraints is what the guesses have shown: letters known to be in a square, letters in the word
+// along with the squares they aren't in, and letters that aren't in the word. Squares count from 0
+type wordleConstraints struct {
+	length  int
+	greens  map[int]byte
+	yellows map[byte][]int
+	grays   map[byte]bool
+}
+
+// wordleCandidate is a possible answer and how much guessing it would narrow things down
+type wordleCandidate struct {
+	word  string
+	score float64
+}
+
+func printWordleCandidates(cmd *cobra.Command, args []string) {
+	constraints, err := parseWordleConstraints(wordleLength, wordleGreens, wordleYellows, wordleGrays)
+	if err != nil {
+		fmt.Printf("Invalid constraints: %v\n", err)
+		os.Exit(1)
+	}
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+
+	printer := newResultPrinter("word", "score")
+	for _, candidate := range rankWordleCandidates(wordleCandidates(rootTrie, constraints)) {
+		score := strconv.FormatFloat(candidate.score, 'f', 3, 64)
+		printer.result(fmt.Sprintf("%s\t%s", candidate.word, score), candidate.word, score)
+	}
+	printer.finish(false)
+}
+
+// parseWordleConstraints reads the --green, --yellow, and --gray flags for words of length letters
+func parseWordleConstraints(length int, greens []string, yellows []string, grays string) (wordleConstraints, error) {
+	if length < 1 {
+		return wordleConstraints{}, fmt.Errorf("the length should be at least 1 but got %d", length)
+	}
+	constraints := wordleConstraints{length, make(map[int]byte), make(map[byte][]int), make(map[byte]bool)}
+	for _, green := range greens {
+		square, letter, err := parseWordleSquare(green, length)
+		if err != nil || square < 0 {
+			return wordleConstraints{}, fmt.Errorf("a green letter should be POSITION=LETTER, such as 2=A, but got %s", green)
+		}
+		if known, present := constraints.greens[square]; present && known != letter {
+			return wordleConstraints{}, fmt.Errorf("square %d can't be both %c and %c", square+1, known, letter)
+		}
+		constraints.greens[square] = letter
+	}
+	for _, yellow := range yellows {
+		square, letter, err := parseWordleSquare(yellow, length)
+		if err != nil {
+			return wordleConstraints{}, fmt.Errorf("a yellow letter should be LETTER or POSITION=LETTER, such as R or 3=R, but got %s", yellow)
+		}
+		if _, present := constraints.yellows[letter]; !present {
+			constraints.yellows[letter] = make([]int, 0)
+		}
+		if square >= 0 {
+			constraints.yellows[letter] = append(constraints.yellows[letter], square)
+		}
+	}
+	for _, letter := range justUppercaseLetters(grays) {
+		constraints.grays[letter] = true
+	}
+	return constraints, nil
+}
+
+// parseWordleSquare reads POSITION=LETTER, or just LETTER, in which case the square is -1
+func parseWordleSquare(value string, length int) (int, byte, error) {
+	positionText, letterText, hasPosition := strings.Cut(strings.ToUpper(strings.TrimSpace(value)), "=")
+	if !hasPosition {
+		letterText = positionText
+	}
+	if len(letterText) != 1 || !isUppercaseAscii(letterText[0]) {
+		return 0, 0, fmt.Errorf("%s doesn't give a letter", value)
+	}
+	if !hasPosition {
+		return -1, letterText[0], nil
+	}
+	position, err := strconv.Atoi(positionText)
+	if err != nil || position < 1 || position > length {
+		return 0, 0, fmt.Errorf("%s doesn't give a square from 1 to %d", value, length)
+	}
+	return position - 1, letterText[0], nil
+}
+
+// allows reports whether word could be the answer
+func (constraints wordleConstraints) allows(word string) bool {
+	if len(word) != constraints.length {
+		return false
+	}
+	for square, letter := range constraints.greens {
+		if word[square] != letter {
+			return false
+		}
+	}
+	for letter, notSquares := range constraints.yellows {
+		if strings.IndexByte(word, letter) < 0 {
+			return false
+		}
+		for _, square := range notSquares {
+			if word[square] == letter {
+				return false
+			}
+		}
+	}
+	for square := 0; square < len(word); square++ {
+		letter := word[square]
+		if !constraints.grays[letter] || constraints.greens[square] == letter {
+			continue
+		}
+		// a letter known to be in the word somewhere was gray in another square, so it can stay
+		if _, yellow := constraints.yellows[letter]; !yellow {
+			return false
+		}
+	}
+	return true
+}
+
+// wordleCandidates is the words in rootTrie that the constraints allow, in alphabetical order
+func wordleCandidates(rootTrie *trieNode, constraints wordleConstraints) []string {
+	pattern := []byte(strings.Repeat("?", constraints.length))
+	for square, letter := range constraints.greens {
+		pattern[square] = letter
+	}
+	candidates := make([]string, 0)
+	for _, word := range rootTrie.MatchPattern(string(pattern)) {
+		if constraints.allows(word) {
+			candidates = append(candidates, word)
+		}
+	}
+	return candidates
+}
+
+// rankWordleCandidates scores each candidate by the information its letters would give as a guess: for each
+// different letter, the binary entropy of the share of candidates that have it. Letters every candidate has,
+// or none do, tell you nothing. The best guesses come first, with ties left alphabetical
+func rankWordleCandidates(candidates []string) []wordleCandidate {
+	containing := make(map[byte]int)
+	for _, word := range candidates {
+		for _, letter := range distinctLetters(word) {
+			containing[letter]++
+		}
+	}
+
+	ranked := make([]wordleCandidate, 0, len(candidates))
+	for _, word := range candidates {
+		score := 0.0
+		for _, letter := range distinctLetters(word) {
+			share := float64(containing[letter]) / float64(len(candidates))
+			if share > 0 && share < 1 {
+				score -= share*math.Log2(share) + (1-share)*math.Log2(1-share)
+			}
+		}
+		ranked = append(ranked, wordleCandidate{word, score})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+	return ranked
+}
+
+// distinctLetters is the letters in word, each once, in the order they first appear
+func distinctLetters(word string) []byte {
+	seen := make(map[byte]bool)
+	letters := make([]byte, 0, len(word))
+	for _, letter := range []byte(word) {
+		if !seen[letter] {
+			seen[letter] = true
+			letters = append(letters, letter)
+		}
+	}
+	return letters
+}
+
+func init() {
+	wordleCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+	wordleCmd.MarkFlagRequired("dictionary")
+	wordleCmd.Flags().IntVarP(&wordleLength, "length", "", 5, "How many letters the answer has")
+	wordleCmd.Flags().StringSliceVarP(&wordleGreens, "green", "", nil, "A letter in the right square, as POSITION=LETTER counting from 1")
+	wordleCmd.Flags().StringSliceVarP(&wordleYellows, "yellow", "", nil, "A letter in the word, as LETTER, or as POSITION=LETTER for a square it isn't in")
+	wordleCmd.Flags().StringVarP(&wordleGrays, "gray", "", "", "The letters that aren't in the word")
+	rootCmd.AddCommand(wordleCmd)
+}
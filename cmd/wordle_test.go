@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWordleCandidates(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"CRANE", "BRAVE", "GRAPE", "RAVEN", "EARLY", "HEART", "ERASE", "CRAB"} {
+		trie.Add(word, nil)
+	}
+
+	testCases := []struct {
+		greens   []string
+		yellows  []string
+		grays    string
+		expected string
+	}{
+		{nil, nil, "", "BRAVE,CRANE,EARLY,ERASE,GRAPE,HEART,RAVEN"},
+		{[]string{"2=R", "3=A"}, nil, "", "BRAVE,CRANE,ERASE,GRAPE"},
+		{[]string{"2=R", "3=A"}, nil, "CG", "BRAVE,ERASE"},
+		{nil, []string{"R", "1=E"}, "", "BRAVE,CRANE,GRAPE,HEART,RAVEN"},
+		{nil, []string{"h"}, "", "HEART"},
+		// the second E of ERASE is gray, but the first is green
+		{[]string{"1=E"}, nil, "E", "EARLY"},
+		// one E was yellow and another gray, so E stays possible
+		{nil, []string{"5=E"}, "E", "EARLY,HEART,RAVEN"},
+	}
+
+	for _, testCase := range testCases {
+		constraints, err := parseWordleConstraints(5, testCase.greens, testCase.yellows, testCase.grays)
+		if err != nil {
+			test.Errorf("Unexpected error for %v %v %s: %v", testCase.greens, testCase.yellows, testCase.grays, err)
+			continue
+		}
+		if actual := strings.Join(wordleCandidates(trie, constraints), ","); actual != testCase.expected {
+			test.Errorf("Expected %s for greens %v, yellows %v, and grays %s but got %s", testCase.expected, testCase.greens, testCase.yellows, testCase.grays, actual)
+		}
+	}
+
+	for _, bad := range [][]string{{"6=A"}, {"0=A"}, {"A"}, {"2=AB"}, {"2=A", "2=B"}} {
+		if _, err := parseWordleConstraints(5, bad, nil, ""); err == nil {
+			test.Errorf("Expected an error for the greens %v", bad)
+		}
+	}
+	if _, err := parseWordleConstraints(5, nil, []string{"9"}, ""); err == nil {
+		test.Errorf("Expected an error for a yellow that isn't a letter")
+	}
+}
+
+func TestRankWordleCandidates(test *testing.T) {
+	// every word has A, so it tells nothing, while B, C, and D are each in half the words
+	ranked := rankWordleCandidates([]string{"AAAA", "ABCD", "ABAA", "ACDA"})
+	if ranked[0].word != "ABCD" || ranked[len(ranked)-1].word != "AAAA" || ranked[len(ranked)-1].score != 0 {
+		test.Errorf("Expected ABCD first and AAAA last with no score but got %v", ranked)
+	}
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// wordListSource is a well-known word list that dictionary fetch can download. read calls use with
+// each raw entry in the download, which is normalized afterward
+type wordListSource struct {
+	name string
+	url  string
+	read func(body io.Reader, use func(entry string)) error
+}
+
+// only lists with a stable, well-known home belong here; a copy in someone's repository can change or go away
+var wordListSources = map[string]wordListSource{
+	"enable":  {"enable", "https://norvig.com/ngrams/enable1.txt", readWordListLines},
+	"wordnet": {"wordnet", "https://wordnetcode.princeton.edu/wn3.1.dict.tar.gz", readWordNetIndexes},
+}
+
+// unfetchableWordLists are lists dictionary fetch knows by name but can't download, with the reason why
+var unfetchableWordLists = map[string]string{
+	"sowpods": "SOWPODS is Collins Scrabble Words, which Collins licenses rather than releasing for free " +
+		"redistribution, so there's no download it can be fetched from. Get a copy you're licensed to use and pass it to --dictionary",
+}
+
+// wordListNames is the names of the lists dictionary fetch knows, sorted
+func wordListNames() []string {
+	names := make([]string, 0, len(wordListSources)+len(unfetchableWordLists))
+	for name := range wordListSources {
+		names = append(names, name)
+	}
+	for name := range unfetchableWordLists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// findWordList is the source dictionary fetch downloads name from, or an error saying why it can't
+func findWordList(name string) (wordListSource, error) {
+	if reason, unfetchable := unfetchableWordLists[name]; unfetchable {
+		return wordListSource{}, fmt.Errorf("%s", reason)
+	}
+	source, known := wordListSources[name]
+	if !known {
+		return wordListSource{}, fmt.Errorf("there's no word list named %s; try %s", name, strings.Join(wordListNames(), ", "))
+	}
+	return source, nil
+}
+
+// fetchWordList downloads source with client and writes it to writer as uppercase words, one per line,
+// in the order they came. Entries with anything besides letters are dropped, as are repeats. It returns
+// the number of words written
+func fetchWordList(client *http.Client, source wordListSource, writer io.Writer) (int, error) {
+	response, err := client.Get(source.url)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%s returned %s", source.url, response.Status)
+	}
+
+	buffered := bufio.NewWriter(writer)
+	seen := make(map[string]bool)
+	var writeErr error
+	err = source.read(response.Body, func(entry string) {
+		word := strings.ToUpper(strings.TrimSpace(entry))
+		if writeErr != nil || !lettersRegex.MatchString(word) || seen[word] {
+			return
+		}
+		seen[word] = true
+		_, writeErr = buffered.WriteString(word + "\n")
+	})
+	if err != nil {
+		return 0, fmt.Errorf("could not read %s: %v", source.url, err)
+	}
+	if writeErr != nil {
+		return 0, writeErr
+	}
+	return len(seen), buffered.Flush()
+}
+
+// readWordListLines reads a list with one entry per line
+func readWordListLines(body io.Reader, use func(entry string)) error {
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		use(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// wordNetIndexes are the files of the WordNet database that list its lemmas
+var wordNetIndexes = map[string]bool{"index.noun": true, "index.verb": true, "index.adj": true, "index.adv": true}
+
+// readWordNetIndexes reads the lemmas out of the index files in a gzipped WordNet database. Lines
+// starting with a space are the license, and multiword lemmas like ice_cream are skipped
+func readWordNetIndexes(body io.Reader, use func(entry string)) error {
+	unzipped, err := gzip.NewReader(body)
+	if err != nil {
+		return err
+	}
+	archive := tar.NewReader(unzipped)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if segments := strings.Split(header.Name, "/"); !wordNetIndexes[segments[len(segments)-1]] {
+			continue
+		}
+		scanner := bufio.NewScanner(archive)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.HasPrefix(line, " ") {
+				continue
+			}
+			if lemma, _, _ := strings.Cut(line, " "); !strings.Contains(lemma, "_") {
+				use(lemma)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+}
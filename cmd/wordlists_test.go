@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchWordList(test *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/words.txt" {
+			http.NotFound(writer, request)
+			return
+		}
+		writer.Write([]byte("aardvark\r\nabacus\nAbacus\nmid-air\n\nzebra\n"))
+	}))
+	defer server.Close()
+
+	var saved bytes.Buffer
+	count, err := fetchWordList(server.Client(), wordListSource{"test", server.URL + "/words.txt", readWordListLines}, &saved)
+	if err != nil {
+		test.Fatalf("Unexpected error fetching: %v", err)
+	}
+	if count != 3 || saved.String() != "AARDVARK\nABACUS\nZEBRA\n" {
+		test.Errorf("Expected 3 words AARDVARK ABACUS ZEBRA but got %d: %q", count, saved.String())
+	}
+
+	if _, err := fetchWordList(server.Client(), wordListSource{"test", server.URL + "/missing.txt", readWordListLines}, &saved); err == nil {
+		test.Errorf("Expected an error for a list that isn't there")
+	}
+}
+
+func TestReadWordNetIndexes(test *testing.T) {
+	var archived bytes.Buffer
+	zipped := gzip.NewWriter(&archived)
+	archive := tar.NewWriter(zipped)
+	files := map[string]string{
+		"dict/index.noun": "  1 This software and database is being provided\ncat n 1 1 @ 1 0 02121620\nice_cream n 1 1 @ 1 0 07611358\n",
+		"dict/index.verb": "run v 1 1 @ 1 0 01926311\n",
+		"dict/data.noun":  "02121620 05 n 01 cat 0 001 @ 02120997 n 0000 | feline mammal\n",
+	}
+	for name, contents := range files {
+		archive.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(contents))})
+		archive.Write([]byte(contents))
+	}
+	archive.Close()
+	zipped.Close()
+
+	lemmas := make([]string, 0)
+	if err := readWordNetIndexes(&archived, func(entry string) { lemmas = append(lemmas, entry) }); err != nil {
+		test.Fatalf("Unexpected error reading the indexes: %v", err)
+	}
+	if joined := strings.Join(lemmas, " "); joined != "cat run" && joined != "run cat" {
+		test.Errorf("Expected the lemmas cat and run but got %v", lemmas)
+	}
+}
+
+func TestFindWordList(test *testing.T) {
+	if source, err := findWordList("enable"); err != nil || source.name != "enable" {
+		test.Errorf("Expected the enable list but got %v (%v)", source, err)
+	}
+	if _, err := findWordList("sowpods"); err == nil || !strings.Contains(err.Error(), "Collins licenses") {
+		test.Errorf("Expected sowpods to fail with the licensing reason but got %v", err)
+	}
+	if _, err := findWordList("nothing"); err == nil {
+		test.Errorf("Expected an error for an unknown list")
+	}
+	if names := strings.Join(wordListNames(), " "); names != "enable sowpods wordnet" {
+		test.Errorf("Expected enable, sowpods and wordnet but got %s", names)
+	}
+}
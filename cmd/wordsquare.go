@@ -0,0 +1,215 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var wordSquareDouble bool
+var wordSquareMaxResults int
+
+// wordSquareCmd represents the wordsquare command
+var wordSquareCmd = &cobra.Command{
+	Use:   "wordsquare SIZE|WORD",
+	Short: "Finds word squares",
+	Long: `Finds squares of letters where every row and every column is a word of the dictionary. Give the size
+	of the square, or a word to start the first row with. In a plain word square each column is the same word as
+	its row, as in
+
+	  HEART
+	  EMBER
+	  ABUSE
+	  RESIN
+	  TREND
+
+	and with --double the columns are different words from the rows, and no word is used twice. A double square
+	also works turned on its side, so each is only listed once, unless it starts with the given word. Squares are printed as they're found;
+	--max-results stops after that many, and --timeout also stops the search.
+
+	Example:
+	  puzzle_helper wordsquare 4 --dictionary words.txt
+	  puzzle_helper wordsquare HEART --double`,
+	Args: cobra.ExactArgs(1),
+	Run:  printWordSquares,
+}
+
+func printWordSquares(cmd *cobra.Command, args []string) {
+	size, first := wordSquareStart(args[0])
+	if size < 2 {
+		fmt.Printf("A word square needs a size of at least 2 or a word to start with, but got %s\n", args[0])
+		os.Exit(1)
+	}
+	rootTrie, _ := loadDictionaryTrie(dictionaryFiles...)
+
+	ctx, cancel := solveContext()
+	defer cancel()
+
+	printer := newResultPrinter("rows")
+	count := 0
+	findWordSquares(ctx, rootTrie, size, first, wordSquareDouble, func(rows []string) bool {
+		printer.result(strings.Join(rows, "\n")+"\n", strings.Join(rows, " "))
+		count++
+		return wordSquareMaxResults <= 0 || count < wordSquareMaxResults
+	})
+	printer.finish(ctx.Err() != nil)
+}
+
+// wordSquareStart reads the command's argument as either the size of a square or the word at the top of it
+func wordSquareStart(arg string) (int, string) {
+	if size, err := strconv.Atoi(arg); err == nil {
+		return size, ""
+	}
+	first := string(justUppercaseLetters(arg))
+	return len(first), first
+}
+
+// findWordSquares searches for size by size squares whose rows and columns are all words, starting with first
+// as the top row if it isn't empty, and calls found with the rows of each one until it returns false or ctx is
+// done. Plain squares read the same across and down; double squares have 2 * size different words, and without
+// a first row only the one of each square and its transpose whose top row comes before its first column is found
+func findWordSquares(ctx context.Context, rootTrie *trieNode, size int, first string, double bool, found func(rows []string) bool) {
+	// only words of the right length matter, so a trie of just those prunes the search much sooner
+	sized := newTrie()
+	rootTrie.Walk(func(word string, value interface{}) bool {
+		if len(word) == size {
+			sized.Add(word, nil)
+		}
+		return true
+	})
+
+	search := wordSquareSearch{ctx: ctx, size: size, double: double, transposes: first != "", found: found}
+	search.grid = make([][]byte, size)
+	for row := range search.grid {
+		search.grid[row] = make([]byte, size)
+	}
+	search.columns = make([]*trieNode, size)
+	for column := range search.columns {
+		search.columns[column] = sized
+	}
+	search.root = sized
+
+	if first == "" {
+		search.fill(0, 0, sized)
+		return
+	}
+	if _, present := sized.Get(first); !present {
+		return
+	}
+	for column := 0; column < size; column++ {
+		search.grid[0][column] = first[column]
+		if search.columns[column] = sized.Child(first[column]); search.columns[column] == nil {
+			return
+		}
+	}
+	search.fill(1, 0, sized)
+}
+
+// wordSquareSearch is the state of findWordSquares as it fills the grid a row at a time, left to right. columns
+// holds the trie node reached by the letters so far in each column
+type wordSquareSearch struct {
+	ctx        context.Context
+	size       int
+	double     bool
+	transposes bool
+	found      func(rows []string) bool
+	root       *trieNode
+	grid       [][]byte
+	columns    []*trieNode
+	stopped    bool
+}
+
+// fill tries each letter that can go at row and column, given rowNode, the trie node for the row's letters so far
+func (search *wordSquareSearch) fill(row int, column int, rowNode *trieNode) {
+	if search.stopped {
+		return
+	}
+	if row == search.size {
+		search.report()
+		return
+	}
+	if column == 0 && search.ctx.Err() != nil {
+		search.stopped = true
+		return
+	}
+
+	columnNode := search.columns[column]
+	for _, child := range rowNode.Children() {
+		letter := child.Letter()[0]
+		if !search.double && column < row && letter != search.grid[column][row] {
+			continue
+		}
+		below := columnNode.Child(letter)
+		if below == nil || (column == search.size-1 && !child.IsWord()) || (row == search.size-1 && !below.IsWord()) {
+			continue
+		}
+
+		search.grid[row][column] = letter
+		search.columns[column] = below
+		if column == search.size-1 {
+			search.fill(row+1, 0, search.root)
+		} else {
+			search.fill(row, column+1, child)
+		}
+		search.columns[column] = columnNode
+		if search.stopped {
+			return
+		}
+	}
+}
+
+// report passes a finished grid to found, unless it's a double square that repeats a word or, when transposes isn't set,
+// is the transpose of one that will be reported instead
+func (search *wordSquareSearch) report() {
+	rows := make([]string, search.size)
+	for row := range search.grid {
+		rows[row] = string(search.grid[row])
+	}
+	if search.double {
+		words := make(map[string]bool, 2*search.size)
+		for column := 0; column < search.size; column++ {
+			down := make([]byte, search.size)
+			for row := range search.grid {
+				down[row] = search.grid[row][column]
+			}
+			words[string(down)] = true
+			if column == 0 && !search.transposes && string(down) < rows[0] {
+				return
+			}
+		}
+		for _, word := range rows {
+			words[word] = true
+		}
+		if len(words) != 2*search.size {
+			return
+		}
+	}
+	search.stopped = !search.found(rows)
+}
+
+func init() {
+	wordSquareCmd.Flags().StringSliceVarP(&dictionaryFiles, "dictionary", "d", nil, "Dictionary file to use, or - to use stdin. Repeat it or separate files with commas to merge several")
+	wordSquareCmd.MarkFlagRequired("dictionary")
+	wordSquareCmd.Flags().BoolVarP(&wordSquareDouble, "double", "", false, "Find double word squares, whose columns are different words from their rows")
+	wordSquareCmd.Flags().IntVarP(&wordSquareMaxResults, "max-results", "", 0, "Stop after this many squares. Defaults to no limit")
+	rootCmd.AddCommand(wordSquareCmd)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFindWordSquares(test *testing.T) {
+	tests := []struct {
+		words    string
+		first    string
+		double   bool
+		expected string
+	}{
+		{"BIT ICE TEN TEA BIG", "", false, "BIT ICE TEA,BIT ICE TEN"},
+		{"BIT ICE TEN TEA BIG", "BIT", false, "BIT ICE TEA,BIT ICE TEN"},
+		// no word starts with G
+		{"BIT ICE TEN TEA BIG", "BIG", false, ""},
+		// BIT ICE TEN reads BIT ICE TEN down too, and BAT ICE TEA down is BIT ACE TEA, which repeats TEA
+		{"BIT ICE TEN TEA BAT ACE", "", true, ""},
+		// BOW ARE TED is the same square turned on its side
+		{"BAT ORE WED BOW ARE TED", "", true, "BAT ORE WED"},
+		{"BAT ORE WED BOW ARE TED", "BOW", true, "BOW ARE TED"},
+	}
+	for _, currentTest := range tests {
+		trie := newTrie()
+		for _, word := range strings.Fields(currentTest.words) {
+			trie.Add(word, nil)
+		}
+		squares := make([]string, 0)
+		findWordSquares(context.Background(), trie, 3, currentTest.first, currentTest.double, func(rows []string) bool {
+			squares = append(squares, strings.Join(rows, " "))
+			return true
+		})
+		if actual := strings.Join(squares, ","); actual != currentTest.expected {
+			test.Errorf("Expected %s for %s starting with %s but got %s", currentTest.expected, currentTest.words, currentTest.first, actual)
+		}
+	}
+}
+
+func TestFindWordSquaresStops(test *testing.T) {
+	trie := newTrie()
+	for _, word := range []string{"BIT", "ICE", "TEN", "TEA"} {
+		trie.Add(word, nil)
+	}
+	count := 0
+	findWordSquares(context.Background(), trie, 3, "", false, func(rows []string) bool {
+		count++
+		return false
+	})
+	if count != 1 {
+		test.Errorf("Expected the search to stop after the first square but got %d", count)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	findWordSquares(ctx, trie, 3, "", false, func(rows []string) bool {
+		test.Errorf("Expected no squares once the context is done but got %v", rows)
+		return true
+	})
+}
+
+func TestWordSquareStart(test *testing.T) {
+	if size, first := wordSquareStart("5"); size != 5 || first != "" {
+		test.Errorf("Expected a size of 5 but got %d and %s", size, first)
+	}
+	if size, first := wordSquareStart("heart"); size != 5 || first != "HEART" {
+		test.Errorf("Expected HEART but got %d and %s", size, first)
+	}
+}
@@ -0,0 +1,245 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var workspaceFile string
+var workspaceNote string
+
+// workspaceCmd represents the workspace command
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Keeps track of puzzles, the commands run against them, and notes in a state file",
+	Long: `A workspace is a JSON file holding named puzzles. Each puzzle has its text, any notes you've
+	added, and the output of every command you've run against it, so a solving session can be picked
+	back up later. The file defaults to puzzle_workspace.json in the current directory.
+
+	Examples:
+	  puzzle_helper workspace add puzzle1 GUVF VF N GRFG --note "looks like rot13"
+	  puzzle_helper workspace run puzzle1 cryptogram caesar
+	  puzzle_helper workspace show puzzle1`,
+}
+
+var workspaceAddCmd = &cobra.Command{
+	Use:   "add NAME [TEXT...]",
+	Short: "Adds a puzzle to the workspace, or updates its text and notes",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   addToWorkspace,
+}
+
+var workspaceRunCmd = &cobra.Command{
+	Use:   "run NAME COMMAND...",
+	Short: "Runs a puzzle_helper command with the puzzle's text as the trailing arguments and records its output",
+	Args:  cobra.MinimumNArgs(2),
+	// flags after the puzzle name belong to the command being run
+	DisableFlagParsing: true,
+	Run:                runInWorkspace,
+}
+
+var workspaceShowCmd = &cobra.Command{
+	Use:   "show [NAME]",
+	Short: "Lists the puzzles in the workspace, or shows everything recorded for one of them",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   showWorkspace,
+}
+
+type workspace struct {
+	Puzzles map[string]*workspacePuzzle `json:"puzzles"`
+}
+
+type workspacePuzzle struct {
+	Text  string         `json:"text"`
+	Notes []string       `json:"notes,omitempty"`
+	Runs  []workspaceRun `json:"runs,omitempty"`
+}
+
+type workspaceRun struct {
+	Command []string  `json:"command"`
+	Output  string    `json:"output"`
+	RanAt   time.Time `json:"ranAt"`
+}
+
+// loadWorkspace reads the workspace at path. A missing file is an empty workspace
+func loadWorkspace(path string) (*workspace, error) {
+	loaded := &workspace{make(map[string]*workspacePuzzle)}
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return loaded, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(contents, loaded)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid workspace: %v", path, err)
+	}
+	if loaded.Puzzles == nil {
+		loaded.Puzzles = make(map[string]*workspacePuzzle)
+	}
+	return loaded, nil
+}
+
+func (space *workspace) save(path string) error {
+	contents, err := json.MarshalIndent(space, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, contents, 0644)
+}
+
+// addPuzzle creates or updates the named puzzle. An empty text leaves existing text alone
+// and an empty note isn't recorded
+func (space *workspace) addPuzzle(name, text, note string) *workspacePuzzle {
+	puzzle, exists := space.Puzzles[name]
+	if !exists {
+		puzzle = &workspacePuzzle{}
+		space.Puzzles[name] = puzzle
+	}
+	if text != "" {
+		puzzle.Text = text
+	}
+	if note != "" {
+		puzzle.Notes = append(puzzle.Notes, note)
+	}
+	return puzzle
+}
+
+func mustLoadWorkspace() *workspace {
+	loaded, err := loadWorkspace(workspaceFile)
+	if err != nil {
+		fmt.Printf("Could not load workspace: %v\n", err)
+		os.Exit(1)
+	}
+	return loaded
+}
+
+func mustSaveWorkspace(space *workspace) {
+	err := space.save(workspaceFile)
+	if err != nil {
+		fmt.Printf("Could not save workspace to %s: %v\n", workspaceFile, err)
+		os.Exit(1)
+	}
+}
+
+func addToWorkspace(cmd *cobra.Command, args []string) {
+	space := mustLoadWorkspace()
+	space.addPuzzle(args[0], strings.Join(args[1:], " "), workspaceNote)
+	mustSaveWorkspace(space)
+}
+
+func runInWorkspace(cmd *cobra.Command, args []string) {
+	// flag parsing is turned off for this command, so the workspace flag has to be pulled out by hand
+	args = extractWorkspaceFlag(args)
+	if len(args) < 2 {
+		fmt.Println("A puzzle name and a command to run are required")
+		os.Exit(1)
+	}
+
+	space := mustLoadWorkspace()
+	puzzle, exists := space.Puzzles[args[0]]
+	if !exists {
+		fmt.Printf("No puzzle named %s in the workspace\n", args[0])
+		os.Exit(1)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		fmt.Printf("Could not find the puzzle_helper executable: %v\n", err)
+		os.Exit(1)
+	}
+
+	commandArgs := make([]string, 0, len(args))
+	commandArgs = append(commandArgs, args[1:]...)
+	commandArgs = append(commandArgs, strings.Fields(puzzle.Text)...)
+	output, err := exec.Command(executable, commandArgs...).CombinedOutput()
+	fmt.Print(string(output))
+	puzzle.Runs = append(puzzle.Runs, workspaceRun{args[1:], string(output), time.Now()})
+	mustSaveWorkspace(space)
+	if err != nil {
+		fmt.Printf("Command failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// extractWorkspaceFlag pulls a leading --workspace/-w and its value out of args, setting workspaceFile.
+// Only flags before the puzzle name are looked at so the command being run keeps all of its own
+func extractWorkspaceFlag(args []string) []string {
+	for len(args) > 0 {
+		if (args[0] == "--workspace" || args[0] == "-w") && len(args) > 1 {
+			workspaceFile = args[1]
+			args = args[2:]
+		} else if strings.HasPrefix(args[0], "--workspace=") {
+			workspaceFile = strings.TrimPrefix(args[0], "--workspace=")
+			args = args[1:]
+		} else {
+			break
+		}
+	}
+	return args
+}
+
+func showWorkspace(cmd *cobra.Command, args []string) {
+	space := mustLoadWorkspace()
+	if len(args) == 0 {
+		names := make([]string, 0, len(space.Puzzles))
+		for name := range space.Puzzles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("%s: %s\n", name, space.Puzzles[name].Text)
+		}
+		return
+	}
+
+	puzzle, exists := space.Puzzles[args[0]]
+	if !exists {
+		fmt.Printf("No puzzle named %s in the workspace\n", args[0])
+		os.Exit(1)
+	}
+	fmt.Printf("%s\n%s\n", args[0], puzzle.Text)
+	if len(puzzle.Notes) > 0 {
+		fmt.Println("\nNotes:")
+		for _, note := range puzzle.Notes {
+			fmt.Printf("  - %s\n", note)
+		}
+	}
+	for _, run := range puzzle.Runs {
+		fmt.Printf("\n[%s] %s\n%s", run.RanAt.Format(time.RFC3339), strings.Join(run.Command, " "), run.Output)
+	}
+}
+
+func init() {
+	workspaceCmd.PersistentFlags().StringVarP(&workspaceFile, "workspace", "w", "puzzle_workspace.json", "the workspace state file")
+	workspaceAddCmd.Flags().StringVarP(&workspaceNote, "note", "n", "", "a note to record with the puzzle")
+	workspaceCmd.AddCommand(workspaceAddCmd)
+	workspaceCmd.AddCommand(workspaceRunCmd)
+	workspaceCmd.AddCommand(workspaceShowCmd)
+	rootCmd.AddCommand(workspaceCmd)
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestWorkspaceSaveLoad(test *testing.T) {
+	path := filepath.Join(test.TempDir(), "workspace.json")
+
+	space, err := loadWorkspace(path)
+	if err != nil {
+		test.Fatalf("A missing workspace should load as empty but got %v", err)
+	}
+	if len(space.Puzzles) != 0 {
+		test.Errorf("Expected an empty workspace but got %d puzzles", len(space.Puzzles))
+	}
+
+	space.addPuzzle("first", "GUVF VF", "rot13?")
+	space.addPuzzle("first", "", "definitely rot13")
+	puzzle := space.addPuzzle("second", "XYZ", "")
+	puzzle.Runs = append(puzzle.Runs, workspaceRun{Command: []string{"cryptogram", "caesar"}, Output: "1. YZA\n"})
+
+	if err := space.save(path); err != nil {
+		test.Fatalf("Could not save workspace: %v", err)
+	}
+
+	loaded, err := loadWorkspace(path)
+	if err != nil {
+		test.Fatalf("Could not load workspace: %v", err)
+	}
+	first := loaded.Puzzles["first"]
+	if first == nil || first.Text != "GUVF VF" || len(first.Notes) != 2 {
+		test.Errorf("Expected first puzzle with its text and 2 notes but got %v", first)
+	}
+	second := loaded.Puzzles["second"]
+	if second == nil || len(second.Runs) != 1 || second.Runs[0].Output != "1. YZA\n" {
+		test.Errorf("Expected second puzzle with one recorded run but got %v", second)
+	}
+}
+
+func TestExtractWorkspaceFlag(test *testing.T) {
+	workspaceFile = "puzzle_workspace.json"
+	remaining := extractWorkspaceFlag([]string{"--workspace", "other.json", "puzzle1", "transposal", "-w", "2"})
+	if workspaceFile != "other.json" {
+		test.Errorf("Expected workspace file other.json but got %s", workspaceFile)
+	}
+	if len(remaining) != 4 || remaining[0] != "puzzle1" || remaining[2] != "-w" {
+		test.Errorf("Expected only the leading flag to be removed from the args but got %v", remaining)
+	}
+}